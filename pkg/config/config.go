@@ -21,6 +21,10 @@ type Config struct {
 
 // Setting sets base configurations.
 type Setting struct {
+	// LoggerFile is used when LoggerOut is file.
+	LoggerFile *LoggerFileSetting
+	// LoggerSyslog is used when LoggerOut is syslog.
+	LoggerSyslog          *LoggerSyslogSetting
 	LoggerFormatter       string
 	LoggerOut             string
 	LoggerLevel           string
@@ -28,7 +32,8 @@ type Setting struct {
 }
 
 // NewConfig sets base configurations.
-func NewConfig(setting *Setting) *Config {
+// It returns an error when the formatter, out or level is not supported.
+func NewConfig(setting *Setting) (*Config, error) {
 	config := &Config{
 		Logger:      nil,
 		SlackClient: nil,
@@ -79,22 +84,19 @@ func NewConfig(setting *Setting) *Config {
 			CallerPrettyfier:          nil,
 		}
 	default:
-		panic("Only json and text can be selected for formatter.")
-	}
-
-	// out
-	out := strings.ToLower(setting.LoggerOut)
-	switch out {
-	case "stdout":
-		l.Out = os.Stdout
-	default:
-		panic("Only stdout can be selected for out.")
+		return nil, fmt.Errorf("%w: %s", ErrInvalidLoggerFormatter, setting.LoggerFormatter)
 	}
 
 	// level
 	level, err := logrus.ParseLevel(setting.LoggerLevel)
 	if err != nil {
-		panic(fmt.Sprintf("level can't set %v", level))
+		return nil, fmt.Errorf("%w: %w", ErrInvalidLoggerLevel, err)
+	}
+
+	// out
+	err = setLoggerOut(l, setting.LoggerOut, setting.LoggerFile, setting.LoggerSyslog)
+	if err != nil {
+		return nil, err
 	}
 	l.Level = level
 	config.Logger = logger.NewLogger(l)
@@ -109,5 +111,5 @@ func NewConfig(setting *Setting) *Config {
 			},
 		)
 	}
-	return config
+	return config, nil
 }
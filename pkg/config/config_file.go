@@ -1,9 +1,11 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/y-miyazaki/go-common/pkg/infrastructure"
 	"github.com/y-miyazaki/go-common/pkg/logger"
@@ -13,15 +15,44 @@
 	"github.com/spf13/viper"
 )
 
+// defaultConfigFileSecretTimeout bounds the resolution of the secret references of NewConfigFile.
+const defaultConfigFileSecretTimeout = 30 * time.Second
+
 // FileSetting sets configurations.
 type FileSetting struct {
+	// SecretResolver replaces the secret references of the config file and of
+	// SlackOauthAccessToken with the secrets when it's set. Rotated secrets
+	// are not picked up unless SecretResolver.Run is started, e.g.
+	// go setting.SecretResolver.Run(ctx), or SecretResolver.Refresh is called.
+	SecretResolver        *SecretResolver
 	ConfigPath            string
 	ConfigFileName        string
 	SlackOauthAccessToken string
 }
 
 // NewConfigFile to read config
-func NewConfigFile(setting *FileSetting) *Config {
+//
+// The logger section of the config file supports the following keys:
+//
+//	logger:
+//	  formatter: json        # json or text
+//	  level: info
+//	  out: file              # stdout, stderr, file or syslog
+//	  file:
+//	    filename: /var/log/app.log
+//	    max_size: 100        # megabytes
+//	    max_age: 7           # days
+//	    max_backups: 3
+//	    compress: true
+//	    local_time: false
+//	  syslog:
+//	    network: udp         # empty for the local syslog server
+//	    address: localhost:514
+//	    tag: app
+//
+// String values may be secret references such as secretsmanager://prod/slack#token,
+// which are resolved by FileSetting.SecretResolver. See SecretResolver.
+func NewConfigFile(setting *FileSetting) (*Config, error) {
 	config := &Config{
 		Logger:      nil,
 		SlackClient: nil,
@@ -35,14 +66,17 @@ func NewConfigFile(setting *FileSetting) *Config {
 	viper.AutomaticEnv()
 	err := viper.ReadInConfig() // Find and read the config file
 	if err != nil {
-		panic("viper can't read config.")
+		return nil, fmt.Errorf("%w: %w", ErrReadConfig, err)
+	}
+	resolver := setting.SecretResolver
+	if resolver != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultConfigFileSecretTimeout)
+		err = resolver.ResolveViper(ctx, viper.GetViper())
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrResolveSecret, err)
+		}
 	}
-	viper.WatchConfig()
-	viper.OnConfigChange(func(e fsnotify.Event) {
-		// use logger instead of fmt.Println
-		log := logrus.New()
-		log.Infof("ConfigHandler file changed: %s", e.Name)
-	})
 	// -------------------------------------------------------------
 	// set Logger
 	// -------------------------------------------------------------
@@ -88,20 +122,31 @@ func NewConfigFile(setting *FileSetting) *Config {
 			CallerPrettyfier:          nil,
 		}
 	default:
-		panic("Only json and text can be selected for formatter.")
+		return nil, fmt.Errorf("%w: %s", ErrInvalidLoggerFormatter, formatter)
 	}
 
-	// out
-	out := strings.ToLower(viper.GetString("logger.out"))
-	if out == "stdout" {
-		l.Out = os.Stdout
-	} else {
-		panic("Only stdout can be selected for out.")
-	}
 	// level
 	level, err := logrus.ParseLevel(viper.GetString("logger.level"))
 	if err != nil {
-		panic(fmt.Sprintf("level can't set %v", level))
+		return nil, fmt.Errorf("%w: %w", ErrInvalidLoggerLevel, err)
+	}
+	// out
+	file := &LoggerFileSetting{
+		FileName:   viper.GetString("logger.file.filename"),
+		MaxSize:    viper.GetInt("logger.file.max_size"),
+		MaxAge:     viper.GetInt("logger.file.max_age"),
+		MaxBackups: viper.GetInt("logger.file.max_backups"),
+		Compress:   viper.GetBool("logger.file.compress"),
+		LocalTime:  viper.GetBool("logger.file.local_time"),
+	}
+	sys := &LoggerSyslogSetting{
+		Network: viper.GetString("logger.syslog.network"),
+		Address: viper.GetString("logger.syslog.address"),
+		Tag:     viper.GetString("logger.syslog.tag"),
+	}
+	err = setLoggerOut(l, viper.GetString("logger.out"), file, sys)
+	if err != nil {
+		return nil, err
 	}
 	l.Level = level
 	config.Logger = logger.NewLogger(l)
@@ -109,12 +154,39 @@ func NewConfigFile(setting *FileSetting) *Config {
 	// -------------------------------------------------------------
 	// set Slack
 	// -------------------------------------------------------------
-	if setting.SlackOauthAccessToken != "" {
+	slackOauthAccessToken := setting.SlackOauthAccessToken
+	if resolver != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultConfigFileSecretTimeout)
+		slackOauthAccessToken, err = resolver.Resolve(ctx, slackOauthAccessToken)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrResolveSecret, err)
+		}
+	}
+	if slackOauthAccessToken != "" {
 		config.SlackClient = infrastructure.NewSlack(
 			&infrastructure.SlackConfig{
-				OauthAccessToken: setting.SlackOauthAccessToken,
+				OauthAccessToken: slackOauthAccessToken,
 			},
 		)
 	}
-	return config
+
+	// -------------------------------------------------------------
+	// watch config
+	// -------------------------------------------------------------
+	// The watcher is registered last so that a failed call leaves no watcher running.
+	viper.WatchConfig()
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		// use logger instead of fmt.Println
+		log := logrus.New()
+		log.Infof("ConfigHandler file changed: %s", e.Name)
+		if resolver != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), defaultConfigFileSecretTimeout)
+			defer cancel()
+			if err := resolver.ResolveViper(ctx, viper.GetViper()); err != nil {
+				log.Errorf("ConfigHandler secret references can't be resolved: %v", err)
+			}
+		}
+	})
+	return config, nil
 }
@@ -5,6 +5,7 @@
 	"path/filepath"
 	"testing"
 
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -27,8 +28,9 @@ func TestNewConfigFile(t *testing.T) {
 		SlackOauthAccessToken: "",
 	}
 
-	config := NewConfigFile(setting)
+	config, err := NewConfigFile(setting)
 
+	assert.NoError(t, err)
 	assert.NotNil(t, config)
 	assert.NotNil(t, config.Logger)
 	assert.Nil(t, config.SlackClient)
@@ -53,8 +55,9 @@ func TestNewConfigFileWithSlack(t *testing.T) {
 		SlackOauthAccessToken: "test-token",
 	}
 
-	config := NewConfigFile(setting)
+	config, err := NewConfigFile(setting)
 
+	assert.NoError(t, err)
 	assert.NotNil(t, config)
 	assert.NotNil(t, config.Logger)
 	assert.NotNil(t, config.SlackClient)
@@ -67,9 +70,9 @@ func TestNewConfigFileInvalidConfig(t *testing.T) {
 		SlackOauthAccessToken: "",
 	}
 
-	assert.Panics(t, func() {
-		NewConfigFile(setting)
-	})
+	config, err := NewConfigFile(setting)
+	assert.Error(t, err)
+	assert.Nil(t, config)
 }
 
 func TestNewConfigFileInvalidFormatter(t *testing.T) {
@@ -90,15 +93,38 @@ func TestNewConfigFileInvalidFormatter(t *testing.T) {
 		SlackOauthAccessToken: "",
 	}
 
-	assert.Panics(t, func() {
-		NewConfigFile(setting)
-	})
+	config, err := NewConfigFile(setting)
+	assert.Error(t, err)
+	assert.Nil(t, config)
 }
 
 func TestNewConfigFileInvalidOut(t *testing.T) {
 	dir := t.TempDir()
 	configPath := dir + "/config.yaml"
 	configContent := `
+logger:
+  formatter: json
+  out: kafka
+  level: info
+`
+	err := os.WriteFile(configPath, []byte(configContent), 0o644)
+	assert.NoError(t, err)
+
+	setting := &FileSetting{
+		ConfigPath:            dir,
+		ConfigFileName:        "config",
+		SlackOauthAccessToken: "",
+	}
+
+	config, err := NewConfigFile(setting)
+	assert.Error(t, err)
+	assert.Nil(t, config)
+}
+
+func TestNewConfigFileStderr(t *testing.T) {
+	dir := t.TempDir()
+	configPath := dir + "/config.yaml"
+	configContent := `
 logger:
   formatter: json
   out: stderr
@@ -113,7 +139,44 @@ func TestNewConfigFileInvalidOut(t *testing.T) {
 		SlackOauthAccessToken: "",
 	}
 
-	assert.Panics(t, func() {
-		NewConfigFile(setting)
-	})
+	config, err := NewConfigFile(setting)
+	assert.NoError(t, err)
+	assert.Equal(t, os.Stderr, config.Logger.Entry.Logger.Out)
+}
+
+func TestNewConfigFileWithSecretResolver(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	configContent := `
+logger:
+  formatter: json
+  out: stdout
+  level: info
+database:
+  password: secretsmanager://prod/db#password
+`
+	err := os.WriteFile(configPath, []byte(configContent), 0o644)
+	assert.NoError(t, err)
+
+	secrets := &testSecretGetter{secrets: map[string]string{
+		"prod/db":    `{"password":"pass"}`,
+		"prod/slack": "xoxb-token",
+	}}
+	setting := &FileSetting{
+		SecretResolver:        NewSecretResolver(secrets),
+		ConfigPath:            dir,
+		ConfigFileName:        "config",
+		SlackOauthAccessToken: "secretsmanager://prod/slack",
+	}
+
+	config, err := NewConfigFile(setting)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, config.SlackClient)
+	assert.Equal(t, "pass", viper.GetString("database.password"))
+
+	setting.SlackOauthAccessToken = "secretsmanager://missing"
+	config, err = NewConfigFile(setting)
+	assert.ErrorIs(t, err, ErrResolveSecret)
+	assert.Nil(t, config)
 }
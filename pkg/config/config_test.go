@@ -14,8 +14,9 @@ func TestNewConfig(t *testing.T) {
 		SlackOauthAccessToken: "",
 	}
 
-	config := NewConfig(setting)
+	config, err := NewConfig(setting)
 
+	assert.NoError(t, err)
 	assert.NotNil(t, config)
 	assert.NotNil(t, config.Logger)
 	assert.Nil(t, config.SlackClient)
@@ -29,8 +30,9 @@ func TestNewConfigWithSlack(t *testing.T) {
 		SlackOauthAccessToken: "test-token",
 	}
 
-	config := NewConfig(setting)
+	config, err := NewConfig(setting)
 
+	assert.NoError(t, err)
 	assert.NotNil(t, config)
 	assert.NotNil(t, config.Logger)
 	assert.NotNil(t, config.SlackClient)
@@ -44,9 +46,9 @@ func TestNewConfigInvalidFormatter(t *testing.T) {
 		SlackOauthAccessToken: "",
 	}
 
-	assert.Panics(t, func() {
-		NewConfig(setting)
-	})
+	config, err := NewConfig(setting)
+	assert.Error(t, err)
+	assert.Nil(t, config)
 }
 
 func TestNewConfigInvalidOut(t *testing.T) {
@@ -57,9 +59,9 @@ func TestNewConfigInvalidOut(t *testing.T) {
 		SlackOauthAccessToken: "",
 	}
 
-	assert.Panics(t, func() {
-		NewConfig(setting)
-	})
+	config, err := NewConfig(setting)
+	assert.Error(t, err)
+	assert.Nil(t, config)
 }
 
 func TestNewConfigInvalidLevel(t *testing.T) {
@@ -70,7 +72,21 @@ func TestNewConfigInvalidLevel(t *testing.T) {
 		SlackOauthAccessToken: "",
 	}
 
-	assert.Panics(t, func() {
-		NewConfig(setting)
-	})
+	config, err := NewConfig(setting)
+	assert.Error(t, err)
+	assert.Nil(t, config)
+}
+
+func TestNewConfigOutFile(t *testing.T) {
+	fileName := t.TempDir() + "/app.log"
+	setting := &Setting{
+		LoggerFormatter: "json",
+		LoggerOut:       "file",
+		LoggerLevel:     "info",
+		LoggerFile:      &LoggerFileSetting{FileName: fileName},
+	}
+
+	config, err := NewConfig(setting)
+	assert.NoError(t, err)
+	assert.NotNil(t, config.Logger)
 }
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Log output destinations selectable by Setting.LoggerOut and logger.out.
+const (
+	LoggerOutStdout = "stdout"
+	LoggerOutStderr = "stderr"
+	LoggerOutFile   = "file"
+	LoggerOutSyslog = "syslog"
+)
+
+var (
+	// ErrInvalidLoggerFormatter indicates that the logger formatter is not supported.
+	ErrInvalidLoggerFormatter = errors.New("only json and text can be selected for formatter")
+	// ErrInvalidLoggerOut indicates that the logger out is not supported.
+	ErrInvalidLoggerOut = errors.New("only stdout, stderr, file and syslog can be selected for out")
+	// ErrInvalidLoggerLevel indicates that the logger level can't be parsed.
+	ErrInvalidLoggerLevel = errors.New("level can't set")
+	// ErrLoggerFileNameEmpty indicates that out is file but no file name is set.
+	ErrLoggerFileNameEmpty = errors.New("file name must be set when out is file")
+	// ErrSyslogNotSupported indicates that syslog is not available on this platform.
+	ErrSyslogNotSupported = errors.New("syslog is not supported on this platform")
+	// ErrReadConfig indicates that the config file can't be read.
+	ErrReadConfig = errors.New("viper can't read config")
+)
+
+// LoggerFileSetting sets configurations of the rotating file output.
+type LoggerFileSetting struct {
+	// FileName is the file to write logs to. Backups use the same directory.
+	FileName string
+	// MaxSize is the maximum size in megabytes before the file is rotated. Default: 100.
+	MaxSize int
+	// MaxAge is the maximum number of days to retain old files. 0 keeps them forever.
+	MaxAge int
+	// MaxBackups is the maximum number of old files to retain. 0 retains all.
+	MaxBackups int
+	// Compress determines if rotated files are compressed using gzip.
+	Compress bool
+	// LocalTime uses local time instead of UTC for backup file names.
+	LocalTime bool
+}
+
+// LoggerSyslogSetting sets configurations of the syslog output.
+type LoggerSyslogSetting struct {
+	// Network is "udp" or "tcp". Empty connects to the local syslog server.
+	Network string
+	// Address is the remote syslog server address (host:port).
+	Address string
+	// Tag is the syslog tag. Default: program name.
+	Tag string
+}
+
+// setLoggerOut sets the output of l according to out.
+// syslog output is implemented as a hook that maps logrus levels to syslog
+// severities, so l.Out is discarded in that case.
+func setLoggerOut(l *logrus.Logger, out string, file *LoggerFileSetting, sys *LoggerSyslogSetting) error {
+	switch strings.ToLower(out) {
+	case LoggerOutStdout:
+		l.Out = os.Stdout
+	case LoggerOutStderr:
+		l.Out = os.Stderr
+	case LoggerOutFile:
+		if file == nil || file.FileName == "" {
+			return ErrLoggerFileNameEmpty
+		}
+		l.Out = &lumberjack.Logger{
+			Filename:   file.FileName,
+			MaxSize:    file.MaxSize,
+			MaxAge:     file.MaxAge,
+			MaxBackups: file.MaxBackups,
+			LocalTime:  file.LocalTime,
+			Compress:   file.Compress,
+		}
+	case LoggerOutSyslog:
+		syslogSetting := sys
+		if syslogSetting == nil {
+			syslogSetting = &LoggerSyslogSetting{}
+		}
+		hook, err := newSyslogHook(syslogSetting)
+		if err != nil {
+			return err
+		}
+		l.Out = io.Discard
+		l.AddHook(hook)
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidLoggerOut, out)
+	}
+	return nil
+}
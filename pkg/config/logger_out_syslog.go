@@ -0,0 +1,20 @@
+//go:build !windows && !plan9
+
+package config
+
+import (
+	"fmt"
+	"log/syslog"
+
+	"github.com/sirupsen/logrus"
+	lsyslog "github.com/sirupsen/logrus/hooks/syslog"
+)
+
+// newSyslogHook connects to the syslog server and returns a hook writing entries to it.
+func newSyslogHook(s *LoggerSyslogSetting) (logrus.Hook, error) {
+	hook, err := lsyslog.NewSyslogHook(s.Network, s.Address, syslog.LOG_INFO|syslog.LOG_USER, s.Tag)
+	if err != nil {
+		return nil, fmt.Errorf("syslog dial: %w", err)
+	}
+	return hook, nil
+}
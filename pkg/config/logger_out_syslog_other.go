@@ -0,0 +1,12 @@
+//go:build windows || plan9
+
+package config
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// newSyslogHook returns ErrSyslogNotSupported because log/syslog is not available.
+func newSyslogHook(_ *LoggerSyslogSetting) (logrus.Hook, error) {
+	return nil, ErrSyslogNotSupported
+}
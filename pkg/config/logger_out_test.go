@@ -0,0 +1,70 @@
+package config
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetLoggerOut(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want io.Writer
+	}{
+		{name: "stdout", out: "stdout", want: os.Stdout},
+		{name: "stderr", out: "STDERR", want: os.Stderr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := logrus.New()
+			err := setLoggerOut(l, tt.out, nil, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, l.Out)
+		})
+	}
+}
+
+func TestSetLoggerOut_File(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "app.log")
+	l := logrus.New()
+	err := setLoggerOut(l, LoggerOutFile, &LoggerFileSetting{
+		FileName:   fileName,
+		MaxSize:    1,
+		MaxAge:     1,
+		MaxBackups: 1,
+		Compress:   true,
+	}, nil)
+	assert.NoError(t, err)
+
+	l.Info("hello")
+	b, err := os.ReadFile(fileName)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "hello")
+}
+
+func TestSetLoggerOut_FileNameEmpty(t *testing.T) {
+	err := setLoggerOut(logrus.New(), LoggerOutFile, nil, nil)
+	assert.ErrorIs(t, err, ErrLoggerFileNameEmpty)
+}
+
+func TestSetLoggerOut_Syslog(t *testing.T) {
+	l := logrus.New()
+	err := setLoggerOut(l, LoggerOutSyslog, nil, &LoggerSyslogSetting{
+		Network: "udp",
+		Address: "127.0.0.1:514",
+		Tag:     "test",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, io.Discard, l.Out)
+	assert.Len(t, l.Hooks[logrus.InfoLevel], 1)
+}
+
+func TestSetLoggerOut_Invalid(t *testing.T) {
+	err := setLoggerOut(logrus.New(), "kafka", nil, nil)
+	assert.ErrorIs(t, err, ErrInvalidLoggerOut)
+}
@@ -0,0 +1,312 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/y-miyazaki/go-common/pkg/repository"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// SecretReferenceSecretsManager is the prefix of references to Secrets Manager secrets,
+	// e.g. "secretsmanager://prod/db#password".
+	SecretReferenceSecretsManager = "secretsmanager://"
+	// SecretReferenceSSM is the prefix of references to Parameter Store parameters,
+	// e.g. "ssm:///prod/slack/token".
+	SecretReferenceSSM = "ssm://"
+
+	defaultSecretResolverRefreshInterval = 5 * time.Minute
+)
+
+var (
+	// ErrSecretReferenceInvalid indicates that a secret reference has no name.
+	ErrSecretReferenceInvalid = errors.New("secret reference has no name")
+	// ErrSecretJSONKeyNotFound indicates that the JSON key of a secret reference is not in the secret.
+	ErrSecretJSONKeyNotFound = errors.New("secret JSON key not found")
+	// ErrSecretParameterGetterNotSet indicates that an ssm:// reference is resolved without a ParameterGetter.
+	ErrSecretParameterGetterNotSet = errors.New("ssm references require a ParameterGetter")
+	// ErrResolveSecret indicates that the secret references of the config can't be resolved.
+	ErrResolveSecret = errors.New("secret references can't be resolved")
+)
+
+// SecretGetter gets secrets from Secrets Manager.
+// *repository.AWSSecretsManagerRepository and *repository.AWSSecretsManagerCache implement it.
+type SecretGetter interface {
+	// GetSecret gets a version of a secret. A nil version is the AWSCURRENT version.
+	GetSecret(ctx context.Context, secretName string, version *repository.AWSSecretsManagerVersion) (*repository.AWSSecretsManagerSecret, error)
+}
+
+// ParameterGetter gets parameters from Parameter Store. *repository.AWSSSMRepository implements it.
+type ParameterGetter interface {
+	// GetParameter gets a parameter, decrypted if it's a SecureString.
+	GetParameter(ctx context.Context, name string) (*repository.AWSSSMParameter, error)
+}
+
+// SecretResolverConfig sets configurations.
+type SecretResolverConfig struct {
+	// Parameters gets the parameters of ssm:// references.
+	Parameters ParameterGetter
+	// OnChange is called by Refresh with the keys whose secrets changed, e.g. after a rotation.
+	OnChange func(key string)
+	// OnError is called with the errors of references that fail to resolve in ResolveViper, Refresh and Run.
+	OnError func(key string, err error)
+	// RefreshInterval is the interval of background refreshes by Run. Default: 5m.
+	RefreshInterval time.Duration
+}
+
+// SecretResolver replaces secret references in config values with the secrets.
+//
+// A reference is a whole string value of one of the following forms. An optional
+// #key selects a key of a JSON secret:
+//
+//	secretsmanager://prod/db#password
+//	secretsmanager://arn:aws:secretsmanager:us-east-1:123456789012:secret:prod/db-AbCdEf
+//	ssm:///prod/slack/token
+//
+// The references of a viper config are remembered so that Refresh and Run
+// pick up rotated secrets, which are set on the viper config again. Run isn't
+// started by ResolveViper: start it to refresh in the background. With
+// AWSSecretsManagerCache as SecretGetter, rotations are seen after its TTL.
+//
+// SecretResolver is safe for concurrent use, but viper is not: reading the
+// viper config while Run sets a rotated secret is a data race. Read secrets
+// that rotate with Get, or when OnChange is called.
+type SecretResolver struct {
+	secrets    SecretGetter
+	config     SecretResolverConfig
+	mu         sync.RWMutex
+	viper      *viper.Viper
+	fromFile   bool
+	references map[string]string
+	values     map[string]resolvedSecret
+}
+
+// resolvedSecret is the secret of a reference.
+type resolvedSecret struct {
+	reference string
+	value     string
+}
+
+// NewSecretResolver returns SecretResolver instance.
+func NewSecretResolver(secrets SecretGetter, cfgs ...*SecretResolverConfig) *SecretResolver {
+	conf := SecretResolverConfig{}
+	if len(cfgs) > 0 && cfgs[0] != nil {
+		conf = *cfgs[0]
+	}
+	if conf.RefreshInterval <= 0 {
+		conf.RefreshInterval = defaultSecretResolverRefreshInterval
+	}
+	return &SecretResolver{
+		secrets:    secrets,
+		config:     conf,
+		references: map[string]string{},
+		values:     map[string]resolvedSecret{},
+	}
+}
+
+// IsSecretReference reports whether value is a secret reference.
+func IsSecretReference(value string) bool {
+	return strings.HasPrefix(value, SecretReferenceSecretsManager) || strings.HasPrefix(value, SecretReferenceSSM)
+}
+
+// Resolve returns the secret of a reference. Values that are not references are returned as is.
+func (r *SecretResolver) Resolve(ctx context.Context, value string) (string, error) {
+	var name, secret string
+	switch {
+	case strings.HasPrefix(value, SecretReferenceSecretsManager):
+		name = strings.TrimPrefix(value, SecretReferenceSecretsManager)
+	case strings.HasPrefix(value, SecretReferenceSSM):
+		name = strings.TrimPrefix(value, SecretReferenceSSM)
+	default:
+		return value, nil
+	}
+	name, jsonKey, hasJSONKey := strings.Cut(name, "#")
+	if name == "" {
+		return "", fmt.Errorf("%s: %w", value, ErrSecretReferenceInvalid)
+	}
+
+	if strings.HasPrefix(value, SecretReferenceSecretsManager) {
+		s, err := r.secrets.GetSecret(ctx, name, nil)
+		if err != nil {
+			return "", err
+		}
+		secret = s.Value
+	} else {
+		if r.config.Parameters == nil {
+			return "", fmt.Errorf("%s: %w", value, ErrSecretParameterGetterNotSet)
+		}
+		p, err := r.config.Parameters.GetParameter(ctx, name)
+		if err != nil {
+			return "", err
+		}
+		secret = p.Value
+	}
+	if !hasJSONKey {
+		return secret, nil
+	}
+	return secretJSONValue(name, secret, jsonKey)
+}
+
+// ResolveViper replaces the references in the values of v with the secrets and
+// remembers them for Refresh, replacing the references of a previous call.
+// The references are read from a new viper loaded from the config file of v,
+// so that the secrets set on v are not taken for config values, or from v
+// itself when it has no config file. The secrets of references removed from
+// the config file are unset, so that v returns the values of the file again.
+// NewConfigFile calls it with the global viper when FileSetting.SecretResolver
+// is set, and again when the config file changes.
+func (r *SecretResolver) ResolveViper(ctx context.Context, v *viper.Viper) error {
+	var file *viper.Viper
+	if path := v.ConfigFileUsed(); path != "" {
+		file = viper.New()
+		file.SetConfigFile(path)
+		if err := file.ReadInConfig(); err != nil {
+			return fmt.Errorf("read config %s: %w", path, err)
+		}
+	}
+
+	r.mu.Lock()
+	if r.viper != v {
+		r.viper = v
+		clear(r.values)
+	}
+	r.fromFile = file != nil
+	source := file
+	if source == nil {
+		source = v
+	}
+	references := map[string]string{}
+	for _, key := range source.AllKeys() {
+		value, _ := source.Get(key).(string)
+		switch {
+		case IsSecretReference(value):
+			references[key] = value
+		case file == nil:
+			// Without a config file, v has the secrets that replaced its references.
+			if previous, ok := r.values[key]; ok && previous.value == value {
+				references[key] = previous.reference
+			}
+		}
+	}
+	r.references = references
+	r.mu.Unlock()
+	return r.Refresh(ctx)
+}
+
+// Get returns the secret of the reference of key resolved by ResolveViper or
+// Refresh. It returns false if key has no reference or it hasn't been resolved.
+func (r *SecretResolver) Get(key string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	secret, ok := r.values[strings.ToLower(key)]
+	return secret.value, ok
+}
+
+// Refresh resolves the references of the viper config again, sets the secrets
+// that changed on it and calls OnChange for their keys. A reference that fails
+// to resolve keeps its previous value. The references are resolved without
+// holding the lock, which is only held to update the secrets.
+func (r *SecretResolver) Refresh(ctx context.Context) error {
+	r.mu.RLock()
+	references := r.references
+	r.mu.RUnlock()
+
+	resolved := make(map[string]resolvedSecret, len(references))
+	var errs []error
+	for key, reference := range references {
+		value, err := r.Resolve(ctx, reference)
+		if err != nil {
+			r.onError(key, err)
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		resolved[key] = resolvedSecret{reference: reference, value: value}
+	}
+
+	// The references may have been replaced by ResolveViper meanwhile: only
+	// the secrets of current references are kept.
+	var changed []string
+	r.mu.Lock()
+	values := make(map[string]resolvedSecret, len(r.references))
+	for key, reference := range r.references {
+		previous, hasPrevious := r.values[key]
+		secret, ok := resolved[key]
+		if !ok || secret.reference != reference {
+			if hasPrevious && previous.reference == reference {
+				values[key] = previous
+			}
+			continue
+		}
+		if hasPrevious && previous.value != secret.value {
+			changed = append(changed, key)
+		}
+		if r.viper.GetString(key) != secret.value {
+			r.viper.Set(key, secret.value)
+		}
+		values[key] = secret
+	}
+	if r.fromFile {
+		// A nil override makes viper return the value of the config file.
+		for key := range r.values {
+			if _, ok := values[key]; !ok {
+				r.viper.Set(key, nil)
+			}
+		}
+	}
+	r.values = values
+	r.mu.Unlock()
+
+	if r.config.OnChange != nil {
+		for _, key := range changed {
+			r.config.OnChange(key)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run refreshes the references every RefreshInterval until ctx is done.
+// Errors are reported to OnError and don't stop the refreshes.
+func (r *SecretResolver) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = r.Refresh(ctx)
+		}
+	}
+}
+
+// onError calls OnError if it's set.
+func (r *SecretResolver) onError(key string, err error) {
+	if r.config.OnError != nil {
+		r.config.OnError(key, err)
+	}
+}
+
+// secretJSONValue returns the value of jsonKey of the JSON object secret.
+// Values that are not strings are returned as JSON.
+func secretJSONValue(name, secret, jsonKey string) (string, error) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(secret), &object); err != nil {
+		return "", fmt.Errorf("unmarshal secret %s: %w", name, err)
+	}
+	raw, ok := object[jsonKey]
+	if !ok {
+		return "", fmt.Errorf("%s#%s: %w", name, jsonKey, ErrSecretJSONKeyNotFound)
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s, nil
+	}
+	return string(raw), nil
+}
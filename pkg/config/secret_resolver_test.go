@@ -0,0 +1,226 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/y-miyazaki/go-common/pkg/repository"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSecretGetter returns the values of secrets by name.
+type testSecretGetter struct {
+	mu      sync.Mutex
+	secrets map[string]string
+	calls   int
+}
+
+func (g *testSecretGetter) GetSecret(_ context.Context, secretName string, _ *repository.AWSSecretsManagerVersion) (*repository.AWSSecretsManagerSecret, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.calls++
+	value, ok := g.secrets[secretName]
+	if !ok {
+		return nil, errors.New("secretsmanager GetSecretValue: not found")
+	}
+	return &repository.AWSSecretsManagerSecret{Name: secretName, Value: value}, nil
+}
+
+func (g *testSecretGetter) set(secretName, value string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.secrets[secretName] = value
+}
+
+// testParameterGetter returns the values of parameters by name.
+type testParameterGetter map[string]string
+
+func (g testParameterGetter) GetParameter(_ context.Context, name string) (*repository.AWSSSMParameter, error) {
+	value, ok := g[name]
+	if !ok {
+		return nil, errors.New("ssm GetParameter: not found")
+	}
+	return &repository.AWSSSMParameter{Name: name, Value: value}, nil
+}
+
+func TestSecretResolver_Resolve(t *testing.T) {
+	secrets := &testSecretGetter{secrets: map[string]string{
+		"prod/db":    `{"username":"app","password":"pass","port":5432}`,
+		"prod/slack": "xoxb-token",
+	}}
+	resolver := NewSecretResolver(secrets, &SecretResolverConfig{
+		Parameters: testParameterGetter{"/prod/api": `{"key":"api-key"}`},
+	})
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr error
+	}{
+		{name: "plain", value: "info", want: "info"},
+		{name: "secret", value: "secretsmanager://prod/slack", want: "xoxb-token"},
+		{name: "json key", value: "secretsmanager://prod/db#password", want: "pass"},
+		{name: "json number", value: "secretsmanager://prod/db#port", want: "5432"},
+		{name: "parameter", value: "ssm:///prod/api", want: `{"key":"api-key"}`},
+		{name: "parameter json key", value: "ssm:///prod/api#key", want: "api-key"},
+		{name: "json key not found", value: "secretsmanager://prod/db#host", wantErr: ErrSecretJSONKeyNotFound},
+		{name: "no name", value: "secretsmanager://#password", wantErr: ErrSecretReferenceInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolver.Resolve(context.Background(), tt.value)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := NewSecretResolver(secrets).Resolve(context.Background(), "ssm:///prod/api")
+	require.ErrorIs(t, err, ErrSecretParameterGetterNotSet)
+	_, err = resolver.Resolve(context.Background(), "secretsmanager://missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func TestSecretResolver_ResolveViper(t *testing.T) {
+	secrets := &testSecretGetter{secrets: map[string]string{"prod/db": `{"password":"old"}`}}
+	var changed, failed []string
+	resolver := NewSecretResolver(secrets, &SecretResolverConfig{
+		OnChange: func(key string) { changed = append(changed, key) },
+		OnError:  func(key string, _ error) { failed = append(failed, key) },
+	})
+	v := viper.New()
+	v.Set("database.host", "localhost")
+	v.Set("database.password", "secretsmanager://prod/db#password")
+	v.Set("database.port", 5432)
+
+	require.NoError(t, resolver.ResolveViper(context.Background(), v))
+	assert.Equal(t, "old", v.GetString("database.password"))
+	assert.Equal(t, "localhost", v.GetString("database.host"))
+	value, ok := resolver.Get("database.password")
+	assert.True(t, ok)
+	assert.Equal(t, "old", value)
+	_, ok = resolver.Get("database.host")
+	assert.False(t, ok)
+	assert.Empty(t, changed)
+
+	// The remembered references pick up rotated secrets.
+	secrets.set("prod/db", `{"password":"new"}`)
+	require.NoError(t, resolver.Refresh(context.Background()))
+	assert.Equal(t, "new", v.GetString("database.password"))
+	assert.Equal(t, []string{"database.password"}, changed)
+
+	// A failed refresh keeps the previous secret.
+	secrets.set("prod/db", `not json`)
+	require.Error(t, resolver.Refresh(context.Background()))
+	assert.Equal(t, "new", v.GetString("database.password"))
+	assert.Equal(t, []string{"database.password"}, failed)
+	assert.Equal(t, []string{"database.password"}, changed)
+}
+
+func TestSecretResolver_ResolveViperConfigFile(t *testing.T) {
+	secrets := &testSecretGetter{secrets: map[string]string{"prod/db": "pass", "prod/slack": "token"}}
+	resolver := NewSecretResolver(secrets)
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("database:\n  password: secretsmanager://prod/db\nslack:\n  token: secretsmanager://prod/slack\n"), 0o600))
+	v := viper.New()
+	v.SetConfigFile(configPath)
+	require.NoError(t, v.ReadInConfig())
+
+	require.NoError(t, resolver.ResolveViper(context.Background(), v))
+	assert.Equal(t, "pass", v.GetString("database.password"))
+	assert.Equal(t, "token", v.GetString("slack.token"))
+
+	// A reference removed from the file is dropped and v returns the value of the file again.
+	require.NoError(t, os.WriteFile(configPath, []byte("database:\n  password: plain\n"), 0o600))
+	require.NoError(t, v.ReadInConfig())
+	require.NoError(t, resolver.ResolveViper(context.Background(), v))
+	assert.Equal(t, "plain", v.GetString("database.password"))
+	assert.Empty(t, v.GetString("slack.token"))
+	_, ok := resolver.Get("slack.token")
+	assert.False(t, ok)
+
+	// The secrets set on v are not taken for config values by the next call.
+	require.NoError(t, os.WriteFile(configPath, []byte("database:\n  password: secretsmanager://prod/db\n"), 0o600))
+	require.NoError(t, v.ReadInConfig())
+	require.NoError(t, resolver.ResolveViper(context.Background(), v))
+	secrets.set("prod/db", "rotated")
+	require.NoError(t, resolver.ResolveViper(context.Background(), v))
+	assert.Equal(t, "rotated", v.GetString("database.password"))
+}
+
+func TestSecretResolver_Race(t *testing.T) {
+	secrets := &testSecretGetter{secrets: map[string]string{"prod/db": "0"}}
+	resolver := NewSecretResolver(secrets, &SecretResolverConfig{RefreshInterval: time.Millisecond})
+	v := viper.New()
+	v.Set("database.password", "secretsmanager://prod/db")
+	require.NoError(t, resolver.ResolveViper(context.Background(), v))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var wg sync.WaitGroup
+	wg.Go(func() { resolver.Run(ctx) })
+	wg.Go(func() {
+		for i := 0; ctx.Err() == nil; i++ {
+			secrets.set("prod/db", strconv.Itoa(i))
+			assert.NoError(t, resolver.ResolveViper(ctx, v))
+		}
+	})
+	wg.Go(func() {
+		for ctx.Err() == nil {
+			_, ok := resolver.Get("database.password")
+			assert.True(t, ok)
+		}
+	})
+	wg.Wait()
+}
+
+func TestSecretResolver_Run(t *testing.T) {
+	secrets := &testSecretGetter{secrets: map[string]string{"prod/slack": "old"}}
+	changed := make(chan string, 1)
+	resolver := NewSecretResolver(secrets, &SecretResolverConfig{
+		RefreshInterval: 5 * time.Millisecond,
+		OnChange:        func(key string) { changed <- key },
+	})
+	v := viper.New()
+	v.Set("slack.token", "secretsmanager://prod/slack")
+	require.NoError(t, resolver.ResolveViper(context.Background(), v))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		resolver.Run(ctx)
+	}()
+	secrets.set("prod/slack", "new")
+
+	select {
+	case key := <-changed:
+		assert.Equal(t, "slack.token", key)
+	case <-time.After(time.Second):
+		t.Fatal("secret was not refreshed")
+	}
+	cancel()
+	<-done
+	assert.Equal(t, "new", v.GetString("slack.token"))
+}
+
+func TestIsSecretReference(t *testing.T) {
+	assert.True(t, IsSecretReference("secretsmanager://prod/db#password"))
+	assert.True(t, IsSecretReference("ssm:///prod/api"))
+	assert.False(t, IsSecretReference("https://example.com"))
+	assert.False(t, IsSecretReference(""))
+}
@@ -4,11 +4,16 @@
 import (
 	"archive/zip"
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"iter"
 	"net/http"
 
+	"github.com/y-miyazaki/go-common/pkg/dto"
 	"github.com/y-miyazaki/go-common/pkg/gincontext"
 	"github.com/y-miyazaki/go-common/pkg/logger"
+	"github.com/y-miyazaki/go-common/pkg/utils/aws/awserror"
 
 	"github.com/gin-gonic/gin"
 )
@@ -18,6 +23,15 @@ type BaseHTTPHandler struct {
 	Logger *logger.Logger
 }
 
+// RequestLogger returns the request-scoped logger stored by
+// middleware.GinRequestLogger, falling back to h.Logger when none is stored.
+func (h *BaseHTTPHandler) RequestLogger(c *gin.Context) *logger.Logger {
+	if c.Request == nil {
+		return h.Logger
+	}
+	return logger.FromContextOr(c.Request.Context(), h.Logger)
+}
+
 // ResponseCSV responses csv data.
 func (h *BaseHTTPHandler) ResponseCSV(c *gin.Context, statusCode int, fileName string, data []byte) {
 	// reference receiver to satisfy linters when receiver is unused
@@ -27,6 +41,63 @@ func (h *BaseHTTPHandler) ResponseCSV(c *gin.Context, statusCode int, fileName s
 	c.Data(statusCode, "text/csv", data)
 }
 
+// StreamChunk is an event of a stream relayed by ResponseStream.
+type StreamChunk struct {
+	// Data is sent as JSON.
+	Data any
+	// Event is the name of the Server-Sent Event.
+	Event string
+}
+
+// StreamChunks returns the events of a stream as StreamChunks named by name,
+// e.g. for repository.AWSBedrockRepository.ConverseStream:
+//
+//	handler.StreamChunks(events, func(e repository.AWSBedrockStreamEvent) string { return string(e.Type) })
+func StreamChunks[T any](events iter.Seq2[T, error], name func(T) string) iter.Seq2[StreamChunk, error] {
+	return func(yield func(StreamChunk, error) bool) {
+		for event, err := range events {
+			if err != nil {
+				yield(StreamChunk{}, err)
+				return
+			}
+			if !yield(StreamChunk{Data: event, Event: name(event)}, nil) {
+				return
+			}
+		}
+	}
+}
+
+// ResponseStream relays chunks to the client as Server-Sent Events with JSON data.
+// An error of the stream is sent as an "error" event without details and returned.
+// When the client disconnects, the iteration is stopped, which closes the stream.
+// Pass c.Request.Context() to the stream so that a pending read is also canceled.
+func (h *BaseHTTPHandler) ResponseStream(c *gin.Context, chunks iter.Seq2[StreamChunk, error]) error {
+	_ = h
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	ctx := c.Request.Context()
+	for chunk, err := range chunks {
+		if ctx.Err() != nil {
+			return fmt.Errorf("stream: %w", ctx.Err())
+		}
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				c.SSEvent("error", &dto.HTTPErrorResponse{Message: http.StatusText(HTTPStatusFromError(err))})
+				c.Writer.Flush()
+			}
+			return fmt.Errorf("stream: %w", err)
+		}
+		c.SSEvent(chunk.Event, chunk.Data)
+		c.Writer.Flush()
+	}
+	return nil
+}
+
 // ResponseStatusBadRequest returns 400 error.
 func (h *BaseHTTPHandler) ResponseStatusBadRequest(c *gin.Context, messages any) {
 	_ = h
@@ -39,6 +110,29 @@ func (h *BaseHTTPHandler) ResponseStatusForbidden(c *gin.Context, messages any)
 	c.JSON(http.StatusForbidden, messages)
 }
 
+// ResponseError returns the HTTP status of err classified by HTTPStatusFromError
+// with dto.HTTPBaseErrorResponse. 5xx errors are recorded in the gin context
+// like ResponseStatusInternalServerError, and their details are not exposed.
+func (h *BaseHTTPHandler) ResponseError(c *gin.Context, err error) {
+	status := HTTPStatusFromError(err)
+	messages := &dto.HTTPBaseErrorResponse{
+		Error: &dto.HTTPErrorResponse{Message: http.StatusText(status)},
+	}
+	_ = h
+	if status >= http.StatusInternalServerError {
+		gincontext.SetGinContextError(c, err)
+		gincontext.SetGinContextErrorMessage(c, messages)
+	}
+	c.JSON(status, messages)
+}
+
+// HTTPStatusFromError maps an error classified by the awserror package to an HTTP status code,
+// e.g. 401 Unauthorized for a failed Cognito sign-in and 404 Not Found for a missing resource.
+// Unclassified errors are 500 Internal Server Error.
+func HTTPStatusFromError(err error) int {
+	return awserror.HTTPStatus(err)
+}
+
 // ResponseStatusInternalServerError returns 500 error.
 func (h *BaseHTTPHandler) ResponseStatusInternalServerError(c *gin.Context, messages any, err error) {
 	_ = h
@@ -2,12 +2,19 @@
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/y-miyazaki/go-common/pkg/gincontext"
 	"github.com/y-miyazaki/go-common/pkg/logger"
+	"github.com/y-miyazaki/go-common/pkg/repository"
+	"github.com/y-miyazaki/go-common/pkg/utils/aws/awserror"
 
+	"github.com/aws/smithy-go"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -118,3 +125,115 @@ func TestBaseHTTPHandler_ResponseStatusInternalServerError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 	assert.Contains(t, w.Body.String(), "internal server error")
 }
+
+func TestBaseHTTPHandler_RequestLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/test", nil)
+
+	handler := &BaseHTTPHandler{
+		Logger: logger.NewLogger(logrus.New()),
+	}
+	assert.Same(t, handler.Logger, handler.RequestLogger(c))
+
+	stored := logger.NewLogger(logrus.New())
+	c.Request = c.Request.WithContext(logger.NewContext(c.Request.Context(), stored))
+	assert.Same(t, stored, handler.RequestLogger(c))
+}
+
+func TestBaseHTTPHandler_ResponseError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		err        error
+		name       string
+		wantBody   string
+		wantStatus int
+		wantCtxErr bool
+	}{
+		{name: "not found", err: fmt.Errorf("service Get: %w", repository.ErrNotFound), wantStatus: http.StatusNotFound, wantBody: `{"error":{"message":"Not Found"}}`},
+		{name: "access denied", err: repository.ErrAccessDenied, wantStatus: http.StatusForbidden},
+		{name: "conflict", err: repository.ErrConflict, wantStatus: http.StatusConflict},
+		{name: "throttled", err: repository.ErrThrottled, wantStatus: http.StatusTooManyRequests},
+		{name: "unknown", err: errors.New("database password leaked"), wantStatus: http.StatusInternalServerError, wantBody: `{"error":{"message":"Internal Server Error"}}`, wantCtxErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			handler := &BaseHTTPHandler{Logger: logger.NewLogger(logrus.New())}
+
+			handler.ResponseError(c, tt.err)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+			ctxErr, _ := gincontext.GetGinContextError(c)
+			if tt.wantCtxErr {
+				assert.Equal(t, tt.err, ctxErr)
+			} else {
+				assert.Nil(t, ctxErr)
+			}
+		})
+	}
+}
+
+func TestHTTPStatusFromError(t *testing.T) {
+	assert.Equal(t, http.StatusOK, HTTPStatusFromError(nil))
+	assert.Equal(t, http.StatusNotFound, HTTPStatusFromError(repository.ErrNotFound))
+	assert.Equal(t, http.StatusUnauthorized, HTTPStatusFromError(&smithy.GenericAPIError{Code: "NotAuthorizedException"}))
+	assert.Equal(t, http.StatusForbidden, HTTPStatusFromError(awserror.ErrAccessDenied))
+	assert.Equal(t, http.StatusInternalServerError, HTTPStatusFromError(errors.New("boom")))
+}
+
+func TestBaseHTTPHandler_ResponseStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/chat", nil)
+
+	handler := &BaseHTTPHandler{
+		Logger: logger.NewLogger(logrus.New()),
+	}
+
+	events := func(yield func(repository.AWSBedrockStreamEvent, error) bool) {
+		if !yield(repository.AWSBedrockStreamEvent{Type: repository.AWSBedrockStreamEventTextDelta, Text: "Hi"}, nil) {
+			return
+		}
+		yield(repository.AWSBedrockStreamEvent{}, repository.ErrThrottled)
+	}
+	err := handler.ResponseStream(c, StreamChunks(events, func(e repository.AWSBedrockStreamEvent) string { return string(e.Type) }))
+
+	assert.ErrorIs(t, err, repository.ErrThrottled)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/event-stream")
+	body := w.Body.String()
+	assert.Contains(t, body, "event:text_delta\ndata:{\"type\":\"text_delta\",\"text\":\"Hi\",\"index\":0}\n\n")
+	assert.Contains(t, body, "event:error\ndata:{\"message\":\"Too Many Requests\"}\n\n")
+}
+
+func TestBaseHTTPHandler_ResponseStream_ClientDisconnected(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	ctx, cancel := context.WithCancel(context.Background())
+	c.Request, _ = http.NewRequestWithContext(ctx, "POST", "/chat", nil)
+
+	handler := &BaseHTTPHandler{
+		Logger: logger.NewLogger(logrus.New()),
+	}
+
+	stopped := false
+	chunks := func(yield func(StreamChunk, error) bool) {
+		cancel()
+		if !yield(StreamChunk{Data: "Hi", Event: "text_delta"}, nil) {
+			stopped = true
+		}
+	}
+	err := handler.ResponseStream(c, chunks)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.True(t, stopped)
+	assert.NotContains(t, w.Body.String(), "text_delta")
+}
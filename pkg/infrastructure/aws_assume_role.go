@@ -0,0 +1,238 @@
+package infrastructure
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/sts/types"
+)
+
+const (
+	// defaultAWSCredentialsExpiryWindow is how long before expiry assumed role credentials are refreshed.
+	defaultAWSCredentialsExpiryWindow = time.Minute
+)
+
+var (
+	// ErrAWSRoleChainEmpty indicates that a role chain has no role.
+	ErrAWSRoleChainEmpty = errors.New("role chain is empty")
+	// ErrAWSRoleARNInvalid indicates that a role ARN is not an IAM role ARN.
+	ErrAWSRoleARNInvalid = errors.New("invalid IAM role ARN")
+)
+
+// AWSAssumeRoleParams holds parameters for assuming a role.
+type AWSAssumeRoleParams struct {
+	// Tags are the session tags.
+	Tags map[string]string
+	// TokenProvider returns the MFA code when SerialNumber is set, e.g. stscreds.StdinTokenProvider.
+	TokenProvider func() (string, error)
+	// RoleARN is the ARN of the assumed role.
+	RoleARN string
+	// RoleSessionName is the name of the session. Default: generated by the SDK.
+	RoleSessionName string
+	// ExternalID is the external ID required by the trust policy of the role.
+	ExternalID string
+	// SerialNumber is the ARN of the MFA device.
+	SerialNumber string
+	// SourceIdentity is the source identity of the session.
+	SourceIdentity string
+	// Policy is a session policy that limits the permissions of the role.
+	Policy string
+	// Region overrides the region of the returned config.
+	Region string
+	// TransitiveTagKeys are the session tags passed to the next roles of a role chain.
+	TransitiveTagKeys []string
+	// Duration is the duration of the credentials. Default: 15m.
+	// Chained role sessions are limited to 1h.
+	Duration time.Duration
+}
+
+// AWSWebIdentityRoleParams holds parameters for assuming a role with a web identity token.
+type AWSWebIdentityRoleParams struct {
+	// RoleARN is the ARN of the assumed role.
+	RoleARN string
+	// RoleSessionName is the name of the session. Default: generated by the SDK.
+	RoleSessionName string
+	// TokenFile is the file of the token, read on each refresh, e.g. AWS_WEB_IDENTITY_TOKEN_FILE.
+	TokenFile string
+	// Policy is a session policy that limits the permissions of the role.
+	Policy string
+	// Region overrides the region of the returned config.
+	Region string
+	// Duration is the duration of the credentials. Default: 15m.
+	Duration time.Duration
+}
+
+// NewAWSAssumeRoleCredentials returns credentials of the role assumed with client,
+// cached and refreshed before they expire.
+func NewAWSAssumeRoleCredentials(client stscreds.AssumeRoleAPIClient, params *AWSAssumeRoleParams) *aws.CredentialsCache {
+	provider := stscreds.NewAssumeRoleProvider(client, params.RoleARN, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = params.RoleSessionName
+		o.Duration = params.Duration
+		o.ExternalID = optionalString(params.ExternalID)
+		o.Policy = optionalString(params.Policy)
+		o.SourceIdentity = optionalString(params.SourceIdentity)
+		o.SerialNumber = optionalString(params.SerialNumber)
+		o.TokenProvider = params.TokenProvider
+		o.TransitiveTagKeys = params.TransitiveTagKeys
+		for _, key := range slices.Sorted(maps.Keys(params.Tags)) {
+			o.Tags = append(o.Tags, types.Tag{Key: aws.String(key), Value: aws.String(params.Tags[key])})
+		}
+	})
+	return newAWSCredentialsCache(provider)
+}
+
+// NewAWSWebIdentityRoleCredentials returns credentials of the role assumed with
+// a web identity token with client, cached and refreshed before they expire.
+func NewAWSWebIdentityRoleCredentials(client stscreds.AssumeRoleWithWebIdentityAPIClient, params *AWSWebIdentityRoleParams) *aws.CredentialsCache {
+	provider := stscreds.NewWebIdentityRoleProvider(client, params.RoleARN, stscreds.IdentityTokenFile(params.TokenFile),
+		func(o *stscreds.WebIdentityRoleOptions) {
+			o.RoleSessionName = params.RoleSessionName
+			o.Duration = params.Duration
+			o.Policy = optionalString(params.Policy)
+		})
+	return newAWSCredentialsCache(provider)
+}
+
+// GetAWSConfigAssumeRole returns a copy of base whose credentials are of the role
+// assumed with the credentials of base. HTTP client, endpoint and other settings of base are kept.
+func GetAWSConfigAssumeRole(base *aws.Config, params *AWSAssumeRoleParams) aws.Config {
+	cfg := base.Copy()
+	cfg.Credentials = NewAWSAssumeRoleCredentials(sts.NewFromConfig(*base), params)
+	if params.Region != "" {
+		cfg.Region = params.Region
+	}
+	return cfg
+}
+
+// GetAWSConfigWebIdentity returns a copy of base whose credentials are of the role
+// assumed with a web identity token. The credentials of base are not used.
+func GetAWSConfigWebIdentity(base *aws.Config, params *AWSWebIdentityRoleParams) aws.Config {
+	cfg := base.Copy()
+	cfg.Credentials = NewAWSWebIdentityRoleCredentials(sts.NewFromConfig(*base), params)
+	if params.Region != "" {
+		cfg.Region = params.Region
+	}
+	return cfg
+}
+
+// GetAWSConfigRoleChain returns a copy of base whose credentials are of the last
+// role of roles, each role being assumed with the credentials of the previous one,
+// e.g. a hub account role and then a role in a member account.
+func GetAWSConfigRoleChain(base *aws.Config, roles ...*AWSAssumeRoleParams) (aws.Config, error) {
+	if len(roles) == 0 {
+		return aws.Config{}, ErrAWSRoleChainEmpty
+	}
+	cfg := base.Copy()
+	for _, role := range roles {
+		cfg = GetAWSConfigAssumeRole(&cfg, role)
+	}
+	return cfg, nil
+}
+
+// AWSCrossAccountConfig sets configurations.
+type AWSCrossAccountConfig struct {
+	// RoleSessionName is the name of the sessions. Default: generated by the SDK.
+	RoleSessionName string
+	// ExternalID is the external ID required by the trust policies of the roles.
+	ExternalID string
+	// Duration is the duration of the credentials. Default: 15m.
+	Duration time.Duration
+}
+
+// AWSCrossAccountConfigs builds configs of roles in other accounts from a base
+// config and caches them, so that the credentials of a role are shared by the
+// clients of all its regions and refreshed only when they expire.
+// It is safe for concurrent use.
+type AWSCrossAccountConfigs struct {
+	base        aws.Config
+	config      AWSCrossAccountConfig
+	mu          sync.Mutex
+	credentials map[string]*aws.CredentialsCache
+}
+
+// NewAWSCrossAccountConfigs returns AWSCrossAccountConfigs instance.
+// base is the config whose credentials assume the roles.
+func NewAWSCrossAccountConfigs(base *aws.Config, cfgs ...*AWSCrossAccountConfig) *AWSCrossAccountConfigs {
+	c := &AWSCrossAccountConfigs{
+		base:        base.Copy(),
+		credentials: map[string]*aws.CredentialsCache{},
+	}
+	if len(cfgs) > 0 && cfgs[0] != nil {
+		c.config = *cfgs[0]
+	}
+	return c
+}
+
+// Get returns the config of roleARN in region. An empty region is the region of the base config.
+func (c *AWSCrossAccountConfigs) Get(roleARN, region string) (aws.Config, error) {
+	parsed, err := arn.Parse(roleARN)
+	if err != nil || parsed.Service != "iam" {
+		return aws.Config{}, fmt.Errorf("%s: %w", roleARN, ErrAWSRoleARNInvalid)
+	}
+
+	c.mu.Lock()
+	credentials, ok := c.credentials[roleARN]
+	if !ok {
+		credentials = NewAWSAssumeRoleCredentials(sts.NewFromConfig(c.base), &AWSAssumeRoleParams{
+			RoleARN:         roleARN,
+			RoleSessionName: c.config.RoleSessionName,
+			ExternalID:      c.config.ExternalID,
+			Duration:        c.config.Duration,
+		})
+		c.credentials[roleARN] = credentials
+	}
+	c.mu.Unlock()
+
+	cfg := c.base.Copy()
+	cfg.Credentials = credentials
+	if region != "" {
+		cfg.Region = region
+	}
+	return cfg, nil
+}
+
+// GetAccount returns the config of the role roleName of accountID in region, in the partition of the base config region.
+func (c *AWSCrossAccountConfigs) GetAccount(accountID, roleName, region string) (aws.Config, error) {
+	return c.Get(AWSRoleARN(AWSPartition(c.base.Region), accountID, roleName), region)
+}
+
+// AWSRoleARN returns the ARN of the role roleName of accountID. roleName may have a path, e.g. "ops/audit".
+func AWSRoleARN(partition, accountID, roleName string) string {
+	return arn.ARN{Partition: partition, Service: "iam", AccountID: accountID, Resource: "role/" + roleName}.String()
+}
+
+// AWSPartition returns the partition of region.
+func AWSPartition(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return "aws-cn"
+	case strings.HasPrefix(region, "us-gov-"):
+		return "aws-us-gov"
+	default:
+		return "aws"
+	}
+}
+
+// newAWSCredentialsCache returns provider cached and refreshed before its credentials expire.
+func newAWSCredentialsCache(provider aws.CredentialsProvider) *aws.CredentialsCache {
+	return aws.NewCredentialsCache(provider, func(o *aws.CredentialsCacheOptions) {
+		o.ExpiryWindow = defaultAWSCredentialsExpiryWindow
+	})
+}
+
+// optionalString returns nil for an empty string.
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return aws.String(s)
+}
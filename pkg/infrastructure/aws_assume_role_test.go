@@ -0,0 +1,216 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/sts/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSTSClient records AssumeRole calls and returns credentials valid for an hour.
+type testSTSClient struct {
+	assumeRole      []*sts.AssumeRoleInput
+	assumeRoleWebID []*sts.AssumeRoleWithWebIdentityInput
+}
+
+func (c *testSTSClient) AssumeRole(_ context.Context, params *sts.AssumeRoleInput, _ ...func(*sts.Options)) (*sts.AssumeRoleOutput, error) {
+	c.assumeRole = append(c.assumeRole, params)
+	return &sts.AssumeRoleOutput{Credentials: testSTSCredentials(fmt.Sprintf("AKID%d", len(c.assumeRole)))}, nil
+}
+
+func (c *testSTSClient) AssumeRoleWithWebIdentity(_ context.Context, params *sts.AssumeRoleWithWebIdentityInput, _ ...func(*sts.Options)) (*sts.AssumeRoleWithWebIdentityOutput, error) {
+	c.assumeRoleWebID = append(c.assumeRoleWebID, params)
+	return &sts.AssumeRoleWithWebIdentityOutput{Credentials: testSTSCredentials("AKIDWEB")}, nil
+}
+
+// testSTSCredentials returns credentials of accessKeyID valid for an hour.
+func testSTSCredentials(accessKeyID string) *types.Credentials {
+	return &types.Credentials{
+		AccessKeyId:     aws.String(accessKeyID),
+		SecretAccessKey: aws.String("secret"),
+		SessionToken:    aws.String("token"),
+		Expiration:      aws.Time(time.Now().Add(time.Hour)),
+	}
+}
+
+// testSTSServer is an STS endpoint that returns credentials whose access key
+// is the name of the assumed role, and records which access key assumed which role.
+type testSTSServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	assumed []string
+}
+
+var testSTSCredentialPattern = regexp.MustCompile(`Credential=([^/]+)/`)
+
+func newTestSTSServer(t *testing.T) *testSTSServer {
+	t.Helper()
+	s := &testSTSServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		roleARN := r.Form.Get("RoleArn")
+		roleName := roleARN[strings.LastIndex(roleARN, "/")+1:]
+		caller := testSTSCredentialPattern.FindStringSubmatch(r.Header.Get("Authorization"))[1]
+
+		s.mu.Lock()
+		s.assumed = append(s.assumed, caller+">"+roleName)
+		s.mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/xml")
+		_, _ = fmt.Fprintf(w, `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/"><AssumeRoleResult>
+<Credentials><AccessKeyId>%s</AccessKeyId><SecretAccessKey>secret</SecretAccessKey><SessionToken>token</SessionToken>
+<Expiration>%s</Expiration></Credentials>
+<AssumedRoleUser><Arn>%s</Arn><AssumedRoleUserId>id:session</AssumedRoleUserId></AssumedRoleUser>
+</AssumeRoleResult></AssumeRoleResponse>`, roleName, time.Now().Add(time.Hour).UTC().Format(time.RFC3339), roleARN)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// config returns a config with static credentials of accessKeyID using the server as STS endpoint.
+func (s *testSTSServer) config(accessKeyID string) *aws.Config {
+	return &aws.Config{
+		Region:       "us-east-1",
+		Credentials:  credentials.NewStaticCredentialsProvider(accessKeyID, "secret", ""),
+		BaseEndpoint: aws.String(s.URL),
+	}
+}
+
+func TestNewAWSAssumeRoleCredentials(t *testing.T) {
+	client := &testSTSClient{}
+	provider := NewAWSAssumeRoleCredentials(client, &AWSAssumeRoleParams{
+		RoleARN:           "arn:aws:iam::123456789012:role/audit",
+		RoleSessionName:   "audit-session",
+		ExternalID:        "external-id",
+		SerialNumber:      "arn:aws:iam::111111111111:mfa/user",
+		TokenProvider:     func() (string, error) { return "123456", nil },
+		Tags:              map[string]string{"team": "ops", "app": "audit"},
+		TransitiveTagKeys: []string{"team"},
+		Duration:          time.Hour,
+	})
+
+	creds, err := provider.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "AKID1", creds.AccessKeyID)
+
+	// The credentials are cached until they expire.
+	_, err = provider.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Len(t, client.assumeRole, 1)
+
+	in := client.assumeRole[0]
+	assert.Equal(t, "arn:aws:iam::123456789012:role/audit", *in.RoleArn)
+	assert.Equal(t, "audit-session", *in.RoleSessionName)
+	assert.Equal(t, "external-id", *in.ExternalId)
+	assert.Equal(t, "123456", *in.TokenCode)
+	assert.Equal(t, int32(3600), *in.DurationSeconds)
+	assert.Equal(t, []types.Tag{
+		{Key: aws.String("app"), Value: aws.String("audit")},
+		{Key: aws.String("team"), Value: aws.String("ops")},
+	}, in.Tags)
+	assert.Equal(t, []string{"team"}, in.TransitiveTagKeys)
+	assert.Nil(t, in.Policy)
+}
+
+func TestNewAWSWebIdentityRoleCredentials(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("jwt"), 0o600))
+	client := &testSTSClient{}
+
+	provider := NewAWSWebIdentityRoleCredentials(client, &AWSWebIdentityRoleParams{
+		RoleARN:         "arn:aws:iam::123456789012:role/ci",
+		RoleSessionName: "ci",
+		TokenFile:       tokenFile,
+	})
+
+	creds, err := provider.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "AKIDWEB", creds.AccessKeyID)
+	require.Len(t, client.assumeRoleWebID, 1)
+	assert.Equal(t, "jwt", *client.assumeRoleWebID[0].WebIdentityToken)
+	assert.Equal(t, "arn:aws:iam::123456789012:role/ci", *client.assumeRoleWebID[0].RoleArn)
+}
+
+func TestGetAWSConfigRoleChain(t *testing.T) {
+	server := newTestSTSServer(t)
+
+	cfg, err := GetAWSConfigRoleChain(server.config("base"),
+		&AWSAssumeRoleParams{RoleARN: "arn:aws:iam::111111111111:role/hub"},
+		&AWSAssumeRoleParams{RoleARN: "arn:aws:iam::222222222222:role/member", Region: "eu-west-1"},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "eu-west-1", cfg.Region)
+
+	creds, err := cfg.Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "member", creds.AccessKeyID)
+	assert.Equal(t, []string{"base>hub", "hub>member"}, server.assumed)
+
+	_, err = GetAWSConfigRoleChain(server.config("base"))
+	require.ErrorIs(t, err, ErrAWSRoleChainEmpty)
+}
+
+func TestAWSCrossAccountConfigs(t *testing.T) {
+	server := newTestSTSServer(t)
+	configs := NewAWSCrossAccountConfigs(server.config("base"), &AWSCrossAccountConfig{RoleSessionName: "audit"})
+
+	east, err := configs.GetAccount("222222222222", "audit", "")
+	require.NoError(t, err)
+	west, err := configs.Get("arn:aws:iam::222222222222:role/audit", "us-west-2")
+	require.NoError(t, err)
+	assert.Equal(t, "us-east-1", east.Region)
+	assert.Equal(t, "us-west-2", west.Region)
+
+	for _, cfg := range []aws.Config{east, west} {
+		creds, err := cfg.Credentials.Retrieve(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "audit", creds.AccessKeyID)
+	}
+	// The credentials of a role are shared by its regions.
+	assert.Equal(t, []string{"base>audit"}, server.assumed)
+
+	_, err = configs.Get("arn:aws:s3:::bucket", "")
+	require.ErrorIs(t, err, ErrAWSRoleARNInvalid)
+	_, err = configs.Get("audit", "")
+	require.ErrorIs(t, err, ErrAWSRoleARNInvalid)
+}
+
+func TestAWSRoleARN(t *testing.T) {
+	assert.Equal(t, "arn:aws:iam::123456789012:role/ops/audit", AWSRoleARN(AWSPartition("ap-northeast-1"), "123456789012", "ops/audit"))
+	assert.Equal(t, "aws-cn", AWSPartition("cn-north-1"))
+	assert.Equal(t, "aws-us-gov", AWSPartition("us-gov-west-1"))
+	assert.Equal(t, "aws", AWSPartition(""))
+}
+
+func TestCreateAWSConfig_AssumeRoles(t *testing.T) {
+	server := newTestSTSServer(t)
+
+	cfg, err := createAWSConfig(&AWSConfigParams{
+		AssumeRoles:    []*AWSAssumeRoleParams{{RoleARN: "arn:aws:iam::123456789012:role/deploy"}},
+		Key:            "base",
+		Secret:         "secret",
+		Region:         "us-east-1",
+		Endpoint:       server.URL,
+		UseCredentials: true,
+	}, nil)
+	require.NoError(t, err)
+
+	creds, err := cfg.Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "deploy", creds.AccessKeyID)
+	assert.Equal(t, []string{"base>deploy"}, server.assumed)
+}
@@ -32,6 +32,8 @@
 
 // AWSConfigParams holds parameters for AWS configuration
 type AWSConfigParams struct {
+	// AssumeRoles are assumed in order with the loaded credentials (role chaining).
+	AssumeRoles    []*AWSAssumeRoleParams
 	Key            string
 	Secret         string
 	SessionToken   string
@@ -117,6 +119,11 @@ func createAWSConfig(params *AWSConfigParams, httpClient *http.Client) (aws.Conf
 		return aws.Config{}, fmt.Errorf("config load: %w", err)
 	}
 
+	if len(params.AssumeRoles) > 0 {
+		// nolint: wrapcheck
+		return GetAWSConfigRoleChain(&cfg, params.AssumeRoles...)
+	}
+
 	return cfg, nil
 }
 
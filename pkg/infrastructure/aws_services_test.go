@@ -12,6 +12,7 @@
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/stretchr/testify/assert"
 )
@@ -83,6 +84,14 @@ func TestNewAWSSecretsManager(t *testing.T) {
 	assert.IsType(t, &secretsmanager.Client{}, client)
 }
 
+func TestNewAWSSSM(t *testing.T) {
+	cfg := &aws.Config{Region: "us-east-1"}
+	client := NewAWSSSM(cfg)
+
+	assert.NotNil(t, client)
+	assert.IsType(t, &ssm.Client{}, client)
+}
+
 func TestNewAWSSES(t *testing.T) {
 	cfg := aws.Config{Region: "us-east-1"}
 	client := NewAWSSES(cfg)
@@ -0,0 +1,11 @@
+package infrastructure
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+)
+
+// NewAWSSSM returns Systems Manager client using AWS SDK v2.
+func NewAWSSSM(c *aws.Config, optFns ...func(*ssm.Options)) *ssm.Client { // nolint:gocritic
+	return ssm.NewFromConfig(*c, optFns...)
+}
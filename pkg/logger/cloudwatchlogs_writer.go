@@ -0,0 +1,345 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/sirupsen/logrus"
+	"go.uber.org/zap/zapcore"
+)
+
+// CloudWatchLogsMaxEventBytes is the maximum size of a log event message of CloudWatch Logs.
+// Longer messages are truncated by CloudWatchLogsWriter.
+const CloudWatchLogsMaxEventBytes = 262_144 - 26
+
+// CloudWatchLogsWriter defaults.
+const (
+	defaultCloudWatchLogsFlushInterval  = 5 * time.Second
+	defaultCloudWatchLogsBufferSize     = 10_000
+	defaultCloudWatchLogsMaxRetries     = 5
+	defaultCloudWatchLogsRetryBaseDelay = 200 * time.Millisecond
+)
+
+var (
+	// ErrCloudWatchLogsWriterClosed indicates that the writer has been closed.
+	ErrCloudWatchLogsWriterClosed = errors.New("cloudwatch logs writer is closed")
+	// ErrCloudWatchLogsEventsRejected indicates that CloudWatch Logs rejected some events.
+	ErrCloudWatchLogsEventsRejected = errors.New("cloudwatch logs events rejected")
+)
+
+// CloudWatchLogsPutResult is the result of CloudWatchLogsPutter.PutLogEventsBatched.
+type CloudWatchLogsPutResult struct {
+	// Unsent are the events that were not sent because of an error.
+	Unsent []types.InputLogEvent
+	// Rejected is the number of events rejected by CloudWatch Logs.
+	Rejected int
+}
+
+// CloudWatchLogsPutter uploads log events for CloudWatchLogsWriter, splitting
+// them within the PutLogEvents quotas and creating the log group and stream
+// when they don't exist. repository.NewAWSCloudWatchLogsLoggerPutter returns one.
+type CloudWatchLogsPutter interface {
+	PutLogEventsBatched(ctx context.Context, group, stream string, events []types.InputLogEvent) (*CloudWatchLogsPutResult, error)
+}
+
+// CloudWatchLogsWriterConfig sets configurations of CloudWatchLogsWriter.
+type CloudWatchLogsWriterConfig struct {
+	// ErrorHandler is called when events can't be delivered or are rejected.
+	// Default: the error is written to stderr.
+	ErrorHandler func(err error)
+	// LogGroupName is the destination log group. It is created when missing.
+	LogGroupName string
+	// LogStreamName is the destination log stream. It is created when missing.
+	LogStreamName string
+	// FlushInterval is the maximum time events are buffered. Default: 5s.
+	FlushInterval time.Duration
+	// BufferSize is the number of events that can be queued before new events
+	// are dropped. Buffered events are also delivered when BufferSize of them
+	// accumulate. Default: 10000.
+	BufferSize int
+	// MaxRetries is the number of retries on throttling. Default: 5.
+	MaxRetries int
+	// RetryBaseDelay is the initial backoff on throttling, doubled on each retry. Default: 200ms.
+	RetryBaseDelay time.Duration
+}
+
+// CloudWatchLogsWriter is a buffered asynchronous io.Writer that delivers each
+// write as a log event to CloudWatch Logs. Writes never block: when the buffer
+// is full, events are dropped and counted in Dropped.
+//
+// It can be used directly as an output (logrus.Logger.Out, SlogConfig.Output)
+// or through NewCloudWatchLogsHook, NewCloudWatchLogsZapCore and
+// NewCloudWatchLogsSlogHandler. Call Close on shutdown to flush buffered events.
+type CloudWatchLogsWriter struct {
+	putter  CloudWatchLogsPutter
+	config  CloudWatchLogsWriterConfig
+	events  chan types.InputLogEvent
+	flushes chan chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+	closed  atomic.Bool
+	dropped atomic.Int64
+}
+
+// NewCloudWatchLogsWriter returns a CloudWatchLogsWriter and starts its background delivery goroutine.
+func NewCloudWatchLogsWriter(putter CloudWatchLogsPutter, cfg *CloudWatchLogsWriterConfig) *CloudWatchLogsWriter {
+	conf := CloudWatchLogsWriterConfig{}
+	if cfg != nil {
+		conf = *cfg
+	}
+	if conf.FlushInterval <= 0 {
+		conf.FlushInterval = defaultCloudWatchLogsFlushInterval
+	}
+	if conf.BufferSize <= 0 {
+		conf.BufferSize = defaultCloudWatchLogsBufferSize
+	}
+	if conf.MaxRetries <= 0 {
+		conf.MaxRetries = defaultCloudWatchLogsMaxRetries
+	}
+	if conf.RetryBaseDelay <= 0 {
+		conf.RetryBaseDelay = defaultCloudWatchLogsRetryBaseDelay
+	}
+	if conf.ErrorHandler == nil {
+		conf.ErrorHandler = func(err error) {
+			_, _ = fmt.Fprintf(os.Stderr, "cloudwatch logs writer: %v\n", err)
+		}
+	}
+	w := &CloudWatchLogsWriter{
+		putter:  putter,
+		config:  conf,
+		events:  make(chan types.InputLogEvent, conf.BufferSize),
+		flushes: make(chan chan struct{}),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Write queues p as a single log event timestamped with the current time.
+// A trailing newline is removed.
+func (w *CloudWatchLogsWriter) Write(p []byte) (int, error) {
+	if err := w.enqueue(time.Now(), p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Sync flushes buffered events. It implements zapcore.WriteSyncer.
+func (w *CloudWatchLogsWriter) Sync() error {
+	return w.Flush(context.Background())
+}
+
+// Flush delivers buffered events and waits until they are sent or ctx is done.
+func (w *CloudWatchLogsWriter) Flush(ctx context.Context) error {
+	ack := make(chan struct{})
+	select {
+	case w.flushes <- ack:
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("cloudwatch logs flush: %w", ctx.Err())
+	}
+	select {
+	case <-ack:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("cloudwatch logs flush: %w", ctx.Err())
+	}
+}
+
+// Close stops accepting events, flushes buffered events and waits until they
+// are sent or ctx is done.
+func (w *CloudWatchLogsWriter) Close(ctx context.Context) error {
+	w.once.Do(func() {
+		w.closed.Store(true)
+		close(w.stop)
+	})
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("cloudwatch logs close: %w", ctx.Err())
+	}
+}
+
+// Dropped returns the number of events dropped because the buffer was full.
+func (w *CloudWatchLogsWriter) Dropped() int64 {
+	return w.dropped.Load()
+}
+
+// enqueue queues a copy of p as an event without blocking.
+func (w *CloudWatchLogsWriter) enqueue(t time.Time, p []byte) error {
+	if w.closed.Load() {
+		return ErrCloudWatchLogsWriterClosed
+	}
+	message := strings.TrimSuffix(string(p), "\n")
+	if message == "" {
+		return nil
+	}
+	message = truncateCloudWatchLogsMessage(message, CloudWatchLogsMaxEventBytes)
+	event := types.InputLogEvent{
+		Message:   aws.String(message),
+		Timestamp: aws.Int64(t.UnixMilli()),
+	}
+	select {
+	case w.events <- event:
+	default:
+		w.dropped.Add(1)
+	}
+	return nil
+}
+
+// truncateCloudWatchLogsMessage returns message cut to at most n bytes without
+// splitting a multi-byte UTF-8 character, which CloudWatch Logs would reject.
+func truncateCloudWatchLogsMessage(message string, n int) string {
+	if len(message) <= n {
+		return message
+	}
+	for n > 0 && !utf8.RuneStart(message[n]) {
+		n--
+	}
+	return message[:n]
+}
+
+// run buffers queued events and delivers them until the writer is closed.
+func (w *CloudWatchLogsWriter) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.config.FlushInterval)
+	defer ticker.Stop()
+
+	var pending []types.InputLogEvent
+	for {
+		select {
+		case event := <-w.events:
+			pending = append(pending, event)
+			if len(pending) >= w.config.BufferSize {
+				w.send(pending)
+				pending = nil
+			}
+		case <-ticker.C:
+			w.send(pending)
+			pending = nil
+		case ack := <-w.flushes:
+			w.drain(pending)
+			pending = nil
+			close(ack)
+		case <-w.stop:
+			w.drain(pending)
+			return
+		}
+	}
+}
+
+// drain sends pending and every queued event.
+func (w *CloudWatchLogsWriter) drain(pending []types.InputLogEvent) {
+	for {
+		select {
+		case event := <-w.events:
+			pending = append(pending, event)
+			if len(pending) >= w.config.BufferSize {
+				w.send(pending)
+				pending = nil
+			}
+		default:
+			w.send(pending)
+			return
+		}
+	}
+}
+
+// send delivers events with PutLogEventsBatched, retrying the unsent events on
+// throttling. Rejected events are reported to ErrorHandler.
+func (w *CloudWatchLogsWriter) send(events []types.InputLogEvent) {
+	if len(events) == 0 {
+		return
+	}
+	ctx := context.Background()
+	group, stream := w.config.LogGroupName, w.config.LogStreamName
+	delay := w.config.RetryBaseDelay
+	for attempt := 0; ; attempt++ {
+		res, err := w.putter.PutLogEventsBatched(ctx, group, stream, events)
+		if res != nil && res.Rejected > 0 {
+			w.config.ErrorHandler(fmt.Errorf("%d events to %s/%s: %w", res.Rejected, group, stream, ErrCloudWatchLogsEventsRejected))
+		}
+		if err == nil {
+			return
+		}
+		if res != nil {
+			events = res.Unsent
+		}
+		if isCloudWatchLogsThrottled(err) && attempt < w.config.MaxRetries {
+			time.Sleep(delay)
+			delay *= 2
+			continue
+		}
+		w.config.ErrorHandler(fmt.Errorf("put %d events to %s/%s: %w", len(events), group, stream, err))
+		return
+	}
+}
+
+// isCloudWatchLogsThrottled reports whether err is a retryable throttling error.
+func isCloudWatchLogsThrottled(err error) bool {
+	var throttling *types.ThrottlingException
+	var unavailable *types.ServiceUnavailableException
+	return errors.As(err, &throttling) || errors.As(err, &unavailable)
+}
+
+// CloudWatchLogsHook is a logrus.Hook that sends entries to CloudWatch Logs
+// with their original timestamp.
+type CloudWatchLogsHook struct {
+	Writer    *CloudWatchLogsWriter
+	Formatter logrus.Formatter
+	LogLevels []logrus.Level
+}
+
+// NewCloudWatchLogsHook returns a hook for all levels. If formatter is nil,
+// logrus.JSONFormatter is used.
+func NewCloudWatchLogsHook(w *CloudWatchLogsWriter, formatter logrus.Formatter) *CloudWatchLogsHook {
+	f := formatter
+	if f == nil {
+		f = &logrus.JSONFormatter{}
+	}
+	return &CloudWatchLogsHook{
+		Writer:    w,
+		Formatter: f,
+		LogLevels: logrus.AllLevels,
+	}
+}
+
+// Levels implements logrus.Hook.
+func (h *CloudWatchLogsHook) Levels() []logrus.Level {
+	return h.LogLevels
+}
+
+// Fire implements logrus.Hook.
+func (h *CloudWatchLogsHook) Fire(entry *logrus.Entry) error {
+	b, err := h.Formatter.Format(entry)
+	if err != nil {
+		return fmt.Errorf("cloudwatch logs hook format: %w", err)
+	}
+	return h.Writer.enqueue(entry.Time, b)
+}
+
+// NewCloudWatchLogsZapCore returns a zapcore.Core that writes to w.
+// Combine it with an existing logger using
+// zap.WrapCore(func(c zapcore.Core) zapcore.Core { return zapcore.NewTee(c, core) }).
+func NewCloudWatchLogsZapCore(w *CloudWatchLogsWriter, encoder zapcore.Encoder, level zapcore.LevelEnabler) zapcore.Core {
+	return zapcore.NewCore(encoder, w, level)
+}
+
+// NewCloudWatchLogsSlogHandler returns a JSON slog.Handler that writes to w.
+func NewCloudWatchLogsSlogHandler(w *CloudWatchLogsWriter, opts *slog.HandlerOptions) slog.Handler {
+	return slog.NewJSONHandler(w, opts)
+}
@@ -0,0 +1,209 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// fakeCloudWatchLogsPutter records PutLogEventsBatched calls and returns queued errors.
+type fakeCloudWatchLogsPutter struct {
+	mu        sync.Mutex
+	batches   [][]types.InputLogEvent
+	putErrors []error
+	rejected  int
+}
+
+func (f *fakeCloudWatchLogsPutter) PutLogEventsBatched(_ context.Context, _, _ string, events []types.InputLogEvent) (*CloudWatchLogsPutResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.putErrors) > 0 {
+		err := f.putErrors[0]
+		f.putErrors = f.putErrors[1:]
+		return &CloudWatchLogsPutResult{Unsent: events}, err
+	}
+	f.batches = append(f.batches, append([]types.InputLogEvent(nil), events...))
+	res := &CloudWatchLogsPutResult{Rejected: f.rejected}
+	f.rejected = 0
+	return res, nil
+}
+
+func (f *fakeCloudWatchLogsPutter) messages() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []string
+	for _, b := range f.batches {
+		for _, e := range b {
+			out = append(out, aws.ToString(e.Message))
+		}
+	}
+	return out
+}
+
+func newTestCloudWatchLogsWriter(putter CloudWatchLogsPutter, handler func(error)) *CloudWatchLogsWriter {
+	return NewCloudWatchLogsWriter(putter, &CloudWatchLogsWriterConfig{
+		LogGroupName:   "group",
+		LogStreamName:  "stream",
+		FlushInterval:  time.Hour,
+		RetryBaseDelay: time.Millisecond,
+		ErrorHandler:   handler,
+	})
+}
+
+func TestCloudWatchLogsWriter_WriteAndClose(t *testing.T) {
+	putter := &fakeCloudWatchLogsPutter{}
+	w := newTestCloudWatchLogsWriter(putter, nil)
+
+	n, err := w.Write([]byte("first\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 6, n)
+	_, _ = w.Write([]byte("second"))
+
+	assert.NoError(t, w.Close(context.Background()))
+	assert.Equal(t, []string{"first", "second"}, putter.messages())
+
+	_, err = w.Write([]byte("after close"))
+	assert.ErrorIs(t, err, ErrCloudWatchLogsWriterClosed)
+	assert.NoError(t, w.Flush(context.Background()))
+}
+
+func TestCloudWatchLogsWriter_Flush(t *testing.T) {
+	putter := &fakeCloudWatchLogsPutter{}
+	w := newTestCloudWatchLogsWriter(putter, nil)
+	defer func() { _ = w.Close(context.Background()) }()
+
+	_, _ = w.Write([]byte("message"))
+	assert.NoError(t, w.Sync())
+	assert.Equal(t, []string{"message"}, putter.messages())
+}
+
+func TestCloudWatchLogsWriter_RetriesThrottling(t *testing.T) {
+	putter := &fakeCloudWatchLogsPutter{
+		putErrors: []error{&types.ThrottlingException{}, &types.ServiceUnavailableException{}},
+	}
+	w := newTestCloudWatchLogsWriter(putter, nil)
+
+	_, _ = w.Write([]byte("message"))
+	assert.NoError(t, w.Close(context.Background()))
+	assert.Equal(t, []string{"message"}, putter.messages())
+}
+
+func TestCloudWatchLogsWriter_ErrorHandler(t *testing.T) {
+	putter := &fakeCloudWatchLogsPutter{putErrors: []error{errors.New("access denied")}}
+	var got error
+	w := newTestCloudWatchLogsWriter(putter, func(err error) { got = err })
+
+	_, _ = w.Write([]byte("message"))
+	assert.NoError(t, w.Close(context.Background()))
+	assert.ErrorContains(t, got, "access denied")
+}
+
+func TestCloudWatchLogsWriter_Rejected(t *testing.T) {
+	putter := &fakeCloudWatchLogsPutter{
+		rejected: 1,
+	}
+	var got error
+	w := newTestCloudWatchLogsWriter(putter, func(err error) { got = err })
+
+	_, _ = w.Write([]byte("message"))
+	assert.NoError(t, w.Close(context.Background()))
+	assert.ErrorIs(t, got, ErrCloudWatchLogsEventsRejected)
+}
+
+func TestCloudWatchLogsWriter_SendsFullBuffer(t *testing.T) {
+	putter := &fakeCloudWatchLogsPutter{}
+	w := NewCloudWatchLogsWriter(putter, &CloudWatchLogsWriterConfig{
+		LogGroupName:  "group",
+		LogStreamName: "stream",
+		FlushInterval: time.Hour,
+		BufferSize:    10,
+	})
+	defer func() { _ = w.Close(context.Background()) }()
+
+	for range 10 {
+		_, _ = w.Write([]byte("m"))
+	}
+	assert.Eventually(t, func() bool { return len(putter.messages()) == 10 }, time.Second, time.Millisecond)
+}
+
+func TestTruncateCloudWatchLogsMessage(t *testing.T) {
+	assert.Equal(t, "short", truncateCloudWatchLogsMessage("short", 10))
+	assert.Equal(t, "abc", truncateCloudWatchLogsMessage("abcdef", 3))
+	// "é" is 2 bytes and "日" is 3 bytes: they are never cut in half.
+	assert.Equal(t, "a", truncateCloudWatchLogsMessage("aé", 2))
+	assert.Equal(t, "a", truncateCloudWatchLogsMessage("a日本", 3))
+	assert.Equal(t, "a日", truncateCloudWatchLogsMessage("a日本", 4))
+
+	long := strings.Repeat("日", CloudWatchLogsMaxEventBytes)
+	truncated := truncateCloudWatchLogsMessage(long, CloudWatchLogsMaxEventBytes)
+	assert.LessOrEqual(t, len(truncated), CloudWatchLogsMaxEventBytes)
+	assert.True(t, utf8.ValidString(truncated))
+}
+
+func TestCloudWatchLogsWriter_Dropped(t *testing.T) {
+	putter := &fakeCloudWatchLogsPutter{}
+	w := NewCloudWatchLogsWriter(putter, &CloudWatchLogsWriterConfig{BufferSize: 1, FlushInterval: time.Hour})
+	for range 100 {
+		_, _ = w.Write([]byte("m"))
+	}
+	assert.NoError(t, w.Close(context.Background()))
+	assert.Equal(t, int64(100), w.Dropped()+int64(len(putter.messages())))
+}
+
+func TestCloudWatchLogsHook(t *testing.T) {
+	putter := &fakeCloudWatchLogsPutter{}
+	w := newTestCloudWatchLogsWriter(putter, nil)
+
+	l := logrus.New()
+	l.Out = &bytes.Buffer{}
+	hook := NewCloudWatchLogsHook(w, nil)
+	l.AddHook(hook)
+	l.WithField("key", "value").Info("from logrus")
+
+	assert.Equal(t, logrus.AllLevels, hook.Levels())
+	assert.NoError(t, w.Close(context.Background()))
+	msgs := putter.messages()
+	assert.Len(t, msgs, 1)
+	assert.Contains(t, msgs[0], `"msg":"from logrus"`)
+	assert.Contains(t, msgs[0], `"key":"value"`)
+}
+
+func TestNewCloudWatchLogsZapCore(t *testing.T) {
+	putter := &fakeCloudWatchLogsPutter{}
+	w := newTestCloudWatchLogsWriter(putter, nil)
+
+	core := NewCloudWatchLogsZapCore(w, zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.InfoLevel)
+	l := &ZapLogger{Logger: zap.New(core)}
+	l.Info("from zap")
+	l.Debug("ignored")
+
+	assert.NoError(t, w.Close(context.Background()))
+	msgs := putter.messages()
+	assert.Len(t, msgs, 1)
+	assert.Contains(t, msgs[0], `"msg":"from zap"`)
+}
+
+func TestNewCloudWatchLogsSlogHandler(t *testing.T) {
+	putter := &fakeCloudWatchLogsPutter{}
+	w := newTestCloudWatchLogsWriter(putter, nil)
+
+	slog.New(NewCloudWatchLogsSlogHandler(w, nil)).Info("from slog")
+
+	assert.NoError(t, w.Close(context.Background()))
+	msgs := putter.messages()
+	assert.Len(t, msgs, 1)
+	assert.Contains(t, msgs[0], `"msg":"from slog"`)
+}
@@ -0,0 +1,92 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"go.uber.org/zap"
+)
+
+// contextKey is an unexported type for keys stored in context.Context so that
+// they never collide with keys defined in other packages.
+type contextKey int
+
+// context keys for each logger backend.
+const (
+	contextKeyLogger contextKey = iota
+	contextKeyZapLogger
+	contextKeySlogLogger
+)
+
+// NewContext returns a copy of ctx that carries the given *Logger.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(nonNilContext(ctx), contextKeyLogger, l)
+}
+
+// FromContext returns the *Logger stored in ctx by NewContext.
+// If no logger is stored, a logger wrapping logrus.StandardLogger() is returned
+// so callers never have to nil-check the result.
+func FromContext(ctx context.Context) *Logger {
+	if l := loggerFromContext(ctx); l != nil {
+		return l
+	}
+	return NewLogger(logrus.StandardLogger())
+}
+
+// FromContextOr returns the *Logger stored in ctx by NewContext, or fallback
+// when no logger is stored.
+func FromContextOr(ctx context.Context, fallback *Logger) *Logger {
+	if l := loggerFromContext(ctx); l != nil {
+		return l
+	}
+	return fallback
+}
+
+// loggerFromContext returns the *Logger stored in ctx or nil.
+func loggerFromContext(ctx context.Context) *Logger {
+	if ctx == nil {
+		return nil
+	}
+	l, _ := ctx.Value(contextKeyLogger).(*Logger)
+	return l
+}
+
+// NewZapContext returns a copy of ctx that carries the given *ZapLogger.
+func NewZapContext(ctx context.Context, l *ZapLogger) context.Context {
+	return context.WithValue(nonNilContext(ctx), contextKeyZapLogger, l)
+}
+
+// ZapFromContext returns the *ZapLogger stored in ctx by NewZapContext.
+// If no logger is stored, a no-op logger is returned.
+func ZapFromContext(ctx context.Context) *ZapLogger {
+	if ctx != nil {
+		if l, ok := ctx.Value(contextKeyZapLogger).(*ZapLogger); ok && l != nil {
+			return l
+		}
+	}
+	return &ZapLogger{Logger: zap.NewNop()}
+}
+
+// NewSlogContext returns a copy of ctx that carries the given *SlogLogger.
+func NewSlogContext(ctx context.Context, l *SlogLogger) context.Context {
+	return context.WithValue(nonNilContext(ctx), contextKeySlogLogger, l)
+}
+
+// SlogFromContext returns the *SlogLogger stored in ctx by NewSlogContext.
+// If no logger is stored, a logger with the default configuration is returned.
+func SlogFromContext(ctx context.Context) *SlogLogger {
+	if ctx != nil {
+		if l, ok := ctx.Value(contextKeySlogLogger).(*SlogLogger); ok && l != nil {
+			return l
+		}
+	}
+	return NewSlogLogger(nil)
+}
+
+// nonNilContext returns ctx, or context.Background() when ctx is nil.
+func nonNilContext(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx
+}
@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestNewContext_FromContext(t *testing.T) {
+	var buf bytes.Buffer
+	l := logrus.New()
+	l.Out = &buf
+	l.Formatter = &logrus.JSONFormatter{}
+	log := NewLogger(l).WithField("requestID", "req-1")
+
+	ctx := NewContext(context.Background(), log)
+	got := FromContext(ctx)
+	assert.Same(t, log, got)
+
+	got.Info("hello")
+	assert.Contains(t, buf.String(), `"requestID":"req-1"`)
+}
+
+func TestFromContext_Fallback(t *testing.T) {
+	assert.NotNil(t, FromContext(context.Background()))
+	//nolint:staticcheck // nil context is intentionally tested
+	assert.NotNil(t, FromContext(nil))
+	//nolint:staticcheck // nil context is intentionally tested
+	ctx := NewContext(nil, NewLogger(nil))
+	assert.NotNil(t, FromContext(ctx))
+}
+
+func TestNewZapContext_ZapFromContext(t *testing.T) {
+	log := &ZapLogger{Logger: zap.NewNop()}
+	ctx := NewZapContext(context.Background(), log)
+	assert.Same(t, log, ZapFromContext(ctx))
+	assert.NotNil(t, ZapFromContext(context.Background()))
+}
+
+func TestNewSlogContext_SlogFromContext(t *testing.T) {
+	log := NewSlogLogger(&SlogConfig{Output: &bytes.Buffer{}})
+	ctx := NewSlogContext(context.Background(), log)
+	assert.Same(t, log, SlogFromContext(ctx))
+	assert.NotNil(t, SlogFromContext(context.Background()))
+}
+
+func TestFromContextOr(t *testing.T) {
+	fallback := NewLogger(nil)
+	assert.Same(t, fallback, FromContextOr(context.Background(), fallback))
+
+	stored := NewLogger(nil)
+	ctx := NewContext(context.Background(), stored)
+	assert.Same(t, stored, FromContextOr(ctx, fallback))
+}
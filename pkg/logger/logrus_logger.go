@@ -3,6 +3,7 @@
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -17,6 +18,9 @@ type stackTracer interface {
 type Logger struct {
 	Entry  *logrus.Entry
 	Config *LoggerConfig
+	// Sampler drops Debug/Info/Warn/Error entries according to LoggerConfig.Sampling.
+	// Fatal and Panic entries are never sampled. nil disables sampling.
+	Sampler *Sampler
 }
 
 // NewLogger returns an instance of logger
@@ -40,44 +44,61 @@ func NewLogger(logger *logrus.Logger, cfg ...*LoggerConfig) *Logger {
 	l.SetLevel(l.Level)
 
 	var config *LoggerConfig
+	var sampler *Sampler
 	if len(cfg) > 0 {
 		config = cfg[0]
+		if config != nil {
+			sampler = NewSampler(config.Sampling)
+		}
 	}
 
 	return &Logger{
-		Entry:  l.WithFields(logrus.Fields{}),
-		Config: config,
+		Entry:   l.WithFields(logrus.Fields{}),
+		Config:  config,
+		Sampler: sampler,
 	}
 }
 
 // Debug outputs debug level log.
 func (l *Logger) Debug(args ...any) {
-	l.Entry.Debug(args...)
+	if l.sampledArgs(logrus.DebugLevel, args) {
+		l.Entry.Debug(args...)
+	}
 }
 
 // Debugf outputs debug level log.
 func (l *Logger) Debugf(format string, args ...any) {
-	l.Entry.Debugf(format, args...)
+	if l.sampledFormat(logrus.DebugLevel, format) {
+		l.Entry.Debugf(format, args...)
+	}
 }
 
 // Debugln outputs debug level log.
 func (l *Logger) Debugln(args ...any) {
-	l.Entry.Debugln(args...)
+	if l.sampledArgs(logrus.DebugLevel, args) {
+		l.Entry.Debugln(args...)
+	}
 }
 
 // Error outputs error level log.
 func (l *Logger) Error(args ...any) {
-	l.Entry.Error(args...)
+	if l.sampledArgs(logrus.ErrorLevel, args) {
+		l.Entry.Error(args...)
+	}
 }
 
 // Errorf outputs error level log.
 func (l *Logger) Errorf(format string, args ...any) {
-	l.Entry.Errorf(format, args...)
+	if l.sampledFormat(logrus.ErrorLevel, format) {
+		l.Entry.Errorf(format, args...)
+	}
 }
 
 // Errorln outputs error level log.
 func (l *Logger) Errorln(args ...any) {
-	l.Entry.Errorln(args...)
+	if l.sampledArgs(logrus.ErrorLevel, args) {
+		l.Entry.Errorln(args...)
+	}
 }
 
 // Fatal outputs fatal level log.
@@ -102,17 +123,23 @@ func (l *Logger) GetEntry() *logrus.Entry {
 
 // Info outputs info level log.
 func (l *Logger) Info(args ...any) {
-	l.Entry.Info(args...)
+	if l.sampledArgs(logrus.InfoLevel, args) {
+		l.Entry.Info(args...)
+	}
 }
 
 // Infof outputs info level log.
 func (l *Logger) Infof(format string, args ...any) {
-	l.Entry.Infof(format, args...)
+	if l.sampledFormat(logrus.InfoLevel, format) {
+		l.Entry.Infof(format, args...)
+	}
 }
 
 // Infoln outputs info level log.
 func (l *Logger) Infoln(args ...any) {
-	l.Entry.Infoln(args...)
+	if l.sampledArgs(logrus.InfoLevel, args) {
+		l.Entry.Infoln(args...)
+	}
 }
 
 // Panic outputs panic log.
@@ -132,54 +159,73 @@ func (l *Logger) Panicln(args ...any) {
 
 // Print outputs printf.
 func (l *Logger) Print(args ...any) {
-	l.Entry.Print(args...)
+	if l.sampledArgs(logrus.InfoLevel, args) {
+		l.Entry.Print(args...)
+	}
 }
 
 // Printf outputs printf.
 func (l *Logger) Printf(format string, args ...any) {
-	l.Entry.Printf(format, args...)
+	if l.sampledFormat(logrus.InfoLevel, format) {
+		l.Entry.Printf(format, args...)
+	}
 }
 
 // Println outputs printf.
 func (l *Logger) Println(args ...any) {
-	l.Entry.Println(args...)
+	if l.sampledArgs(logrus.InfoLevel, args) {
+		l.Entry.Println(args...)
+	}
 }
 
 // Warn outputs warn level log.
 func (l *Logger) Warn(args ...any) {
-	l.Entry.Warn(args...)
+	if l.sampledArgs(logrus.WarnLevel, args) {
+		l.Entry.Warn(args...)
+	}
 }
 
 // Warnf outputs warn level log.
 func (l *Logger) Warnf(format string, args ...any) {
-	l.Entry.Warnf(format, args...)
+	if l.sampledFormat(logrus.WarnLevel, format) {
+		l.Entry.Warnf(format, args...)
+	}
 }
 
 // Warning outputs warn level log.
 func (l *Logger) Warning(args ...any) {
-	l.Entry.Warning(args...)
+	if l.sampledArgs(logrus.WarnLevel, args) {
+		l.Entry.Warning(args...)
+	}
 }
 
 // Warningf outputs warn level log.
 func (l *Logger) Warningf(format string, args ...any) {
-	l.Entry.Warningf(format, args...)
+	if l.sampledFormat(logrus.WarnLevel, format) {
+		l.Entry.Warningf(format, args...)
+	}
 }
 
 // Warningln outputs warn level log.
 func (l *Logger) Warningln(args ...any) {
-	l.Entry.Warningln(args...)
+	if l.sampledArgs(logrus.WarnLevel, args) {
+		l.Entry.Warningln(args...)
+	}
 }
 
 // Warnln outputs warn level log.
 func (l *Logger) Warnln(args ...any) {
-	l.Entry.Warnln(args...)
+	if l.sampledArgs(logrus.WarnLevel, args) {
+		l.Entry.Warnln(args...)
+	}
 }
 
 // WithContext calls WithContext function of logger entry.
 func (l *Logger) WithContext(ctx context.Context) *Logger {
 	return &Logger{
-		Entry:  l.Entry.WithContext(ctx),
-		Config: l.Config,
+		Entry:   l.Entry.WithContext(ctx),
+		Config:  l.Config,
+		Sampler: l.Sampler,
 	}
 }
 
@@ -190,8 +236,9 @@ func (l *Logger) WithContextValue(key string) *Logger {
 	}
 
 	return &Logger{
-		Entry:  l.Entry.WithField(key, l.Entry.Context.Value(key)),
-		Config: l.Config,
+		Entry:   l.Entry.WithField(key, l.Entry.Context.Value(key)),
+		Config:  l.Config,
+		Sampler: l.Sampler,
 	}
 }
 
@@ -202,13 +249,15 @@ func (l *Logger) WithError(err error) *Logger {
 	}
 	if e, ok := err.(stackTracer); ok {
 		return &Logger{
-			Entry:  l.Entry.WithField("stacktrace", fmt.Sprintf("%+v", e.StackTrace())).WithError(err),
-			Config: l.Config,
+			Entry:   l.Entry.WithField("stacktrace", fmt.Sprintf("%+v", e.StackTrace())).WithError(err),
+			Config:  l.Config,
+			Sampler: l.Sampler,
 		}
 	}
 	return &Logger{
-		Entry:  l.Entry.WithError(err),
-		Config: l.Config,
+		Entry:   l.Entry.WithError(err),
+		Config:  l.Config,
+		Sampler: l.Sampler,
 	}
 }
 
@@ -217,20 +266,51 @@ func (l *Logger) WithField(key string, value any) *Logger {
 	cfg := defaultConfig(l.Config)
 	if !cfg.AllowSensitive && isSensitiveKey(key) {
 		return &Logger{
-			Entry:  l.Entry.WithField(key, "[REDACTED]"),
-			Config: l.Config,
+			Entry:   l.Entry.WithField(key, "[REDACTED]"),
+			Config:  l.Config,
+			Sampler: l.Sampler,
 		}
 	}
 	return &Logger{
-		Entry:  l.Entry.WithField(key, value),
-		Config: l.Config,
+		Entry:   l.Entry.WithField(key, value),
+		Config:  l.Config,
+		Sampler: l.Sampler,
 	}
 }
 
 // WithFields calls WithField function of logger entry.
 func (l *Logger) WithFields(fields logrus.Fields) *Logger {
 	return &Logger{
-		Entry:  l.Entry.WithFields(SanitizeFields(fields, l.Config)),
-		Config: l.Config,
+		Entry:   l.Entry.WithFields(SanitizeFields(fields, l.Config)),
+		Config:  l.Config,
+		Sampler: l.Sampler,
+	}
+}
+
+// sampledArgs reports whether an entry built from args passes the sampler.
+// The first argument is used as the key, or its type when it is not a string,
+// so that entries with variable arguments (e.g. IDs or errors) are sampled together.
+func (l *Logger) sampledArgs(level logrus.Level, args []any) bool {
+	if l.Sampler == nil || !l.Entry.Logger.IsLevelEnabled(level) {
+		return true
+	}
+	var key string
+	if len(args) > 0 {
+		if s, ok := args[0].(string); ok {
+			key = s
+		} else {
+			key = fmt.Sprintf("%T", args[0])
+		}
+	}
+	return l.Sampler.Allow(level.String(), key, time.Now())
+}
+
+// sampledFormat reports whether an entry with format passes the sampler.
+// The format string is used as the key so that entries differing only in
+// their arguments are sampled together.
+func (l *Logger) sampledFormat(level logrus.Level, format string) bool {
+	if l.Sampler == nil || !l.Entry.Logger.IsLevelEnabled(level) {
+		return true
 	}
+	return l.Sampler.Allow(level.String(), format, time.Now())
 }
@@ -11,6 +11,9 @@ type LoggerConfig struct {
 	// AllowSensitive controls whether sensitive fields (password, token, etc.)
 	// are allowed to be output in clear text. Default: false (do not allow).
 	AllowSensitive bool
+	// Sampling enables log sampling for Debug/Info/Warn/Error entries.
+	// nil disables sampling.
+	Sampling *SamplingConfig
 }
 
 // defaultConfig returns a non-nil config with default values.
@@ -0,0 +1,163 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SamplingConfig sets log sampling. Within each Interval, the first First
+// entries with the same level and message are logged, and thereafter only
+// every Thereafter-th entry is logged. Thereafter of 0 drops every entry after First.
+type SamplingConfig struct {
+	Interval   time.Duration
+	First      int
+	Thereafter int
+}
+
+// Sampler decides whether a log entry is written according to SamplingConfig.
+// It is safe for concurrent use.
+type Sampler struct {
+	counts      map[string]int
+	windowStart time.Time
+	config      SamplingConfig
+	mu          sync.Mutex
+}
+
+// NewSampler returns a Sampler. It returns nil if cfg is nil, and a nil
+// *Sampler allows every entry.
+func NewSampler(cfg *SamplingConfig) *Sampler {
+	if cfg == nil {
+		return nil
+	}
+	conf := *cfg
+	if conf.Interval <= 0 {
+		conf.Interval = time.Second
+	}
+	return &Sampler{
+		counts: map[string]int{},
+		config: conf,
+	}
+}
+
+// Allow reports whether an entry with level and message at t should be logged.
+func (s *Sampler) Allow(level, message string, t time.Time) bool {
+	if s == nil {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t.Sub(s.windowStart) >= s.config.Interval {
+		s.windowStart = t
+		clear(s.counts)
+	}
+	key := level + "\x00" + message
+	s.counts[key]++
+	n := s.counts[key]
+	if n <= s.config.First {
+		return true
+	}
+	return s.config.Thereafter > 0 && (n-s.config.First)%s.config.Thereafter == 0
+}
+
+// samplingZapCore is a zapcore.Core that drops entries rejected by a Sampler.
+type samplingZapCore struct {
+	zapcore.Core
+	sampler *Sampler
+}
+
+// NewSamplingZapCore returns a zapcore.Core that samples entries before
+// passing them to core. It returns core unchanged if cfg is nil.
+func NewSamplingZapCore(core zapcore.Core, cfg *SamplingConfig) zapcore.Core {
+	if cfg == nil {
+		return core
+	}
+	return &samplingZapCore{Core: core, sampler: NewSampler(cfg)}
+}
+
+// With implements zapcore.Core. The sampler is shared with the parent.
+func (c *samplingZapCore) With(fields []zapcore.Field) zapcore.Core {
+	return &samplingZapCore{Core: c.Core.With(fields), sampler: c.sampler}
+}
+
+// Check implements zapcore.Core.
+func (c *samplingZapCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry { // nolint:gocritic
+	if !c.Enabled(ent.Level) || !c.sampler.Allow(ent.Level.String(), ent.Message, ent.Time) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// WithoutSampling returns a ZapLogger that writes every entry, bypassing the
+// sampling of LoggerConfig.Sampling, e.g. for errors that must always be logged.
+func (l *ZapLogger) WithoutSampling() *ZapLogger {
+	return &ZapLogger{
+		Logger: l.Logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			if c, ok := core.(*samplingZapCore); ok {
+				return c.Core
+			}
+			return core
+		})),
+	}
+}
+
+// samplingSlogHandler is a slog.Handler that drops records rejected by a Sampler.
+type samplingSlogHandler struct {
+	handler slog.Handler
+	sampler *Sampler
+}
+
+// NewSamplingSlogHandler returns a slog.Handler that samples records before
+// passing them to h. It returns h unchanged if cfg is nil.
+func NewSamplingSlogHandler(h slog.Handler, cfg *SamplingConfig) slog.Handler {
+	if cfg == nil {
+		return h
+	}
+	return &samplingSlogHandler{handler: h, sampler: NewSampler(cfg)}
+}
+
+// Enabled implements slog.Handler.
+func (h *samplingSlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler.
+func (h *samplingSlogHandler) Handle(ctx context.Context, r slog.Record) error { // nolint:gocritic
+	if !h.sampler.Allow(r.Level.String(), r.Message, r.Time) {
+		return nil
+	}
+	return h.handler.Handle(ctx, r) // nolint:wrapcheck
+}
+
+// WithAttrs implements slog.Handler. The sampler is shared with the parent.
+func (h *samplingSlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &samplingSlogHandler{handler: h.handler.WithAttrs(attrs), sampler: h.sampler}
+}
+
+// WithGroup implements slog.Handler. The sampler is shared with the parent.
+func (h *samplingSlogHandler) WithGroup(name string) slog.Handler {
+	return &samplingSlogHandler{handler: h.handler.WithGroup(name), sampler: h.sampler}
+}
+
+// WithoutSampling returns a SlogLogger that writes every record, bypassing the
+// sampling of SlogConfig.Sampling, e.g. for errors that must always be logged.
+func (l *SlogLogger) WithoutSampling() *SlogLogger {
+	if h, ok := l.log.Handler().(*samplingSlogHandler); ok {
+		return &SlogLogger{log: slog.New(h.handler)}
+	}
+	return l
+}
+
+// WithoutSampling returns a Logger that writes every entry, bypassing the
+// sampling of LoggerConfig.Sampling, e.g. for errors that must always be logged.
+func (l *Logger) WithoutSampling() *Logger {
+	return &Logger{
+		Entry:  l.Entry,
+		Config: l.Config,
+	}
+}
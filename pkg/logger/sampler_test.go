@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSampler_Allow(t *testing.T) {
+	s := NewSampler(&SamplingConfig{Interval: time.Minute, First: 2, Thereafter: 3})
+	now := time.Now()
+
+	var got []bool
+	for range 8 {
+		got = append(got, s.Allow("info", "msg", now))
+	}
+	assert.Equal(t, []bool{true, true, false, false, true, false, false, true}, got)
+	// other keys are counted separately
+	assert.True(t, s.Allow("error", "msg", now))
+	// counters reset after the interval
+	assert.True(t, s.Allow("info", "msg", now.Add(time.Minute)))
+}
+
+func TestSampler_ThereafterZero(t *testing.T) {
+	s := NewSampler(&SamplingConfig{First: 1})
+	now := time.Now()
+	assert.True(t, s.Allow("info", "msg", now))
+	assert.False(t, s.Allow("info", "msg", now))
+	assert.False(t, s.Allow("info", "msg", now))
+}
+
+func TestSampler_Nil(t *testing.T) {
+	var s *Sampler
+	assert.Nil(t, NewSampler(nil))
+	assert.True(t, s.Allow("info", "msg", time.Now()))
+}
+
+func TestLogger_Sampling(t *testing.T) {
+	var buf bytes.Buffer
+	l := logrus.New()
+	l.Out = &buf
+	log := NewLogger(l, &LoggerConfig{Sampling: &SamplingConfig{Interval: time.Hour, First: 1}})
+
+	for i := range 3 {
+		log.WithField("i", i).Infof("request %d", i)
+	}
+	log.Info("other")
+	log.Info("other")
+	log.Info("user ", 1)
+	log.Info("user ", 2)
+	log.Error(errors.New("error 1"))
+	log.Error(errors.New("error 2"))
+	log.Debug("below level is not counted")
+
+	assert.Equal(t, 4, strings.Count(buf.String(), "\n"))
+	assert.Contains(t, buf.String(), "request 0")
+	assert.Contains(t, buf.String(), "other")
+}
+
+func TestNewSamplingZapCore(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	l := &ZapLogger{Logger: zap.New(NewSamplingZapCore(core, &SamplingConfig{Interval: time.Hour, First: 1})).With(zap.String("key", "value"))}
+	for range 3 {
+		l.Info("same")
+	}
+	assert.Equal(t, 1, logs.Len())
+
+	// WithoutSampling keeps the fields and writes every entry.
+	for range 2 {
+		l.WithoutSampling().Info("same")
+	}
+	assert.Equal(t, 3, logs.Len())
+	assert.Equal(t, "value", logs.All()[2].ContextMap()["key"])
+
+	assert.Same(t, core, NewSamplingZapCore(core, nil))
+}
+
+func TestSlogLogger_Sampling(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewSlogLogger(&SlogConfig{
+		Output:   &buf,
+		Sampling: &SamplingConfig{Interval: time.Hour, First: 1, Thereafter: 2},
+	}).With("key", "value")
+	for range 3 {
+		l.Info("same")
+	}
+	assert.Equal(t, 2, strings.Count(buf.String(), "\n"))
+	assert.Contains(t, buf.String(), "key=value")
+}
@@ -0,0 +1,438 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	utilsSlack "github.com/y-miyazaki/go-common/pkg/utils/slack"
+
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"go.uber.org/zap/zapcore"
+)
+
+// SlackAlerter defaults.
+const (
+	defaultSlackAlertColor         = "danger"
+	defaultSlackAlertDedupWindow   = 5 * time.Minute
+	defaultSlackAlertRatePerMinute = 10
+	defaultSlackAlertBufferSize    = 100
+	defaultSlackAlertFlushTimeout  = 5 * time.Second
+)
+
+// SlackAttachmentPoster is the subset of repository.SlackRepository used by SlackAlerter.
+type SlackAttachmentPoster interface {
+	PostMessageAttachment(attachment *slack.Attachment) error
+}
+
+// SlackAlertConfig sets configurations of SlackAlerter.
+type SlackAlertConfig struct {
+	// ErrorHandler is called when an alert can't be delivered.
+	// Default: the error is written to stderr.
+	ErrorHandler func(err error)
+	// Function, AccountID, Region, Service and Env build the attachment title
+	// with utils/slack.GetSlackAWSTitle.
+	Function  string
+	AccountID string
+	Region    string
+	Service   string
+	Env       string
+	// Color is the attachment color. Default: danger.
+	Color string
+	// DedupWindow suppresses alerts with the same level and message within the window. Default: 5m.
+	DedupWindow time.Duration
+	// RatePerMinute is the maximum number of alerts sent per minute. Default: 10.
+	RatePerMinute int
+	// BufferSize is the number of alerts that can be queued before new alerts
+	// are dropped. Default: 100.
+	BufferSize int
+}
+
+// slackAlert is a queued alert.
+type slackAlert struct {
+	time       time.Time
+	fields     map[string]any
+	level      string
+	message    string
+	suppressed int
+	flush      chan struct{}
+}
+
+// SlackAlerter forwards error logs to Slack asynchronously with deduplication
+// and a per-minute rate limit, so that Slack outages never block the caller.
+// Use it through NewSlackAlertHook, NewSlackAlertZapCore or NewSlackAlertSlogHandler.
+type SlackAlerter struct {
+	poster      SlackAttachmentPoster
+	config      SlackAlertConfig
+	alerts      chan *slackAlert
+	stop        chan struct{}
+	done        chan struct{}
+	lastSent    map[string]time.Time
+	suppressed  map[string]int
+	windowStart time.Time
+	once        sync.Once
+	mu          sync.Mutex
+	windowCount int
+	dropped     atomic.Int64
+	closed      atomic.Bool
+}
+
+// NewSlackAlerter returns a SlackAlerter and starts its background delivery goroutine.
+func NewSlackAlerter(poster SlackAttachmentPoster, cfg *SlackAlertConfig) *SlackAlerter {
+	conf := SlackAlertConfig{}
+	if cfg != nil {
+		conf = *cfg
+	}
+	if conf.Color == "" {
+		conf.Color = defaultSlackAlertColor
+	}
+	if conf.DedupWindow <= 0 {
+		conf.DedupWindow = defaultSlackAlertDedupWindow
+	}
+	if conf.RatePerMinute <= 0 {
+		conf.RatePerMinute = defaultSlackAlertRatePerMinute
+	}
+	if conf.BufferSize <= 0 {
+		conf.BufferSize = defaultSlackAlertBufferSize
+	}
+	if conf.ErrorHandler == nil {
+		conf.ErrorHandler = func(err error) {
+			_, _ = fmt.Fprintf(os.Stderr, "slack alerter: %v\n", err)
+		}
+	}
+	a := &SlackAlerter{
+		poster:     poster,
+		config:     conf,
+		alerts:     make(chan *slackAlert, conf.BufferSize),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+		lastSent:   map[string]time.Time{},
+		suppressed: map[string]int{},
+	}
+	go a.run()
+	return a
+}
+
+// Alert queues an alert without blocking. It returns false when the alert was
+// deduplicated, rate limited, dropped because the buffer is full, or the
+// alerter is closed. fields are sanitized with SanitizeFields.
+func (a *SlackAlerter) Alert(t time.Time, level, message string, fields map[string]any) bool {
+	if a.closed.Load() {
+		return false
+	}
+	key := level + "\x00" + message
+	suppressed, ok := a.admit(key, t)
+	if !ok {
+		return false
+	}
+	alert := &slackAlert{
+		time:       t,
+		level:      level,
+		message:    message,
+		fields:     SanitizeFields(fields, nil),
+		suppressed: suppressed,
+	}
+	select {
+	case a.alerts <- alert:
+		return true
+	default:
+		a.dropped.Add(1)
+		return false
+	}
+}
+
+// Flush waits until queued alerts are delivered or ctx is done.
+func (a *SlackAlerter) Flush(ctx context.Context) error {
+	ack := &slackAlert{flush: make(chan struct{})}
+	select {
+	case a.alerts <- ack:
+	case <-a.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("slack alerter flush: %w", ctx.Err())
+	}
+	select {
+	case <-ack.flush:
+		return nil
+	case <-a.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("slack alerter flush: %w", ctx.Err())
+	}
+}
+
+// Close stops accepting alerts and waits until queued alerts are delivered or ctx is done.
+func (a *SlackAlerter) Close(ctx context.Context) error {
+	a.once.Do(func() {
+		a.closed.Store(true)
+		close(a.stop)
+	})
+	select {
+	case <-a.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("slack alerter close: %w", ctx.Err())
+	}
+}
+
+// Dropped returns the number of alerts dropped because the buffer was full
+// or the rate limit was exceeded.
+func (a *SlackAlerter) Dropped() int64 {
+	return a.dropped.Load()
+}
+
+// admit applies deduplication and the rate limit. It returns the number of
+// duplicates of key suppressed since it was last sent.
+func (a *SlackAlerter) admit(key string, t time.Time) (int, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if last, ok := a.lastSent[key]; ok && t.Sub(last) < a.config.DedupWindow {
+		a.suppressed[key]++
+		return 0, false
+	}
+	if t.Sub(a.windowStart) >= time.Minute {
+		a.windowStart = t
+		a.windowCount = 0
+	}
+	if a.windowCount >= a.config.RatePerMinute {
+		a.dropped.Add(1)
+		return 0, false
+	}
+	a.windowCount++
+	a.lastSent[key] = t
+	suppressed := a.suppressed[key]
+	delete(a.suppressed, key)
+	// drop expired entries so that the map doesn't grow unbounded. Suppressed
+	// counts are kept until the next alert of their key reports them.
+	for k, last := range a.lastSent {
+		if t.Sub(last) >= a.config.DedupWindow {
+			delete(a.lastSent, k)
+		}
+	}
+	return suppressed, true
+}
+
+// run delivers queued alerts until the alerter is closed.
+func (a *SlackAlerter) run() {
+	defer close(a.done)
+	for {
+		select {
+		case alert := <-a.alerts:
+			a.deliver(alert)
+		case <-a.stop:
+			for {
+				select {
+				case alert := <-a.alerts:
+					a.deliver(alert)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// deliver posts alert to Slack or acknowledges a flush request.
+func (a *SlackAlerter) deliver(alert *slackAlert) {
+	if alert.flush != nil {
+		close(alert.flush)
+		return
+	}
+	if err := a.poster.PostMessageAttachment(a.attachment(alert)); err != nil {
+		a.config.ErrorHandler(err)
+	}
+}
+
+// attachment formats alert with utils/slack helpers.
+func (a *SlackAlerter) attachment(alert *slackAlert) *slack.Attachment {
+	var b strings.Builder
+	b.WriteString(alert.message)
+	for _, k := range slices.Sorted(maps.Keys(alert.fields)) {
+		_, _ = fmt.Fprintf(&b, "\n%s: %v", k, alert.fields[k])
+	}
+	fields := []slack.AttachmentField{
+		{Title: "Level", Value: alert.level, Short: true},
+	}
+	if alert.suppressed > 0 {
+		fields = append(fields, slack.AttachmentField{
+			Title: "Suppressed",
+			Value: fmt.Sprintf("%d similar alerts suppressed", alert.suppressed),
+			Short: true,
+		})
+	}
+	return &slack.Attachment{
+		Color:  a.config.Color,
+		Title:  utilsSlack.GetSlackAWSTitle(a.config.Function, a.config.AccountID, a.config.Region, a.config.Service, a.config.Env),
+		Text:   utilsSlack.GetSlackLog(b.String()),
+		Fields: fields,
+		Ts:     json.Number(strconv.FormatInt(alert.time.Unix(), 10)),
+	}
+}
+
+// flushOnExit flushes queued alerts before the process exits on fatal/panic logs.
+func (a *SlackAlerter) flushOnExit() {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultSlackAlertFlushTimeout)
+	defer cancel()
+	_ = a.Flush(ctx)
+}
+
+// SlackAlertHook is a logrus.Hook that forwards Error, Fatal and Panic entries to Slack.
+type SlackAlertHook struct {
+	Alerter   *SlackAlerter
+	LogLevels []logrus.Level
+}
+
+// NewSlackAlertHook returns a hook for Error, Fatal and Panic levels.
+func NewSlackAlertHook(a *SlackAlerter) *SlackAlertHook {
+	return &SlackAlertHook{
+		Alerter:   a,
+		LogLevels: []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel},
+	}
+}
+
+// Levels implements logrus.Hook.
+func (h *SlackAlertHook) Levels() []logrus.Level {
+	return h.LogLevels
+}
+
+// Fire implements logrus.Hook.
+func (h *SlackAlertHook) Fire(entry *logrus.Entry) error {
+	fields := make(map[string]any, len(entry.Data))
+	for k, v := range entry.Data {
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		fields[k] = v
+	}
+	h.Alerter.Alert(entry.Time, entry.Level.String(), entry.Message, fields)
+	if entry.Level <= logrus.FatalLevel {
+		h.Alerter.flushOnExit()
+	}
+	return nil
+}
+
+// slackAlertZapCore is a zapcore.Core that forwards entries to SlackAlerter.
+type slackAlertZapCore struct {
+	zapcore.LevelEnabler
+	alerter *SlackAlerter
+	fields  []zapcore.Field
+}
+
+// NewSlackAlertZapCore returns a zapcore.Core that forwards entries at or above
+// level (zapcore.ErrorLevel if nil) to Slack.
+// Combine it with an existing logger using
+// zap.WrapCore(func(c zapcore.Core) zapcore.Core { return zapcore.NewTee(c, core) }).
+func NewSlackAlertZapCore(a *SlackAlerter, level zapcore.LevelEnabler) zapcore.Core {
+	enabler := level
+	if enabler == nil {
+		enabler = zapcore.ErrorLevel
+	}
+	return &slackAlertZapCore{LevelEnabler: enabler, alerter: a}
+}
+
+// With implements zapcore.Core.
+func (c *slackAlertZapCore) With(fields []zapcore.Field) zapcore.Core {
+	return &slackAlertZapCore{
+		LevelEnabler: c.LevelEnabler,
+		alerter:      c.alerter,
+		fields:       append(slices.Clip(c.fields), fields...),
+	}
+}
+
+// Check implements zapcore.Core.
+func (c *slackAlertZapCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return ce.AddCore(entry, c)
+	}
+	return ce
+}
+
+// Write implements zapcore.Core.
+func (c *slackAlertZapCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range c.fields {
+		f.AddTo(enc)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	c.alerter.Alert(entry.Time, entry.Level.String(), entry.Message, enc.Fields)
+	if entry.Level >= zapcore.DPanicLevel {
+		c.alerter.flushOnExit()
+	}
+	return nil
+}
+
+// Sync implements zapcore.Core.
+func (c *slackAlertZapCore) Sync() error {
+	return c.alerter.Flush(context.Background())
+}
+
+// slackAlertSlogHandler is a slog.Handler that forwards records to SlackAlerter.
+type slackAlertSlogHandler struct {
+	level   slog.Leveler
+	alerter *SlackAlerter
+	prefix  string
+	attrs   []slog.Attr
+}
+
+// NewSlackAlertSlogHandler returns a slog.Handler that forwards records at or
+// above level (slog.LevelError if nil) to Slack. Combine it with the main
+// handler using a fan-out handler.
+func NewSlackAlertSlogHandler(a *SlackAlerter, level slog.Leveler) slog.Handler {
+	leveler := level
+	if leveler == nil {
+		leveler = slog.LevelError
+	}
+	return &slackAlertSlogHandler{level: leveler, alerter: a}
+}
+
+// Enabled implements slog.Handler.
+func (h *slackAlertSlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle implements slog.Handler.
+func (h *slackAlertSlogHandler) Handle(_ context.Context, r slog.Record) error { // nolint:gocritic
+	fields := make(map[string]any, len(h.attrs)+r.NumAttrs())
+	for _, attr := range h.attrs {
+		fields[attr.Key] = attr.Value.Any()
+	}
+	r.Attrs(func(attr slog.Attr) bool {
+		fields[h.prefix+attr.Key] = attr.Value.Resolve().Any()
+		return true
+	})
+	h.alerter.Alert(r.Time, strings.ToLower(r.Level.String()), r.Message, fields)
+	return nil
+}
+
+// WithAttrs implements slog.Handler.
+func (h *slackAlertSlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := *h
+	next.attrs = slices.Clip(h.attrs)
+	for _, attr := range attrs {
+		next.attrs = append(next.attrs, slog.Attr{Key: h.prefix + attr.Key, Value: attr.Value.Resolve()})
+	}
+	return &next
+}
+
+// WithGroup implements slog.Handler.
+func (h *slackAlertSlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	next := *h
+	next.prefix = h.prefix + name + "."
+	return &next
+}
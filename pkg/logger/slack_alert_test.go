@@ -0,0 +1,154 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// fakeSlackPoster records posted attachments.
+type fakeSlackPoster struct {
+	err         error
+	attachments []*slack.Attachment
+	mu          sync.Mutex
+}
+
+func (f *fakeSlackPoster) PostMessageAttachment(attachment *slack.Attachment) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.attachments = append(f.attachments, attachment)
+	return f.err
+}
+
+func (f *fakeSlackPoster) posted() []*slack.Attachment {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]*slack.Attachment(nil), f.attachments...)
+}
+
+func TestSlackAlerter_Alert(t *testing.T) {
+	poster := &fakeSlackPoster{}
+	a := NewSlackAlerter(poster, &SlackAlertConfig{Function: "api", Env: "production"})
+
+	assert.True(t, a.Alert(time.Now(), "error", "db failed", map[string]any{"password": "secret", "id": 1}))
+	assert.NoError(t, a.Close(context.Background()))
+
+	posted := poster.posted()
+	assert.Len(t, posted, 1)
+	assert.Equal(t, "api | Env: production", posted[0].Title)
+	assert.Equal(t, "danger", posted[0].Color)
+	assert.Contains(t, posted[0].Text, "db failed")
+	assert.Contains(t, posted[0].Text, "id: 1")
+	assert.Contains(t, posted[0].Text, "password: [REDACTED]")
+	assert.Equal(t, "error", posted[0].Fields[0].Value)
+
+	assert.False(t, a.Alert(time.Now(), "error", "after close", nil))
+}
+
+func TestSlackAlerter_Dedup(t *testing.T) {
+	poster := &fakeSlackPoster{}
+	a := NewSlackAlerter(poster, &SlackAlertConfig{DedupWindow: time.Minute})
+
+	now := time.Now()
+	assert.True(t, a.Alert(now, "error", "same", nil))
+	assert.False(t, a.Alert(now.Add(time.Second), "error", "same", nil))
+	assert.False(t, a.Alert(now.Add(2*time.Second), "error", "same", nil))
+	assert.True(t, a.Alert(now.Add(time.Second), "error", "other", nil))
+	// the expired entry of "same" is cleaned up without losing its count
+	assert.True(t, a.Alert(now.Add(2*time.Minute), "error", "other", nil))
+	assert.True(t, a.Alert(now.Add(3*time.Minute), "error", "same", nil))
+	assert.NoError(t, a.Close(context.Background()))
+
+	posted := poster.posted()
+	assert.Len(t, posted, 4)
+	assert.Len(t, posted[3].Fields, 2)
+	assert.Equal(t, "2 similar alerts suppressed", posted[3].Fields[1].Value)
+}
+
+func TestSlackAlerter_RateLimit(t *testing.T) {
+	poster := &fakeSlackPoster{}
+	a := NewSlackAlerter(poster, &SlackAlertConfig{RatePerMinute: 2})
+
+	now := time.Now()
+	assert.True(t, a.Alert(now, "error", "1", nil))
+	assert.True(t, a.Alert(now, "error", "2", nil))
+	assert.False(t, a.Alert(now, "error", "3", nil))
+	assert.True(t, a.Alert(now.Add(time.Minute), "error", "4", nil))
+	assert.NoError(t, a.Close(context.Background()))
+	assert.Len(t, poster.posted(), 3)
+	assert.Equal(t, int64(1), a.Dropped())
+}
+
+func TestSlackAlerter_ErrorHandler(t *testing.T) {
+	poster := &fakeSlackPoster{err: errors.New("slack down")}
+	var got error
+	a := NewSlackAlerter(poster, &SlackAlertConfig{ErrorHandler: func(err error) { got = err }})
+
+	a.Alert(time.Now(), "error", "message", nil)
+	assert.NoError(t, a.Flush(context.Background()))
+	assert.ErrorContains(t, got, "slack down")
+	assert.NoError(t, a.Close(context.Background()))
+	assert.NoError(t, a.Flush(context.Background()))
+}
+
+func TestSlackAlertHook(t *testing.T) {
+	poster := &fakeSlackPoster{}
+	a := NewSlackAlerter(poster, nil)
+
+	l := logrus.New()
+	l.Out = &bytes.Buffer{}
+	l.AddHook(NewSlackAlertHook(a))
+	l.WithError(errors.New("boom")).Error("request failed")
+	l.Info("not forwarded")
+
+	assert.NoError(t, a.Close(context.Background()))
+	posted := poster.posted()
+	assert.Len(t, posted, 1)
+	assert.Contains(t, posted[0].Text, "request failed")
+	assert.Contains(t, posted[0].Text, "error: boom")
+}
+
+func TestNewSlackAlertZapCore(t *testing.T) {
+	poster := &fakeSlackPoster{}
+	a := NewSlackAlerter(poster, nil)
+
+	l := zap.New(NewSlackAlertZapCore(a, nil)).With(zap.String("service", "api"))
+	l.Error("zap failed", zap.Int("code", 500))
+	l.Warn("not forwarded")
+	assert.NoError(t, l.Sync())
+
+	assert.NoError(t, a.Close(context.Background()))
+	posted := poster.posted()
+	assert.Len(t, posted, 1)
+	assert.Contains(t, posted[0].Text, "zap failed")
+	assert.Contains(t, posted[0].Text, "code: 500")
+	assert.Contains(t, posted[0].Text, "service: api")
+	assert.True(t, NewSlackAlertZapCore(a, zapcore.WarnLevel).Enabled(zapcore.WarnLevel))
+}
+
+func TestNewSlackAlertSlogHandler(t *testing.T) {
+	poster := &fakeSlackPoster{}
+	a := NewSlackAlerter(poster, nil)
+
+	l := slog.New(NewSlackAlertSlogHandler(a, nil)).With("service", "api").WithGroup("req")
+	l.Error("slog failed", "id", "r1")
+	l.Warn("not forwarded")
+
+	assert.NoError(t, a.Close(context.Background()))
+	posted := poster.posted()
+	assert.Len(t, posted, 1)
+	assert.Contains(t, posted[0].Text, "slog failed")
+	assert.Contains(t, posted[0].Text, "service: api")
+	assert.Contains(t, posted[0].Text, "req.id: r1")
+	assert.Equal(t, "error", posted[0].Fields[0].Value)
+}
@@ -31,6 +31,8 @@ type SlogConfig struct {
 	Output io.Writer
 	// Format specifies the output format ("json" or "text")
 	Format string
+	// Sampling enables log sampling. nil disables sampling.
+	Sampling *SamplingConfig
 }
 
 // SlogLogger implements the logger interface
@@ -67,6 +69,7 @@ func NewSlogLogger(cfg *SlogConfig) *SlogLogger {
 	} else {
 		handler = slog.NewTextHandler(conf.Output, opts)
 	}
+	handler = NewSamplingSlogHandler(handler, conf.Sampling)
 
 	return &SlogLogger{
 		log: slog.New(handler),
@@ -10,8 +10,9 @@ type ZapLogger struct {
 	Logger *zap.Logger
 }
 
-// NewZapLogger returns an instance of logger
-func NewZapLogger(config *zap.Config) *ZapLogger {
+// NewZapLogger returns an instance of logger.
+// An optional LoggerConfig enables sampling with LoggerConfig.Sampling; its other fields are not used.
+func NewZapLogger(config *zap.Config, cfgs ...*LoggerConfig) *ZapLogger {
 	var cfg zap.Config
 	if config == nil {
 		cfg = zap.NewProductionConfig()
@@ -38,6 +39,11 @@ func NewZapLogger(config *zap.Config) *ZapLogger {
 	if err != nil {
 		logger = zap.NewNop()
 	}
+	if len(cfgs) > 0 && cfgs[0] != nil && cfgs[0].Sampling != nil {
+		logger = logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return NewSamplingZapCore(core, cfgs[0].Sampling)
+		}))
+	}
 	return &ZapLogger{Logger: logger}
 }
 
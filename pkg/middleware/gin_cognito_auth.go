@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/y-miyazaki/go-common/pkg/dto"
+	"github.com/y-miyazaki/go-common/pkg/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GinCognitoClaimsKey is the gin context key of verified Cognito claims.
+const GinCognitoClaimsKey = "cognitoClaims"
+
+// GinCognitoTokenVerifier verifies a Cognito token.
+// repository.AWSCognitoJWTVerifier satisfies this interface.
+type GinCognitoTokenVerifier interface {
+	Verify(ctx context.Context, token string) (*repository.AWSCognitoClaims, error)
+}
+
+// GinCognitoAuth verifies the Bearer token of the Authorization header and
+// stores the claims in the gin context. Requests without a valid token are
+// aborted with 401 Unauthorized. When the token can't be verified, e.g. the
+// JWKS can't be fetched, requests are aborted with 503 Service Unavailable.
+func GinCognitoAuth(v GinCognitoTokenVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, err := repository.GetAWSCognitoBearerToken(c.GetHeader("Authorization"))
+		if err != nil {
+			abortWithError(c, http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+			return
+		}
+		claims, err := v.Verify(c.Request.Context(), token)
+		if err != nil {
+			status := http.StatusServiceUnavailable
+			if isCognitoTokenError(err) {
+				status = http.StatusUnauthorized
+			}
+			abortWithError(c, status, http.StatusText(status))
+			return
+		}
+		c.Set(GinCognitoClaimsKey, claims)
+		c.Next()
+	}
+}
+
+// isCognitoTokenError reports whether err of GinCognitoTokenVerifier means the token is not valid.
+func isCognitoTokenError(err error) bool {
+	return errors.Is(err, repository.ErrAWSCognitoTokenInvalid) ||
+		errors.Is(err, repository.ErrAWSCognitoTokenUseNotAllowed) ||
+		errors.Is(err, repository.ErrAWSCognitoTokenClientNotAllowed) ||
+		errors.Is(err, repository.ErrAWSCognitoJWKSKeyNotFound)
+}
+
+// GinCognitoRequireGroups allows requests whose claims contain any of groups
+// in cognito:groups. It must be placed after GinCognitoAuth. Requests without
+// claims are aborted with 401 Unauthorized, and requests without a matching
+// group with 403 Forbidden.
+func GinCognitoRequireGroups(groups ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := GetCognitoClaims(c)
+		if !ok {
+			abortWithError(c, http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+			return
+		}
+		if !claims.HasAnyGroup(groups...) {
+			abortWithError(c, http.StatusForbidden, http.StatusText(http.StatusForbidden))
+			return
+		}
+		c.Next()
+	}
+}
+
+// GetCognitoClaims returns the claims stored by GinCognitoAuth.
+func GetCognitoClaims(c *gin.Context) (*repository.AWSCognitoClaims, bool) {
+	v, ok := c.Get(GinCognitoClaimsKey)
+	if !ok {
+		return nil, false
+	}
+	claims, ok := v.(*repository.AWSCognitoClaims)
+	return claims, ok && claims != nil
+}
+
+// abortWithError aborts the request with dto.HTTPBaseErrorResponse.
+func abortWithError(c *gin.Context, status int, message string) {
+	c.AbortWithStatusJSON(status, &dto.HTTPBaseErrorResponse{
+		Error: &dto.HTTPErrorResponse{Message: message},
+	})
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/y-miyazaki/go-common/pkg/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeCognitoTokenVerifier struct {
+	claims map[string]*repository.AWSCognitoClaims
+}
+
+func (f *fakeCognitoTokenVerifier) Verify(_ context.Context, token string) (*repository.AWSCognitoClaims, error) {
+	if c, ok := f.claims[token]; ok {
+		return c, nil
+	}
+	if token == "jwks-down" {
+		return nil, fmt.Errorf("cognito Verify: %w: %w", repository.ErrAWSCognitoJWKSFetch, errors.New("timeout"))
+	}
+	return nil, fmt.Errorf("cognito Verify: %w", repository.ErrAWSCognitoTokenInvalid)
+}
+
+func newCognitoAuthRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	v := &fakeCognitoTokenVerifier{claims: map[string]*repository.AWSCognitoClaims{
+		"admin-token": {Username: "admin-user", Groups: []string{"admin"}},
+		"user-token":  {Username: "user-1"},
+	}}
+	r := gin.New()
+	r.Use(GinCognitoAuth(v))
+	r.GET("/me", func(c *gin.Context) {
+		claims, _ := GetCognitoClaims(c)
+		c.String(http.StatusOK, claims.Username)
+	})
+	r.GET("/admin", GinCognitoRequireGroups("admin", "ops"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return r
+}
+
+func TestGinCognitoAuth(t *testing.T) {
+	r := newCognitoAuthRouter()
+	tests := []struct {
+		name          string
+		path          string
+		authorization string
+		wantBody      string
+		wantStatus    int
+	}{
+		{name: "valid token", path: "/me", authorization: "Bearer user-token", wantStatus: http.StatusOK, wantBody: "user-1"},
+		{name: "missing header", path: "/me", wantStatus: http.StatusUnauthorized, wantBody: `{"error":{"message":"Unauthorized"}}`},
+		{name: "not bearer", path: "/me", authorization: "Basic abc", wantStatus: http.StatusUnauthorized, wantBody: `{"error":{"message":"Unauthorized"}}`},
+		{name: "invalid token", path: "/me", authorization: "Bearer bad", wantStatus: http.StatusUnauthorized, wantBody: `{"error":{"message":"Unauthorized"}}`},
+		{name: "jwks unavailable", path: "/me", authorization: "Bearer jwks-down", wantStatus: http.StatusServiceUnavailable, wantBody: `{"error":{"message":"Service Unavailable"}}`},
+		{name: "group allowed", path: "/admin", authorization: "Bearer admin-token", wantStatus: http.StatusOK},
+		{name: "group forbidden", path: "/admin", authorization: "Bearer user-token", wantStatus: http.StatusForbidden, wantBody: `{"error":{"message":"Forbidden"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tt.path, http.NoBody)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestGinCognitoRequireGroups_WithoutAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/admin", GinCognitoRequireGroups("admin"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin", http.NoBody))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -12,15 +12,70 @@
 	"go.uber.org/zap"
 )
 
+// GinHTTPLoggerConfig sets optional configurations of GinHTTPLogger and GinHTTPZapLogger.
+type GinHTTPLoggerConfig struct {
+	// Message is the message of the request logs. The default is an empty message.
+	Message string
+	// Sampling samples logs of successful and fast requests keyed by method and
+	// route. Requests with status >= 400, gin context errors or slow requests
+	// are always logged, bypassing the sampling of the logger too. nil disables sampling.
+	Sampling *logger.SamplingConfig
+	// SlowThreshold marks requests taking at least this long with "slow": true.
+	// 0 disables slow request detection.
+	SlowThreshold time.Duration
+}
+
+// httpLogSampler decides whether a request log can be dropped.
+type httpLogSampler struct {
+	sampler       *logger.Sampler
+	slowThreshold time.Duration
+	message       string
+}
+
+// newHTTPLogSampler returns a httpLogSampler from the optional config.
+func newHTTPLogSampler(cfg []*GinHTTPLoggerConfig) *httpLogSampler {
+	if len(cfg) == 0 || cfg[0] == nil {
+		return &httpLogSampler{}
+	}
+	return &httpLogSampler{
+		sampler:       logger.NewSampler(cfg[0].Sampling),
+		slowThreshold: cfg[0].SlowThreshold,
+		message:       cfg[0].Message,
+	}
+}
+
+// isSlow reports whether the request took at least the slow threshold.
+func (s *httpLogSampler) isSlow(duration time.Duration) bool {
+	return s.slowThreshold > 0 && duration >= s.slowThreshold
+}
+
+// always reports whether the log of a request must not be dropped by sampling:
+// requests with status >= 400, gin context errors or slow requests.
+func (s *httpLogSampler) always(c *gin.Context, duration time.Duration, hasError bool) bool {
+	return hasError || s.isSlow(duration) || c.Writer.Status() >= http.StatusBadRequest
+}
+
+// skip reports whether the log of a successful, fast request is dropped by sampling.
+func (s *httpLogSampler) skip(c *gin.Context) bool {
+	return s.sampler != nil && !s.sampler.Allow(c.Request.Method, c.FullPath(), time.Now())
+}
+
 // GinHTTPLogger retrieves the request/response logs.
 // It logs HTTP requests and responses using logrus logger with configurable headers.
-func GinHTTPLogger(l *logger.Logger, traceIDHeader, clientIPHeader string,
+// An optional GinHTTPLoggerConfig enables sampling and slow request detection.
+func GinHTTPLogger(l *logger.Logger, traceIDHeader, clientIPHeader string, cfg ...*GinHTTPLoggerConfig,
 ) gin.HandlerFunc {
+	sampler := newHTTPLogSampler(cfg)
 	return func(c *gin.Context) {
 		// Record start time for duration calculation
 		start := time.Now()
 		c.Next()
 		duration := time.Since(start)
+		ginErr, errGet := gincontext.GetGinContextError(c)
+		always := sampler.always(c, duration, ginErr != nil || len(c.Errors) > 0)
+		if !always && sampler.skip(c) {
+			return
+		}
 		fields := logrus.Fields{
 			"host":      c.Request.Host,
 			"duration":  duration.String(),
@@ -31,36 +86,54 @@ func GinHTTPLogger(l *logger.Logger, traceIDHeader, clientIPHeader string,
 			"referer":   c.Request.Referer(),
 			"userAgent": c.Request.UserAgent(),
 		}
+		if sampler.isSlow(duration) {
+			fields["slow"] = true
+		}
 		if traceIDHeader != "" {
 			fields[traceIDHeader] = logger.SanitizeValue(traceIDHeader, c.Request.Header.Get(traceIDHeader))
 		}
 		// get error
 		loggerWithContext := l
-		if err, err2 := gincontext.GetGinContextError(c); err2 == nil {
-			loggerWithContext = loggerWithContext.WithError(err)
+		if always {
+			loggerWithContext = loggerWithContext.WithoutSampling()
+		}
+		if errGet == nil {
+			loggerWithContext = loggerWithContext.WithError(ginErr)
 		}
 		// get error message
 		if messages, err := gincontext.GetGinContextErrorMessage(c); err == nil {
 			loggerWithContext = loggerWithContext.WithField("messages", messages)
 		}
 		if c.Writer.Status() >= http.StatusInternalServerError {
-			loggerWithContext.WithFields(fields).Error()
+			loggerWithContext.WithFields(fields).Error(sampler.message)
 		} else {
-			loggerWithContext.WithFields(fields).Info()
+			loggerWithContext.WithFields(fields).Info(sampler.message)
 		}
 	}
 }
 
 // GinHTTPZapLogger retrieves the request/response logs.
+// An optional GinHTTPLoggerConfig enables sampling and slow request detection.
 func GinHTTPZapLogger(
 	l *logger.ZapLogger,
 	traceIDHeader, clientIPHeader string,
+	cfg ...*GinHTTPLoggerConfig,
 ) gin.HandlerFunc {
+	sampler := newHTTPLogSampler(cfg)
 	return func(c *gin.Context) {
 		start := time.Now()
 		c.Next()
 		duration := time.Since(start)
-		loggerWithContext := l.With(
+		ginErr, errGet := gincontext.GetGinContextError(c)
+		always := sampler.always(c, duration, ginErr != nil || len(c.Errors) > 0)
+		if !always && sampler.skip(c) {
+			return
+		}
+		base := l
+		if always {
+			base = base.WithoutSampling()
+		}
+		loggerWithContext := base.With(
 			zap.String("host", c.Request.Host),
 			zap.String("duration", duration.String()),
 			zap.String("clientIP", clientIP(c, clientIPHeader)),
@@ -70,22 +143,25 @@ func GinHTTPZapLogger(
 			zap.String("referer", c.Request.Referer()),
 			zap.String("userAgent", c.Request.UserAgent()),
 		)
+		if sampler.isSlow(duration) {
+			loggerWithContext = loggerWithContext.With(zap.Bool("slow", true))
+		}
 
 		if traceIDHeader != "" {
 			loggerWithContext = loggerWithContext.With(zap.String(traceIDHeader, logger.SanitizeValue(traceIDHeader, c.Request.Header.Get(traceIDHeader))))
 		}
 		// get error
-		if err, err2 := gincontext.GetGinContextError(c); err2 == nil {
-			loggerWithContext = loggerWithContext.WithError(err)
+		if errGet == nil {
+			loggerWithContext = loggerWithContext.WithError(ginErr)
 		}
 		// get error message
 		if messages, err := gincontext.GetGinContextErrorMessage(c); err == nil {
 			loggerWithContext = loggerWithContext.With(zap.String("messages", messages))
 		}
 		if c.Writer.Status() >= http.StatusInternalServerError {
-			loggerWithContext.Error("")
+			loggerWithContext.Error(sampler.message)
 		} else {
-			loggerWithContext.Info("")
+			loggerWithContext.Info(sampler.message)
 		}
 	}
 }
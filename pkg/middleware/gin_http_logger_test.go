@@ -1,9 +1,12 @@
 package middleware
 
 import (
+	"bytes"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/y-miyazaki/go-common/pkg/logger"
 
@@ -11,6 +14,8 @@
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestGinHTTPLogger(t *testing.T) {
@@ -102,3 +107,107 @@ func TestClientIP_WithoutHeader(t *testing.T) {
 	ip := clientIP(c, "X-Forwarded-For")
 	assert.Equal(t, "127.0.0.1", ip)
 }
+
+func TestGinHTTPLogger_Sampling(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	l := logrus.New()
+	l.Out = &buf
+
+	r := gin.New()
+	r.Use(GinHTTPLogger(logger.NewLogger(l), "", "", &GinHTTPLoggerConfig{
+		Sampling:      &logger.SamplingConfig{Interval: time.Hour, First: 1},
+		SlowThreshold: 50 * time.Millisecond,
+	}))
+	r.GET("/ok", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/bad", func(c *gin.Context) { c.Status(http.StatusBadRequest) })
+	r.GET("/slow", func(c *gin.Context) {
+		time.Sleep(60 * time.Millisecond)
+		c.Status(http.StatusOK)
+	})
+
+	for _, path := range []string{"/ok", "/ok", "/ok", "/bad", "/bad", "/slow", "/slow"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	out := buf.String()
+	assert.Equal(t, 1, strings.Count(out, "url=/ok"))
+	assert.Equal(t, 2, strings.Count(out, "url=/bad"))
+	assert.Equal(t, 2, strings.Count(out, "url=/slow"))
+	assert.Equal(t, 2, strings.Count(out, "slow=true"))
+}
+
+func TestGinHTTPZapLogger_Sampling(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	core, logs := observer.New(zapcore.InfoLevel)
+
+	r := gin.New()
+	r.Use(GinHTTPZapLogger(&logger.ZapLogger{Logger: zap.New(core)}, "", "", &GinHTTPLoggerConfig{
+		Sampling: &logger.SamplingConfig{Interval: time.Hour, First: 1},
+	}))
+	r.GET("/ok", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/error", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+
+	for _, path := range []string{"/ok", "/ok", "/error", "/error"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	assert.Equal(t, 3, logs.Len())
+}
+
+func TestGinHTTPZapLogger_Message(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	core, logs := observer.New(zapcore.InfoLevel)
+
+	r := gin.New()
+	r.Use(GinHTTPZapLogger(&logger.ZapLogger{Logger: zap.New(core)}, "", "", &GinHTTPLoggerConfig{Message: "http request"}))
+	r.GET("/ok", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ok", nil))
+
+	assert.Equal(t, 1, logs.FilterMessage("http request").Len())
+}
+
+func TestGinHTTPLogger_LoggerSampling(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	l := logrus.New()
+	l.Out = &buf
+
+	// The logger samples by level and message, so only the first success is logged
+	// while every 5xx bypasses it.
+	r := gin.New()
+	r.Use(GinHTTPLogger(logger.NewLogger(l, &logger.LoggerConfig{
+		Sampling: &logger.SamplingConfig{Interval: time.Hour, First: 1},
+	}), "", ""))
+	r.GET("/ok", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/error", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+
+	for _, path := range []string{"/ok", "/ok", "/error", "/error", "/error"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	out := buf.String()
+	assert.Equal(t, 1, strings.Count(out, "url=/ok"))
+	assert.Equal(t, 3, strings.Count(out, "url=/error"))
+	assert.Equal(t, 4, strings.Count(out, "\n"))
+}
+
+func TestGinHTTPZapLogger_LoggerSampling(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	core, logs := observer.New(zapcore.InfoLevel)
+
+	r := gin.New()
+	r.Use(GinHTTPZapLogger(&logger.ZapLogger{Logger: zap.New(logger.NewSamplingZapCore(core, &logger.SamplingConfig{
+		Interval: time.Hour,
+		First:    1,
+	}))}, "", ""))
+	r.GET("/ok", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/error", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+
+	for _, path := range []string{"/ok", "/ok", "/error", "/error", "/error"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	assert.Equal(t, 4, logs.Len())
+	assert.Equal(t, 3, logs.FilterMessage("").FilterField(zap.Int("status", http.StatusInternalServerError)).Len())
+}
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/y-miyazaki/go-common/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultRequestIDHeader is the header used for request correlation when
+	// GinRequestLoggerConfig.RequestIDHeader is empty.
+	DefaultRequestIDHeader = "X-Request-ID"
+	// requestIDBytes is the number of random bytes used for a generated request ID.
+	requestIDBytes = 16
+	// maxRequestIDLength is the maximum length of an incoming request ID.
+	maxRequestIDLength = 128
+)
+
+// GinRequestLoggerConfig sets configurations of request-scoped logger middleware.
+type GinRequestLoggerConfig struct {
+	// RequestIDHeader is the header read for an incoming request ID and written
+	// back to the response. Default: X-Request-ID.
+	RequestIDHeader string
+	// UserFunc returns the user identifier of the request (e.g. from auth claims).
+	// The "user" field is omitted when UserFunc is nil or returns an empty string.
+	UserFunc func(c *gin.Context) string
+}
+
+// GinRequestLogger creates a child logger enriched with request ID, route,
+// method and user, and stores it in the request context.Context.
+// Handlers, services and repositories can retrieve it with
+// logger.FromContext(c.Request.Context()).
+func GinRequestLogger(l *logger.Logger, cs *GinRequestLoggerConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID, user := requestIdentity(c, cs)
+		fields := logrus.Fields{
+			"requestID": requestID,
+			"route":     c.FullPath(),
+			"method":    c.Request.Method,
+		}
+		if user != "" {
+			fields["user"] = user
+		}
+		ctx := logger.NewContext(c.Request.Context(), l.WithFields(fields))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// GinRequestZapLogger creates a child zap logger enriched with request ID,
+// route, method and user, and stores it in the request context.Context.
+// It can be retrieved with logger.ZapFromContext(c.Request.Context()).
+func GinRequestZapLogger(l *logger.ZapLogger, cs *GinRequestLoggerConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID, user := requestIdentity(c, cs)
+		fields := []zap.Field{
+			zap.String("requestID", requestID),
+			zap.String("route", c.FullPath()),
+			zap.String("method", c.Request.Method),
+		}
+		if user != "" {
+			fields = append(fields, zap.String("user", user))
+		}
+		ctx := logger.NewZapContext(c.Request.Context(), l.With(fields...))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// GinRequestSlogLogger creates a child slog logger enriched with request ID,
+// route, method and user, and stores it in the request context.Context.
+// It can be retrieved with logger.SlogFromContext(c.Request.Context()).
+func GinRequestSlogLogger(l *logger.SlogLogger, cs *GinRequestLoggerConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID, user := requestIdentity(c, cs)
+		args := []any{
+			"requestID", requestID,
+			"route", c.FullPath(),
+			"method", c.Request.Method,
+		}
+		if user != "" {
+			args = append(args, "user", user)
+		}
+		ctx := logger.NewSlogContext(c.Request.Context(), l.With(args...))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// requestIdentity resolves the request ID (generating and echoing one when the
+// header is missing or invalid) and the user of the request.
+func requestIdentity(c *gin.Context, cs *GinRequestLoggerConfig) (requestID, user string) {
+	header := DefaultRequestIDHeader
+	if cs != nil && cs.RequestIDHeader != "" {
+		header = cs.RequestIDHeader
+	}
+	requestID = c.Request.Header.Get(header)
+	if !isValidRequestID(requestID) {
+		requestID = newRequestID()
+		c.Request.Header.Set(header, requestID)
+	}
+	c.Header(header, requestID)
+
+	if cs != nil && cs.UserFunc != nil {
+		user = cs.UserFunc(c)
+	}
+	return requestID, user
+}
+
+// isValidRequestID reports whether an incoming request ID can be logged and echoed as is:
+// 1 to 128 characters of letters, digits, '-', '_', '.' and ':', which covers UUIDs and trace IDs.
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		switch ch := requestID[i]; {
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9':
+		case ch == '-', ch == '_', ch == '.', ch == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns a random hex encoded request ID.
+func newRequestID() string {
+	b := make([]byte, requestIDBytes)
+	// crypto/rand.Read never returns an error on supported platforms.
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
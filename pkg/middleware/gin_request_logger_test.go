@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/y-miyazaki/go-common/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestGinRequestLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	l := logrus.New()
+	l.Out = &buf
+	l.Formatter = &logrus.JSONFormatter{}
+
+	r := gin.New()
+	r.Use(GinRequestLogger(logger.NewLogger(l), &GinRequestLoggerConfig{
+		UserFunc: func(_ *gin.Context) string { return "user-1" },
+	}))
+	r.GET("/users/:id", func(c *gin.Context) {
+		logger.FromContext(c.Request.Context()).Info("in handler")
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/users/10", nil)
+	req.Header.Set(DefaultRequestIDHeader, "req-123")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "req-123", w.Header().Get(DefaultRequestIDHeader))
+	out := buf.String()
+	assert.Contains(t, out, `"requestID":"req-123"`)
+	assert.Contains(t, out, `"route":"/users/:id"`)
+	assert.Contains(t, out, `"method":"GET"`)
+	assert.Contains(t, out, `"user":"user-1"`)
+}
+
+func TestGinRequestLogger_GeneratesRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(GinRequestLogger(logger.NewLogger(logrus.New()), &GinRequestLoggerConfig{RequestIDHeader: "X-Trace-ID"}))
+	var requestID string
+	r.GET("/test", func(c *gin.Context) {
+		requestID = c.Request.Header.Get("X-Trace-ID")
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/test", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Len(t, requestID, requestIDBytes*2)
+	assert.Equal(t, requestID, w.Header().Get("X-Trace-ID"))
+}
+
+func TestGinRequestLogger_ReplacesInvalidRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(GinRequestLogger(logger.NewLogger(logrus.New()), nil))
+	r.GET("/test", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for _, requestID := range []string{"req\nforged=1", strings.Repeat("a", maxRequestIDLength+1), "<script>"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/test", nil)
+		req.Header.Set(DefaultRequestIDHeader, requestID)
+		r.ServeHTTP(w, req)
+
+		assert.Len(t, w.Header().Get(DefaultRequestIDHeader), requestIDBytes*2)
+		assert.NotEqual(t, requestID, w.Header().Get(DefaultRequestIDHeader))
+	}
+}
+
+func TestIsValidRequestID(t *testing.T) {
+	assert.True(t, isValidRequestID("3f2504e0-4f89-11d3-9a0c-0305e82c3301"))
+	assert.True(t, isValidRequestID("1-5759e988-bd862e3fe1be46a994272793"))
+	assert.True(t, isValidRequestID(strings.Repeat("a", maxRequestIDLength)))
+	assert.False(t, isValidRequestID(""))
+	assert.False(t, isValidRequestID("id with space"))
+	assert.False(t, isValidRequestID("id\r\nSet-Cookie: a=b"))
+	assert.False(t, isValidRequestID(strings.Repeat("a", maxRequestIDLength+1)))
+}
+
+func TestGinRequestZapLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	core, logs := observer.New(zapcore.InfoLevel)
+	l := &logger.ZapLogger{Logger: zap.New(core)}
+
+	r := gin.New()
+	r.Use(GinRequestZapLogger(l, nil))
+	r.GET("/test", func(c *gin.Context) {
+		logger.ZapFromContext(c.Request.Context()).Info("in handler")
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/test", nil)
+	req.Header.Set(DefaultRequestIDHeader, "req-456")
+	r.ServeHTTP(w, req)
+
+	entries := logs.All()
+	assert.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, "req-456", fields["requestID"])
+	assert.Equal(t, "/test", fields["route"])
+	assert.NotContains(t, fields, "user")
+}
+
+func TestGinRequestSlogLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	l := logger.NewSlogLogger(&logger.SlogConfig{Output: &buf, Format: "json"})
+
+	r := gin.New()
+	r.Use(GinRequestSlogLogger(l, &GinRequestLoggerConfig{
+		UserFunc: func(_ *gin.Context) string { return "user-1" },
+	}))
+	r.GET("/users/:id", func(c *gin.Context) {
+		logger.SlogFromContext(c.Request.Context()).Info("in handler")
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/users/10", nil)
+	req.Header.Set(DefaultRequestIDHeader, "req-789")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, "req-789", w.Header().Get(DefaultRequestIDHeader))
+	out := buf.String()
+	assert.Contains(t, out, `"requestID":"req-789"`)
+	assert.Contains(t, out, `"route":"/users/:id"`)
+	assert.Contains(t, out, `"method":"GET"`)
+	assert.Contains(t, out, `"user":"user-1"`)
+}
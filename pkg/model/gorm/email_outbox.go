@@ -0,0 +1,42 @@
+package gorm
+
+import (
+	"time"
+)
+
+// EmailOutboxStatus is the delivery status of an EmailOutbox.
+type EmailOutboxStatus string
+
+// EmailOutboxStatus values.
+const (
+	// EmailOutboxStatusPending is waiting for delivery, including retries.
+	EmailOutboxStatusPending EmailOutboxStatus = "pending"
+	// EmailOutboxStatusSending is claimed by a worker until LockedUntil.
+	EmailOutboxStatusSending EmailOutboxStatus = "sending"
+	// EmailOutboxStatusSent is delivered to SES.
+	EmailOutboxStatusSent EmailOutboxStatus = "sent"
+	// EmailOutboxStatusFailed is given up after a permanent error or too many attempts.
+	EmailOutboxStatusFailed EmailOutboxStatus = "failed"
+)
+
+// EmailOutbox is an email job of the transactional outbox.
+// nolint:tagliatelle
+type EmailOutbox struct {
+	BaseModel
+	NextAttemptAt  time.Time         `mapstructure:"next_attempt_at" gorm:"column:next_attempt_at;type:timestamp;not null;index:idx_email_outbox_status_next_attempt_at,priority:2"`
+	LockedUntil    *time.Time        `mapstructure:"locked_until" gorm:"column:locked_until;type:timestamp"`
+	SentAt         *time.Time        `mapstructure:"sent_at" gorm:"column:sent_at;type:timestamp"`
+	IdempotencyKey string            `mapstructure:"idempotency_key" gorm:"column:idempotency_key;type:varchar(255);not null;uniqueIndex"`
+	Status         EmailOutboxStatus `mapstructure:"status" gorm:"column:status;type:varchar(16);not null;index:idx_email_outbox_status_next_attempt_at,priority:1"`
+	Payload        string            `mapstructure:"payload" gorm:"column:payload;type:text;not null"`
+	MessageID      string            `mapstructure:"message_id" gorm:"column:message_id;type:varchar(255);not null;default:''"`
+	LastError      string            `mapstructure:"last_error" gorm:"column:last_error;type:text"`
+	ID             uint64            `mapstructure:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	Attempts       int               `mapstructure:"attempts" gorm:"column:attempts;not null;default:0"`
+	MaxAttempts    int               `mapstructure:"max_attempts" gorm:"column:max_attempts;not null"`
+}
+
+// TableName returns the table name of EmailOutbox.
+func (EmailOutbox) TableName() string {
+	return "email_outbox"
+}
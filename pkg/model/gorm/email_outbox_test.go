@@ -0,0 +1,42 @@
+package gorm
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/schema"
+)
+
+func TestEmailOutbox_TableName(t *testing.T) {
+	assert.Equal(t, "email_outbox", EmailOutbox{}.TableName())
+}
+
+func TestEmailOutbox_Indexes(t *testing.T) {
+	s, err := schema.Parse(&EmailOutbox{}, &sync.Map{}, schema.NamingStrategy{})
+	require.NoError(t, err)
+	assert.Equal(t, "email_outbox", s.Table)
+	assert.Equal(t, []string{"id"}, s.PrimaryFieldDBNames)
+
+	indexes := map[string]*schema.Index{}
+	for _, idx := range s.ParseIndexes() {
+		indexes[idx.Name] = idx
+	}
+	require.Len(t, indexes, 2)
+
+	// IdempotencyKey deduplicates jobs enqueued more than once.
+	idempotency := indexes["idx_email_outbox_idempotency_key"]
+	require.NotNil(t, idempotency)
+	assert.Equal(t, "UNIQUE", idempotency.Class)
+	require.Len(t, idempotency.Fields, 1)
+	assert.Equal(t, "idempotency_key", idempotency.Fields[0].DBName)
+
+	// Workers claim due pending jobs by status, then next_attempt_at.
+	due := indexes["idx_email_outbox_status_next_attempt_at"]
+	require.NotNil(t, due)
+	assert.Empty(t, due.Class)
+	require.Len(t, due.Fields, 2)
+	assert.Equal(t, "status", due.Fields[0].DBName)
+	assert.Equal(t, "next_attempt_at", due.Fields[1].DBName)
+}
@@ -0,0 +1,451 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/document"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+)
+
+const (
+	// defaultAWSBedrockMaxToolIterations is the default maximum number of model calls in a tool-use loop.
+	defaultAWSBedrockMaxToolIterations = 10
+)
+
+var (
+	ErrAWSBedrockToolLoopLimit        = errors.New("tool use loop exceeded max iterations")
+	ErrAWSBedrockUnsupportedOutput    = errors.New("unsupported converse output")
+	ErrAWSBedrockMessagesEmpty        = errors.New("messages are empty")
+	ErrAWSBedrockToolNotFound         = errors.New("tool not found")
+	ErrAWSBedrockUnsupportedToolValue = errors.New("unsupported tool value")
+)
+
+// AWSBedrockToolUse is a request from the model to run a tool.
+type AWSBedrockToolUse struct {
+	Input json.RawMessage `json:"input,omitempty"`
+	ID    string          `json:"id"`
+	Name  string          `json:"name"`
+}
+
+// AWSBedrockToolResult is the result of a tool run returned to the model.
+// Content is sent as text when it is a string and as JSON otherwise.
+type AWSBedrockToolResult struct {
+	Content   any
+	ToolUseID string
+	IsError   bool
+}
+
+// AWSBedrockReasoning is the reasoning of a model with extended thinking.
+// It is kept in the conversation because the model requires it back,
+// unchanged, together with the results of the tools it requested.
+type AWSBedrockReasoning struct {
+	// Text is the reasoning text and Signature verifies that the model generated it.
+	Text      string
+	Signature string
+	// RedactedContent is reasoning encrypted by the model provider. Text is empty then.
+	RedactedContent []byte
+}
+
+// AWSBedrockContent is a content block of a message. Exactly one field is set.
+type AWSBedrockContent struct {
+	ToolUse    *AWSBedrockToolUse
+	ToolResult *AWSBedrockToolResult
+	File       *AWSBedrockFile
+	Reasoning  *AWSBedrockReasoning
+	Text       string
+}
+
+// AWSBedrockMessage is a message of a conversation.
+type AWSBedrockMessage struct {
+	Role    types.ConversationRole
+	Content []AWSBedrockContent
+}
+
+// NewAWSBedrockUserMessage returns a user message with text.
+func NewAWSBedrockUserMessage(text string) AWSBedrockMessage {
+	return AWSBedrockMessage{
+		Role:    types.ConversationRoleUser,
+		Content: []AWSBedrockContent{{Text: text}},
+	}
+}
+
+// NewAWSBedrockAssistantMessage returns an assistant message with text.
+func NewAWSBedrockAssistantMessage(text string) AWSBedrockMessage {
+	return AWSBedrockMessage{
+		Role:    types.ConversationRoleAssistant,
+		Content: []AWSBedrockContent{{Text: text}},
+	}
+}
+
+// Text returns the concatenated text content of the message.
+func (m *AWSBedrockMessage) Text() string {
+	var sb strings.Builder
+	for i := range m.Content {
+		sb.WriteString(m.Content[i].Text)
+	}
+	return sb.String()
+}
+
+// ToolUses returns the tool use requests of the message.
+func (m *AWSBedrockMessage) ToolUses() []AWSBedrockToolUse {
+	var res []AWSBedrockToolUse
+	for i := range m.Content {
+		if m.Content[i].ToolUse != nil {
+			res = append(res, *m.Content[i].ToolUse)
+		}
+	}
+	return res
+}
+
+// AWSBedrockInferenceConfig sets inference parameters. Nil fields use the model defaults.
+type AWSBedrockInferenceConfig struct {
+	MaxTokens     *int32
+	Temperature   *float32
+	TopP          *float32
+	StopSequences []string
+}
+
+// AWSBedrockToolHandler runs a tool with the JSON input requested by the model.
+// A returned string is sent to the model as text, other values as JSON.
+// A returned error is sent to the model as an error result so that it can recover.
+type AWSBedrockToolHandler func(ctx context.Context, input json.RawMessage) (any, error)
+
+// AWSBedrockTool defines a tool the model can use.
+type AWSBedrockTool struct {
+	// InputSchema is the JSON schema of the tool input.
+	InputSchema map[string]any
+	// Handler runs the tool. When a requested tool has no Handler, Converse
+	// returns the tool use request to the caller instead of looping.
+	Handler     AWSBedrockToolHandler
+	Name        string
+	Description string
+}
+
+// AWSBedrockConverseRequest is a request of Converse.
+type AWSBedrockConverseRequest struct {
+	InferenceConfig *AWSBedrockInferenceConfig
+	ModelID         string
+	System          []string
+	Messages        []AWSBedrockMessage
+	Tools           []AWSBedrockTool
+	// MaxToolIterations limits the number of model calls in the tool-use loop. Default: 10.
+	MaxToolIterations int
+}
+
+// AWSBedrockUsage is the token usage of a conversation.
+type AWSBedrockUsage struct {
+	InputTokens  int32 `json:"inputTokens"`
+	OutputTokens int32 `json:"outputTokens"`
+	TotalTokens  int32 `json:"totalTokens"`
+}
+
+// add adds the usage of a model call.
+func (u *AWSBedrockUsage) add(usage *types.TokenUsage) {
+	if usage == nil {
+		return
+	}
+	u.InputTokens += aws.ToInt32(usage.InputTokens)
+	u.OutputTokens += aws.ToInt32(usage.OutputTokens)
+	u.TotalTokens += aws.ToInt32(usage.TotalTokens)
+}
+
+// AWSBedrockConverseResponse is a response of Converse.
+type AWSBedrockConverseResponse struct {
+	// Message is the last assistant message.
+	Message AWSBedrockMessage
+	// StopReason is the stop reason of the last model call.
+	StopReason types.StopReason
+	// Messages is the request messages followed by every message of the
+	// tool-use loop, ready to be sent with the next user message.
+	Messages []AWSBedrockMessage
+	// Usage is the total usage of all model calls.
+	Usage AWSBedrockUsage
+}
+
+// Text returns the text of the last assistant message.
+func (r *AWSBedrockConverseResponse) Text() string {
+	return r.Message.Text()
+}
+
+// Converse sends the conversation to the model and returns the assistant
+// reply. When the model requests tools that have a Handler, the handlers are
+// called and their results are sent back until the model stops requesting tools.
+// When MaxToolIterations is reached, the response so far is returned with
+// ErrAWSBedrockToolLoopLimit so that the conversation can be continued or inspected.
+func (r *AWSBedrockRepository) Converse(ctx context.Context, req *AWSBedrockConverseRequest) (*AWSBedrockConverseResponse, error) {
+	if len(req.Messages) == 0 {
+		return nil, fmt.Errorf("converse: %w", ErrAWSBedrockMessagesEmpty)
+	}
+	maxIterations := req.MaxToolIterations
+	if maxIterations <= 0 {
+		maxIterations = defaultAWSBedrockMaxToolIterations
+	}
+	toolConfig := newAWSBedrockToolConfig(req.Tools)
+
+	res := &AWSBedrockConverseResponse{
+		Messages: append([]AWSBedrockMessage(nil), req.Messages...),
+	}
+	for range maxIterations {
+		messages, err := toAWSBedrockMessages(res.Messages)
+		if err != nil {
+			return nil, fmt.Errorf("converse: %w", err)
+		}
+		in := &bedrockruntime.ConverseInput{
+			ModelId:         aws.String(req.ModelID),
+			Messages:        messages,
+			System:          toAWSBedrockSystem(req.System),
+			InferenceConfig: toAWSBedrockInferenceConfig(req.InferenceConfig),
+			ToolConfig:      toolConfig,
+		}
+		out, err := awsBedrockRetry(ctx, &r.config, func() (*bedrockruntime.ConverseOutput, error) {
+			return r.Client.Converse(ctx, in)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("converse: %w", err)
+		}
+		res.Usage.add(out.Usage)
+		res.StopReason = out.StopReason
+
+		output, ok := out.Output.(*types.ConverseOutputMemberMessage)
+		if !ok {
+			return nil, fmt.Errorf("converse: %w: %T", ErrAWSBedrockUnsupportedOutput, out.Output)
+		}
+		message, err := fromAWSBedrockMessage(&output.Value)
+		if err != nil {
+			return nil, fmt.Errorf("converse: %w", err)
+		}
+		res.Message = message
+		res.Messages = append(res.Messages, message)
+
+		toolUses := message.ToolUses()
+		if out.StopReason != types.StopReasonToolUse || !hasAWSBedrockToolHandlers(req.Tools, toolUses) {
+			return res, nil
+		}
+		res.Messages = append(res.Messages, runAWSBedrockTools(ctx, req.Tools, toolUses))
+	}
+	return res, fmt.Errorf("converse: %w: %d", ErrAWSBedrockToolLoopLimit, maxIterations)
+}
+
+// hasAWSBedrockToolHandlers reports whether every requested tool has a Handler.
+// Unknown tools count as handled; they are answered with an error result.
+func hasAWSBedrockToolHandlers(tools []AWSBedrockTool, toolUses []AWSBedrockToolUse) bool {
+	if len(toolUses) == 0 {
+		return false
+	}
+	for _, u := range toolUses {
+		if tool := findAWSBedrockTool(tools, u.Name); tool != nil && tool.Handler == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// findAWSBedrockTool returns the tool named name, or nil.
+func findAWSBedrockTool(tools []AWSBedrockTool, name string) *AWSBedrockTool {
+	for i := range tools {
+		if tools[i].Name == name {
+			return &tools[i]
+		}
+	}
+	return nil
+}
+
+// runAWSBedrockTools runs the requested tools and returns a user message with their results.
+func runAWSBedrockTools(ctx context.Context, tools []AWSBedrockTool, toolUses []AWSBedrockToolUse) AWSBedrockMessage {
+	message := AWSBedrockMessage{Role: types.ConversationRoleUser}
+	for _, u := range toolUses {
+		result := &AWSBedrockToolResult{ToolUseID: u.ID}
+		tool := findAWSBedrockTool(tools, u.Name)
+		if tool == nil {
+			result.Content = fmt.Sprintf("%s: %s", ErrAWSBedrockToolNotFound, u.Name)
+			result.IsError = true
+		} else if v, err := tool.Handler(ctx, u.Input); err != nil {
+			result.Content = err.Error()
+			result.IsError = true
+		} else {
+			result.Content = v
+		}
+		message.Content = append(message.Content, AWSBedrockContent{ToolResult: result})
+	}
+	return message
+}
+
+// newAWSBedrockToolConfig converts tools to types.ToolConfiguration.
+func newAWSBedrockToolConfig(tools []AWSBedrockTool) *types.ToolConfiguration {
+	if len(tools) == 0 {
+		return nil
+	}
+	config := &types.ToolConfiguration{}
+	for i := range tools {
+		schema := tools[i].InputSchema
+		if schema == nil {
+			schema = map[string]any{"type": "object", "properties": map[string]any{}}
+		}
+		spec := types.ToolSpecification{
+			Name:        aws.String(tools[i].Name),
+			InputSchema: &types.ToolInputSchemaMemberJson{Value: document.NewLazyDocument(schema)},
+		}
+		if tools[i].Description != "" {
+			spec.Description = aws.String(tools[i].Description)
+		}
+		config.Tools = append(config.Tools, &types.ToolMemberToolSpec{Value: spec})
+	}
+	return config
+}
+
+// toAWSBedrockSystem converts system prompts to types.SystemContentBlock.
+func toAWSBedrockSystem(system []string) []types.SystemContentBlock {
+	if len(system) == 0 {
+		return nil
+	}
+	res := make([]types.SystemContentBlock, 0, len(system))
+	for _, s := range system {
+		res = append(res, &types.SystemContentBlockMemberText{Value: s})
+	}
+	return res
+}
+
+// toAWSBedrockInferenceConfig converts AWSBedrockInferenceConfig to types.InferenceConfiguration.
+func toAWSBedrockInferenceConfig(c *AWSBedrockInferenceConfig) *types.InferenceConfiguration {
+	if c == nil {
+		return nil
+	}
+	return &types.InferenceConfiguration{
+		MaxTokens:     c.MaxTokens,
+		Temperature:   c.Temperature,
+		TopP:          c.TopP,
+		StopSequences: c.StopSequences,
+	}
+}
+
+// toAWSBedrockMessages converts messages to types.Message.
+func toAWSBedrockMessages(messages []AWSBedrockMessage) ([]types.Message, error) {
+	res := make([]types.Message, 0, len(messages))
+	for i := range messages {
+		content := make([]types.ContentBlock, 0, len(messages[i].Content))
+		for j := range messages[i].Content {
+			block, err := toAWSBedrockContentBlock(&messages[i].Content[j])
+			if err != nil {
+				return nil, err
+			}
+			content = append(content, block)
+		}
+		res = append(res, types.Message{Role: messages[i].Role, Content: content})
+	}
+	return res, nil
+}
+
+// toAWSBedrockContentBlock converts AWSBedrockContent to types.ContentBlock.
+func toAWSBedrockContentBlock(c *AWSBedrockContent) (types.ContentBlock, error) {
+	switch {
+	case c.ToolUse != nil:
+		var input any = map[string]any{}
+		if len(c.ToolUse.Input) > 0 {
+			if err := json.Unmarshal(c.ToolUse.Input, &input); err != nil {
+				return nil, fmt.Errorf("unmarshal tool input: %w", err)
+			}
+		}
+		return &types.ContentBlockMemberToolUse{Value: types.ToolUseBlock{
+			ToolUseId: aws.String(c.ToolUse.ID),
+			Name:      aws.String(c.ToolUse.Name),
+			Input:     document.NewLazyDocument(input),
+		}}, nil
+	case c.ToolResult != nil:
+		block := types.ToolResultBlock{
+			ToolUseId: aws.String(c.ToolResult.ToolUseID),
+			Status:    types.ToolResultStatusSuccess,
+		}
+		if c.ToolResult.IsError {
+			block.Status = types.ToolResultStatusError
+		}
+		content, err := toAWSBedrockToolResultContent(c.ToolResult.Content)
+		if err != nil {
+			return nil, err
+		}
+		block.Content = []types.ToolResultContentBlock{content}
+		return &types.ContentBlockMemberToolResult{Value: block}, nil
+	case c.File != nil:
+		return toAWSBedrockFileContentBlock(c.File)
+	case c.Reasoning != nil:
+		if c.Reasoning.RedactedContent != nil {
+			return &types.ContentBlockMemberReasoningContent{
+				Value: &types.ReasoningContentBlockMemberRedactedContent{Value: c.Reasoning.RedactedContent},
+			}, nil
+		}
+		text := types.ReasoningTextBlock{Text: aws.String(c.Reasoning.Text)}
+		if c.Reasoning.Signature != "" {
+			text.Signature = aws.String(c.Reasoning.Signature)
+		}
+		return &types.ContentBlockMemberReasoningContent{
+			Value: &types.ReasoningContentBlockMemberReasoningText{Value: text},
+		}, nil
+	default:
+		return &types.ContentBlockMemberText{Value: c.Text}, nil
+	}
+}
+
+// toAWSBedrockToolResultContent converts a tool result value to text or JSON content.
+// Values are round-tripped through encoding/json so that struct tags are honored.
+func toAWSBedrockToolResultContent(v any) (types.ToolResultContentBlock, error) {
+	if s, ok := v.(string); ok {
+		return &types.ToolResultContentBlockMemberText{Value: s}, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAWSBedrockUnsupportedToolValue, err)
+	}
+	var doc any
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAWSBedrockUnsupportedToolValue, err)
+	}
+	// JSON tool results must be objects.
+	if _, ok := doc.(map[string]any); !ok {
+		doc = map[string]any{"result": doc}
+	}
+	return &types.ToolResultContentBlockMemberJson{Value: document.NewLazyDocument(doc)}, nil
+}
+
+// fromAWSBedrockMessage converts types.Message to AWSBedrockMessage.
+// Content blocks other than text, tool use and reasoning are skipped.
+func fromAWSBedrockMessage(m *types.Message) (AWSBedrockMessage, error) {
+	res := AWSBedrockMessage{Role: m.Role}
+	for _, block := range m.Content {
+		switch b := block.(type) {
+		case *types.ContentBlockMemberText:
+			res.Content = append(res.Content, AWSBedrockContent{Text: b.Value})
+		case *types.ContentBlockMemberToolUse:
+			input := json.RawMessage("{}")
+			if b.Value.Input != nil {
+				raw, err := b.Value.Input.MarshalSmithyDocument()
+				if err != nil {
+					return AWSBedrockMessage{}, fmt.Errorf("marshal tool input: %w", err)
+				}
+				input = raw
+			}
+			res.Content = append(res.Content, AWSBedrockContent{ToolUse: &AWSBedrockToolUse{
+				Input: input,
+				ID:    aws.ToString(b.Value.ToolUseId),
+				Name:  aws.ToString(b.Value.Name),
+			}})
+		case *types.ContentBlockMemberReasoningContent:
+			switch r := b.Value.(type) {
+			case *types.ReasoningContentBlockMemberReasoningText:
+				res.Content = append(res.Content, AWSBedrockContent{Reasoning: &AWSBedrockReasoning{
+					Text:      aws.ToString(r.Value.Text),
+					Signature: aws.ToString(r.Value.Signature),
+				}})
+			case *types.ReasoningContentBlockMemberRedactedContent:
+				res.Content = append(res.Content, AWSBedrockContent{Reasoning: &AWSBedrockReasoning{
+					RedactedContent: r.Value,
+				}})
+			}
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/document"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestConverseOutput(stopReason types.StopReason, content ...types.ContentBlock) *bedrockruntime.ConverseOutput {
+	return &bedrockruntime.ConverseOutput{
+		Output: &types.ConverseOutputMemberMessage{Value: types.Message{
+			Role:    types.ConversationRoleAssistant,
+			Content: content,
+		}},
+		StopReason: stopReason,
+		Usage: &types.TokenUsage{
+			InputTokens:  aws.Int32(10),
+			OutputTokens: aws.Int32(5),
+			TotalTokens:  aws.Int32(15),
+		},
+	}
+}
+
+func TestAWSBedrockRepository_Converse(t *testing.T) {
+	mockClient := new(MockAWSBedrockClient)
+	repo := NewAWSBedrockRepositoryWithInterface(mockClient)
+
+	mockClient.On("Converse", mock.Anything, mock.MatchedBy(func(input *bedrockruntime.ConverseInput) bool {
+		system, ok := input.System[0].(*types.SystemContentBlockMemberText)
+		text, ok2 := input.Messages[0].Content[0].(*types.ContentBlockMemberText)
+		return *input.ModelId == "anthropic.claude-v2" &&
+			ok && system.Value == "You are helpful." &&
+			ok2 && text.Value == "Hello" &&
+			input.Messages[0].Role == types.ConversationRoleUser &&
+			*input.InferenceConfig.MaxTokens == 100 &&
+			*input.InferenceConfig.Temperature == 0.5 &&
+			input.ToolConfig == nil
+	})).Return(newTestConverseOutput(types.StopReasonEndTurn, &types.ContentBlockMemberText{Value: "Hi!"}), nil)
+
+	res, err := repo.Converse(context.Background(), &AWSBedrockConverseRequest{
+		ModelID:  "anthropic.claude-v2",
+		System:   []string{"You are helpful."},
+		Messages: []AWSBedrockMessage{NewAWSBedrockUserMessage("Hello")},
+		InferenceConfig: &AWSBedrockInferenceConfig{
+			MaxTokens:   aws.Int32(100),
+			Temperature: aws.Float32(0.5),
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Hi!", res.Text())
+	assert.Equal(t, types.ConversationRoleAssistant, res.Message.Role)
+	assert.Equal(t, types.StopReasonEndTurn, res.StopReason)
+	assert.Equal(t, AWSBedrockUsage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15}, res.Usage)
+	assert.Equal(t, []AWSBedrockMessage{
+		NewAWSBedrockUserMessage("Hello"),
+		NewAWSBedrockAssistantMessage("Hi!"),
+	}, res.Messages)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSBedrockRepository_Converse_ToolUseLoop(t *testing.T) {
+	mockClient := new(MockAWSBedrockClient)
+	repo := NewAWSBedrockRepositoryWithInterface(mockClient)
+
+	type weatherInput struct {
+		City string `json:"city"`
+	}
+	var gotCity string
+	tools := []AWSBedrockTool{{
+		Name:        "get_weather",
+		Description: "Returns the weather of a city",
+		InputSchema: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"city": map[string]any{"type": "string"}},
+		},
+		Handler: func(_ context.Context, input json.RawMessage) (any, error) {
+			var in weatherInput
+			if err := json.Unmarshal(input, &in); err != nil {
+				return nil, err
+			}
+			gotCity = in.City
+			return map[string]any{"weather": "sunny"}, nil
+		},
+	}, {
+		Name: "failing_tool",
+		Handler: func(_ context.Context, _ json.RawMessage) (any, error) {
+			return nil, errors.New("tool failed")
+		},
+	}}
+
+	mockClient.On("Converse", mock.Anything, mock.MatchedBy(func(input *bedrockruntime.ConverseInput) bool {
+		return len(input.Messages) == 1 && len(input.ToolConfig.Tools) == 2
+	})).Return(newTestConverseOutput(types.StopReasonToolUse,
+		&types.ContentBlockMemberText{Value: "Let me check."},
+		&types.ContentBlockMemberToolUse{Value: types.ToolUseBlock{
+			ToolUseId: aws.String("tool-1"),
+			Name:      aws.String("get_weather"),
+			Input:     document.NewLazyDocument(map[string]any{"city": "Tokyo"}),
+		}},
+		&types.ContentBlockMemberToolUse{Value: types.ToolUseBlock{
+			ToolUseId: aws.String("tool-2"),
+			Name:      aws.String("failing_tool"),
+		}},
+	), nil).Once()
+	mockClient.On("Converse", mock.Anything, mock.MatchedBy(func(input *bedrockruntime.ConverseInput) bool {
+		if len(input.Messages) != 3 || input.Messages[2].Role != types.ConversationRoleUser {
+			return false
+		}
+		first, ok := input.Messages[2].Content[0].(*types.ContentBlockMemberToolResult)
+		second, ok2 := input.Messages[2].Content[1].(*types.ContentBlockMemberToolResult)
+		return ok && ok2 &&
+			*first.Value.ToolUseId == "tool-1" && first.Value.Status == types.ToolResultStatusSuccess &&
+			*second.Value.ToolUseId == "tool-2" && second.Value.Status == types.ToolResultStatusError
+	})).Return(newTestConverseOutput(types.StopReasonEndTurn, &types.ContentBlockMemberText{Value: "It's sunny."}), nil).Once()
+
+	res, err := repo.Converse(context.Background(), &AWSBedrockConverseRequest{
+		ModelID:  "anthropic.claude-v2",
+		Messages: []AWSBedrockMessage{NewAWSBedrockUserMessage("Weather in Tokyo?")},
+		Tools:    tools,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Tokyo", gotCity)
+	assert.Equal(t, "It's sunny.", res.Text())
+	assert.Len(t, res.Messages, 4)
+	assert.Equal(t, AWSBedrockUsage{InputTokens: 20, OutputTokens: 10, TotalTokens: 30}, res.Usage)
+	toolResults := res.Messages[2].Content
+	assert.Equal(t, map[string]any{"weather": "sunny"}, toolResults[0].ToolResult.Content)
+	assert.Equal(t, "tool failed", toolResults[1].ToolResult.Content)
+	assert.True(t, toolResults[1].ToolResult.IsError)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSBedrockRepository_Converse_ToolWithoutHandler(t *testing.T) {
+	mockClient := new(MockAWSBedrockClient)
+	repo := NewAWSBedrockRepositoryWithInterface(mockClient)
+
+	mockClient.On("Converse", mock.Anything, mock.Anything).Return(newTestConverseOutput(types.StopReasonToolUse,
+		&types.ContentBlockMemberToolUse{Value: types.ToolUseBlock{
+			ToolUseId: aws.String("tool-1"),
+			Name:      aws.String("search"),
+			Input:     document.NewLazyDocument(map[string]any{"q": "go"}),
+		}},
+	), nil).Once()
+
+	res, err := repo.Converse(context.Background(), &AWSBedrockConverseRequest{
+		ModelID:  "anthropic.claude-v2",
+		Messages: []AWSBedrockMessage{NewAWSBedrockUserMessage("Search go")},
+		Tools:    []AWSBedrockTool{{Name: "search"}},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, types.StopReasonToolUse, res.StopReason)
+	toolUses := res.Message.ToolUses()
+	require.Len(t, toolUses, 1)
+	assert.Equal(t, "search", toolUses[0].Name)
+	assert.JSONEq(t, `{"q":"go"}`, string(toolUses[0].Input))
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSBedrockRepository_Converse_Reasoning(t *testing.T) {
+	mockClient := new(MockAWSBedrockClient)
+	repo := NewAWSBedrockRepositoryWithInterface(mockClient)
+
+	mockClient.On("Converse", mock.Anything, mock.MatchedBy(func(input *bedrockruntime.ConverseInput) bool {
+		return len(input.Messages) == 1
+	})).Return(newTestConverseOutput(types.StopReasonToolUse,
+		&types.ContentBlockMemberReasoningContent{Value: &types.ReasoningContentBlockMemberReasoningText{
+			Value: types.ReasoningTextBlock{Text: aws.String("I need the time."), Signature: aws.String("sig")},
+		}},
+		&types.ContentBlockMemberReasoningContent{Value: &types.ReasoningContentBlockMemberRedactedContent{Value: []byte("secret")}},
+		&types.ContentBlockMemberToolUse{Value: types.ToolUseBlock{ToolUseId: aws.String("tool-1"), Name: aws.String("now")}},
+	), nil).Once()
+	// the reasoning is sent back unchanged with the tool result.
+	mockClient.On("Converse", mock.Anything, mock.MatchedBy(func(input *bedrockruntime.ConverseInput) bool {
+		if len(input.Messages) != 3 || len(input.Messages[1].Content) != 3 {
+			return false
+		}
+		text, ok := input.Messages[1].Content[0].(*types.ContentBlockMemberReasoningContent)
+		redacted, ok2 := input.Messages[1].Content[1].(*types.ContentBlockMemberReasoningContent)
+		if !ok || !ok2 {
+			return false
+		}
+		r, ok := text.Value.(*types.ReasoningContentBlockMemberReasoningText)
+		r2, ok2 := redacted.Value.(*types.ReasoningContentBlockMemberRedactedContent)
+		return ok && ok2 && aws.ToString(r.Value.Signature) == "sig" && string(r2.Value) == "secret"
+	})).Return(newTestConverseOutput(types.StopReasonEndTurn, &types.ContentBlockMemberText{Value: "It's noon."}), nil).Once()
+
+	res, err := repo.Converse(context.Background(), &AWSBedrockConverseRequest{
+		ModelID:  "anthropic.claude-3-7-sonnet",
+		Messages: []AWSBedrockMessage{NewAWSBedrockUserMessage("What time is it?")},
+		Tools: []AWSBedrockTool{{Name: "now", Handler: func(_ context.Context, _ json.RawMessage) (any, error) {
+			return "12:00", nil
+		}}},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "It's noon.", res.Text())
+	assert.Equal(t, &AWSBedrockReasoning{Text: "I need the time.", Signature: "sig"}, res.Messages[1].Content[0].Reasoning)
+	assert.Empty(t, res.Messages[1].Text())
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSBedrockRepository_Converse_ToolLoopLimit(t *testing.T) {
+	mockClient := new(MockAWSBedrockClient)
+	repo := NewAWSBedrockRepositoryWithInterface(mockClient)
+
+	mockClient.On("Converse", mock.Anything, mock.Anything).Return(newTestConverseOutput(types.StopReasonToolUse,
+		&types.ContentBlockMemberToolUse{Value: types.ToolUseBlock{ToolUseId: aws.String("t"), Name: aws.String("loop")}},
+	), nil)
+
+	res, err := repo.Converse(context.Background(), &AWSBedrockConverseRequest{
+		ModelID:  "anthropic.claude-v2",
+		Messages: []AWSBedrockMessage{NewAWSBedrockUserMessage("loop")},
+		Tools: []AWSBedrockTool{{Name: "loop", Handler: func(_ context.Context, _ json.RawMessage) (any, error) {
+			return "again", nil
+		}}},
+		MaxToolIterations: 2,
+	})
+
+	assert.ErrorIs(t, err, ErrAWSBedrockToolLoopLimit)
+	mockClient.AssertNumberOfCalls(t, "Converse", 2)
+	// the partial conversation is returned with the error.
+	require.NotNil(t, res)
+	assert.Len(t, res.Messages, 5)
+	assert.Equal(t, types.StopReasonToolUse, res.StopReason)
+}
+
+func TestAWSBedrockRepository_Converse_Error(t *testing.T) {
+	mockClient := new(MockAWSBedrockClient)
+	repo := NewAWSBedrockRepositoryWithInterface(mockClient)
+
+	mockClient.On("Converse", mock.Anything, mock.Anything).Return(nil, assert.AnError)
+
+	_, err := repo.Converse(context.Background(), &AWSBedrockConverseRequest{
+		ModelID:  "anthropic.claude-v2",
+		Messages: []AWSBedrockMessage{NewAWSBedrockUserMessage("Hello")},
+	})
+
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Contains(t, err.Error(), "converse")
+
+	_, err = repo.Converse(context.Background(), &AWSBedrockConverseRequest{ModelID: "anthropic.claude-v2"})
+	assert.ErrorIs(t, err, ErrAWSBedrockMessagesEmpty)
+	mockClient.AssertExpectations(t)
+}
+
+func TestToAWSBedrockToolResultContent(t *testing.T) {
+	content, err := toAWSBedrockToolResultContent("plain")
+	require.NoError(t, err)
+	assert.Equal(t, &types.ToolResultContentBlockMemberText{Value: "plain"}, content)
+
+	content, err = toAWSBedrockToolResultContent([]int{1, 2})
+	require.NoError(t, err)
+	doc, ok := content.(*types.ToolResultContentBlockMemberJson)
+	require.True(t, ok)
+	b, err := doc.Value.MarshalSmithyDocument()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"result":[1,2]}`, string(b))
+
+	_, err = toAWSBedrockToolResultContent(make(chan int))
+	assert.ErrorIs(t, err, ErrAWSBedrockUnsupportedToolValue)
+}
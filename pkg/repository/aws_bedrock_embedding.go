@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// AWSBedrockEmbeddingInputType is the input type of Cohere embedding models.
+type AWSBedrockEmbeddingInputType string
+
+// AWSBedrockEmbeddingInputType values.
+const (
+	// AWSBedrockEmbeddingInputTypeSearchDocument is for documents stored in a vector index.
+	AWSBedrockEmbeddingInputTypeSearchDocument AWSBedrockEmbeddingInputType = "search_document"
+	// AWSBedrockEmbeddingInputTypeSearchQuery is for search queries against a vector index.
+	AWSBedrockEmbeddingInputTypeSearchQuery AWSBedrockEmbeddingInputType = "search_query"
+	// AWSBedrockEmbeddingInputTypeClassification is for texts of a classifier.
+	AWSBedrockEmbeddingInputTypeClassification AWSBedrockEmbeddingInputType = "classification"
+	// AWSBedrockEmbeddingInputTypeClustering is for texts to be clustered.
+	AWSBedrockEmbeddingInputTypeClustering AWSBedrockEmbeddingInputType = "clustering"
+)
+
+const (
+	// awsBedrockCohereMaxBatchSize is the maximum number of texts of a Cohere embedding request.
+	awsBedrockCohereMaxBatchSize = 96
+)
+
+var (
+	ErrAWSBedrockEmbeddingModelUnsupported = errors.New("unsupported embedding model")
+	ErrAWSBedrockEmbeddingNotFound         = errors.New("embedding not found")
+)
+
+// AWSBedrockEmbeddingConfig sets configurations.
+type AWSBedrockEmbeddingConfig struct {
+	// Normalize normalizes the vectors of Titan Text Embeddings V2. Default: true.
+	Normalize *bool
+	// InputType is the input type of Cohere models. Default: search_document.
+	InputType AWSBedrockEmbeddingInputType
+	// Dimensions is the vector size of Titan Text Embeddings V2 (256, 512 or 1024). Default: model default.
+	Dimensions int
+	// BatchSize is the number of texts per Cohere request. Default and max: 96.
+	// Titan models embed a single text per request.
+	BatchSize int
+}
+
+// awsBedrockTitanEmbeddingRequest is the payload of Titan embedding models.
+type awsBedrockTitanEmbeddingRequest struct {
+	Normalize  *bool  `json:"normalize,omitempty"`
+	InputText  string `json:"inputText"`
+	Dimensions int    `json:"dimensions,omitempty"`
+}
+
+// awsBedrockTitanEmbeddingResponse is the response of Titan embedding models.
+type awsBedrockTitanEmbeddingResponse struct {
+	Embedding []float32 `json:"embedding"`
+}
+
+// awsBedrockCohereEmbeddingRequest is the payload of Cohere embedding models.
+type awsBedrockCohereEmbeddingRequest struct {
+	InputType AWSBedrockEmbeddingInputType `json:"input_type"` //nolint:tagliatelle // Cohere payload
+	Truncate  string                       `json:"truncate"`
+	Texts     []string                     `json:"texts"`
+}
+
+// awsBedrockCohereEmbeddingResponse is the response of Cohere embedding models.
+// Embeddings is a list of vectors, or an object keyed by embedding type
+// when embedding types are requested (e.g. Embed v4).
+type awsBedrockCohereEmbeddingResponse struct {
+	Embeddings json.RawMessage `json:"embeddings"`
+}
+
+// Embed returns the embedding vectors of texts in the same order, using a Titan
+// (amazon.titan-embed-*) or Cohere (cohere.embed-*) model. Texts are sent in
+// batches for Cohere models and one by one for Titan models.
+func (r *AWSBedrockRepository) Embed(ctx context.Context, modelID string, texts []string, cfgs ...*AWSBedrockEmbeddingConfig) ([][]float32, error) {
+	cfg := &AWSBedrockEmbeddingConfig{}
+	if len(cfgs) > 0 && cfgs[0] != nil {
+		cfg = cfgs[0]
+	}
+	switch {
+	case strings.Contains(modelID, "amazon.titan-embed"):
+		return r.embedTitan(ctx, modelID, texts, cfg)
+	case strings.Contains(modelID, "cohere.embed"):
+		return r.embedCohere(ctx, modelID, texts, cfg)
+	default:
+		return nil, fmt.Errorf("embed: %w: %s", ErrAWSBedrockEmbeddingModelUnsupported, modelID)
+	}
+}
+
+// EmbedText returns the embedding vector of text.
+func (r *AWSBedrockRepository) EmbedText(ctx context.Context, modelID, text string, cfgs ...*AWSBedrockEmbeddingConfig) ([]float32, error) {
+	vectors, err := r.Embed(ctx, modelID, []string{text}, cfgs...)
+	if err != nil {
+		return nil, err
+	}
+	return vectors[0], nil
+}
+
+// embedTitan embeds texts one by one with a Titan model.
+func (r *AWSBedrockRepository) embedTitan(ctx context.Context, modelID string, texts []string, cfg *AWSBedrockEmbeddingConfig) ([][]float32, error) {
+	normalize := cfg.Normalize
+	if normalize == nil && strings.Contains(modelID, "embed-text-v2") {
+		normalize = aws.Bool(true)
+	}
+	res := make([][]float32, 0, len(texts))
+	for _, text := range texts {
+		payload, err := json.Marshal(&awsBedrockTitanEmbeddingRequest{
+			InputText:  text,
+			Dimensions: cfg.Dimensions,
+			Normalize:  normalize,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("embed: %w", err)
+		}
+		body, err := r.InvokeModel(ctx, modelID, payload)
+		if err != nil {
+			return nil, fmt.Errorf("embed: %w", err)
+		}
+		var out awsBedrockTitanEmbeddingResponse
+		if err := json.Unmarshal(body, &out); err != nil {
+			return nil, fmt.Errorf("embed: unmarshal response: %w", err)
+		}
+		if len(out.Embedding) == 0 {
+			return nil, fmt.Errorf("embed: %w", ErrAWSBedrockEmbeddingNotFound)
+		}
+		res = append(res, out.Embedding)
+	}
+	return res, nil
+}
+
+// embedCohere embeds texts in batches with a Cohere model.
+func (r *AWSBedrockRepository) embedCohere(ctx context.Context, modelID string, texts []string, cfg *AWSBedrockEmbeddingConfig) ([][]float32, error) {
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 || batchSize > awsBedrockCohereMaxBatchSize {
+		batchSize = awsBedrockCohereMaxBatchSize
+	}
+	inputType := cfg.InputType
+	if inputType == "" {
+		inputType = AWSBedrockEmbeddingInputTypeSearchDocument
+	}
+	res := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += batchSize {
+		batch := texts[start:min(start+batchSize, len(texts))]
+		payload, err := json.Marshal(&awsBedrockCohereEmbeddingRequest{
+			Texts:     batch,
+			InputType: inputType,
+			Truncate:  "END",
+		})
+		if err != nil {
+			return nil, fmt.Errorf("embed: %w", err)
+		}
+		body, err := r.InvokeModel(ctx, modelID, payload)
+		if err != nil {
+			return nil, fmt.Errorf("embed: %w", err)
+		}
+		vectors, err := parseAWSBedrockCohereEmbeddings(body)
+		if err != nil {
+			return nil, fmt.Errorf("embed: %w", err)
+		}
+		if len(vectors) != len(batch) {
+			return nil, fmt.Errorf("embed: %w: got %d vectors for %d texts", ErrAWSBedrockEmbeddingNotFound, len(vectors), len(batch))
+		}
+		res = append(res, vectors...)
+	}
+	return res, nil
+}
+
+// parseAWSBedrockCohereEmbeddings returns the float vectors of a Cohere response.
+func parseAWSBedrockCohereEmbeddings(body []byte) ([][]float32, error) {
+	var out awsBedrockCohereEmbeddingResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w", err)
+	}
+	var vectors [][]float32
+	if err := json.Unmarshal(out.Embeddings, &vectors); err == nil {
+		return vectors, nil
+	}
+	var byType struct {
+		Float [][]float32 `json:"float"`
+	}
+	if err := json.Unmarshal(out.Embeddings, &byType); err != nil {
+		return nil, fmt.Errorf("unmarshal embeddings: %w", err)
+	}
+	return byType.Float, nil
+}
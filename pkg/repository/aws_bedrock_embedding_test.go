@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAWSBedrockRepository_Embed_Titan(t *testing.T) {
+	mockClient := new(MockAWSBedrockClient)
+	repo := NewAWSBedrockRepositoryWithInterface(mockClient)
+
+	for i, text := range []string{"first", "second"} {
+		mockClient.On("InvokeModel", mock.Anything, mock.MatchedBy(func(input *bedrockruntime.InvokeModelInput) bool {
+			var req map[string]any
+			_ = json.Unmarshal(input.Body, &req)
+			return *input.ModelId == "amazon.titan-embed-text-v2:0" &&
+				req["inputText"] == text && req["dimensions"] == float64(256) && req["normalize"] == true
+		})).Return(&bedrockruntime.InvokeModelOutput{
+			Body: fmt.Appendf(nil, `{"embedding":[%d,0.5],"inputTextTokenCount":1}`, i),
+		}, nil).Once()
+	}
+
+	vectors, err := repo.Embed(context.Background(), "amazon.titan-embed-text-v2:0", []string{"first", "second"},
+		&AWSBedrockEmbeddingConfig{Dimensions: 256})
+
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{0, 0.5}, {1, 0.5}}, vectors)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSBedrockRepository_Embed_Cohere(t *testing.T) {
+	mockClient := new(MockAWSBedrockClient)
+	repo := NewAWSBedrockRepositoryWithInterface(mockClient)
+
+	mockClient.On("InvokeModel", mock.Anything, mock.MatchedBy(func(input *bedrockruntime.InvokeModelInput) bool {
+		var req awsBedrockCohereEmbeddingRequest
+		_ = json.Unmarshal(input.Body, &req)
+		return len(req.Texts) == 2 && req.InputType == AWSBedrockEmbeddingInputTypeSearchQuery
+	})).Return(&bedrockruntime.InvokeModelOutput{Body: []byte(`{"embeddings":[[1,0],[0,1]]}`)}, nil).Once()
+	mockClient.On("InvokeModel", mock.Anything, mock.MatchedBy(func(input *bedrockruntime.InvokeModelInput) bool {
+		var req awsBedrockCohereEmbeddingRequest
+		_ = json.Unmarshal(input.Body, &req)
+		return len(req.Texts) == 1 && req.Texts[0] == "c"
+	})).Return(&bedrockruntime.InvokeModelOutput{Body: []byte(`{"embeddings":{"float":[[0.5,0.5]]}}`)}, nil).Once()
+
+	vectors, err := repo.Embed(context.Background(), "cohere.embed-multilingual-v3", []string{"a", "b", "c"},
+		&AWSBedrockEmbeddingConfig{BatchSize: 2, InputType: AWSBedrockEmbeddingInputTypeSearchQuery})
+
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{1, 0}, {0, 1}, {0.5, 0.5}}, vectors)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSBedrockRepository_Embed_Error(t *testing.T) {
+	mockClient := new(MockAWSBedrockClient)
+	repo := NewAWSBedrockRepositoryWithInterface(mockClient)
+
+	_, err := repo.Embed(context.Background(), "anthropic.claude-v2", []string{"a"})
+	assert.ErrorIs(t, err, ErrAWSBedrockEmbeddingModelUnsupported)
+
+	mockClient.On("InvokeModel", mock.Anything, mock.Anything).Return(&bedrockruntime.InvokeModelOutput{Body: []byte(`{"embeddings":[]}`)}, nil).Once()
+	_, err = repo.Embed(context.Background(), "cohere.embed-english-v3", []string{"a"})
+	assert.ErrorIs(t, err, ErrAWSBedrockEmbeddingNotFound)
+
+	mockClient.On("InvokeModel", mock.Anything, mock.Anything).Return(nil, assert.AnError).Once()
+	_, err = repo.EmbedText(context.Background(), "amazon.titan-embed-text-v1", "a")
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Contains(t, err.Error(), "embed")
+	mockClient.AssertExpectations(t)
+}
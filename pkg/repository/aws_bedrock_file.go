@@ -0,0 +1,347 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const (
+	// AWSBedrockMaxImageSize is the maximum size of an image in bytes (3.75 MB).
+	AWSBedrockMaxImageSize = 3_932_160
+	// AWSBedrockMaxDocumentSize is the maximum size of a document in bytes (4.5 MB).
+	AWSBedrockMaxDocumentSize = 4_718_592
+	// defaultAWSBedrockDocumentName is the document name used when a file has no usable name.
+	defaultAWSBedrockDocumentName = "document"
+)
+
+var (
+	ErrAWSBedrockFileFormatUnsupported = errors.New("unsupported file format")
+	ErrAWSBedrockFileTooLarge          = errors.New("file too large")
+	ErrAWSBedrockFileEmpty             = errors.New("file is empty")
+	ErrAWSBedrockPayloadUnsupported    = errors.New("unsupported model payload")
+)
+
+// awsBedrockFileMediaTypes maps the supported image and document formats to media types.
+var awsBedrockFileMediaTypes = map[string]string{
+	string(types.ImageFormatPng):     "image/png",
+	string(types.ImageFormatJpeg):    "image/jpeg",
+	string(types.ImageFormatGif):     "image/gif",
+	string(types.ImageFormatWebp):    "image/webp",
+	string(types.DocumentFormatPdf):  "application/pdf",
+	string(types.DocumentFormatCsv):  "text/csv",
+	string(types.DocumentFormatDoc):  "application/msword",
+	string(types.DocumentFormatDocx): "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	string(types.DocumentFormatXls):  "application/vnd.ms-excel",
+	string(types.DocumentFormatXlsx): "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	string(types.DocumentFormatHtml): "text/html",
+	string(types.DocumentFormatTxt):  "text/plain",
+	string(types.DocumentFormatMd):   "text/markdown",
+}
+
+// awsBedrockFileExtensions maps file extensions that differ from the format name.
+var awsBedrockFileExtensions = map[string]string{
+	"jpg":      string(types.ImageFormatJpeg),
+	"htm":      string(types.DocumentFormatHtml),
+	"markdown": string(types.DocumentFormatMd),
+}
+
+// awsBedrockDocumentNameInvalidChars matches characters not allowed in document names.
+var awsBedrockDocumentNameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9\s\-()\[\]]+`)
+
+// awsBedrockDocumentNameSpaces matches consecutive whitespaces, which are not allowed in document names.
+var awsBedrockDocumentNameSpaces = regexp.MustCompile(`\s+`)
+
+// AWSBedrockFile is an image or a document attached to a message.
+type AWSBedrockFile struct {
+	Data []byte
+	// Name is the file name. It is used to detect the format and as the document name,
+	// which must be unique in a request.
+	Name string
+	// Format is a types.ImageFormat or types.DocumentFormat value.
+	Format string
+}
+
+// NewAWSBedrockFile returns a file with the format detected from name and data.
+// It returns an error when the format is unsupported or the file is too large.
+func NewAWSBedrockFile(name string, data []byte) (*AWSBedrockFile, error) {
+	format, err := DetectAWSBedrockFileFormat(name, data)
+	if err != nil {
+		return nil, err
+	}
+	f := &AWSBedrockFile{Data: data, Name: name, Format: format}
+	if err := f.validate(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// NewAWSBedrockFileFromPath reads the file of filePath.
+func NewAWSBedrockFileFromPath(filePath string) (*AWSBedrockFile, error) {
+	file, err := os.Open(filepath.Clean(filePath))
+	if err != nil {
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(file, AWSBedrockMaxDocumentSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+	return NewAWSBedrockFile(filepath.Base(filePath), data)
+}
+
+// NewAWSBedrockFileFromS3 downloads the object of bucket and key with s3Repo.
+// Objects larger than AWSBedrockMaxDocumentSize are rejected without being read.
+func NewAWSBedrockFileFromS3(ctx context.Context, s3Repo *AWSS3Repository, bucket, key string) (*AWSBedrockFile, error) {
+	out, err := s3Repo.Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(s3Repo.normalizePath(key)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s3 GetObject: %w", err)
+	}
+	defer func() { _ = out.Body.Close() }()
+
+	if aws.ToInt64(out.ContentLength) > AWSBedrockMaxDocumentSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrAWSBedrockFileTooLarge, aws.ToInt64(out.ContentLength))
+	}
+	data, err := io.ReadAll(io.LimitReader(out.Body, AWSBedrockMaxDocumentSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("s3 read object: %w", err)
+	}
+	return NewAWSBedrockFile(path.Base(key), data)
+}
+
+// DetectAWSBedrockFileFormat returns the types.ImageFormat or types.DocumentFormat
+// value of a file. Images and PDF are detected from data, other documents
+// (e.g. CSV, DOCX, XLSX) from the extension of name.
+func DetectAWSBedrockFileFormat(name string, data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	for format, t := range awsBedrockFileMediaTypes {
+		if t == mediaType && (strings.HasPrefix(t, "image/") || format == string(types.DocumentFormatPdf)) {
+			return format, nil
+		}
+	}
+
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(name), "."))
+	if format, ok := awsBedrockFileExtensions[ext]; ok {
+		return format, nil
+	}
+	if _, ok := awsBedrockFileMediaTypes[ext]; ok {
+		return ext, nil
+	}
+
+	switch mediaType {
+	case "text/html":
+		return string(types.DocumentFormatHtml), nil
+	case "text/plain":
+		return string(types.DocumentFormatTxt), nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrAWSBedrockFileFormatUnsupported, contentType)
+}
+
+// IsImage reports whether the file is an image.
+func (f *AWSBedrockFile) IsImage() bool {
+	return strings.HasPrefix(f.MediaType(), "image/")
+}
+
+// MediaType returns the media type of the file format.
+func (f *AWSBedrockFile) MediaType() string {
+	return awsBedrockFileMediaTypes[f.Format]
+}
+
+// validate checks the format and the size of the file.
+func (f *AWSBedrockFile) validate() error {
+	if _, ok := awsBedrockFileMediaTypes[f.Format]; !ok {
+		return fmt.Errorf("%w: %q", ErrAWSBedrockFileFormatUnsupported, f.Format)
+	}
+	if len(f.Data) == 0 {
+		return ErrAWSBedrockFileEmpty
+	}
+	limit := AWSBedrockMaxDocumentSize
+	if f.IsImage() {
+		limit = AWSBedrockMaxImageSize
+	}
+	if len(f.Data) > limit {
+		return fmt.Errorf("%w: %d bytes exceeds %d bytes", ErrAWSBedrockFileTooLarge, len(f.Data), limit)
+	}
+	return nil
+}
+
+// documentName returns the file name converted to a valid document name:
+// alphanumeric characters, single whitespaces, hyphens, parentheses and square brackets.
+func (f *AWSBedrockFile) documentName() string {
+	name := strings.TrimSuffix(f.Name, filepath.Ext(f.Name))
+	name = awsBedrockDocumentNameInvalidChars.ReplaceAllString(name, " ")
+	name = strings.TrimSpace(awsBedrockDocumentNameSpaces.ReplaceAllString(name, " "))
+	if name == "" {
+		return defaultAWSBedrockDocumentName
+	}
+	return name
+}
+
+// NewAWSBedrockUserMessageWithFiles returns a user message with files followed by text.
+func NewAWSBedrockUserMessageWithFiles(text string, files ...*AWSBedrockFile) AWSBedrockMessage {
+	content := make([]AWSBedrockContent, 0, len(files)+1)
+	for _, f := range files {
+		content = append(content, AWSBedrockContent{File: f})
+	}
+	content = append(content, AWSBedrockContent{Text: text})
+	return AWSBedrockMessage{Role: types.ConversationRoleUser, Content: content}
+}
+
+// toAWSBedrockFileContentBlock converts a file to an image or a document content block.
+func toAWSBedrockFileContentBlock(f *AWSBedrockFile) (types.ContentBlock, error) {
+	if err := f.validate(); err != nil {
+		return nil, err
+	}
+	if f.IsImage() {
+		return &types.ContentBlockMemberImage{Value: types.ImageBlock{
+			Format: types.ImageFormat(f.Format),
+			Source: &types.ImageSourceMemberBytes{Value: f.Data},
+		}}, nil
+	}
+	return &types.ContentBlockMemberDocument{Value: types.DocumentBlock{
+		Format: types.DocumentFormat(f.Format),
+		Name:   aws.String(f.documentName()),
+		Source: &types.DocumentSourceMemberBytes{Value: f.Data},
+	}}, nil
+}
+
+// addAWSBedrockFileToPayload adds the file as base64 to the last user message of
+// an InvokeModel payload. Anthropic Messages API payloads (with "anthropic_version")
+// and Amazon Nova payloads (messages with content lists) are supported.
+// Payloads of other schemas (e.g. Amazon Titan) and payloads that already contain
+// the file data are returned unchanged, as the caller built them for the model.
+func addAWSBedrockFileToPayload(payload []byte, f *AWSBedrockFile) ([]byte, error) {
+	var body map[string]any
+	if err := json.Unmarshal(payload, &body); err != nil {
+		return payload, nil
+	}
+	messages, ok := body["messages"].([]any)
+	if !ok {
+		return payload, nil
+	}
+	data := base64.StdEncoding.EncodeToString(f.Data)
+	if containsAWSBedrockFileData(messages, data) {
+		return payload, nil
+	}
+
+	var message map[string]any
+	for i := len(messages) - 1; i >= 0; i-- {
+		if m, ok := messages[i].(map[string]any); ok && m["role"] == string(types.ConversationRoleUser) {
+			message = m
+			break
+		}
+	}
+	if message == nil {
+		return nil, fmt.Errorf("%w: user message not found", ErrAWSBedrockPayloadUnsupported)
+	}
+
+	_, anthropic := body["anthropic_version"]
+	var block map[string]any
+	var err error
+	if anthropic {
+		block, err = newAWSBedrockAnthropicFileBlock(f, data)
+	} else {
+		block = newAWSBedrockNovaFileBlock(f, data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var content []any
+	switch c := message["content"].(type) {
+	case []any:
+		content = c
+	case string:
+		if anthropic {
+			content = []any{map[string]any{"type": "text", "text": c}}
+		} else {
+			content = []any{map[string]any{"text": c}}
+		}
+	}
+	// Files are placed before the text, as recommended for image prompts.
+	message["content"] = append([]any{block}, content...)
+
+	res, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("marshal payload: %w", err)
+	}
+	return res, nil
+}
+
+// containsAWSBedrockFileData reports whether a string of the decoded JSON value v is data.
+func containsAWSBedrockFileData(v any, data string) bool {
+	switch v := v.(type) {
+	case string:
+		return v == data
+	case []any:
+		for _, e := range v {
+			if containsAWSBedrockFileData(e, data) {
+				return true
+			}
+		}
+	case map[string]any:
+		for _, e := range v {
+			if containsAWSBedrockFileData(e, data) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// newAWSBedrockAnthropicFileBlock returns a content block of the Anthropic Messages API.
+// Images, PDF and plain text documents are supported.
+func newAWSBedrockAnthropicFileBlock(f *AWSBedrockFile, data string) (map[string]any, error) {
+	switch {
+	case f.IsImage():
+		return map[string]any{
+			"type":   "image",
+			"source": map[string]any{"type": "base64", "media_type": f.MediaType(), "data": data},
+		}, nil
+	case f.Format == string(types.DocumentFormatPdf):
+		return map[string]any{
+			"type":   "document",
+			"source": map[string]any{"type": "base64", "media_type": f.MediaType(), "data": data},
+		}, nil
+	case strings.HasPrefix(f.MediaType(), "text/"):
+		return map[string]any{
+			"type":   "document",
+			"source": map[string]any{"type": "text", "media_type": "text/plain", "data": string(f.Data)},
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s for anthropic models", ErrAWSBedrockFileFormatUnsupported, f.Format)
+	}
+}
+
+// newAWSBedrockNovaFileBlock returns a content block of the Amazon Nova messages schema.
+func newAWSBedrockNovaFileBlock(f *AWSBedrockFile, data string) map[string]any {
+	if f.IsImage() {
+		return map[string]any{"image": map[string]any{
+			"format": f.Format,
+			"source": map[string]any{"bytes": data},
+		}}
+	}
+	return map[string]any{"document": map[string]any{
+		"format": f.Format,
+		"name":   f.documentName(),
+		"source": map[string]any{"bytes": data},
+	}}
+}
@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testAWSBedrockPNGData = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+const testAWSBedrockAnthropicPayload = `{"anthropic_version":"bedrock-2023-05-31","max_tokens":100,` +
+	`"messages":[{"role":"user","content":"Describe this file"}]}`
+
+func TestDetectAWSBedrockFileFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		want     string
+		data     []byte
+		wantErr  bool
+	}{
+		{name: "png", fileName: "", data: testAWSBedrockPNGData, want: "png"},
+		{name: "jpeg", fileName: "photo.bin", data: []byte("\xff\xd8\xff\xe0\x00\x10JFIF"), want: "jpeg"},
+		{name: "gif", data: []byte("GIF89a...."), want: "gif"},
+		{name: "pdf", fileName: "report", data: []byte("%PDF-1.7\n"), want: "pdf"},
+		{name: "docx by extension", fileName: "Report.DOCX", data: []byte("PK\x03\x04"), want: "docx"},
+		{name: "csv by extension", fileName: "data.csv", data: []byte("a,b\n1,2\n"), want: "csv"},
+		{name: "jpg extension", fileName: "photo.jpg", data: []byte{0x00, 0x01}, want: "jpeg"},
+		{name: "plain text", data: []byte("hello"), want: "txt"},
+		{name: "html", data: []byte("<html><body>hi</body></html>"), want: "html"},
+		{name: "unsupported", fileName: "archive.zip", data: []byte("PK\x03\x04"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectAWSBedrockFileFormat(tt.fileName, tt.data)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrAWSBedrockFileFormatUnsupported)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewAWSBedrockFile(t *testing.T) {
+	f, err := NewAWSBedrockFile("image.png", testAWSBedrockPNGData)
+	require.NoError(t, err)
+	assert.True(t, f.IsImage())
+	assert.Equal(t, "image/png", f.MediaType())
+
+	_, err = NewAWSBedrockFile("image.png", append(bytes.Clone(testAWSBedrockPNGData), make([]byte, AWSBedrockMaxImageSize)...))
+	assert.ErrorIs(t, err, ErrAWSBedrockFileTooLarge)
+
+	_, err = NewAWSBedrockFile("empty.csv", nil)
+	assert.ErrorIs(t, err, ErrAWSBedrockFileEmpty)
+
+	f, err = NewAWSBedrockFile("Q3 report (final).v2.docx", []byte("PK\x03\x04"))
+	require.NoError(t, err)
+	assert.False(t, f.IsImage())
+	assert.Equal(t, "Q3 report (final) v2", f.documentName())
+	assert.Equal(t, "document", (&AWSBedrockFile{Name: "...pdf"}).documentName())
+}
+
+func TestNewAWSBedrockFileFromPath(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "data.csv")
+	require.NoError(t, os.WriteFile(filePath, []byte("a,b\n1,2\n"), 0o600))
+
+	f, err := NewAWSBedrockFileFromPath(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, "csv", f.Format)
+	assert.Equal(t, "data.csv", f.Name)
+
+	_, err = NewAWSBedrockFileFromPath(filepath.Join(t.TempDir(), "missing.csv"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestNewAWSBedrockFileFromS3(t *testing.T) {
+	mockS3 := new(MockS3Client)
+	s3Repo := NewAWSS3RepositoryWithInterface(mockS3, nil, nil, nil)
+
+	mockS3.On("GetObject", mock.Anything, mock.MatchedBy(func(input *s3.GetObjectInput) bool {
+		return *input.Bucket == "bucket" && *input.Key == "docs/report.pdf"
+	}), mock.Anything).Return(&s3.GetObjectOutput{
+		Body:          io.NopCloser(bytes.NewReader([]byte("%PDF-1.7\n"))),
+		ContentLength: aws.Int64(9),
+	}, nil).Once()
+	f, err := NewAWSBedrockFileFromS3(context.Background(), s3Repo, "bucket", "docs/report.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, "pdf", f.Format)
+	assert.Equal(t, "report.pdf", f.Name)
+
+	mockS3.On("GetObject", mock.Anything, mock.Anything, mock.Anything).Return(&s3.GetObjectOutput{
+		Body:          io.NopCloser(bytes.NewReader(nil)),
+		ContentLength: aws.Int64(AWSBedrockMaxDocumentSize + 1),
+	}, nil).Once()
+	_, err = NewAWSBedrockFileFromS3(context.Background(), s3Repo, "bucket", "docs/large.pdf")
+	assert.ErrorIs(t, err, ErrAWSBedrockFileTooLarge)
+
+	mockS3.On("GetObject", mock.Anything, mock.Anything, mock.Anything).Return(nil, assert.AnError).Once()
+	_, err = NewAWSBedrockFileFromS3(context.Background(), s3Repo, "bucket", "docs/missing.pdf")
+	assert.ErrorIs(t, err, assert.AnError)
+	mockS3.AssertExpectations(t)
+}
+
+func TestAWSBedrockRepository_Converse_Files(t *testing.T) {
+	mockClient := new(MockAWSBedrockClient)
+	repo := NewAWSBedrockRepositoryWithInterface(mockClient)
+
+	image, err := NewAWSBedrockFile("chart.png", testAWSBedrockPNGData)
+	require.NoError(t, err)
+	doc, err := NewAWSBedrockFile("sales_2024.csv", []byte("a,b\n1,2\n"))
+	require.NoError(t, err)
+
+	mockClient.On("Converse", mock.Anything, mock.MatchedBy(func(input *bedrockruntime.ConverseInput) bool {
+		content := input.Messages[0].Content
+		img, ok := content[0].(*types.ContentBlockMemberImage)
+		document, ok2 := content[1].(*types.ContentBlockMemberDocument)
+		text, ok3 := content[2].(*types.ContentBlockMemberText)
+		return ok && ok2 && ok3 &&
+			img.Value.Format == types.ImageFormatPng &&
+			document.Value.Format == types.DocumentFormatCsv && *document.Value.Name == "sales 2024" &&
+			text.Value == "Summarize"
+	})).Return(newTestConverseOutput(types.StopReasonEndTurn, &types.ContentBlockMemberText{Value: "Summary"}), nil)
+
+	res, err := repo.Converse(context.Background(), &AWSBedrockConverseRequest{
+		ModelID:  "anthropic.claude-v2",
+		Messages: []AWSBedrockMessage{NewAWSBedrockUserMessageWithFiles("Summarize", image, doc)},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Summary", res.Text())
+	mockClient.AssertExpectations(t)
+
+	_, err = repo.Converse(context.Background(), &AWSBedrockConverseRequest{
+		ModelID:  "anthropic.claude-v2",
+		Messages: []AWSBedrockMessage{NewAWSBedrockUserMessageWithFiles("Summarize", &AWSBedrockFile{Format: "zip", Data: []byte("x")})},
+	})
+	assert.ErrorIs(t, err, ErrAWSBedrockFileFormatUnsupported)
+}
+
+func TestAddAWSBedrockFileToPayload(t *testing.T) {
+	image, err := NewAWSBedrockFile("", testAWSBedrockPNGData)
+	require.NoError(t, err)
+	data := base64.StdEncoding.EncodeToString(testAWSBedrockPNGData)
+
+	t.Run("anthropic", func(t *testing.T) {
+		res, err := addAWSBedrockFileToPayload([]byte(testAWSBedrockAnthropicPayload), image)
+		require.NoError(t, err)
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(res, &body))
+		content := body["messages"].([]any)[0].(map[string]any)["content"]
+		assert.Equal(t, []any{
+			map[string]any{"type": "image", "source": map[string]any{"type": "base64", "media_type": "image/png", "data": data}},
+			map[string]any{"type": "text", "text": "Describe this file"},
+		}, content)
+	})
+
+	t.Run("anthropic text document", func(t *testing.T) {
+		doc, err := NewAWSBedrockFile("notes.md", []byte("# Notes"))
+		require.NoError(t, err)
+		res, err := addAWSBedrockFileToPayload([]byte(testAWSBedrockAnthropicPayload), doc)
+		require.NoError(t, err)
+		assert.Contains(t, string(res), `{"data":"# Notes","media_type":"text/plain","type":"text"}`)
+
+		docx, err := NewAWSBedrockFile("notes.docx", []byte("PK\x03\x04"))
+		require.NoError(t, err)
+		_, err = addAWSBedrockFileToPayload([]byte(testAWSBedrockAnthropicPayload), docx)
+		assert.ErrorIs(t, err, ErrAWSBedrockFileFormatUnsupported)
+	})
+
+	t.Run("nova", func(t *testing.T) {
+		payload := `{"schemaVersion":"messages-v1","messages":[{"role":"user","content":[{"text":"Describe"}]}]}`
+		res, err := addAWSBedrockFileToPayload([]byte(payload), image)
+		require.NoError(t, err)
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(res, &body))
+		content := body["messages"].([]any)[0].(map[string]any)["content"]
+		assert.Equal(t, []any{
+			map[string]any{"image": map[string]any{"format": "png", "source": map[string]any{"bytes": data}}},
+			map[string]any{"text": "Describe"},
+		}, content)
+	})
+
+	t.Run("already contains data", func(t *testing.T) {
+		payload := []byte(`{"anthropic_version":"bedrock-2023-05-31","messages":[{"role":"user","content":[` +
+			`{"type":"image","source":{"type":"base64","media_type":"image/png","data":"` + data + `"}}]}]}`)
+		res, err := addAWSBedrockFileToPayload(payload, image)
+		require.NoError(t, err)
+		assert.Equal(t, payload, res)
+
+		// Data in another field, e.g. the system prompt, is not the attached file.
+		payload = []byte(`{"anthropic_version":"bedrock-2023-05-31","system":"` + data + `","messages":[{"role":"user","content":"Describe"}]}`)
+		res, err = addAWSBedrockFileToPayload(payload, image)
+		require.NoError(t, err)
+		assert.NotEqual(t, payload, res)
+	})
+
+	t.Run("other schemas", func(t *testing.T) {
+		for _, payload := range []string{
+			`{"inputText":"Describe","inputImage":"` + data + `"}`,
+			`{"taskType":"TEXT_IMAGE","textToImageParams":{"text":"cat"}}`,
+			`not json`,
+		} {
+			res, err := addAWSBedrockFileToPayload([]byte(payload), image)
+			require.NoError(t, err)
+			assert.Equal(t, payload, string(res))
+		}
+	})
+
+	t.Run("unsupported payload", func(t *testing.T) {
+		_, err := addAWSBedrockFileToPayload([]byte(`{"messages":[{"role":"assistant","content":"Hi"}]}`), image)
+		assert.ErrorIs(t, err, ErrAWSBedrockPayloadUnsupported)
+	})
+}
+
+func TestAWSBedrockRepository_InvokeModelWithAttachment(t *testing.T) {
+	mockClient := new(MockAWSBedrockClient)
+	repo := NewAWSBedrockRepositoryWithInterface(mockClient)
+
+	doc, err := NewAWSBedrockFile("report.pdf", []byte("%PDF-1.7\n"))
+	require.NoError(t, err)
+
+	mockClient.On("InvokeModel", mock.Anything, mock.MatchedBy(func(input *bedrockruntime.InvokeModelInput) bool {
+		return bytes.Contains(input.Body, []byte(`"type":"document"`)) &&
+			bytes.Contains(input.Body, []byte(`"media_type":"application/pdf"`))
+	})).Return(&bedrockruntime.InvokeModelOutput{Body: []byte(`{"content":[]}`)}, nil)
+
+	res, err := repo.InvokeModelWithAttachment(context.Background(), "anthropic.claude-v2", doc, []byte(testAWSBedrockAnthropicPayload))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"content":[]}`, string(res))
+	mockClient.AssertExpectations(t)
+
+	_, err = repo.InvokeModelWithAttachment(context.Background(), "anthropic.claude-v2", &AWSBedrockFile{Format: "pdf"}, []byte(testAWSBedrockAnthropicPayload))
+	assert.ErrorIs(t, err, ErrAWSBedrockFileEmpty)
+}
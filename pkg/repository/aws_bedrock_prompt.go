@@ -0,0 +1,281 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"text/template/parse"
+)
+
+var (
+	ErrAWSBedrockPromptNotFound      = errors.New("prompt template not found")
+	ErrAWSBedrockPromptVersionExists = errors.New("prompt template version already exists")
+	ErrAWSBedrockPromptInvalid       = errors.New("invalid prompt template")
+)
+
+// AWSBedrockPromptRegistry stores versioned prompt templates written in text/template.
+// Templates are parsed with missingkey=error, so that a missing variable fails
+// rendering instead of producing "<no value>" in the prompt.
+// It is safe for concurrent use.
+type AWSBedrockPromptRegistry struct {
+	templates map[string]map[int]*template.Template
+	funcs     template.FuncMap
+	mu        sync.RWMutex
+}
+
+// AWSBedrockPrompt is a prompt template of AWSBedrockPromptRegistry rendered with variables of type T.
+type AWSBedrockPrompt[T any] struct {
+	registry *AWSBedrockPromptRegistry
+	name     string
+	version  int
+}
+
+// NewAWSBedrockPromptRegistry returns an empty registry. funcs are added to
+// the functions available in templates.
+func NewAWSBedrockPromptRegistry(funcs ...template.FuncMap) *AWSBedrockPromptRegistry {
+	r := &AWSBedrockPromptRegistry{
+		templates: map[string]map[int]*template.Template{},
+		funcs:     template.FuncMap{"join": strings.Join, "trim": strings.TrimSpace},
+	}
+	for _, f := range funcs {
+		maps.Copy(r.funcs, f)
+	}
+	return r
+}
+
+// Register parses text and stores it as version of name. Versions start at 1
+// and are immutable: registering an existing version returns an error.
+func (r *AWSBedrockPromptRegistry) Register(name string, version int, text string) error {
+	return r.register(name, version, text, nil)
+}
+
+// register parses text, checks it with validate when set and stores it as version of name.
+func (r *AWSBedrockPromptRegistry) register(name string, version int, text string, validate func(*template.Template) error) error {
+	if version <= 0 {
+		return fmt.Errorf("prompt %s@%d: %w: version must be positive", name, version, ErrAWSBedrockPromptInvalid)
+	}
+	tmpl, err := template.New(fmt.Sprintf("%s@%d", name, version)).
+		Option("missingkey=error").
+		Funcs(r.funcs).
+		Parse(text)
+	if err == nil && validate != nil {
+		err = validate(tmpl)
+	}
+	if err != nil {
+		return fmt.Errorf("prompt %s@%d: %w: %w", name, version, ErrAWSBedrockPromptInvalid, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	versions, ok := r.templates[name]
+	if !ok {
+		versions = map[int]*template.Template{}
+		r.templates[name] = versions
+	}
+	if _, ok := versions[version]; ok {
+		return fmt.Errorf("prompt %s@%d: %w", name, version, ErrAWSBedrockPromptVersionExists)
+	}
+	versions[version] = tmpl
+	return nil
+}
+
+// Versions returns the registered versions of name in ascending order.
+func (r *AWSBedrockPromptRegistry) Versions(name string) []int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Sorted(maps.Keys(r.templates[name]))
+}
+
+// Render renders version of name with vars. Version 0 is the latest version.
+func (r *AWSBedrockPromptRegistry) Render(name string, version int, vars any) (string, error) {
+	tmpl, err := r.lookup(name, version)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, vars); err != nil {
+		return "", fmt.Errorf("prompt %s: render: %w", tmpl.Name(), err)
+	}
+	return sb.String(), nil
+}
+
+// lookup returns version of name, or the latest version when version is 0.
+func (r *AWSBedrockPromptRegistry) lookup(name string, version int) (*template.Template, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	versions := r.templates[name]
+	if version == 0 && len(versions) > 0 {
+		version = slices.Max(slices.Collect(maps.Keys(versions)))
+	}
+	tmpl, ok := versions[version]
+	if !ok {
+		return nil, fmt.Errorf("prompt %s@%d: %w", name, version, ErrAWSBedrockPromptNotFound)
+	}
+	return tmpl, nil
+}
+
+// RegisterAWSBedrockPrompt registers text as version of name and returns the
+// prompt rendered with variables of type T. The fields and methods the template
+// references on the variables are checked against T, so that references to
+// fields T doesn't have fail at registration instead of at request time.
+// References inside range and with blocks over values other than fields, and
+// references to map keys, are checked when rendering.
+func RegisterAWSBedrockPrompt[T any](r *AWSBedrockPromptRegistry, name string, version int, text string) (*AWSBedrockPrompt[T], error) {
+	validate := func(tmpl *template.Template) error {
+		c := &awsBedrockPromptChecker{root: reflect.TypeFor[T]()}
+		c.checkList(tmpl.Root, c.root)
+		return errors.Join(c.errs...)
+	}
+	if err := r.register(name, version, text, validate); err != nil {
+		return nil, err
+	}
+	return &AWSBedrockPrompt[T]{registry: r, name: name, version: version}, nil
+}
+
+// Render renders the prompt with vars.
+func (p *AWSBedrockPrompt[T]) Render(vars T) (string, error) {
+	return p.registry.Render(p.name, p.version, vars)
+}
+
+// Name returns the name of the prompt.
+func (p *AWSBedrockPrompt[T]) Name() string {
+	return p.name
+}
+
+// Version returns the version of the prompt.
+func (p *AWSBedrockPrompt[T]) Version() int {
+	return p.version
+}
+
+// awsBedrockPromptChecker checks the field references of a template against
+// the type of its variables. A nil type is unknown and isn't checked.
+type awsBedrockPromptChecker struct {
+	root reflect.Type
+	errs []error
+}
+
+// checkList checks the nodes of list with dot of type dot.
+func (c *awsBedrockPromptChecker) checkList(list *parse.ListNode, dot reflect.Type) {
+	if list == nil {
+		return
+	}
+	for _, node := range list.Nodes {
+		switch n := node.(type) {
+		case *parse.ActionNode:
+			c.checkPipe(n.Pipe, dot)
+		case *parse.IfNode:
+			c.checkPipe(n.Pipe, dot)
+			c.checkList(n.List, dot)
+			c.checkList(n.ElseList, dot)
+		case *parse.WithNode:
+			c.checkList(n.List, c.checkPipe(n.Pipe, dot))
+			c.checkList(n.ElseList, dot)
+		case *parse.RangeNode:
+			c.checkList(n.List, awsBedrockPromptElemType(c.checkPipe(n.Pipe, dot)))
+			c.checkList(n.ElseList, dot)
+		case *parse.TemplateNode:
+			c.checkPipe(n.Pipe, dot)
+		}
+	}
+}
+
+// checkPipe checks the commands of pipe and returns the type of its value
+// when it is a single field reference.
+func (c *awsBedrockPromptChecker) checkPipe(pipe *parse.PipeNode, dot reflect.Type) reflect.Type {
+	if pipe == nil {
+		return nil
+	}
+	var typ reflect.Type
+	for _, cmd := range pipe.Cmds {
+		for _, arg := range cmd.Args {
+			typ = c.checkArg(arg, dot)
+		}
+	}
+	if len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return nil
+	}
+	return typ
+}
+
+// checkArg checks a command argument and returns its type when it is known.
+func (c *awsBedrockPromptChecker) checkArg(arg parse.Node, dot reflect.Type) reflect.Type {
+	switch n := arg.(type) {
+	case *parse.DotNode:
+		return dot
+	case *parse.FieldNode:
+		return c.checkFields(dot, n.Ident)
+	case *parse.VariableNode:
+		// $ is the variables of the template; other variables are not tracked.
+		if n.Ident[0] == "$" {
+			return c.checkFields(c.root, n.Ident[1:])
+		}
+	case *parse.ChainNode:
+		if p, ok := n.Node.(*parse.PipeNode); ok {
+			return c.checkFields(c.checkPipe(p, dot), n.Field)
+		}
+	case *parse.PipeNode:
+		return c.checkPipe(n, dot)
+	}
+	return nil
+}
+
+// checkFields resolves the chain of field and method names on typ and
+// records an error for a name typ doesn't have.
+func (c *awsBedrockPromptChecker) checkFields(typ reflect.Type, names []string) reflect.Type {
+	for _, name := range names {
+		if typ == nil {
+			return nil
+		}
+		for typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+		if typ.Kind() == reflect.Interface {
+			return nil
+		}
+		// the method set of *T contains the methods of T.
+		if m, ok := reflect.PointerTo(typ).MethodByName(name); ok {
+			if m.Type.NumOut() == 0 {
+				return nil
+			}
+			typ = m.Type.Out(0)
+			continue
+		}
+		switch typ.Kind() {
+		case reflect.Struct:
+			f, ok := typ.FieldByName(name)
+			if !ok || !f.IsExported() {
+				c.errs = append(c.errs, fmt.Errorf("can't evaluate field %s in type %s", name, typ))
+				return nil
+			}
+			typ = f.Type
+		case reflect.Map:
+			// map keys are checked by missingkey=error when rendering.
+			typ = typ.Elem()
+		default:
+			c.errs = append(c.errs, fmt.Errorf("can't evaluate field %s in type %s", name, typ))
+			return nil
+		}
+	}
+	return typ
+}
+
+// awsBedrockPromptElemType returns the type of dot inside a range over a value of typ.
+func awsBedrockPromptElemType(typ reflect.Type) reflect.Type {
+	if typ == nil {
+		return nil
+	}
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Chan:
+		return typ.Elem()
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testAWSBedrockPromptVars struct {
+	User     *testAWSBedrockPromptUser
+	Meta     map[string]string
+	Question string
+	Context  []string
+	Docs     []testAWSBedrockPromptDoc
+}
+
+type testAWSBedrockPromptUser struct {
+	Name string
+}
+
+func (u *testAWSBedrockPromptUser) Greeting() string {
+	return "Hello, " + u.Name
+}
+
+type testAWSBedrockPromptDoc struct {
+	Title string
+}
+
+func TestAWSBedrockPromptRegistry(t *testing.T) {
+	registry := NewAWSBedrockPromptRegistry(template.FuncMap{"upper": strings.ToUpper})
+
+	require.NoError(t, registry.Register("answer", 1, "Q: {{.Question}}"))
+	require.NoError(t, registry.Register("answer", 2, "Context: {{join .Context \", \"}}\nQ: {{upper .Question}}"))
+	assert.Equal(t, []int{1, 2}, registry.Versions("answer"))
+
+	vars := testAWSBedrockPromptVars{Question: "why?", Context: []string{"a", "b"}}
+	res, err := registry.Render("answer", 1, vars)
+	require.NoError(t, err)
+	assert.Equal(t, "Q: why?", res)
+
+	res, err = registry.Render("answer", 0, vars)
+	require.NoError(t, err)
+	assert.Equal(t, "Context: a, b\nQ: WHY?", res)
+
+	_, err = registry.Render("answer", 3, vars)
+	assert.ErrorIs(t, err, ErrAWSBedrockPromptNotFound)
+	_, err = registry.Render("unknown", 0, vars)
+	assert.ErrorIs(t, err, ErrAWSBedrockPromptNotFound)
+
+	assert.ErrorIs(t, registry.Register("answer", 1, "changed"), ErrAWSBedrockPromptVersionExists)
+	assert.ErrorIs(t, registry.Register("broken", 1, "{{.Question"), ErrAWSBedrockPromptInvalid)
+	assert.ErrorIs(t, registry.Register("zero", 0, "{{.Question}}"), ErrAWSBedrockPromptInvalid)
+	assert.ErrorIs(t, registry.Register("negative", -1, "{{.Question}}"), ErrAWSBedrockPromptInvalid)
+
+	_, err = registry.Render("answer", 1, map[string]any{})
+	assert.Error(t, err, "missing variables fail rendering")
+}
+
+func TestRegisterAWSBedrockPrompt(t *testing.T) {
+	registry := NewAWSBedrockPromptRegistry()
+
+	prompt, err := RegisterAWSBedrockPrompt[testAWSBedrockPromptVars](registry, "summary", 1, "Summarize: {{.Question}}")
+	require.NoError(t, err)
+	assert.Equal(t, "summary", prompt.Name())
+	assert.Equal(t, 1, prompt.Version())
+	res, err := prompt.Render(testAWSBedrockPromptVars{Question: "text"})
+	require.NoError(t, err)
+	assert.Equal(t, "Summarize: text", res)
+
+	// fields are checked by type, so nil pointers and maps are fine.
+	prompt, err = RegisterAWSBedrockPrompt[testAWSBedrockPromptVars](registry, "summary", 2,
+		`{{.User.Greeting}} {{with .User}}{{.Name}}{{end}} {{.Meta.lang}} {{range .Docs}}{{.Title}} {{$.Question}}{{end}}`)
+	require.NoError(t, err)
+	res, err = prompt.Render(testAWSBedrockPromptVars{
+		User:     &testAWSBedrockPromptUser{Name: "Ann"},
+		Meta:     map[string]string{"lang": "en"},
+		Question: "q",
+		Docs:     []testAWSBedrockPromptDoc{{Title: "a"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Hello, Ann Ann en a q", res)
+
+	for _, text := range []string{
+		"{{.Typo}}",
+		"{{.User.Typo}}",
+		"{{with .User}}{{.Typo}}{{end}}",
+		"{{range .Docs}}{{.Typo}}{{end}}",
+		"{{range .Docs}}{{$.Typo}}{{end}}",
+		"{{if .Question}}{{.Question.Typo}}{{end}}",
+	} {
+		_, err = RegisterAWSBedrockPrompt[testAWSBedrockPromptVars](registry, "summary", 3, text)
+		assert.ErrorIs(t, err, ErrAWSBedrockPromptInvalid, text)
+	}
+	assert.Equal(t, []int{1, 2}, registry.Versions("summary"), "invalid templates aren't registered")
+}
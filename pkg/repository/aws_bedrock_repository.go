@@ -2,11 +2,7 @@
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"os"
-	"path/filepath"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
@@ -16,6 +12,8 @@
 type AWSBedrockClientInterface interface {
 	// Converse enables conversational interactions with models
 	Converse(_ context.Context, _ *bedrockruntime.ConverseInput, _ ...func(*bedrockruntime.Options)) (*bedrockruntime.ConverseOutput, error)
+	// ConverseStream enables conversational interactions with models with streaming response
+	ConverseStream(_ context.Context, _ *bedrockruntime.ConverseStreamInput, _ ...func(*bedrockruntime.Options)) (*bedrockruntime.ConverseStreamOutput, error)
 	// InvokeModel invokes a model with the provided input
 	InvokeModel(_ context.Context, _ *bedrockruntime.InvokeModelInput, _ ...func(*bedrockruntime.Options)) (*bedrockruntime.InvokeModelOutput, error)
 	// InvokeModelWithResponseStream invokes a model with streaming response
@@ -25,39 +23,21 @@ type AWSBedrockClientInterface interface {
 // AWSBedrockRepository struct.
 type AWSBedrockRepository struct {
 	Client AWSBedrockClientInterface
+	config AWSBedrockRepositoryConfig
 }
 
 // NewAWSBedrockRepository creates a new repository backed by the provided SDK client.
-func NewAWSBedrockRepository(c *bedrockruntime.Client) *AWSBedrockRepository {
+// Calls are retried on throttling as configured by cfgs.
+func NewAWSBedrockRepository(c *bedrockruntime.Client, cfgs ...*AWSBedrockRepositoryConfig) *AWSBedrockRepository {
 	if c == nil {
-		return &AWSBedrockRepository{}
+		return &AWSBedrockRepository{config: newAWSBedrockRepositoryConfig(cfgs)}
 	}
-	return &AWSBedrockRepository{Client: c}
+	return &AWSBedrockRepository{Client: c, config: newAWSBedrockRepositoryConfig(cfgs)}
 }
 
 // NewAWSBedrockRepositoryWithInterface creates a repository using the provided client interface (for testing).
-func NewAWSBedrockRepositoryWithInterface(c AWSBedrockClientInterface) *AWSBedrockRepository {
-	return &AWSBedrockRepository{Client: c}
-}
-
-// Converse wraps the Converse API for conversation-based models. It returns extracted text when possible.
-func (r *AWSBedrockRepository) Converse(ctx context.Context, modelID string, message any) (string, error) {
-	// For convenience, marshal message to JSON and pass as Message Content per docs.
-	b, err := json.Marshal(message)
-	if err != nil {
-		return "", fmt.Errorf("marshal message: %w", err)
-	}
-	// The Converse API input expects complex types; callers may prefer to call SDK directly for rich types.
-	in := &bedrockruntime.ConverseInput{
-		ModelId: aws.String(modelID),
-		// For simplicity this example omits constructing the full types.Message structures.
-		// Callers who need Converse should construct proper types.Message values and use the SDK directly.
-	}
-	_, err = r.Client.Converse(ctx, in)
-	if err != nil {
-		return "", fmt.Errorf("converse: %w", err)
-	}
-	return string(b), nil
+func NewAWSBedrockRepositoryWithInterface(c AWSBedrockClientInterface, cfgs ...*AWSBedrockRepositoryConfig) *AWSBedrockRepository {
+	return &AWSBedrockRepository{Client: c, config: newAWSBedrockRepositoryConfig(cfgs)}
 }
 
 // InvokeModel calls the Bedrock Runtime InvokeModel API for the specified modelID with JSON payload.
@@ -68,7 +48,9 @@ func (r *AWSBedrockRepository) InvokeModel(ctx context.Context, modelID string,
 		ContentType: aws.String("application/json"),
 		Body:        payload,
 	}
-	out, err := r.Client.InvokeModel(ctx, in)
+	out, err := awsBedrockRetry(ctx, &r.config, func() (*bedrockruntime.InvokeModelOutput, error) {
+		return r.Client.InvokeModel(ctx, in)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("invoke model: %w", err)
 	}
@@ -77,26 +59,15 @@ func (r *AWSBedrockRepository) InvokeModel(ctx context.Context, modelID string,
 }
 
 // InvokeModelWithFile calls the Bedrock Runtime InvokeModel API with a file attachment.
-// It reads the file from the provided filePath and returns the file data as bytes.
-// The caller is responsible for constructing the appropriate payload structure for their model.
+// It reads the file from the provided filePath, detects its format from the
+// name and the content, and adds it to payload like InvokeModelWithAttachment.
 func (r *AWSBedrockRepository) InvokeModelWithFile(ctx context.Context, modelID, filePath string, payload []byte) ([]byte, error) {
-	// Read file content
-	file, err := os.Open(filepath.Clean(filePath))
-	if err != nil {
-		return nil, fmt.Errorf("open file: %w", err)
-	}
-	defer func() {
-		if closeErr := file.Close(); closeErr != nil {
-			err = fmt.Errorf("close file: %w", closeErr)
-		}
-	}()
-
-	fileData, err := io.ReadAll(file)
+	f, err := NewAWSBedrockFileFromPath(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("read file: %w", err)
+		return nil, fmt.Errorf("invoke model with file: %w", err)
 	}
 
-	result, err := r.InvokeModelWithFileData(ctx, modelID, fileData, payload)
+	result, err := r.InvokeModelWithAttachment(ctx, modelID, f, payload)
 	if err != nil {
 		return nil, fmt.Errorf("invoke model with file: %w", err)
 	}
@@ -105,46 +76,27 @@ func (r *AWSBedrockRepository) InvokeModelWithFile(ctx context.Context, modelID,
 }
 
 // InvokeModelWithFileData calls the Bedrock Runtime InvokeModel API with file data.
-// This is a convenience wrapper that accepts fileData parameter for API clarity,
-// but the caller must encode and include the file data in the payload.
-// The fileData parameter exists to distinguish file-based operations from text-only operations.
+// The format of fileData is detected from the content, so documents other than
+// PDF and plain text need InvokeModelWithAttachment with a named file.
 //
 // Example for image models (Claude 3):
 //
-//	import "encoding/base64"
-//	import "encoding/json"
-//
-//	fileData := []byte("...image bytes...")
 //	payload := map[string]any{
 //	    "anthropic_version": "bedrock-2023-05-31",
 //	    "max_tokens": 1000,
 //	    "messages": []map[string]any{
-//	        {
-//	            "role": "user",
-//	            "content": []map[string]any{
-//	                {
-//	                    "type": "image",
-//	                    "source": map[string]string{
-//	                        "type": "base64",
-//	                        "media_type": "image/png",
-//	                        "data": base64.StdEncoding.EncodeToString(fileData),
-//	                    },
-//	                },
-//	                {
-//	                    "type": "text",
-//	                    "text": "Describe this image",
-//	                },
-//	            },
-//	        },
+//	        {"role": "user", "content": "Describe this image"},
 //	    },
 //	}
 //	payloadBytes, _ := json.Marshal(payload)
-//	result, err := repo.InvokeModelWithFileData(ctx, modelID, fileData, payloadBytes)
+//	result, err := repo.InvokeModelWithFileData(ctx, modelID, imageBytes, payloadBytes)
 func (r *AWSBedrockRepository) InvokeModelWithFileData(ctx context.Context, modelID string, fileData, payload []byte) ([]byte, error) {
-	// fileData parameter kept for API semantics; caller must include it in payload
-	_ = fileData // explicitly unused - included for method signature clarity
+	f, err := NewAWSBedrockFile("", fileData)
+	if err != nil {
+		return nil, fmt.Errorf("invoke model with file data: %w", err)
+	}
 
-	result, err := r.InvokeModel(ctx, modelID, payload)
+	result, err := r.InvokeModelWithAttachment(ctx, modelID, f, payload)
 	if err != nil {
 		return nil, fmt.Errorf("invoke model with file data: %w", err)
 	}
@@ -152,6 +104,23 @@ func (r *AWSBedrockRepository) InvokeModelWithFileData(ctx context.Context, mode
 	return result, nil
 }
 
+// InvokeModelWithAttachment calls the Bedrock Runtime InvokeModel API with f
+// added as base64 to the last user message of payload. Payloads of the
+// Anthropic Messages API and of Amazon Nova are supported. Use
+// NewAWSBedrockFileFromS3 to attach an object of Amazon S3.
+// Payloads of other models and payloads that already contain the file data
+// are sent unchanged.
+func (r *AWSBedrockRepository) InvokeModelWithAttachment(ctx context.Context, modelID string, f *AWSBedrockFile, payload []byte) ([]byte, error) {
+	if err := f.validate(); err != nil {
+		return nil, fmt.Errorf("invoke model with attachment: %w", err)
+	}
+	body, err := addAWSBedrockFileToPayload(payload, f)
+	if err != nil {
+		return nil, fmt.Errorf("invoke model with attachment: %w", err)
+	}
+	return r.InvokeModel(ctx, modelID, body)
+}
+
 // InvokeModelWithStream calls the streaming variant and returns the SDK output for callers that need streaming.
 func (r *AWSBedrockRepository) InvokeModelWithStream(ctx context.Context, modelID string, payload []byte) (*bedrockruntime.InvokeModelWithResponseStreamOutput, error) {
 	in := &bedrockruntime.InvokeModelWithResponseStreamInput{
@@ -159,7 +128,9 @@ func (r *AWSBedrockRepository) InvokeModelWithStream(ctx context.Context, modelI
 		ContentType: aws.String("application/json"),
 		Body:        payload,
 	}
-	out, err := r.Client.InvokeModelWithResponseStream(ctx, in)
+	out, err := awsBedrockRetry(ctx, &r.config, func() (*bedrockruntime.InvokeModelWithResponseStreamOutput, error) {
+		return r.Client.InvokeModelWithResponseStream(ctx, in)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("invoke model with stream: %w", err)
 	}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"testing"
@@ -39,6 +40,14 @@ func (m *MockAWSBedrockClient) Converse(ctx context.Context, params *bedrockrunt
 	return args.Get(0).(*bedrockruntime.ConverseOutput), args.Error(1)
 }
 
+func (m *MockAWSBedrockClient) ConverseStream(ctx context.Context, params *bedrockruntime.ConverseStreamInput, optFns ...func(*bedrockruntime.Options)) (*bedrockruntime.ConverseStreamOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*bedrockruntime.ConverseStreamOutput), args.Error(1)
+}
+
 func TestNewAWSBedrockRepository(t *testing.T) {
 	// Test with nil client
 	repo := NewAWSBedrockRepository(nil)
@@ -92,25 +101,6 @@ func TestAWSBedrockRepository_InvokeModelWithStream(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
-func TestAWSBedrockRepository_Converse(t *testing.T) {
-	mockClient := new(MockAWSBedrockClient)
-	repo := NewAWSBedrockRepositoryWithInterface(mockClient)
-
-	modelID := "anthropic.claude-v2"
-	message := map[string]string{"role": "user", "content": "Hello"}
-	expectedOutput := &bedrockruntime.ConverseOutput{}
-
-	mockClient.On("Converse", mock.Anything, mock.MatchedBy(func(input *bedrockruntime.ConverseInput) bool {
-		return *input.ModelId == modelID
-	})).Return(expectedOutput, nil)
-
-	result, err := repo.Converse(context.Background(), modelID, message)
-
-	assert.NoError(t, err)
-	assert.NotEmpty(t, result)
-	mockClient.AssertExpectations(t)
-}
-
 func TestAWSBedrockRepository_InvokeModel_Error(t *testing.T) {
 	mockClient := new(MockAWSBedrockClient)
 	repo := NewAWSBedrockRepositoryWithInterface(mockClient)
@@ -143,33 +133,17 @@ func TestAWSBedrockRepository_InvokeModelWithStream_Error(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
-func TestAWSBedrockRepository_Converse_Error(t *testing.T) {
-	mockClient := new(MockAWSBedrockClient)
-	repo := NewAWSBedrockRepositoryWithInterface(mockClient)
-
-	modelID := "anthropic.claude-v2"
-	message := map[string]string{"role": "user", "content": "Hello"}
-
-	mockClient.On("Converse", mock.Anything, mock.Anything).Return(nil, assert.AnError)
-
-	_, err := repo.Converse(context.Background(), modelID, message)
-
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "converse")
-	mockClient.AssertExpectations(t)
-}
-
 func TestAWSBedrockRepository_InvokeModelWithFileData(t *testing.T) {
 	mockClient := new(MockAWSBedrockClient)
 	repo := NewAWSBedrockRepositoryWithInterface(mockClient)
 
 	modelID := "anthropic.claude-v2"
-	fileData := []byte("test image data")
-	payload := []byte(`{"prompt": "Describe this image"}`)
+	fileData := testAWSBedrockPNGData
+	payload := []byte(testAWSBedrockAnthropicPayload)
 	expectedResponse := []byte(`{"completion":"response"}`)
 
 	mockClient.On("InvokeModel", mock.Anything, mock.MatchedBy(func(input *bedrockruntime.InvokeModelInput) bool {
-		return *input.ModelId == modelID
+		return *input.ModelId == modelID && bytes.Contains(input.Body, []byte(`"media_type":"image/png"`))
 	})).Return(&bedrockruntime.InvokeModelOutput{
 		Body: expectedResponse,
 	}, nil)
@@ -186,8 +160,8 @@ func TestAWSBedrockRepository_InvokeModelWithFileData_Error(t *testing.T) {
 	repo := NewAWSBedrockRepositoryWithInterface(mockClient)
 
 	modelID := "anthropic.claude-v2"
-	fileData := []byte("test image data")
-	payload := []byte(`{"prompt": "Describe this image"}`)
+	fileData := testAWSBedrockPNGData
+	payload := []byte(testAWSBedrockAnthropicPayload)
 
 	mockClient.On("InvokeModel", mock.Anything, mock.Anything).Return(nil, assert.AnError)
 
@@ -207,17 +181,17 @@ func TestAWSBedrockRepository_InvokeModelWithFile(t *testing.T) {
 	assert.NoError(t, err)
 	defer os.Remove(tmpFile.Name())
 
-	testData := []byte("test image data")
+	testData := testAWSBedrockPNGData
 	_, err = tmpFile.Write(testData)
 	assert.NoError(t, err)
 	tmpFile.Close()
 
 	modelID := "anthropic.claude-v2"
-	payload := []byte(`{"prompt": "Describe this image"}`)
+	payload := []byte(testAWSBedrockAnthropicPayload)
 	expectedResponse := []byte(`{"completion":"response"}`)
 
 	mockClient.On("InvokeModel", mock.Anything, mock.MatchedBy(func(input *bedrockruntime.InvokeModelInput) bool {
-		return *input.ModelId == modelID
+		return *input.ModelId == modelID && bytes.Contains(input.Body, []byte(`"media_type":"image/png"`))
 	})).Return(&bedrockruntime.InvokeModelOutput{
 		Body: expectedResponse,
 	}, nil)
@@ -234,7 +208,7 @@ func TestAWSBedrockRepository_InvokeModelWithFile_FileNotFound(t *testing.T) {
 	repo := NewAWSBedrockRepositoryWithInterface(mockClient)
 
 	modelID := "anthropic.claude-v2"
-	payload := []byte(`{"prompt": "Describe this image"}`)
+	payload := []byte(testAWSBedrockAnthropicPayload)
 
 	_, err := repo.InvokeModelWithFile(context.Background(), modelID, "/nonexistent/file.png", payload)
 
@@ -251,13 +225,13 @@ func TestAWSBedrockRepository_InvokeModelWithFile_InvokeError(t *testing.T) {
 	assert.NoError(t, err)
 	defer os.Remove(tmpFile.Name())
 
-	testData := []byte("test image data")
+	testData := testAWSBedrockPNGData
 	_, err = tmpFile.Write(testData)
 	assert.NoError(t, err)
 	tmpFile.Close()
 
 	modelID := "anthropic.claude-v2"
-	payload := []byte(`{"prompt": "Describe this image"}`)
+	payload := []byte(testAWSBedrockAnthropicPayload)
 
 	mockClient.On("InvokeModel", mock.Anything, mock.Anything).Return(nil, assert.AnError)
 
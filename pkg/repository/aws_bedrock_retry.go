@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+)
+
+const (
+	defaultAWSBedrockMaxRetries     = 4
+	defaultAWSBedrockRetryBaseDelay = 500 * time.Millisecond
+	defaultAWSBedrockRetryMaxDelay  = 20 * time.Second
+)
+
+// AWSBedrockRepositoryConfig sets configurations.
+type AWSBedrockRepositoryConfig struct {
+	// MaxRetries is the number of retries on throttling and
+	// ModelNotReadyException. Default: 4. Set a negative value to disable retries.
+	// Each attempt is a call of the SDK client, which retries throttling itself
+	// up to its RetryMaxAttempts (3 by default), so a throttled call makes at most
+	// (MaxRetries+1)*RetryMaxAttempts requests. Create the client with
+	// RetryMaxAttempts 1 to retry only here, or set MaxRetries negative to retry
+	// only in the SDK.
+	MaxRetries int
+	// RetryBaseDelay is the initial backoff, doubled on each retry. Default: 500ms.
+	RetryBaseDelay time.Duration
+	// RetryMaxDelay caps the backoff. Default: 20s.
+	RetryMaxDelay time.Duration
+}
+
+// newAWSBedrockRepositoryConfig returns cfgs[0] with defaults applied.
+func newAWSBedrockRepositoryConfig(cfgs []*AWSBedrockRepositoryConfig) AWSBedrockRepositoryConfig {
+	var conf AWSBedrockRepositoryConfig
+	if len(cfgs) > 0 && cfgs[0] != nil {
+		conf = *cfgs[0]
+	}
+	if conf.MaxRetries == 0 {
+		conf.MaxRetries = defaultAWSBedrockMaxRetries
+	}
+	if conf.RetryBaseDelay <= 0 {
+		conf.RetryBaseDelay = defaultAWSBedrockRetryBaseDelay
+	}
+	if conf.RetryMaxDelay <= 0 {
+		conf.RetryMaxDelay = defaultAWSBedrockRetryMaxDelay
+	}
+	return conf
+}
+
+// isAWSBedrockRetryable reports whether err is caused by throttling or a model that isn't ready yet.
+func isAWSBedrockRetryable(err error) bool {
+	var notReady *types.ModelNotReadyException
+	return IsThrottled(err) || errors.As(err, &notReady)
+}
+
+// awsBedrockRetry calls fn, retrying with exponential backoff and full jitter
+// while it fails with a retryable error. It stops when ctx is done.
+// These retries are on top of the retries of the SDK client, so that
+// sustained throttling of models doesn't fail requests immediately;
+// see AWSBedrockRepositoryConfig.MaxRetries for the combined limit.
+func awsBedrockRetry[T any](ctx context.Context, conf *AWSBedrockRepositoryConfig, fn func() (T, error)) (T, error) {
+	delay := conf.RetryBaseDelay
+	for attempt := 0; ; attempt++ {
+		res, err := fn()
+		if err == nil || attempt >= conf.MaxRetries || !isAWSBedrockRetryable(err) {
+			return res, err
+		}
+		timer := time.NewTimer(rand.N(delay) + 1) //nolint:gosec // jitter doesn't need a secure random
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			var zero T
+			return zero, fmt.Errorf("%w: %w", err, ctx.Err())
+		case <-timer.C:
+		}
+		delay = min(delay*2, conf.RetryMaxDelay)
+	}
+}
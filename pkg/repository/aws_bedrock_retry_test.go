@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAWSBedrockRepositoryConfig(t *testing.T) {
+	conf := newAWSBedrockRepositoryConfig(nil)
+	assert.Equal(t, defaultAWSBedrockMaxRetries, conf.MaxRetries)
+	assert.Equal(t, defaultAWSBedrockRetryBaseDelay, conf.RetryBaseDelay)
+	assert.Equal(t, defaultAWSBedrockRetryMaxDelay, conf.RetryMaxDelay)
+
+	conf = newAWSBedrockRepositoryConfig([]*AWSBedrockRepositoryConfig{{MaxRetries: -1, RetryBaseDelay: time.Second}})
+	assert.Equal(t, -1, conf.MaxRetries)
+	assert.Equal(t, time.Second, conf.RetryBaseDelay)
+}
+
+func TestAWSBedrockRepository_Retry(t *testing.T) {
+	mockClient := new(MockAWSBedrockClient)
+	repo := NewAWSBedrockRepositoryWithInterface(mockClient, &AWSBedrockRepositoryConfig{
+		MaxRetries:     3,
+		RetryBaseDelay: time.Millisecond,
+	})
+
+	mockClient.On("InvokeModel", mock.Anything, mock.Anything).Return(nil, &types.ThrottlingException{}).Once()
+	mockClient.On("InvokeModel", mock.Anything, mock.Anything).Return(nil, &types.ModelNotReadyException{}).Once()
+	mockClient.On("InvokeModel", mock.Anything, mock.Anything).Return(nil, ErrThrottled).Once()
+	mockClient.On("InvokeModel", mock.Anything, mock.Anything).Return(&bedrockruntime.InvokeModelOutput{Body: []byte(`{}`)}, nil).Once()
+
+	res, err := repo.InvokeModel(context.Background(), "anthropic.claude-v2", []byte(`{}`))
+
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`{}`), res)
+	mockClient.AssertNumberOfCalls(t, "InvokeModel", 4)
+}
+
+func TestAWSBedrockRepository_Retry_Exhausted(t *testing.T) {
+	mockClient := new(MockAWSBedrockClient)
+	repo := NewAWSBedrockRepositoryWithInterface(mockClient, &AWSBedrockRepositoryConfig{
+		MaxRetries:     2,
+		RetryBaseDelay: time.Millisecond,
+	})
+
+	mockClient.On("Converse", mock.Anything, mock.Anything).Return(nil, &types.ThrottlingException{})
+
+	_, err := repo.Converse(context.Background(), &AWSBedrockConverseRequest{
+		ModelID:  "anthropic.claude-v2",
+		Messages: []AWSBedrockMessage{NewAWSBedrockUserMessage("Hello")},
+	})
+
+	var throttling *types.ThrottlingException
+	assert.ErrorAs(t, err, &throttling)
+	assert.True(t, IsThrottled(err))
+	mockClient.AssertNumberOfCalls(t, "Converse", 3)
+}
+
+func TestAWSBedrockRepository_Retry_NotRetryable(t *testing.T) {
+	mockClient := new(MockAWSBedrockClient)
+	repo := NewAWSBedrockRepositoryWithInterface(mockClient, &AWSBedrockRepositoryConfig{RetryBaseDelay: time.Millisecond})
+
+	mockClient.On("ConverseStream", mock.Anything, mock.Anything).Return(nil, &types.ValidationException{})
+
+	_, err := repo.ConverseStream(context.Background(), &AWSBedrockConverseRequest{
+		ModelID:  "anthropic.claude-v2",
+		Messages: []AWSBedrockMessage{NewAWSBedrockUserMessage("Hello")},
+	})
+
+	assert.Error(t, err)
+	mockClient.AssertNumberOfCalls(t, "ConverseStream", 1)
+}
+
+func TestAWSBedrockRepository_Retry_ContextCanceled(t *testing.T) {
+	mockClient := new(MockAWSBedrockClient)
+	repo := NewAWSBedrockRepositoryWithInterface(mockClient, &AWSBedrockRepositoryConfig{RetryBaseDelay: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	mockClient.On("InvokeModelWithResponseStream", mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+		cancel()
+	}).Return(nil, &types.ThrottlingException{})
+
+	_, err := repo.InvokeModelWithStream(ctx, "anthropic.claude-v2", []byte(`{}`))
+
+	assert.ErrorIs(t, err, context.Canceled)
+	mockClient.AssertNumberOfCalls(t, "InvokeModelWithResponseStream", 1)
+}
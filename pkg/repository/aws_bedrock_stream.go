@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+)
+
+// AWSBedrockStreamEventType is the type of AWSBedrockStreamEvent.
+type AWSBedrockStreamEventType string
+
+// AWSBedrockStreamEventType values.
+const (
+	// AWSBedrockStreamEventTextDelta is a chunk of generated text.
+	AWSBedrockStreamEventTextDelta AWSBedrockStreamEventType = "text_delta"
+	// AWSBedrockStreamEventToolUseDelta is a chunk of the JSON input of a tool use.
+	AWSBedrockStreamEventToolUseDelta AWSBedrockStreamEventType = "tool_use_delta"
+	// AWSBedrockStreamEventToolUse is a completed tool use with the whole JSON input.
+	AWSBedrockStreamEventToolUse AWSBedrockStreamEventType = "tool_use"
+	// AWSBedrockStreamEventMessageStop is the end of the assistant message.
+	AWSBedrockStreamEventMessageStop AWSBedrockStreamEventType = "message_stop"
+	// AWSBedrockStreamEventUsage is the token usage, sent last.
+	AWSBedrockStreamEventUsage AWSBedrockStreamEventType = "usage"
+)
+
+var (
+	ErrAWSBedrockStreamNotFound = errors.New("event stream not found")
+)
+
+// AWSBedrockStreamEvent is a decoded event of ConverseStream.
+type AWSBedrockStreamEvent struct {
+	// ToolUse is set for tool_use_delta (ID and Name only) and tool_use events.
+	ToolUse *AWSBedrockToolUse `json:"toolUse,omitempty"`
+	// Usage is set for usage events.
+	Usage *AWSBedrockUsage `json:"usage,omitempty"`
+	// Type is the type of the event.
+	Type AWSBedrockStreamEventType `json:"type"`
+	// Text is the text chunk of text_delta events and the partial JSON input of tool_use_delta events.
+	Text string `json:"text,omitempty"`
+	// StopReason is set for message_stop events.
+	StopReason types.StopReason `json:"stopReason,omitempty"`
+	// Index is the index of the content block in the assistant message.
+	Index int32 `json:"index"`
+}
+
+// ConverseStream sends the conversation to the model and returns the assistant
+// reply as a stream of decoded events. Tools are sent to the model, but their
+// handlers are not called: tool uses are returned as events instead.
+// The stream is closed when the iteration ends, is stopped or ctx is done.
+func (r *AWSBedrockRepository) ConverseStream(ctx context.Context, req *AWSBedrockConverseRequest) (iter.Seq2[AWSBedrockStreamEvent, error], error) {
+	if len(req.Messages) == 0 {
+		return nil, fmt.Errorf("converse stream: %w", ErrAWSBedrockMessagesEmpty)
+	}
+	messages, err := toAWSBedrockMessages(req.Messages)
+	if err != nil {
+		return nil, fmt.Errorf("converse stream: %w", err)
+	}
+	in := &bedrockruntime.ConverseStreamInput{
+		ModelId:         aws.String(req.ModelID),
+		Messages:        messages,
+		System:          toAWSBedrockSystem(req.System),
+		InferenceConfig: toAWSBedrockInferenceConfig(req.InferenceConfig),
+		ToolConfig:      newAWSBedrockToolConfig(req.Tools),
+	}
+	// Only the request is retried: errors in the middle of the stream are returned.
+	out, err := awsBedrockRetry(ctx, &r.config, func() (*bedrockruntime.ConverseStreamOutput, error) {
+		return r.Client.ConverseStream(ctx, in)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("converse stream: %w", err)
+	}
+	stream := out.GetStream()
+	if stream == nil {
+		return nil, fmt.Errorf("converse stream: %w", ErrAWSBedrockStreamNotFound)
+	}
+	return decodeAWSBedrockConverseStream(ctx, stream), nil
+}
+
+// InvokeModelStream calls the streaming variant of InvokeModel and returns the
+// model-specific JSON chunks of the response.
+// The stream is closed when the iteration ends, is stopped or ctx is done.
+func (r *AWSBedrockRepository) InvokeModelStream(ctx context.Context, modelID string, payload []byte) (iter.Seq2[[]byte, error], error) {
+	out, err := r.InvokeModelWithStream(ctx, modelID, payload)
+	if err != nil {
+		return nil, err
+	}
+	stream := out.GetStream()
+	if stream == nil {
+		return nil, fmt.Errorf("invoke model with stream: %w", ErrAWSBedrockStreamNotFound)
+	}
+	return decodeAWSBedrockResponseStream(ctx, stream), nil
+}
+
+// decodeAWSBedrockConverseStream converts the events of reader to AWSBedrockStreamEvent.
+func decodeAWSBedrockConverseStream(ctx context.Context, reader bedrockruntime.ConverseStreamOutputReader) iter.Seq2[AWSBedrockStreamEvent, error] {
+	return func(yield func(AWSBedrockStreamEvent, error) bool) {
+		defer func() { _ = reader.Close() }()
+
+		// toolUses collects the partial inputs of tool uses by content block index.
+		toolUses := map[int32]*awsBedrockStreamToolUse{}
+		events := reader.Events()
+		for {
+			var event types.ConverseStreamOutput
+			var ok bool
+			select {
+			case <-ctx.Done():
+				yield(AWSBedrockStreamEvent{}, fmt.Errorf("converse stream: %w", ctx.Err()))
+				return
+			case event, ok = <-events:
+			}
+			if !ok {
+				if err := reader.Err(); err != nil {
+					yield(AWSBedrockStreamEvent{}, fmt.Errorf("converse stream: %w", err))
+				}
+				return
+			}
+			res, ok := toAWSBedrockStreamEvent(event, toolUses)
+			if ok && !yield(res, nil) {
+				return
+			}
+		}
+	}
+}
+
+// awsBedrockStreamToolUse is a tool use being received.
+type awsBedrockStreamToolUse struct {
+	input strings.Builder
+	id    string
+	name  string
+}
+
+// toAWSBedrockStreamEvent converts event. It returns false for events that aren't sent to callers.
+func toAWSBedrockStreamEvent(event types.ConverseStreamOutput, toolUses map[int32]*awsBedrockStreamToolUse) (AWSBedrockStreamEvent, bool) {
+	switch e := event.(type) {
+	case *types.ConverseStreamOutputMemberContentBlockStart:
+		if start, ok := e.Value.Start.(*types.ContentBlockStartMemberToolUse); ok {
+			toolUses[aws.ToInt32(e.Value.ContentBlockIndex)] = &awsBedrockStreamToolUse{
+				id:   aws.ToString(start.Value.ToolUseId),
+				name: aws.ToString(start.Value.Name),
+			}
+		}
+	case *types.ConverseStreamOutputMemberContentBlockDelta:
+		index := aws.ToInt32(e.Value.ContentBlockIndex)
+		switch delta := e.Value.Delta.(type) {
+		case *types.ContentBlockDeltaMemberText:
+			return AWSBedrockStreamEvent{Type: AWSBedrockStreamEventTextDelta, Index: index, Text: delta.Value}, true
+		case *types.ContentBlockDeltaMemberToolUse:
+			toolUse, ok := toolUses[index]
+			if !ok {
+				toolUse = &awsBedrockStreamToolUse{}
+				toolUses[index] = toolUse
+			}
+			input := aws.ToString(delta.Value.Input)
+			toolUse.input.WriteString(input)
+			return AWSBedrockStreamEvent{
+				Type:    AWSBedrockStreamEventToolUseDelta,
+				Index:   index,
+				Text:    input,
+				ToolUse: &AWSBedrockToolUse{ID: toolUse.id, Name: toolUse.name},
+			}, true
+		}
+	case *types.ConverseStreamOutputMemberContentBlockStop:
+		index := aws.ToInt32(e.Value.ContentBlockIndex)
+		toolUse, ok := toolUses[index]
+		if !ok {
+			return AWSBedrockStreamEvent{}, false
+		}
+		delete(toolUses, index)
+		input := toolUse.input.String()
+		if input == "" {
+			input = "{}"
+		}
+		return AWSBedrockStreamEvent{
+			Type:    AWSBedrockStreamEventToolUse,
+			Index:   index,
+			ToolUse: &AWSBedrockToolUse{ID: toolUse.id, Name: toolUse.name, Input: json.RawMessage(input)},
+		}, true
+	case *types.ConverseStreamOutputMemberMessageStop:
+		return AWSBedrockStreamEvent{Type: AWSBedrockStreamEventMessageStop, StopReason: e.Value.StopReason}, true
+	case *types.ConverseStreamOutputMemberMetadata:
+		usage := &AWSBedrockUsage{}
+		usage.add(e.Value.Usage)
+		return AWSBedrockStreamEvent{Type: AWSBedrockStreamEventUsage, Usage: usage}, true
+	}
+	return AWSBedrockStreamEvent{}, false
+}
+
+// decodeAWSBedrockResponseStream returns the chunk bytes of reader.
+func decodeAWSBedrockResponseStream(ctx context.Context, reader bedrockruntime.ResponseStreamReader) iter.Seq2[[]byte, error] {
+	return func(yield func([]byte, error) bool) {
+		defer func() { _ = reader.Close() }()
+
+		events := reader.Events()
+		for {
+			var event types.ResponseStream
+			var ok bool
+			select {
+			case <-ctx.Done():
+				yield(nil, fmt.Errorf("invoke model with stream: %w", ctx.Err()))
+				return
+			case event, ok = <-events:
+			}
+			if !ok {
+				if err := reader.Err(); err != nil {
+					yield(nil, fmt.Errorf("invoke model with stream: %w", err))
+				}
+				return
+			}
+			chunk, ok := event.(*types.ResponseStreamMemberChunk)
+			if ok && !yield(chunk.Value.Bytes, nil) {
+				return
+			}
+		}
+	}
+}
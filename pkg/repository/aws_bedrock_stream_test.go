@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAWSBedrockStreamReader implements the event stream readers of bedrockruntime.
+type fakeAWSBedrockStreamReader[T any] struct {
+	err    error
+	events chan T
+	closed bool
+}
+
+func newFakeAWSBedrockStreamReader[T any](err error, events ...T) *fakeAWSBedrockStreamReader[T] {
+	ch := make(chan T, len(events))
+	for _, e := range events {
+		ch <- e
+	}
+	close(ch)
+	return &fakeAWSBedrockStreamReader[T]{events: ch, err: err}
+}
+
+func (r *fakeAWSBedrockStreamReader[T]) Events() <-chan T { return r.events }
+func (r *fakeAWSBedrockStreamReader[T]) Close() error     { r.closed = true; return nil }
+func (r *fakeAWSBedrockStreamReader[T]) Err() error       { return r.err }
+
+func TestDecodeAWSBedrockConverseStream(t *testing.T) {
+	reader := newFakeAWSBedrockStreamReader[types.ConverseStreamOutput](nil,
+		&types.ConverseStreamOutputMemberMessageStart{Value: types.MessageStartEvent{Role: types.ConversationRoleAssistant}},
+		&types.ConverseStreamOutputMemberContentBlockDelta{Value: types.ContentBlockDeltaEvent{
+			ContentBlockIndex: aws.Int32(0),
+			Delta:             &types.ContentBlockDeltaMemberText{Value: "Hello"},
+		}},
+		&types.ConverseStreamOutputMemberContentBlockDelta{Value: types.ContentBlockDeltaEvent{
+			ContentBlockIndex: aws.Int32(0),
+			Delta:             &types.ContentBlockDeltaMemberText{Value: " world"},
+		}},
+		&types.ConverseStreamOutputMemberContentBlockStop{Value: types.ContentBlockStopEvent{ContentBlockIndex: aws.Int32(0)}},
+		&types.ConverseStreamOutputMemberContentBlockStart{Value: types.ContentBlockStartEvent{
+			ContentBlockIndex: aws.Int32(1),
+			Start: &types.ContentBlockStartMemberToolUse{Value: types.ToolUseBlockStart{
+				ToolUseId: aws.String("tool-1"),
+				Name:      aws.String("get_weather"),
+			}},
+		}},
+		&types.ConverseStreamOutputMemberContentBlockDelta{Value: types.ContentBlockDeltaEvent{
+			ContentBlockIndex: aws.Int32(1),
+			Delta:             &types.ContentBlockDeltaMemberToolUse{Value: types.ToolUseBlockDelta{Input: aws.String(`{"city":`)}},
+		}},
+		&types.ConverseStreamOutputMemberContentBlockDelta{Value: types.ContentBlockDeltaEvent{
+			ContentBlockIndex: aws.Int32(1),
+			Delta:             &types.ContentBlockDeltaMemberToolUse{Value: types.ToolUseBlockDelta{Input: aws.String(`"Tokyo"}`)}},
+		}},
+		&types.ConverseStreamOutputMemberContentBlockStop{Value: types.ContentBlockStopEvent{ContentBlockIndex: aws.Int32(1)}},
+		&types.ConverseStreamOutputMemberMessageStop{Value: types.MessageStopEvent{StopReason: types.StopReasonToolUse}},
+		&types.ConverseStreamOutputMemberMetadata{Value: types.ConverseStreamMetadataEvent{Usage: &types.TokenUsage{
+			InputTokens:  aws.Int32(10),
+			OutputTokens: aws.Int32(5),
+			TotalTokens:  aws.Int32(15),
+		}}},
+	)
+
+	var events []AWSBedrockStreamEvent
+	for event, err := range decodeAWSBedrockConverseStream(context.Background(), reader) {
+		require.NoError(t, err)
+		events = append(events, event)
+	}
+
+	toolUse := &AWSBedrockToolUse{ID: "tool-1", Name: "get_weather"}
+	assert.Equal(t, []AWSBedrockStreamEvent{
+		{Type: AWSBedrockStreamEventTextDelta, Text: "Hello"},
+		{Type: AWSBedrockStreamEventTextDelta, Text: " world"},
+		{Type: AWSBedrockStreamEventToolUseDelta, Index: 1, Text: `{"city":`, ToolUse: toolUse},
+		{Type: AWSBedrockStreamEventToolUseDelta, Index: 1, Text: `"Tokyo"}`, ToolUse: toolUse},
+		{Type: AWSBedrockStreamEventToolUse, Index: 1, ToolUse: &AWSBedrockToolUse{
+			ID: "tool-1", Name: "get_weather", Input: json.RawMessage(`{"city":"Tokyo"}`),
+		}},
+		{Type: AWSBedrockStreamEventMessageStop, StopReason: types.StopReasonToolUse},
+		{Type: AWSBedrockStreamEventUsage, Usage: &AWSBedrockUsage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15}},
+	}, events)
+	assert.True(t, reader.closed)
+}
+
+func TestDecodeAWSBedrockConverseStream_Error(t *testing.T) {
+	reader := newFakeAWSBedrockStreamReader[types.ConverseStreamOutput](assert.AnError,
+		&types.ConverseStreamOutputMemberContentBlockDelta{Value: types.ContentBlockDeltaEvent{
+			Delta: &types.ContentBlockDeltaMemberText{Value: "Hello"},
+		}},
+	)
+
+	var texts []string
+	var gotErr error
+	for event, err := range decodeAWSBedrockConverseStream(context.Background(), reader) {
+		if err != nil {
+			gotErr = err
+			continue
+		}
+		texts = append(texts, event.Text)
+	}
+
+	assert.Equal(t, []string{"Hello"}, texts)
+	assert.ErrorIs(t, gotErr, assert.AnError)
+	assert.True(t, reader.closed)
+}
+
+func TestDecodeAWSBedrockConverseStream_Stop(t *testing.T) {
+	delta := &types.ConverseStreamOutputMemberContentBlockDelta{Value: types.ContentBlockDeltaEvent{
+		Delta: &types.ContentBlockDeltaMemberText{Value: "Hello"},
+	}}
+	reader := newFakeAWSBedrockStreamReader[types.ConverseStreamOutput](nil, delta, delta)
+
+	count := 0
+	for range decodeAWSBedrockConverseStream(context.Background(), reader) {
+		count++
+		break
+	}
+
+	assert.Equal(t, 1, count)
+	assert.True(t, reader.closed)
+}
+
+func TestDecodeAWSBedrockConverseStream_ContextCanceled(t *testing.T) {
+	reader := &fakeAWSBedrockStreamReader[types.ConverseStreamOutput]{events: make(chan types.ConverseStreamOutput)}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var gotErr error
+	for _, err := range decodeAWSBedrockConverseStream(ctx, reader) {
+		gotErr = err
+	}
+
+	assert.ErrorIs(t, gotErr, context.Canceled)
+	assert.True(t, reader.closed)
+}
+
+func TestDecodeAWSBedrockResponseStream(t *testing.T) {
+	reader := newFakeAWSBedrockStreamReader[types.ResponseStream](nil,
+		&types.ResponseStreamMemberChunk{Value: types.PayloadPart{Bytes: []byte(`{"completion":"Hi"}`)}},
+		&types.ResponseStreamMemberChunk{Value: types.PayloadPart{Bytes: []byte(`{"completion":"!"}`)}},
+	)
+
+	var chunks []string
+	for chunk, err := range decodeAWSBedrockResponseStream(context.Background(), reader) {
+		require.NoError(t, err)
+		chunks = append(chunks, string(chunk))
+	}
+
+	assert.Equal(t, []string{`{"completion":"Hi"}`, `{"completion":"!"}`}, chunks)
+	assert.True(t, reader.closed)
+}
+
+func TestAWSBedrockRepository_ConverseStream(t *testing.T) {
+	mockClient := new(MockAWSBedrockClient)
+	repo := NewAWSBedrockRepositoryWithInterface(mockClient)
+
+	mockClient.On("ConverseStream", mock.Anything, mock.MatchedBy(func(input *bedrockruntime.ConverseStreamInput) bool {
+		text, ok := input.Messages[0].Content[0].(*types.ContentBlockMemberText)
+		return *input.ModelId == "anthropic.claude-v2" && ok && text.Value == "Hello" &&
+			len(input.ToolConfig.Tools) == 1
+	})).Return(&bedrockruntime.ConverseStreamOutput{}, nil).Once()
+
+	// the SDK sets the event stream of the output only on real responses
+	_, err := repo.ConverseStream(context.Background(), &AWSBedrockConverseRequest{
+		ModelID:  "anthropic.claude-v2",
+		Messages: []AWSBedrockMessage{NewAWSBedrockUserMessage("Hello")},
+		Tools:    []AWSBedrockTool{{Name: "search"}},
+	})
+	assert.ErrorIs(t, err, ErrAWSBedrockStreamNotFound)
+
+	mockClient.On("ConverseStream", mock.Anything, mock.Anything).Return(nil, assert.AnError).Once()
+	_, err = repo.ConverseStream(context.Background(), &AWSBedrockConverseRequest{
+		ModelID:  "anthropic.claude-v2",
+		Messages: []AWSBedrockMessage{NewAWSBedrockUserMessage("Hello")},
+	})
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Contains(t, err.Error(), "converse stream")
+
+	_, err = repo.ConverseStream(context.Background(), &AWSBedrockConverseRequest{ModelID: "anthropic.claude-v2"})
+	assert.ErrorIs(t, err, ErrAWSBedrockMessagesEmpty)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSBedrockRepository_InvokeModelStream_Error(t *testing.T) {
+	mockClient := new(MockAWSBedrockClient)
+	repo := NewAWSBedrockRepositoryWithInterface(mockClient)
+
+	mockClient.On("InvokeModelWithResponseStream", mock.Anything, mock.Anything).Return(nil, assert.AnError).Once()
+	_, err := repo.InvokeModelStream(context.Background(), "anthropic.claude-v2", []byte(`{}`))
+	assert.ErrorIs(t, err, assert.AnError)
+
+	mockClient.On("InvokeModelWithResponseStream", mock.Anything, mock.Anything).Return(&bedrockruntime.InvokeModelWithResponseStreamOutput{}, nil).Once()
+	_, err = repo.InvokeModelStream(context.Background(), "anthropic.claude-v2", []byte(`{}`))
+	assert.ErrorIs(t, err, ErrAWSBedrockStreamNotFound)
+	mockClient.AssertExpectations(t)
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"encoding/json"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+)
+
+const (
+	// awsBedrockCharsPerToken is the approximate number of ASCII characters per token.
+	awsBedrockCharsPerToken = 4
+	// awsBedrockMessageOverheadTokens is the approximate number of tokens of a message besides its content.
+	awsBedrockMessageOverheadTokens = 4
+	// awsBedrockImageTokens is the approximate number of tokens of an image,
+	// the maximum of Anthropic models for images resized to the default limit.
+	awsBedrockImageTokens = 1600
+)
+
+// EstimateAWSBedrockTokens returns the approximate number of tokens of text:
+// about 4 ASCII characters per token and 1 token per other character (e.g. CJK).
+// It doesn't depend on the tokenizer of a model, so leave a margin in budgets.
+func EstimateAWSBedrockTokens(text string) int {
+	ascii, other := 0, 0
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return (ascii+awsBedrockCharsPerToken-1)/awsBedrockCharsPerToken + other
+}
+
+// EstimateAWSBedrockMessageTokens returns the approximate number of tokens of m.
+func EstimateAWSBedrockMessageTokens(m *AWSBedrockMessage) int {
+	tokens := awsBedrockMessageOverheadTokens
+	for i := range m.Content {
+		c := &m.Content[i]
+		switch {
+		case c.ToolUse != nil:
+			tokens += EstimateAWSBedrockTokens(c.ToolUse.Name) + EstimateAWSBedrockTokens(string(c.ToolUse.Input))
+		case c.ToolResult != nil:
+			if s, ok := c.ToolResult.Content.(string); ok {
+				tokens += EstimateAWSBedrockTokens(s)
+			} else if b, err := json.Marshal(c.ToolResult.Content); err == nil {
+				tokens += EstimateAWSBedrockTokens(string(b))
+			}
+		case c.File != nil:
+			if c.File.IsImage() {
+				tokens += awsBedrockImageTokens
+			} else {
+				tokens += len(c.File.Data) / awsBedrockCharsPerToken
+			}
+		case c.Reasoning != nil:
+			tokens += EstimateAWSBedrockTokens(c.Reasoning.Text) + len(c.Reasoning.RedactedContent)/awsBedrockCharsPerToken
+		default:
+			tokens += EstimateAWSBedrockTokens(c.Text)
+		}
+	}
+	return tokens
+}
+
+// EstimateAWSBedrockMessagesTokens returns the approximate number of tokens of messages.
+func EstimateAWSBedrockMessagesTokens(messages []AWSBedrockMessage) int {
+	tokens := 0
+	for i := range messages {
+		tokens += EstimateAWSBedrockMessageTokens(&messages[i])
+	}
+	return tokens
+}
+
+// TrimAWSBedrockMessages drops the oldest messages so that the approximate
+// number of tokens of messages fits in budget. The last message is always
+// kept, and the result starts with a user message that isn't a tool result,
+// so that it is a valid conversation. A budget of 0 or less means no limit.
+func TrimAWSBedrockMessages(messages []AWSBedrockMessage, budget int) []AWSBedrockMessage {
+	if budget <= 0 || len(messages) == 0 {
+		return messages
+	}
+	start := len(messages) - 1
+	tokens := EstimateAWSBedrockMessageTokens(&messages[start])
+	for start > 0 {
+		t := EstimateAWSBedrockMessageTokens(&messages[start-1])
+		if tokens+t > budget {
+			break
+		}
+		tokens += t
+		start--
+	}
+	for start < len(messages)-1 && !isAWSBedrockConversationStart(&messages[start]) {
+		start++
+	}
+	return messages[start:]
+}
+
+// isAWSBedrockConversationStart reports whether a conversation can start with m.
+func isAWSBedrockConversationStart(m *AWSBedrockMessage) bool {
+	if m.Role != types.ConversationRoleUser {
+		return false
+	}
+	for i := range m.Content {
+		if m.Content[i].ToolResult != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateAWSBedrockTokens(t *testing.T) {
+	assert.Equal(t, 0, EstimateAWSBedrockTokens(""))
+	assert.Equal(t, 1, EstimateAWSBedrockTokens("abc"))
+	assert.Equal(t, 3, EstimateAWSBedrockTokens("Hello world!"))
+	assert.Equal(t, 5, EstimateAWSBedrockTokens("こんにちは"))
+	assert.Equal(t, 6, EstimateAWSBedrockTokens("Hi, こんにちは"))
+}
+
+func TestEstimateAWSBedrockMessageTokens(t *testing.T) {
+	m := AWSBedrockMessage{Role: types.ConversationRoleAssistant, Content: []AWSBedrockContent{
+		{Text: "abcd"},
+		{ToolUse: &AWSBedrockToolUse{Name: "tool", Input: json.RawMessage(`{"a":1}`)}},
+		{ToolResult: &AWSBedrockToolResult{Content: map[string]int{"a": 1}}},
+		{File: &AWSBedrockFile{Format: "png", Data: []byte("x")}},
+	}}
+	assert.Equal(t, awsBedrockMessageOverheadTokens+1+1+2+2+awsBedrockImageTokens, EstimateAWSBedrockMessageTokens(&m))
+}
+
+func TestTrimAWSBedrockMessages(t *testing.T) {
+	long := strings.Repeat("a", 400) // 100 tokens
+	messages := []AWSBedrockMessage{
+		NewAWSBedrockUserMessage(long),
+		NewAWSBedrockAssistantMessage(long),
+		NewAWSBedrockUserMessage(long),
+		{Role: types.ConversationRoleAssistant, Content: []AWSBedrockContent{{ToolUse: &AWSBedrockToolUse{ID: "t", Name: "tool"}}}},
+		{Role: types.ConversationRoleUser, Content: []AWSBedrockContent{{ToolResult: &AWSBedrockToolResult{ToolUseID: "t", Content: long}}}},
+		NewAWSBedrockAssistantMessage("done"),
+	}
+
+	assert.Equal(t, messages, TrimAWSBedrockMessages(messages, 0))
+	assert.Equal(t, messages, TrimAWSBedrockMessages(messages, 10_000))
+
+	// the budget fits the last 4 messages, but the conversation can't start with the assistant tool use
+	trimmed := TrimAWSBedrockMessages(messages, EstimateAWSBedrockMessagesTokens(messages[2:])+10)
+	assert.Equal(t, messages[2:], trimmed)
+	trimmed = TrimAWSBedrockMessages(messages, EstimateAWSBedrockMessagesTokens(messages[3:]))
+	assert.Equal(t, messages[5:], trimmed)
+
+	assert.Equal(t, messages[5:], TrimAWSBedrockMessages(messages, 1), "the last message is always kept")
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"iter"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/y-miyazaki/go-common/pkg/logger"
+)
+
+// CloudWatch Logs PutLogEvents quotas.
+// https://docs.aws.amazon.com/AmazonCloudWatchLogs/latest/APIReference/API_PutLogEvents.html
+const (
+	awsCloudWatchLogsMaxBatchBytes  = 1_048_576
+	awsCloudWatchLogsMaxBatchEvents = 10_000
+	awsCloudWatchLogsMaxBatchSpan   = 24 * time.Hour
+	awsCloudWatchLogsEventOverhead  = 26
+)
+
+// AWSCloudWatchLogsMaxEventBytes is the maximum size of a log event message.
+// Larger events are rejected by PutLogEventsBatched.
+const AWSCloudWatchLogsMaxEventBytes = 262_144 - awsCloudWatchLogsEventOverhead
+
+// AWSCloudWatchLogsRejectReason is the reason why a log event was rejected.
+type AWSCloudWatchLogsRejectReason string
+
+// AWSCloudWatchLogsRejectReason values.
+const (
+	// AWSCloudWatchLogsRejectTooOld is an event older than 14 days or than the creation of the log group.
+	AWSCloudWatchLogsRejectTooOld AWSCloudWatchLogsRejectReason = "too_old"
+	// AWSCloudWatchLogsRejectExpired is an event older than the retention of the log group.
+	AWSCloudWatchLogsRejectExpired AWSCloudWatchLogsRejectReason = "expired"
+	// AWSCloudWatchLogsRejectTooNew is an event more than 2 hours in the future.
+	AWSCloudWatchLogsRejectTooNew AWSCloudWatchLogsRejectReason = "too_new"
+	// AWSCloudWatchLogsRejectTooLarge is an event larger than 256 KB. It isn't sent.
+	AWSCloudWatchLogsRejectTooLarge AWSCloudWatchLogsRejectReason = "too_large"
+)
+
+// AWSCloudWatchLogsRejectedEvent is a log event rejected by PutLogEventsBatched.
+type AWSCloudWatchLogsRejectedEvent struct {
+	Reason AWSCloudWatchLogsRejectReason
+	Event  types.InputLogEvent
+}
+
+// AWSCloudWatchLogsPutResult is the result of PutLogEventsBatched.
+type AWSCloudWatchLogsPutResult struct {
+	// Rejected are the events that were not stored.
+	Rejected []AWSCloudWatchLogsRejectedEvent
+	// Unsent are the sorted events that were not sent because of an error.
+	Unsent []types.InputLogEvent
+	// Batches is the number of PutLogEvents calls.
+	Batches int
+	// Accepted is the number of stored events.
+	Accepted int
+}
+
+// PutLogEventsBatched uploads any number of log events to the log stream.
+// Events are sorted by timestamp and split into PutLogEvents calls within the
+// 1 MB, 10,000 events and 24 hours quotas, without sequence tokens.
+// The log group and stream are created when they don't exist.
+// Events rejected by CloudWatch Logs are reported in the result; they don't make it fail.
+// On error, the result has the batches sent before it and the unsent events,
+// so that the caller can retry them.
+func (r *AWSCloudWatchLogsRepository) PutLogEventsBatched(ctx context.Context, group, stream string, events []types.InputLogEvent) (*AWSCloudWatchLogsPutResult, error) {
+	res := &AWSCloudWatchLogsPutResult{}
+	sorted := make([]types.InputLogEvent, 0, len(events))
+	for _, event := range events {
+		if len(aws.ToString(event.Message)) > AWSCloudWatchLogsMaxEventBytes {
+			res.Rejected = append(res.Rejected, AWSCloudWatchLogsRejectedEvent{Reason: AWSCloudWatchLogsRejectTooLarge, Event: event})
+			continue
+		}
+		sorted = append(sorted, event)
+	}
+	slices.SortStableFunc(sorted, func(a, b types.InputLogEvent) int {
+		return cmp.Compare(aws.ToInt64(a.Timestamp), aws.ToInt64(b.Timestamp))
+	})
+
+	created, sent := false, 0
+	for batch := range splitAWSCloudWatchLogsEvents(sorted) {
+		out, err := r.PutLogEvents(ctx, group, stream, batch, nil)
+		var notFound *types.ResourceNotFoundException
+		if errors.As(err, &notFound) && !created {
+			created = true
+			if err = r.createLogGroupAndStream(ctx, group, stream); err != nil {
+				res.Unsent = sorted[sent:]
+				return res, err
+			}
+			out, err = r.PutLogEvents(ctx, group, stream, batch, nil)
+		}
+		if err != nil {
+			res.Unsent = sorted[sent:]
+			return res, err
+		}
+		sent += len(batch)
+		res.Batches++
+		rejected := rejectedAWSCloudWatchLogsEvents(batch, out.RejectedLogEventsInfo)
+		res.Rejected = append(res.Rejected, rejected...)
+		res.Accepted += len(batch) - len(rejected)
+	}
+	return res, nil
+}
+
+// awsCloudWatchLogsLoggerPutter adapts PutLogEventsBatched to logger.CloudWatchLogsPutter.
+type awsCloudWatchLogsLoggerPutter struct {
+	repo *AWSCloudWatchLogsRepository
+}
+
+// NewAWSCloudWatchLogsLoggerPutter returns a logger.CloudWatchLogsPutter that
+// delivers the events of logger.CloudWatchLogsWriter with PutLogEventsBatched.
+func NewAWSCloudWatchLogsLoggerPutter(r *AWSCloudWatchLogsRepository) logger.CloudWatchLogsPutter {
+	return &awsCloudWatchLogsLoggerPutter{repo: r}
+}
+
+// PutLogEventsBatched implements logger.CloudWatchLogsPutter.
+func (p *awsCloudWatchLogsLoggerPutter) PutLogEventsBatched(ctx context.Context, group, stream string, events []types.InputLogEvent) (*logger.CloudWatchLogsPutResult, error) {
+	res, err := p.repo.PutLogEventsBatched(ctx, group, stream, events)
+	return &logger.CloudWatchLogsPutResult{Unsent: res.Unsent, Rejected: len(res.Rejected)}, err
+}
+
+// createLogGroupAndStream creates the log group and stream, ignoring ResourceAlreadyExistsException.
+func (r *AWSCloudWatchLogsRepository) createLogGroupAndStream(ctx context.Context, group, stream string) error {
+	var exists *types.ResourceAlreadyExistsException
+	if _, err := r.CreateLogGroup(ctx, group); err != nil && !errors.As(err, &exists) {
+		return err
+	}
+	if _, err := r.CreateLogStream(ctx, group, stream); err != nil && !errors.As(err, &exists) {
+		return err
+	}
+	return nil
+}
+
+// splitAWSCloudWatchLogsEvents returns the sorted events in batches within the PutLogEvents quotas.
+func splitAWSCloudWatchLogsEvents(events []types.InputLogEvent) iter.Seq[[]types.InputLogEvent] {
+	return func(yield func([]types.InputLogEvent) bool) {
+		start, size := 0, 0
+		for i := range events {
+			eventSize := len(aws.ToString(events[i].Message)) + awsCloudWatchLogsEventOverhead
+			full := i-start >= awsCloudWatchLogsMaxBatchEvents ||
+				size+eventSize > awsCloudWatchLogsMaxBatchBytes ||
+				aws.ToInt64(events[i].Timestamp)-aws.ToInt64(events[start].Timestamp) >= awsCloudWatchLogsMaxBatchSpan.Milliseconds()
+			if i > start && full {
+				if !yield(events[start:i]) {
+					return
+				}
+				start, size = i, 0
+			}
+			size += eventSize
+		}
+		if start < len(events) {
+			yield(events[start:])
+		}
+	}
+}
+
+// rejectedAWSCloudWatchLogsEvents returns the events of batch rejected according to info.
+func rejectedAWSCloudWatchLogsEvents(batch []types.InputLogEvent, info *types.RejectedLogEventsInfo) []AWSCloudWatchLogsRejectedEvent {
+	if info == nil {
+		return nil
+	}
+	// Too old and expired events are at the start of the sorted batch and too new events at the end.
+	tooOld := min(int(aws.ToInt32(info.TooOldLogEventEndIndex)), len(batch))
+	expired := min(int(aws.ToInt32(info.ExpiredLogEventEndIndex)), len(batch))
+	tooNew := len(batch)
+	if info.TooNewLogEventStartIndex != nil {
+		tooNew = max(int(*info.TooNewLogEventStartIndex), tooOld, expired)
+	}
+	var rejected []AWSCloudWatchLogsRejectedEvent
+	for i, event := range batch {
+		switch {
+		case i < tooOld:
+			rejected = append(rejected, AWSCloudWatchLogsRejectedEvent{Reason: AWSCloudWatchLogsRejectTooOld, Event: event})
+		case i < expired:
+			rejected = append(rejected, AWSCloudWatchLogsRejectedEvent{Reason: AWSCloudWatchLogsRejectExpired, Event: event})
+		case i >= tooNew:
+			rejected = append(rejected, AWSCloudWatchLogsRejectedEvent{Reason: AWSCloudWatchLogsRejectTooNew, Event: event})
+		}
+	}
+	return rejected
+}
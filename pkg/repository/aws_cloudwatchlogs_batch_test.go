@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/y-miyazaki/go-common/pkg/logger"
+)
+
+// testInputLogEvents returns n events of message at timestamp.
+func testInputLogEvents(n int, message string, timestamp int64) []types.InputLogEvent {
+	events := make([]types.InputLogEvent, n)
+	for i := range events {
+		events[i] = types.InputLogEvent{Message: aws.String(message), Timestamp: aws.Int64(timestamp)}
+	}
+	return events
+}
+
+func TestAWSCloudWatchLogsRepository_PutLogEventsBatched(t *testing.T) {
+	mockClient := &MockCloudWatchLogsClient{}
+	repo := NewAWSCloudWatchLogsRepositoryWithInterface(mockClient)
+
+	events := []types.InputLogEvent{
+		{Message: aws.String("second"), Timestamp: aws.Int64(2000)},
+		{Message: aws.String("first"), Timestamp: aws.Int64(1000)},
+	}
+	mockClient.On("PutLogEvents", mock.Anything, mock.MatchedBy(func(input *cloudwatchlogs.PutLogEventsInput) bool {
+		return input.SequenceToken == nil && *input.LogGroupName == "group" && *input.LogStreamName == "stream" &&
+			len(input.LogEvents) == 2 && *input.LogEvents[0].Message == "first"
+	})).Return(&cloudwatchlogs.PutLogEventsOutput{}, nil)
+
+	res, err := repo.PutLogEventsBatched(context.Background(), "group", "stream", events)
+
+	require.NoError(t, err)
+	assert.Equal(t, &AWSCloudWatchLogsPutResult{Batches: 1, Accepted: 2}, res)
+	// The events of the caller are not reordered.
+	assert.Equal(t, "second", *events[0].Message)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSCloudWatchLogsRepository_PutLogEventsBatched_CreatesGroupAndStream(t *testing.T) {
+	mockClient := &MockCloudWatchLogsClient{}
+	repo := NewAWSCloudWatchLogsRepositoryWithInterface(mockClient)
+
+	mockClient.On("PutLogEvents", mock.Anything, mock.Anything).
+		Return(nil, &types.ResourceNotFoundException{Message: aws.String("log stream does not exist")}).Once()
+	mockClient.On("CreateLogGroup", mock.Anything, mock.Anything).
+		Return(nil, &types.ResourceAlreadyExistsException{Message: aws.String("exists")})
+	mockClient.On("CreateLogStream", mock.Anything, mock.MatchedBy(func(input *cloudwatchlogs.CreateLogStreamInput) bool {
+		return *input.LogGroupName == "group" && *input.LogStreamName == "stream"
+	})).Return(&cloudwatchlogs.CreateLogStreamOutput{}, nil)
+	mockClient.On("PutLogEvents", mock.Anything, mock.Anything).Return(&cloudwatchlogs.PutLogEventsOutput{}, nil).Once()
+
+	res, err := repo.PutLogEventsBatched(context.Background(), "group", "stream", testInputLogEvents(1, "message", 1000))
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Accepted)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSCloudWatchLogsRepository_PutLogEventsBatched_Error(t *testing.T) {
+	mockClient := &MockCloudWatchLogsClient{}
+	repo := NewAWSCloudWatchLogsRepositoryWithInterface(mockClient)
+
+	mockClient.On("PutLogEvents", mock.Anything, mock.Anything).Return(&cloudwatchlogs.PutLogEventsOutput{}, nil).Once()
+	mockClient.On("PutLogEvents", mock.Anything, mock.Anything).Return(nil, errors.New("access denied")).Once()
+
+	events := append(testInputLogEvents(1, "day 1", 0), testInputLogEvents(1, "day 2", time.Hour.Milliseconds()*24)...)
+	res, err := repo.PutLogEventsBatched(context.Background(), "group", "stream", events)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cloudwatchlogs PutLogEvents")
+	assert.Equal(t, &AWSCloudWatchLogsPutResult{Batches: 1, Accepted: 1, Unsent: events[1:]}, res)
+}
+
+func TestAWSCloudWatchLogsRepository_PutLogEventsBatched_Rejected(t *testing.T) {
+	mockClient := &MockCloudWatchLogsClient{}
+	repo := NewAWSCloudWatchLogsRepositoryWithInterface(mockClient)
+
+	events := []types.InputLogEvent{
+		{Message: aws.String("too old"), Timestamp: aws.Int64(1000)},
+		{Message: aws.String("expired"), Timestamp: aws.Int64(2000)},
+		{Message: aws.String("ok"), Timestamp: aws.Int64(3000)},
+		{Message: aws.String("too new"), Timestamp: aws.Int64(4000)},
+		{Message: aws.String(strings.Repeat("x", 262_144)), Timestamp: aws.Int64(3000)},
+	}
+	mockClient.On("PutLogEvents", mock.Anything, mock.MatchedBy(func(input *cloudwatchlogs.PutLogEventsInput) bool {
+		return len(input.LogEvents) == 4
+	})).Return(&cloudwatchlogs.PutLogEventsOutput{
+		RejectedLogEventsInfo: &types.RejectedLogEventsInfo{
+			TooOldLogEventEndIndex:   aws.Int32(1),
+			ExpiredLogEventEndIndex:  aws.Int32(2),
+			TooNewLogEventStartIndex: aws.Int32(3),
+		},
+	}, nil)
+
+	res, err := repo.PutLogEventsBatched(context.Background(), "group", "stream", events)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Accepted)
+	reasons := make(map[string]AWSCloudWatchLogsRejectReason, len(res.Rejected))
+	for _, rejected := range res.Rejected {
+		reasons[(*rejected.Event.Message)[:min(7, len(*rejected.Event.Message))]] = rejected.Reason
+	}
+	assert.Equal(t, map[string]AWSCloudWatchLogsRejectReason{
+		"too old": AWSCloudWatchLogsRejectTooOld,
+		"expired": AWSCloudWatchLogsRejectExpired,
+		"too new": AWSCloudWatchLogsRejectTooNew,
+		"xxxxxxx": AWSCloudWatchLogsRejectTooLarge,
+	}, reasons)
+}
+
+func TestNewAWSCloudWatchLogsLoggerPutter(t *testing.T) {
+	mockClient := &MockCloudWatchLogsClient{}
+	putter := NewAWSCloudWatchLogsLoggerPutter(NewAWSCloudWatchLogsRepositoryWithInterface(mockClient))
+
+	mockClient.On("PutLogEvents", mock.Anything, mock.Anything).Return(&cloudwatchlogs.PutLogEventsOutput{
+		RejectedLogEventsInfo: &types.RejectedLogEventsInfo{TooOldLogEventEndIndex: aws.Int32(1)},
+	}, nil).Once()
+	mockClient.On("PutLogEvents", mock.Anything, mock.Anything).Return(nil, errors.New("access denied")).Once()
+
+	events := append(testInputLogEvents(2, "day 1", 0), testInputLogEvents(1, "day 2", time.Hour.Milliseconds()*24)...)
+	res, err := putter.PutLogEventsBatched(context.Background(), "group", "stream", events)
+
+	require.Error(t, err)
+	assert.Equal(t, &logger.CloudWatchLogsPutResult{Unsent: events[2:], Rejected: 1}, res)
+	assert.Equal(t, AWSCloudWatchLogsMaxEventBytes, logger.CloudWatchLogsMaxEventBytes)
+}
+
+func TestSplitAWSCloudWatchLogsEvents(t *testing.T) {
+	large := strings.Repeat("x", 100_000)
+	tests := []struct {
+		name   string
+		events []types.InputLogEvent
+		want   []int
+	}{
+		{
+			name:   "count",
+			events: testInputLogEvents(25_000, "m", 0),
+			want:   []int{10_000, 10_000, 5_000},
+		},
+		{
+			name:   "size",
+			events: testInputLogEvents(25, large, 0),
+			want:   []int{10, 10, 5},
+		},
+		{
+			name: "span",
+			events: append(append(testInputLogEvents(2, "m", 0), testInputLogEvents(2, "m", time.Hour.Milliseconds()*24-1)...),
+				testInputLogEvents(1, "m", time.Hour.Milliseconds()*24)...),
+			want: []int{4, 1},
+		},
+		{
+			name: "empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for batch := range splitAWSCloudWatchLogsEvents(tt.events) {
+				got = append(got, len(batch))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+const (
+	// awsCloudWatchLogsInsightsTimeLayout is the layout of @timestamp and other time fields of query results.
+	awsCloudWatchLogsInsightsTimeLayout = "2006-01-02 15:04:05.000"
+	defaultAWSCloudWatchLogsQueryPoll   = time.Second
+)
+
+var (
+	// ErrAWSCloudWatchLogsQueryFailed indicates that a Logs Insights query ended as Failed, Cancelled, Timeout or Unknown.
+	ErrAWSCloudWatchLogsQueryFailed = errors.New("logs insights query did not complete")
+	// ErrAWSCloudWatchLogsQueryLogGroupsEmpty indicates that a Logs Insights query has no log group.
+	ErrAWSCloudWatchLogsQueryLogGroupsEmpty = errors.New("logs insights query log groups are empty")
+	// ErrAWSCloudWatchLogsQueryFieldNotFound indicates that a result row doesn't have the field.
+	ErrAWSCloudWatchLogsQueryFieldNotFound = errors.New("logs insights query field not found")
+)
+
+// AWSCloudWatchLogsQuery is a Logs Insights query.
+type AWSCloudWatchLogsQuery struct {
+	// StartTime and EndTime are the time range of the searched log events.
+	StartTime time.Time
+	EndTime   time.Time
+	// QueryString is the query in the Logs Insights query language.
+	QueryString string
+	// LogGroups are the names or ARNs of the log groups to query.
+	LogGroups []string
+	// Limit is the maximum number of returned rows. Default: the limit of the query or 1,000.
+	Limit int32
+}
+
+// AWSCloudWatchLogsQueryConfig sets configurations.
+type AWSCloudWatchLogsQueryConfig struct {
+	// PollInterval is the wait between GetQueryResults calls. Default: 1s.
+	PollInterval time.Duration
+}
+
+// AWSCloudWatchLogsQueryRow is a row of Logs Insights query results by field name.
+type AWSCloudWatchLogsQueryRow map[string]string
+
+// AWSCloudWatchLogsQueryResult is the results of a Logs Insights query.
+type AWSCloudWatchLogsQueryResult struct {
+	// Statistics is the scanned records and bytes of the query.
+	Statistics *types.QueryStatistics
+	QueryID    string
+	Status     types.QueryStatus
+	Rows       []AWSCloudWatchLogsQueryRow
+}
+
+// StartQuery starts a Logs Insights query and returns the query ID.
+func (r *AWSCloudWatchLogsRepository) StartQuery(ctx context.Context, query *AWSCloudWatchLogsQuery) (string, error) {
+	if len(query.LogGroups) == 0 {
+		return "", fmt.Errorf("cloudwatchlogs StartQuery: %w", ErrAWSCloudWatchLogsQueryLogGroupsEmpty)
+	}
+	in := &cloudwatchlogs.StartQueryInput{
+		QueryString:         aws.String(query.QueryString),
+		StartTime:           aws.Int64(query.StartTime.Unix()),
+		EndTime:             aws.Int64(query.EndTime.Unix()),
+		LogGroupIdentifiers: query.LogGroups,
+	}
+	if query.Limit > 0 {
+		in.Limit = aws.Int32(query.Limit)
+	}
+	out, err := r.Client.StartQuery(ctx, in)
+	if err != nil {
+		return "", fmt.Errorf("cloudwatchlogs StartQuery: %w", err)
+	}
+	return aws.ToString(out.QueryId), nil
+}
+
+// GetQueryResults returns the current status and results of a Logs Insights query.
+// Rows are partial until the status is Complete.
+func (r *AWSCloudWatchLogsRepository) GetQueryResults(ctx context.Context, queryID string) (*AWSCloudWatchLogsQueryResult, error) {
+	out, err := r.Client.GetQueryResults(ctx, &cloudwatchlogs.GetQueryResultsInput{
+		QueryId: aws.String(queryID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cloudwatchlogs GetQueryResults: %w", err)
+	}
+	rows := make([]AWSCloudWatchLogsQueryRow, 0, len(out.Results))
+	for _, fields := range out.Results {
+		row := make(AWSCloudWatchLogsQueryRow, len(fields))
+		for _, field := range fields {
+			row[aws.ToString(field.Field)] = aws.ToString(field.Value)
+		}
+		rows = append(rows, row)
+	}
+	return &AWSCloudWatchLogsQueryResult{
+		Statistics: out.Statistics,
+		QueryID:    queryID,
+		Status:     out.Status,
+		Rows:       rows,
+	}, nil
+}
+
+// StopQuery stops a running Logs Insights query.
+func (r *AWSCloudWatchLogsRepository) StopQuery(ctx context.Context, queryID string) error {
+	_, err := r.Client.StopQuery(ctx, &cloudwatchlogs.StopQueryInput{
+		QueryId: aws.String(queryID),
+	})
+	if err != nil {
+		return fmt.Errorf("cloudwatchlogs StopQuery: %w", err)
+	}
+	return nil
+}
+
+// RunQuery starts a Logs Insights query and polls its results until it completes.
+// The query is stopped when ctx is done before it completes.
+// The error wraps ErrAWSCloudWatchLogsQueryFailed when the query fails, is cancelled or times out.
+func (r *AWSCloudWatchLogsRepository) RunQuery(ctx context.Context, query *AWSCloudWatchLogsQuery, cfgs ...*AWSCloudWatchLogsQueryConfig) (*AWSCloudWatchLogsQueryResult, error) {
+	pollInterval := defaultAWSCloudWatchLogsQueryPoll
+	if len(cfgs) > 0 && cfgs[0] != nil && cfgs[0].PollInterval > 0 {
+		pollInterval = cfgs[0].PollInterval
+	}
+	queryID, err := r.StartQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		res, err := r.GetQueryResults(ctx, queryID)
+		if err != nil {
+			return nil, err
+		}
+		switch res.Status {
+		case types.QueryStatusComplete:
+			return res, nil
+		case types.QueryStatusScheduled, types.QueryStatusRunning:
+		default:
+			return res, fmt.Errorf("cloudwatchlogs query %s %s: %w", queryID, res.Status, ErrAWSCloudWatchLogsQueryFailed)
+		}
+		timer := time.NewTimer(pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			// The query keeps running in CloudWatch Logs unless it's stopped.
+			_ = r.StopQuery(context.WithoutCancel(ctx), queryID)
+			return nil, fmt.Errorf("cloudwatchlogs query %s: %w", queryID, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// Field returns the value of field. The error wraps ErrAWSCloudWatchLogsQueryFieldNotFound when the row doesn't have it.
+func (row AWSCloudWatchLogsQueryRow) Field(field string) (string, error) {
+	v, ok := row[field]
+	if !ok {
+		return "", fmt.Errorf("%s: %w", field, ErrAWSCloudWatchLogsQueryFieldNotFound)
+	}
+	return v, nil
+}
+
+// Int returns the value of field as an integer, e.g. of count().
+func (row AWSCloudWatchLogsQueryRow) Int(field string) (int64, error) {
+	v, err := row.Field(field)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", field, err)
+	}
+	return n, nil
+}
+
+// Float returns the value of field as a float, e.g. of avg().
+func (row AWSCloudWatchLogsQueryRow) Float(field string) (float64, error) {
+	v, err := row.Field(field)
+	if err != nil {
+		return 0, err
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", field, err)
+	}
+	return f, nil
+}
+
+// Time returns the value of field as a UTC time, e.g. of @timestamp or bin().
+func (row AWSCloudWatchLogsQueryRow) Time(field string) (time.Time, error) {
+	v, err := row.Field(field)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := time.Parse(awsCloudWatchLogsInsightsTimeLayout, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %w", field, err)
+	}
+	return t, nil
+}
+
+// Timestamp returns @timestamp of the row.
+func (row AWSCloudWatchLogsQueryRow) Timestamp() (time.Time, error) {
+	return row.Time("@timestamp")
+}
+
+// Message returns @message of the row, or an empty string when it's not queried.
+func (row AWSCloudWatchLogsQueryRow) Message() string {
+	return row["@message"]
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// testAWSCloudWatchLogsQuery is a Logs Insights query of an hour.
+var testAWSCloudWatchLogsQuery = &AWSCloudWatchLogsQuery{
+	StartTime:   time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC),
+	EndTime:     time.Date(2026, 1, 2, 4, 0, 0, 0, time.UTC),
+	QueryString: "fields @timestamp, @message | stats count() as errors by bin(5m)",
+	LogGroups:   []string{"/app/api"},
+	Limit:       100,
+}
+
+func TestAWSCloudWatchLogsRepository_RunQuery(t *testing.T) {
+	mockClient := &MockCloudWatchLogsClient{}
+	repo := NewAWSCloudWatchLogsRepositoryWithInterface(mockClient)
+
+	mockClient.On("StartQuery", mock.Anything, mock.MatchedBy(func(input *cloudwatchlogs.StartQueryInput) bool {
+		return *input.StartTime == testAWSCloudWatchLogsQuery.StartTime.Unix() &&
+			*input.EndTime == testAWSCloudWatchLogsQuery.EndTime.Unix() &&
+			*input.Limit == 100 && input.LogGroupIdentifiers[0] == "/app/api"
+	})).Return(&cloudwatchlogs.StartQueryOutput{QueryId: aws.String("query-id")}, nil)
+	mockClient.On("GetQueryResults", mock.Anything, mock.Anything).
+		Return(&cloudwatchlogs.GetQueryResultsOutput{Status: types.QueryStatusRunning}, nil).Twice()
+	mockClient.On("GetQueryResults", mock.Anything, mock.Anything).Return(&cloudwatchlogs.GetQueryResultsOutput{
+		Status: types.QueryStatusComplete,
+		Results: [][]types.ResultField{
+			{
+				{Field: aws.String("bin(5m)"), Value: aws.String("2026-01-02 03:05:00.000")},
+				{Field: aws.String("errors"), Value: aws.String("42")},
+			},
+		},
+		Statistics: &types.QueryStatistics{RecordsMatched: 42},
+	}, nil).Once()
+
+	res, err := repo.RunQuery(context.Background(), testAWSCloudWatchLogsQuery, &AWSCloudWatchLogsQueryConfig{PollInterval: time.Millisecond})
+
+	require.NoError(t, err)
+	assert.Equal(t, "query-id", res.QueryID)
+	assert.Equal(t, types.QueryStatusComplete, res.Status)
+	assert.InDelta(t, 42, res.Statistics.RecordsMatched, 0)
+	require.Len(t, res.Rows, 1)
+	count, err := res.Rows[0].Int("errors")
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), count)
+	bin, err := res.Rows[0].Time("bin(5m)")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 1, 2, 3, 5, 0, 0, time.UTC), bin)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSCloudWatchLogsRepository_RunQuery_Failed(t *testing.T) {
+	mockClient := &MockCloudWatchLogsClient{}
+	repo := NewAWSCloudWatchLogsRepositoryWithInterface(mockClient)
+
+	mockClient.On("StartQuery", mock.Anything, mock.Anything).Return(&cloudwatchlogs.StartQueryOutput{QueryId: aws.String("query-id")}, nil)
+	mockClient.On("GetQueryResults", mock.Anything, mock.Anything).
+		Return(&cloudwatchlogs.GetQueryResultsOutput{Status: types.QueryStatusTimeout}, nil)
+
+	res, err := repo.RunQuery(context.Background(), testAWSCloudWatchLogsQuery)
+
+	require.ErrorIs(t, err, ErrAWSCloudWatchLogsQueryFailed)
+	assert.Equal(t, types.QueryStatusTimeout, res.Status)
+}
+
+func TestAWSCloudWatchLogsRepository_RunQuery_ContextDone(t *testing.T) {
+	mockClient := &MockCloudWatchLogsClient{}
+	repo := NewAWSCloudWatchLogsRepositoryWithInterface(mockClient)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	mockClient.On("StartQuery", mock.Anything, mock.Anything).Return(&cloudwatchlogs.StartQueryOutput{QueryId: aws.String("query-id")}, nil)
+	mockClient.On("GetQueryResults", mock.Anything, mock.Anything).
+		Run(func(mock.Arguments) { cancel() }).
+		Return(&cloudwatchlogs.GetQueryResultsOutput{Status: types.QueryStatusRunning}, nil)
+	mockClient.On("StopQuery", mock.Anything, mock.MatchedBy(func(input *cloudwatchlogs.StopQueryInput) bool {
+		return *input.QueryId == "query-id"
+	})).Return(&cloudwatchlogs.StopQueryOutput{Success: true}, nil)
+
+	_, err := repo.RunQuery(ctx, testAWSCloudWatchLogsQuery, &AWSCloudWatchLogsQueryConfig{PollInterval: time.Hour})
+
+	require.ErrorIs(t, err, context.Canceled)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSCloudWatchLogsRepository_StartQuery_Errors(t *testing.T) {
+	mockClient := &MockCloudWatchLogsClient{}
+	repo := NewAWSCloudWatchLogsRepositoryWithInterface(mockClient)
+
+	_, err := repo.StartQuery(context.Background(), &AWSCloudWatchLogsQuery{QueryString: "fields @message"})
+	require.ErrorIs(t, err, ErrAWSCloudWatchLogsQueryLogGroupsEmpty)
+
+	mockClient.On("StartQuery", mock.Anything, mock.Anything).Return(nil, errors.New("malformed query"))
+	_, err = repo.StartQuery(context.Background(), testAWSCloudWatchLogsQuery)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cloudwatchlogs StartQuery")
+}
+
+func TestAWSCloudWatchLogsQueryRow(t *testing.T) {
+	row := AWSCloudWatchLogsQueryRow{
+		"@timestamp": "2026-01-02 03:04:05.678",
+		"@message":   "GET /health 200",
+		"latency":    "12.5",
+		"status":     "ok",
+	}
+
+	ts, err := row.Timestamp()
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 678000000, time.UTC), ts)
+	assert.Equal(t, "GET /health 200", row.Message())
+	latency, err := row.Float("latency")
+	require.NoError(t, err)
+	assert.InDelta(t, 12.5, latency, 0)
+
+	_, err = row.Int("status")
+	require.Error(t, err)
+	_, err = row.Field("missing")
+	require.ErrorIs(t, err, ErrAWSCloudWatchLogsQueryFieldNotFound)
+}
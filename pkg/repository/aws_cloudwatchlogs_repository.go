@@ -44,6 +44,12 @@ type AWSCloudWatchLogsClientInterface interface {
 	PutLogEvents(_ context.Context, _ *cloudwatchlogs.PutLogEventsInput, _ ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutLogEventsOutput, error)
 	// PutRetentionPolicy sets the retention policy for a log group
 	PutRetentionPolicy(_ context.Context, _ *cloudwatchlogs.PutRetentionPolicyInput, _ ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutRetentionPolicyOutput, error)
+	// StartQuery starts a Logs Insights query
+	StartQuery(_ context.Context, _ *cloudwatchlogs.StartQueryInput, _ ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.StartQueryOutput, error)
+	// GetQueryResults returns the status and results of a Logs Insights query
+	GetQueryResults(_ context.Context, _ *cloudwatchlogs.GetQueryResultsInput, _ ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.GetQueryResultsOutput, error)
+	// StopQuery stops a running Logs Insights query
+	StopQuery(_ context.Context, _ *cloudwatchlogs.StopQueryInput, _ ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.StopQueryOutput, error)
 }
 
 // AWSCloudWatchLogsRepository implements AWSCloudWatchLogsRepositoryInterface backed by AWS SDK v2 client.
@@ -133,6 +139,8 @@ func (r *AWSCloudWatchLogsRepository) FilterLogEvents(ctx context.Context, in *c
 }
 
 // GetNextSequenceToken retrieves the next sequence token for a log stream.
+//
+// Deprecated: PutLogEvents ignores sequence tokens. Use PutLogEventsBatched without a token.
 func (r *AWSCloudWatchLogsRepository) GetNextSequenceToken(ctx context.Context, group, stream string) (*string, error) {
 	streams, err := r.DescribeLogStreams(ctx, &cloudwatchlogs.DescribeLogStreamsInput{
 		LogGroupName:        aws.String(group),
@@ -148,7 +156,9 @@ func (r *AWSCloudWatchLogsRepository) GetNextSequenceToken(ctx context.Context,
 }
 
 // PutLogEvents uploads log events to the specified log stream.
-// The sequenceToken should be provided for subsequent calls after the first PutLogEvents.
+// The events must be sorted by timestamp and within the PutLogEvents quotas; use
+// PutLogEventsBatched for any number of events. sequenceToken is ignored by
+// CloudWatch Logs and should be nil.
 func (r *AWSCloudWatchLogsRepository) PutLogEvents(ctx context.Context, group, stream string, events []types.InputLogEvent, sequenceToken *string) (*cloudwatchlogs.PutLogEventsOutput, error) {
 	in := &cloudwatchlogs.PutLogEventsInput{
 		LogGroupName:  aws.String(group),
@@ -82,6 +82,30 @@ func (m *MockCloudWatchLogsClient) DescribeMetricFilters(ctx context.Context, pa
 	return args.Get(0).(*cloudwatchlogs.DescribeMetricFiltersOutput), args.Error(1)
 }
 
+func (m *MockCloudWatchLogsClient) StartQuery(ctx context.Context, params *cloudwatchlogs.StartQueryInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.StartQueryOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*cloudwatchlogs.StartQueryOutput), args.Error(1)
+}
+
+func (m *MockCloudWatchLogsClient) GetQueryResults(ctx context.Context, params *cloudwatchlogs.GetQueryResultsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.GetQueryResultsOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*cloudwatchlogs.GetQueryResultsOutput), args.Error(1)
+}
+
+func (m *MockCloudWatchLogsClient) StopQuery(ctx context.Context, params *cloudwatchlogs.StopQueryInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.StopQueryOutput, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*cloudwatchlogs.StopQueryOutput), args.Error(1)
+}
+
 func TestAWSCloudWatchLogsRepository_CreateLogGroup(t *testing.T) {
 	mockClient := &MockCloudWatchLogsClient{}
 	repo := NewAWSCloudWatchLogsRepositoryWithInterface(mockClient)
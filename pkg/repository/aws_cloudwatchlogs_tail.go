@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+const (
+	defaultAWSCloudWatchLogsTailPoll = 2 * time.Second
+)
+
+// AWSCloudWatchLogsTailConfig sets configurations.
+type AWSCloudWatchLogsTailConfig struct {
+	// PollInterval is the wait between polls after all events are read. Default: 2s.
+	PollInterval time.Duration
+	// Lag is how far back each poll searches before the latest event, so that
+	// events ingested late are still returned. Default: 0.
+	Lag time.Duration
+}
+
+// FilterLogEventsSeq returns the events of all pages of FilterLogEvents.
+// An error ends the iteration.
+func (r *AWSCloudWatchLogsRepository) FilterLogEventsSeq(ctx context.Context, in *cloudwatchlogs.FilterLogEventsInput) iter.Seq2[types.FilteredLogEvent, error] {
+	return func(yield func(types.FilteredLogEvent, error) bool) {
+		if in == nil {
+			yield(types.FilteredLogEvent{}, ErrFilterLogEventsInputNil)
+			return
+		}
+		params := *in
+		for {
+			out, err := r.FilterLogEvents(ctx, &params)
+			if err != nil {
+				yield(types.FilteredLogEvent{}, err)
+				return
+			}
+			for _, event := range out.Events {
+				if !yield(event, nil) {
+					return
+				}
+			}
+			if out.NextToken == nil || aws.ToString(out.NextToken) == aws.ToString(params.NextToken) {
+				return
+			}
+			params.NextToken = out.NextToken
+		}
+	}
+}
+
+// TailLogEvents returns the events matching in like "tail -f": it returns the
+// events from in.StartTime (default: now) and then polls for new events until
+// ctx is done. in.EndTime and in.NextToken are ignored. Each event is returned
+// once. It ends without an error when ctx is done; other errors end the iteration.
+func (r *AWSCloudWatchLogsRepository) TailLogEvents(ctx context.Context, in *cloudwatchlogs.FilterLogEventsInput, cfgs ...*AWSCloudWatchLogsTailConfig) iter.Seq2[types.FilteredLogEvent, error] {
+	conf := AWSCloudWatchLogsTailConfig{PollInterval: defaultAWSCloudWatchLogsTailPoll}
+	if len(cfgs) > 0 && cfgs[0] != nil {
+		if cfgs[0].PollInterval > 0 {
+			conf.PollInterval = cfgs[0].PollInterval
+		}
+		conf.Lag = max(cfgs[0].Lag, 0)
+	}
+	return func(yield func(types.FilteredLogEvent, error) bool) {
+		if in == nil {
+			yield(types.FilteredLogEvent{}, ErrFilterLogEventsInputNil)
+			return
+		}
+		params := *in
+		params.EndTime = nil
+		params.NextToken = nil
+		if params.StartTime == nil {
+			params.StartTime = aws.Int64(time.Now().UnixMilli())
+		}
+		// seen has the IDs and timestamps of the events returned since the start time of the poll.
+		seen := map[string]int64{}
+		latest := aws.ToInt64(params.StartTime)
+		for {
+			for event, err := range r.FilterLogEventsSeq(ctx, &params) {
+				if err != nil {
+					if ctx.Err() == nil {
+						yield(types.FilteredLogEvent{}, fmt.Errorf("cloudwatchlogs tail: %w", err))
+					}
+					return
+				}
+				id := aws.ToString(event.EventId)
+				if _, ok := seen[id]; ok {
+					continue
+				}
+				timestamp := aws.ToInt64(event.Timestamp)
+				seen[id] = timestamp
+				latest = max(latest, timestamp)
+				if !yield(event, nil) {
+					return
+				}
+			}
+
+			params.StartTime = aws.Int64(latest - conf.Lag.Milliseconds())
+			for id, timestamp := range seen {
+				if timestamp < *params.StartTime {
+					delete(seen, id)
+				}
+			}
+
+			timer := time.NewTimer(conf.PollInterval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// testFilteredLogEvent returns a log event with id at timestamp.
+func testFilteredLogEvent(id string, timestamp int64) types.FilteredLogEvent {
+	return types.FilteredLogEvent{
+		EventId:   aws.String(id),
+		Timestamp: aws.Int64(timestamp),
+		Message:   aws.String("message " + id),
+	}
+}
+
+func TestAWSCloudWatchLogsRepository_FilterLogEventsSeq(t *testing.T) {
+	mockClient := &MockCloudWatchLogsClient{}
+	repo := NewAWSCloudWatchLogsRepositoryWithInterface(mockClient)
+
+	mockClient.On("FilterLogEvents", mock.Anything, mock.MatchedBy(func(input *cloudwatchlogs.FilterLogEventsInput) bool {
+		return input.NextToken == nil && *input.FilterPattern == "ERROR"
+	})).Return(&cloudwatchlogs.FilterLogEventsOutput{
+		Events:    []types.FilteredLogEvent{testFilteredLogEvent("1", 1), testFilteredLogEvent("2", 2)},
+		NextToken: aws.String("next"),
+	}, nil).Once()
+	mockClient.On("FilterLogEvents", mock.Anything, mock.MatchedBy(func(input *cloudwatchlogs.FilterLogEventsInput) bool {
+		return aws.ToString(input.NextToken) == "next"
+	})).Return(&cloudwatchlogs.FilterLogEventsOutput{
+		Events: []types.FilteredLogEvent{testFilteredLogEvent("3", 3)},
+	}, nil).Once()
+
+	in := &cloudwatchlogs.FilterLogEventsInput{LogGroupName: aws.String("/app/api"), FilterPattern: aws.String("ERROR")}
+	var ids []string
+	for event, err := range repo.FilterLogEventsSeq(context.Background(), in) {
+		require.NoError(t, err)
+		ids = append(ids, *event.EventId)
+	}
+
+	assert.Equal(t, []string{"1", "2", "3"}, ids)
+	assert.Nil(t, in.NextToken)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSCloudWatchLogsRepository_FilterLogEventsSeq_Error(t *testing.T) {
+	mockClient := &MockCloudWatchLogsClient{}
+	repo := NewAWSCloudWatchLogsRepositoryWithInterface(mockClient)
+
+	mockClient.On("FilterLogEvents", mock.Anything, mock.Anything).Return(nil, errors.New("access denied"))
+
+	for _, err := range repo.FilterLogEventsSeq(context.Background(), &cloudwatchlogs.FilterLogEventsInput{}) {
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cloudwatchlogs FilterLogEvents")
+	}
+	for _, err := range repo.FilterLogEventsSeq(context.Background(), nil) {
+		require.ErrorIs(t, err, ErrFilterLogEventsInputNil)
+	}
+}
+
+func TestAWSCloudWatchLogsRepository_TailLogEvents(t *testing.T) {
+	mockClient := &MockCloudWatchLogsClient{}
+	repo := NewAWSCloudWatchLogsRepositoryWithInterface(mockClient)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockClient.On("FilterLogEvents", mock.Anything, mock.MatchedBy(func(input *cloudwatchlogs.FilterLogEventsInput) bool {
+		return *input.StartTime == 1000 && input.EndTime == nil
+	})).Return(&cloudwatchlogs.FilterLogEventsOutput{
+		Events: []types.FilteredLogEvent{testFilteredLogEvent("1", 1000), testFilteredLogEvent("2", 2000)},
+	}, nil).Once()
+	// The next poll starts at the latest event, so that event 2 is read again and skipped.
+	mockClient.On("FilterLogEvents", mock.Anything, mock.MatchedBy(func(input *cloudwatchlogs.FilterLogEventsInput) bool {
+		return *input.StartTime == 2000
+	})).Return(&cloudwatchlogs.FilterLogEventsOutput{
+		Events: []types.FilteredLogEvent{testFilteredLogEvent("2", 2000), testFilteredLogEvent("3", 2000)},
+	}, nil).Once()
+	mockClient.On("FilterLogEvents", mock.Anything, mock.Anything).
+		Return(&cloudwatchlogs.FilterLogEventsOutput{}, nil)
+
+	in := &cloudwatchlogs.FilterLogEventsInput{
+		LogGroupName: aws.String("/app/api"),
+		StartTime:    aws.Int64(1000),
+		EndTime:      aws.Int64(1500),
+	}
+	var ids []string
+	for event, err := range repo.TailLogEvents(ctx, in, &AWSCloudWatchLogsTailConfig{PollInterval: time.Millisecond}) {
+		require.NoError(t, err)
+		ids = append(ids, *event.EventId)
+		if len(ids) == 3 {
+			cancel()
+		}
+	}
+
+	assert.Equal(t, []string{"1", "2", "3"}, ids)
+}
+
+func TestAWSCloudWatchLogsRepository_TailLogEvents_Lag(t *testing.T) {
+	mockClient := &MockCloudWatchLogsClient{}
+	repo := NewAWSCloudWatchLogsRepositoryWithInterface(mockClient)
+
+	mockClient.On("FilterLogEvents", mock.Anything, mock.MatchedBy(func(input *cloudwatchlogs.FilterLogEventsInput) bool {
+		return *input.StartTime == 0
+	})).Return(&cloudwatchlogs.FilterLogEventsOutput{
+		Events: []types.FilteredLogEvent{testFilteredLogEvent("1", 10000)},
+	}, nil).Once()
+	// An event ingested late is returned by the next poll, which starts Lag before the latest event.
+	mockClient.On("FilterLogEvents", mock.Anything, mock.MatchedBy(func(input *cloudwatchlogs.FilterLogEventsInput) bool {
+		return *input.StartTime == 5000
+	})).Return(&cloudwatchlogs.FilterLogEventsOutput{
+		Events: []types.FilteredLogEvent{testFilteredLogEvent("late", 9000), testFilteredLogEvent("1", 10000)},
+	}, nil).Once()
+
+	var ids []string
+	tail := repo.TailLogEvents(context.Background(), &cloudwatchlogs.FilterLogEventsInput{StartTime: aws.Int64(0)},
+		&AWSCloudWatchLogsTailConfig{PollInterval: time.Millisecond, Lag: 5 * time.Second})
+	for event, err := range tail {
+		require.NoError(t, err)
+		ids = append(ids, *event.EventId)
+		if len(ids) == 2 {
+			break
+		}
+	}
+
+	assert.Equal(t, []string{"1", "late"}, ids)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSCloudWatchLogsRepository_TailLogEvents_Error(t *testing.T) {
+	mockClient := &MockCloudWatchLogsClient{}
+	repo := NewAWSCloudWatchLogsRepositoryWithInterface(mockClient)
+
+	mockClient.On("FilterLogEvents", mock.Anything, mock.Anything).Return(nil, errors.New("throttled"))
+
+	var errs []error
+	for _, err := range repo.TailLogEvents(context.Background(), &cloudwatchlogs.FilterLogEventsInput{}) {
+		errs = append(errs, err)
+	}
+
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "cloudwatchlogs tail")
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/smithy-go"
+)
+
+// Cognito errors mapped from Cognito exceptions. The original SDK error stays
+// in the chain, so both errors.Is(err, ErrAWSCognitoCodeMismatch) and
+// errors.As(err, &*types.CodeMismatchException) work.
+var (
+	ErrAWSCognitoUsernameExists        = errors.New("username already exists")
+	ErrAWSCognitoAliasExists           = errors.New("alias already exists")
+	ErrAWSCognitoCodeMismatch          = errors.New("code mismatch")
+	ErrAWSCognitoExpiredCode           = errors.New("code expired")
+	ErrAWSCognitoCodeDeliveryFailure   = errors.New("code delivery failed")
+	ErrAWSCognitoUserNotFound          = errors.New("user not found")
+	ErrAWSCognitoUserNotConfirmed      = errors.New("user not confirmed")
+	ErrAWSCognitoNotAuthorized         = errors.New("not authorized")
+	ErrAWSCognitoPasswordResetRequired = errors.New("password reset required")
+	ErrAWSCognitoInvalidPassword       = errors.New("invalid password")
+	ErrAWSCognitoInvalidParameter      = errors.New("invalid parameter")
+	ErrAWSCognitoLimitExceeded         = errors.New("limit exceeded")
+	ErrAWSCognitoTooManyRequests       = errors.New("too many requests")
+	ErrAWSCognitoTooManyFailedAttempts = errors.New("too many failed attempts")
+)
+
+// awsCognitoErrors maps Cognito exception codes to sentinel errors.
+var awsCognitoErrors = map[string]error{
+	"UsernameExistsException":        ErrAWSCognitoUsernameExists,
+	"AliasExistsException":           ErrAWSCognitoAliasExists,
+	"CodeMismatchException":          ErrAWSCognitoCodeMismatch,
+	"ExpiredCodeException":           ErrAWSCognitoExpiredCode,
+	"CodeDeliveryFailureException":   ErrAWSCognitoCodeDeliveryFailure,
+	"UserNotFoundException":          ErrAWSCognitoUserNotFound,
+	"UserNotConfirmedException":      ErrAWSCognitoUserNotConfirmed,
+	"NotAuthorizedException":         ErrAWSCognitoNotAuthorized,
+	"PasswordResetRequiredException": ErrAWSCognitoPasswordResetRequired,
+	"InvalidPasswordException":       ErrAWSCognitoInvalidPassword,
+	"InvalidParameterException":      ErrAWSCognitoInvalidParameter,
+	"LimitExceededException":         ErrAWSCognitoLimitExceeded,
+	"TooManyRequestsException":       ErrAWSCognitoTooManyRequests,
+	"TooManyFailedAttemptsException": ErrAWSCognitoTooManyFailedAttempts,
+}
+
+// toAWSCognitoError joins the sentinel error of a Cognito exception to err.
+// err is returned unchanged when it is not a known Cognito exception.
+func toAWSCognitoError(err error) error {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	sentinel, ok := awsCognitoErrors[apiErr.ErrorCode()]
+	if !ok {
+		return err
+	}
+	return fmt.Errorf("%w: %w", sentinel, err)
+}
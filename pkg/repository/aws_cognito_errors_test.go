@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToAWSCognitoError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want error
+		name string
+	}{
+		{name: "username exists", err: &types.UsernameExistsException{}, want: ErrAWSCognitoUsernameExists},
+		{name: "code mismatch", err: &types.CodeMismatchException{}, want: ErrAWSCognitoCodeMismatch},
+		{name: "expired code", err: &types.ExpiredCodeException{}, want: ErrAWSCognitoExpiredCode},
+		{name: "not authorized", err: &types.NotAuthorizedException{Message: aws.String("Incorrect username or password.")}, want: ErrAWSCognitoNotAuthorized},
+		{name: "user not confirmed", err: &types.UserNotConfirmedException{}, want: ErrAWSCognitoUserNotConfirmed},
+		{name: "invalid password", err: &types.InvalidPasswordException{}, want: ErrAWSCognitoInvalidPassword},
+		{name: "too many requests", err: &types.TooManyRequestsException{}, want: ErrAWSCognitoTooManyRequests},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := toAWSCognitoError(tt.err)
+			assert.ErrorIs(t, err, tt.want)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	t.Run("unknown error", func(t *testing.T) {
+		err := errors.New("network error")
+		assert.Equal(t, err, toAWSCognitoError(err))
+	})
+
+	t.Run("unmapped exception", func(t *testing.T) {
+		err := &types.InternalErrorException{}
+		assert.Equal(t, error(err), toAWSCognitoError(err))
+	})
+}
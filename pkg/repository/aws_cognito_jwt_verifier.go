@@ -0,0 +1,323 @@
+package repository
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// AWSCognitoTokenUseAccess is the token_use claim of access tokens.
+	AWSCognitoTokenUseAccess = "access"
+	// AWSCognitoTokenUseID is the token_use claim of ID tokens.
+	AWSCognitoTokenUseID = "id"
+
+	defaultAWSCognitoJWKSCacheTTL       = time.Hour
+	defaultAWSCognitoJWKSRefreshMinWait = time.Minute
+	defaultAWSCognitoClockSkew          = time.Minute
+	defaultAWSCognitoJWKSFetchTimeout   = 10 * time.Second
+)
+
+var (
+	// ErrAWSCognitoTokenInvalid indicates that the token signature, issuer or expiry is invalid.
+	ErrAWSCognitoTokenInvalid = errors.New("token is invalid")
+	// ErrAWSCognitoTokenUseNotAllowed indicates that the token_use claim is not allowed.
+	ErrAWSCognitoTokenUseNotAllowed = errors.New("token_use is not allowed")
+	// ErrAWSCognitoTokenClientNotAllowed indicates that the aud/client_id claim is not allowed.
+	ErrAWSCognitoTokenClientNotAllowed = errors.New("token client is not allowed")
+	// ErrAWSCognitoJWKSKeyNotFound indicates that the kid of the token is not in the JWKS.
+	ErrAWSCognitoJWKSKeyNotFound = errors.New("jwks key not found")
+	// ErrAWSCognitoJWKSFetch indicates that the JWKS could not be fetched.
+	ErrAWSCognitoJWKSFetch = errors.New("jwks fetch failed")
+)
+
+// AWSCognitoJWTVerifierConfig sets configurations of AWSCognitoJWTVerifier.
+type AWSCognitoJWTVerifierConfig struct {
+	// HTTPClient fetches the JWKS. Default: http.DefaultClient.
+	HTTPClient *http.Client
+	// Region and UserPoolID build the issuer and the default JWKS URL.
+	Region     string
+	UserPoolID string
+	// Issuer overrides https://cognito-idp.{Region}.amazonaws.com/{UserPoolID}.
+	Issuer string
+	// JWKSURL overrides {Issuer}/.well-known/jwks.json.
+	JWKSURL string
+	// TokenUse restricts the accepted token_use claim ("access" or "id").
+	// Empty accepts both.
+	TokenUse string
+	// ClientIDs are the accepted app client IDs, matched against client_id for
+	// access tokens and aud for ID tokens. Empty rejects every token unless
+	// AllowAnyClient is set.
+	ClientIDs []string
+	// AllowAnyClient accepts tokens of any app client of the user pool when ClientIDs is empty.
+	AllowAnyClient bool
+	// ClockSkew is the leeway applied to exp, nbf and iat. Default: 1m.
+	ClockSkew time.Duration
+	// JWKSCacheTTL is how long fetched keys are cached. Default: 1h.
+	JWKSCacheTTL time.Duration
+}
+
+// AWSCognitoClaims holds verified claims of a Cognito access or ID token.
+type AWSCognitoClaims struct {
+	ExpiresAt time.Time
+	IssuedAt  time.Time
+	Raw       jwt.MapClaims
+	Subject   string
+	Username  string
+	Email     string
+	TokenUse  string
+	ClientID  string
+	Issuer    string
+	Scope     string
+	Groups    []string
+}
+
+// HasGroup reports whether the claims contain group in cognito:groups.
+func (c *AWSCognitoClaims) HasGroup(group string) bool {
+	return slices.Contains(c.Groups, group)
+}
+
+// HasAnyGroup reports whether the claims contain any of groups in cognito:groups.
+func (c *AWSCognitoClaims) HasAnyGroup(groups ...string) bool {
+	return slices.ContainsFunc(groups, c.HasGroup)
+}
+
+// AWSCognitoJWTVerifier verifies Cognito user pool tokens against the
+// user pool's JWKS, which is cached and refreshed when an unknown kid appears.
+type AWSCognitoJWTVerifier struct {
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	fetchErr    error
+	keys        map[string]*rsa.PublicKey
+	group       singleflight.Group
+	config      AWSCognitoJWTVerifierConfig
+	mu          sync.Mutex
+}
+
+// NewAWSCognitoJWTVerifier returns AWSCognitoJWTVerifier instance.
+func NewAWSCognitoJWTVerifier(cfg *AWSCognitoJWTVerifierConfig) *AWSCognitoJWTVerifier {
+	conf := *cfg
+	if conf.Issuer == "" {
+		conf.Issuer = fmt.Sprintf("https://cognito-idp.%s.amazonaws.com/%s", conf.Region, conf.UserPoolID)
+	}
+	if conf.JWKSURL == "" {
+		conf.JWKSURL = conf.Issuer + "/.well-known/jwks.json"
+	}
+	if conf.HTTPClient == nil {
+		conf.HTTPClient = http.DefaultClient
+	}
+	if conf.ClockSkew <= 0 {
+		conf.ClockSkew = defaultAWSCognitoClockSkew
+	}
+	if conf.JWKSCacheTTL <= 0 {
+		conf.JWKSCacheTTL = defaultAWSCognitoJWKSCacheTTL
+	}
+	return &AWSCognitoJWTVerifier{config: conf}
+}
+
+// Verify verifies the signature, iss, exp, token_use and aud/client_id of
+// token and returns its claims.
+func (v *AWSCognitoJWTVerifier) Verify(ctx context.Context, token string) (*AWSCognitoClaims, error) {
+	mapClaims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, mapClaims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.getKey(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithIssuer(v.config.Issuer),
+		jwt.WithLeeway(v.config.ClockSkew),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		if errors.Is(err, ErrAWSCognitoJWKSFetch) {
+			return nil, fmt.Errorf("cognito Verify: %w", err)
+		}
+		return nil, fmt.Errorf("cognito Verify: %w: %w", ErrAWSCognitoTokenInvalid, err)
+	}
+
+	claims := newAWSCognitoClaims(mapClaims)
+	if v.config.TokenUse != "" && claims.TokenUse != v.config.TokenUse {
+		return nil, fmt.Errorf("cognito Verify: %w: %s", ErrAWSCognitoTokenUseNotAllowed, claims.TokenUse)
+	}
+	switch claims.TokenUse {
+	case AWSCognitoTokenUseAccess, AWSCognitoTokenUseID:
+	default:
+		return nil, fmt.Errorf("cognito Verify: %w: %s", ErrAWSCognitoTokenUseNotAllowed, claims.TokenUse)
+	}
+	if !v.config.AllowAnyClient && !slices.Contains(v.config.ClientIDs, claims.ClientID) {
+		return nil, fmt.Errorf("cognito Verify: %w: %s", ErrAWSCognitoTokenClientNotAllowed, claims.ClientID)
+	}
+	return claims, nil
+}
+
+// VerifyAuthorizationHeader extracts the Bearer token from authorizationHeader and verifies it.
+func (v *AWSCognitoJWTVerifier) VerifyAuthorizationHeader(ctx context.Context, authorizationHeader string) (*AWSCognitoClaims, error) {
+	token, err := GetAWSCognitoBearerToken(authorizationHeader)
+	if err != nil {
+		return nil, fmt.Errorf("cognito GetAWSCognitoBearerToken: %w", err)
+	}
+	return v.Verify(ctx, token)
+}
+
+// getKey returns the public key for kid, refreshing the JWKS when the cache
+// is expired or kid is unknown. Refreshes are limited to once per minute so that
+// forged tokens can't be used to flood the JWKS endpoint, and an expired key is
+// still served while the JWKS can't be refreshed.
+func (v *AWSCognitoJWTVerifier) getKey(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	v.mu.Lock()
+	key, ok := v.keys[kid]
+	expired := time.Since(v.fetchedAt) >= v.config.JWKSCacheTTL
+	v.mu.Unlock()
+	if ok && !expired {
+		return key, nil
+	}
+
+	// concurrent refreshes share one fetch.
+	keys, err, _ := v.group.Do("jwks", func() (any, error) {
+		return v.refreshJWKS(ctx)
+	})
+	if err != nil {
+		if ok {
+			return key, nil
+		}
+		return nil, err
+	}
+	if key, ok = keys.(map[string]*rsa.PublicKey)[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrAWSCognitoJWKSKeyNotFound, kid)
+}
+
+// refreshJWKS fetches the JWKS unless it was attempted within the refresh wait,
+// in which case the cached keys, or the error of the last fetch, are returned.
+func (v *AWSCognitoJWTVerifier) refreshJWKS(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	v.mu.Lock()
+	now := time.Now()
+	if now.Sub(v.attemptedAt) < defaultAWSCognitoJWKSRefreshMinWait {
+		keys, err := v.keys, v.fetchErr
+		v.mu.Unlock()
+		if keys == nil && err != nil {
+			return nil, err
+		}
+		return keys, nil
+	}
+	v.attemptedAt = now
+	v.mu.Unlock()
+
+	// the fetch is shared by the callers, so it doesn't stop when one of them is canceled.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), defaultAWSCognitoJWKSFetchTimeout)
+	defer cancel()
+	keys, err := v.fetchJWKS(ctx)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.fetchErr = err
+	if err != nil {
+		return nil, err
+	}
+	v.keys = keys
+	v.fetchedAt = now
+	return keys, nil
+}
+
+// awsCognitoJWKS is the JSON Web Key Set document.
+type awsCognitoJWKS struct {
+	Keys []struct {
+		Kid string `json:"kid"`
+		Kty string `json:"kty"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+// fetchJWKS downloads and parses the RSA keys of the JWKS.
+func (v *AWSCognitoJWTVerifier) fetchJWKS(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.config.JWKSURL, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAWSCognitoJWKSFetch, err)
+	}
+	res, err := v.config.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAWSCognitoJWKSFetch, err)
+	}
+	defer func() { _ = res.Body.Close() }()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", ErrAWSCognitoJWKSFetch, res.StatusCode)
+	}
+	var jwks awsCognitoJWKS
+	if err := json.NewDecoder(res.Body).Decode(&jwks); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAWSCognitoJWKSFetch, err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(jwks.Keys))
+	for _, k := range jwks.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("%w: kid %s: %w", ErrAWSCognitoJWKSFetch, k.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("%w: kid %s: %w", ErrAWSCognitoJWKSFetch, k.Kid, err)
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	return keys, nil
+}
+
+// newAWSCognitoClaims maps Cognito specific claims.
+func newAWSCognitoClaims(m jwt.MapClaims) *AWSCognitoClaims {
+	str := func(key string) string {
+		s, _ := m[key].(string)
+		return s
+	}
+	claims := &AWSCognitoClaims{
+		Raw:      m,
+		Subject:  str("sub"),
+		Email:    str("email"),
+		TokenUse: str("token_use"),
+		Issuer:   str("iss"),
+		Scope:    str("scope"),
+	}
+	if exp, err := m.GetExpirationTime(); err == nil && exp != nil {
+		claims.ExpiresAt = exp.Time
+	}
+	if iat, err := m.GetIssuedAt(); err == nil && iat != nil {
+		claims.IssuedAt = iat.Time
+	}
+	// access tokens carry client_id and username, ID tokens carry aud and cognito:username.
+	if claims.TokenUse == AWSCognitoTokenUseID {
+		if aud, err := m.GetAudience(); err == nil && len(aud) > 0 {
+			claims.ClientID = aud[0]
+		}
+		claims.Username = str("cognito:username")
+	} else {
+		claims.ClientID = str("client_id")
+		claims.Username = str("username")
+	}
+	if groups, ok := m["cognito:groups"].([]any); ok {
+		for _, g := range groups {
+			if s, ok := g.(string); ok {
+				claims.Groups = append(claims.Groups, s)
+			}
+		}
+	}
+	return claims
+}
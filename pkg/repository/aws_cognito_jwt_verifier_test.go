@@ -0,0 +1,306 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCognitoIssuer = "https://cognito-idp.ap-northeast-1.amazonaws.com/ap-northeast-1_test"
+
+type testJWKS struct {
+	key     *rsa.PrivateKey
+	server  *httptest.Server
+	kid     string
+	fetches atomic.Int32
+	fail    atomic.Bool
+}
+
+func newTestJWKS(t *testing.T) *testJWKS {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	j := &testJWKS{key: key, kid: "kid-1"}
+	j.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		j.fetches.Add(1)
+		if j.fail.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kid": j.kid,
+				"kty": "RSA",
+				"alg": "RS256",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	t.Cleanup(j.server.Close)
+	return j
+}
+
+func (j *testJWKS) sign(t *testing.T, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	s, err := token.SignedString(j.key)
+	require.NoError(t, err)
+	return s
+}
+
+func (j *testJWKS) verifier(cfg *AWSCognitoJWTVerifierConfig) *AWSCognitoJWTVerifier {
+	cfg.Issuer = testCognitoIssuer
+	cfg.JWKSURL = j.server.URL
+	return NewAWSCognitoJWTVerifier(cfg)
+}
+
+func testAccessClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"sub":            "sub-1",
+		"iss":            testCognitoIssuer,
+		"token_use":      "access",
+		"client_id":      "client-1",
+		"username":       "user-1",
+		"scope":          "aws.cognito.signin.user.admin",
+		"cognito:groups": []string{"admin", "dev"},
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+	}
+}
+
+func TestNewAWSCognitoJWTVerifier(t *testing.T) {
+	v := NewAWSCognitoJWTVerifier(&AWSCognitoJWTVerifierConfig{Region: "ap-northeast-1", UserPoolID: "ap-northeast-1_test"})
+	assert.Equal(t, testCognitoIssuer, v.config.Issuer)
+	assert.Equal(t, testCognitoIssuer+"/.well-known/jwks.json", v.config.JWKSURL)
+	assert.Equal(t, defaultAWSCognitoClockSkew, v.config.ClockSkew)
+	assert.Equal(t, defaultAWSCognitoJWKSCacheTTL, v.config.JWKSCacheTTL)
+	assert.Equal(t, http.DefaultClient, v.config.HTTPClient)
+}
+
+func TestAWSCognitoJWTVerifier_Verify(t *testing.T) {
+	j := newTestJWKS(t)
+
+	t.Run("access token", func(t *testing.T) {
+		v := j.verifier(&AWSCognitoJWTVerifierConfig{TokenUse: AWSCognitoTokenUseAccess, ClientIDs: []string{"client-1"}})
+		claims, err := v.Verify(context.Background(), j.sign(t, j.kid, testAccessClaims()))
+		require.NoError(t, err)
+		assert.Equal(t, "sub-1", claims.Subject)
+		assert.Equal(t, "user-1", claims.Username)
+		assert.Equal(t, "client-1", claims.ClientID)
+		assert.Equal(t, []string{"admin", "dev"}, claims.Groups)
+		assert.True(t, claims.HasGroup("admin"))
+		assert.True(t, claims.HasAnyGroup("ops", "dev"))
+		assert.False(t, claims.HasAnyGroup("ops"))
+	})
+
+	t.Run("id token", func(t *testing.T) {
+		c := testAccessClaims()
+		delete(c, "client_id")
+		delete(c, "username")
+		c["token_use"] = "id"
+		c["aud"] = "client-1"
+		c["cognito:username"] = "user-1"
+		c["email"] = "user@example.com"
+		v := j.verifier(&AWSCognitoJWTVerifierConfig{ClientIDs: []string{"client-1"}})
+		claims, err := v.Verify(context.Background(), j.sign(t, j.kid, c))
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", claims.Username)
+		assert.Equal(t, "client-1", claims.ClientID)
+		assert.Equal(t, "user@example.com", claims.Email)
+	})
+
+	t.Run("expired within clock skew", func(t *testing.T) {
+		c := testAccessClaims()
+		c["exp"] = time.Now().Add(-30 * time.Second).Unix()
+		v := j.verifier(&AWSCognitoJWTVerifierConfig{AllowAnyClient: true})
+		_, err := v.Verify(context.Background(), j.sign(t, j.kid, c))
+		assert.NoError(t, err)
+	})
+
+	tests := []struct {
+		name    string
+		cfg     *AWSCognitoJWTVerifierConfig
+		claims  func() jwt.MapClaims
+		kid     string
+		wantErr error
+	}{
+		{
+			name: "expired",
+			cfg:  &AWSCognitoJWTVerifierConfig{AllowAnyClient: true},
+			claims: func() jwt.MapClaims {
+				c := testAccessClaims()
+				c["exp"] = time.Now().Add(-2 * time.Minute).Unix()
+				return c
+			},
+			wantErr: ErrAWSCognitoTokenInvalid,
+		},
+		{
+			name: "wrong issuer",
+			cfg:  &AWSCognitoJWTVerifierConfig{AllowAnyClient: true},
+			claims: func() jwt.MapClaims {
+				c := testAccessClaims()
+				c["iss"] = "https://example.com"
+				return c
+			},
+			wantErr: ErrAWSCognitoTokenInvalid,
+		},
+		{
+			name:    "wrong token_use",
+			cfg:     &AWSCognitoJWTVerifierConfig{TokenUse: AWSCognitoTokenUseID},
+			claims:  testAccessClaims,
+			wantErr: ErrAWSCognitoTokenUseNotAllowed,
+		},
+		{
+			name:    "wrong client",
+			cfg:     &AWSCognitoJWTVerifierConfig{ClientIDs: []string{"client-2"}},
+			claims:  testAccessClaims,
+			wantErr: ErrAWSCognitoTokenClientNotAllowed,
+		},
+		{
+			name:    "no client IDs",
+			cfg:     &AWSCognitoJWTVerifierConfig{},
+			claims:  testAccessClaims,
+			wantErr: ErrAWSCognitoTokenClientNotAllowed,
+		},
+		{
+			name:    "unknown kid",
+			cfg:     &AWSCognitoJWTVerifierConfig{AllowAnyClient: true},
+			claims:  testAccessClaims,
+			kid:     "kid-unknown",
+			wantErr: ErrAWSCognitoJWKSKeyNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kid := tt.kid
+			if kid == "" {
+				kid = j.kid
+			}
+			_, err := j.verifier(tt.cfg).Verify(context.Background(), j.sign(t, kid, tt.claims()))
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	t.Run("forged signature", func(t *testing.T) {
+		other, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, testAccessClaims())
+		token.Header["kid"] = j.kid
+		s, err := token.SignedString(other)
+		require.NoError(t, err)
+		_, err = j.verifier(&AWSCognitoJWTVerifierConfig{AllowAnyClient: true}).Verify(context.Background(), s)
+		assert.ErrorIs(t, err, ErrAWSCognitoTokenInvalid)
+	})
+}
+
+func TestAWSCognitoJWTVerifier_JWKSCache(t *testing.T) {
+	j := newTestJWKS(t)
+	v := j.verifier(&AWSCognitoJWTVerifierConfig{AllowAnyClient: true})
+	token := j.sign(t, j.kid, testAccessClaims())
+
+	for range 3 {
+		_, err := v.Verify(context.Background(), token)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), j.fetches.Load())
+
+	// unknown kids refetch at most once per minute.
+	for range 3 {
+		_, err := v.Verify(context.Background(), j.sign(t, "kid-unknown", testAccessClaims()))
+		assert.ErrorIs(t, err, ErrAWSCognitoJWKSKeyNotFound)
+	}
+	assert.Equal(t, int32(1), j.fetches.Load())
+
+	// rotated keys are picked up once the refresh wait has passed.
+	v.attemptedAt = time.Now().Add(-defaultAWSCognitoJWKSRefreshMinWait)
+	j.kid = "kid-2"
+	_, err := v.Verify(context.Background(), j.sign(t, "kid-2", testAccessClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), j.fetches.Load())
+}
+
+func TestAWSCognitoJWTVerifier_JWKSExpired(t *testing.T) {
+	j := newTestJWKS(t)
+	v := j.verifier(&AWSCognitoJWTVerifierConfig{AllowAnyClient: true, JWKSCacheTTL: time.Millisecond})
+	token := j.sign(t, j.kid, testAccessClaims())
+
+	_, err := v.Verify(context.Background(), token)
+	require.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+
+	// expired keys are refreshed at most once per minute too.
+	for range 3 {
+		_, err = v.Verify(context.Background(), token)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), j.fetches.Load())
+
+	// expired keys are served while the JWKS can't be fetched.
+	v.attemptedAt = time.Now().Add(-defaultAWSCognitoJWKSRefreshMinWait)
+	j.fail.Store(true)
+	_, err = v.Verify(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), j.fetches.Load())
+
+	_, err = v.Verify(context.Background(), j.sign(t, "kid-unknown", testAccessClaims()))
+	assert.ErrorIs(t, err, ErrAWSCognitoJWKSKeyNotFound)
+}
+
+func TestAWSCognitoJWTVerifier_ConcurrentFetch(t *testing.T) {
+	j := newTestJWKS(t)
+	v := j.verifier(&AWSCognitoJWTVerifierConfig{AllowAnyClient: true})
+	token := j.sign(t, j.kid, testAccessClaims())
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			_, err := v.Verify(context.Background(), token)
+			assert.NoError(t, err)
+		})
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), j.fetches.Load())
+}
+
+func TestAWSCognitoJWTVerifier_VerifyAuthorizationHeader(t *testing.T) {
+	j := newTestJWKS(t)
+	v := j.verifier(&AWSCognitoJWTVerifierConfig{AllowAnyClient: true})
+
+	claims, err := v.VerifyAuthorizationHeader(context.Background(), "Bearer "+j.sign(t, j.kid, testAccessClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, "sub-1", claims.Subject)
+
+	_, err = v.VerifyAuthorizationHeader(context.Background(), "")
+	assert.ErrorIs(t, err, ErrAWSCognitoAccessTokenNotFound)
+
+	_, err = v.VerifyAuthorizationHeader(context.Background(), "Basic abc")
+	assert.ErrorIs(t, err, ErrAWSCognitoAccessTokenFormatNotSupported)
+}
+
+func TestAWSCognitoJWTVerifier_JWKSFetchError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	j := newTestJWKS(t)
+	v := NewAWSCognitoJWTVerifier(&AWSCognitoJWTVerifierConfig{Issuer: testCognitoIssuer, JWKSURL: server.URL, AllowAnyClient: true})
+	_, err := v.Verify(context.Background(), j.sign(t, j.kid, testAccessClaims()))
+	assert.ErrorIs(t, err, ErrAWSCognitoJWKSFetch)
+}
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+var (
+	ErrAWSCognitoSoftwareTokenVerificationFailed = errors.New("software token verification failed")
+	ErrAWSCognitoChallengeNotSupported           = errors.New("challenge is not supported")
+)
+
+// AWSCognitoSoftwareToken is the result of AssociateSoftwareToken.
+// SecretCode is registered in the authenticator app, e.g. via GetAWSCognitoTOTPURI.
+type AWSCognitoSoftwareToken struct {
+	SecretCode string
+	Session    string
+}
+
+// AWSCognitoMFAPreference sets the MFA methods enabled for a user and the preferred one.
+type AWSCognitoMFAPreference struct {
+	SMSEnabled             bool
+	SMSPreferred           bool
+	SoftwareTokenEnabled   bool
+	SoftwareTokenPreferred bool
+}
+
+// RespondToAuthChallenge responds to a challenge returned by Login.
+// USERNAME and SECRET_HASH are added to responses automatically.
+func (r *AWSCognitoRepository) RespondToAuthChallenge(
+	ctx context.Context,
+	username string,
+	challenge *AWSCognitoChallenge,
+	responses map[string]string,
+) (AWSCognitoLoginResult, error) {
+	challengeResponses := maps.Clone(responses)
+	if challengeResponses == nil {
+		challengeResponses = map[string]string{}
+	}
+	challengeResponses["USERNAME"] = username
+	challengeResponses["SECRET_HASH"] = r.getSecretHash(username)
+
+	res, err := r.Client.AdminRespondToAuthChallenge(ctx, &cognitoidentityprovider.AdminRespondToAuthChallengeInput{
+		ChallengeName:      challenge.Name,
+		ClientId:           aws.String(r.userPoolClientID),
+		UserPoolId:         aws.String(r.userPoolID),
+		Session:            aws.String(challenge.Session),
+		ChallengeResponses: challengeResponses,
+	})
+	if err != nil {
+		return AWSCognitoLoginResult{}, fmt.Errorf("cognito AdminRespondToAuthChallenge: %w", toAWSCognitoError(err))
+	}
+	result, err := newAWSCognitoLoginResult(res.ChallengeName, res.Session, res.ChallengeParameters, res.AuthenticationResult)
+	if err != nil {
+		return AWSCognitoLoginResult{}, fmt.Errorf("cognito AdminRespondToAuthChallenge: %w", err)
+	}
+	return result, nil
+}
+
+// RespondToNewPasswordChallenge responds to NEW_PASSWORD_REQUIRED with the new password.
+func (r *AWSCognitoRepository) RespondToNewPasswordChallenge(
+	ctx context.Context,
+	username, newPassword string,
+	challenge *AWSCognitoChallenge,
+) (AWSCognitoLoginResult, error) {
+	return r.RespondToAuthChallenge(ctx, username, challenge, map[string]string{
+		"NEW_PASSWORD": newPassword, // pragma: allowlist-secret
+	})
+}
+
+// RespondToMFAChallenge responds to SMS_MFA or SOFTWARE_TOKEN_MFA with the code.
+func (r *AWSCognitoRepository) RespondToMFAChallenge(
+	ctx context.Context,
+	username, code string,
+	challenge *AWSCognitoChallenge,
+) (AWSCognitoLoginResult, error) {
+	var key string
+	switch challenge.Name {
+	case types.ChallengeNameTypeSmsMfa:
+		key = "SMS_MFA_CODE"
+	case types.ChallengeNameTypeSoftwareTokenMfa:
+		key = "SOFTWARE_TOKEN_MFA_CODE"
+	default:
+		return AWSCognitoLoginResult{}, fmt.Errorf("cognito RespondToMFAChallenge: %w: %s", ErrAWSCognitoChallengeNotSupported, challenge.Name)
+	}
+	return r.RespondToAuthChallenge(ctx, username, challenge, map[string]string{key: code})
+}
+
+// RespondToMFASetupChallenge completes MFA_SETUP. Call AssociateSoftwareTokenWithSession
+// with the challenge session first to show the secret to the user, then call this
+// with the session it returned and the TOTP code entered by the user.
+func (r *AWSCognitoRepository) RespondToMFASetupChallenge(
+	ctx context.Context,
+	username, code, friendlyDeviceName, session string,
+) (AWSCognitoLoginResult, error) {
+	verifiedSession, err := r.VerifySoftwareTokenWithSession(ctx, session, code, friendlyDeviceName)
+	if err != nil {
+		return AWSCognitoLoginResult{}, err
+	}
+	return r.RespondToAuthChallenge(ctx, username, &AWSCognitoChallenge{
+		Name:    types.ChallengeNameTypeMfaSetup,
+		Session: verifiedSession,
+	}, nil)
+}
+
+// AssociateSoftwareToken begins TOTP setup for the signed-in user and returns the secret code.
+func (r *AWSCognitoRepository) AssociateSoftwareToken(ctx context.Context, authorizationHeader string) (AWSCognitoSoftwareToken, error) {
+	accessToken, err := r.getAccessToken(authorizationHeader)
+	if err != nil {
+		return AWSCognitoSoftwareToken{}, fmt.Errorf("cognito getAccessToken: %w", err)
+	}
+	return r.associateSoftwareToken(ctx, &cognitoidentityprovider.AssociateSoftwareTokenInput{
+		AccessToken: aws.String(accessToken),
+	})
+}
+
+// AssociateSoftwareTokenWithSession begins TOTP setup during an MFA_SETUP challenge and returns the secret code.
+func (r *AWSCognitoRepository) AssociateSoftwareTokenWithSession(ctx context.Context, session string) (AWSCognitoSoftwareToken, error) {
+	return r.associateSoftwareToken(ctx, &cognitoidentityprovider.AssociateSoftwareTokenInput{
+		Session: aws.String(session),
+	})
+}
+
+// VerifySoftwareToken verifies the TOTP code of the signed-in user and completes TOTP setup.
+func (r *AWSCognitoRepository) VerifySoftwareToken(ctx context.Context, authorizationHeader, code, friendlyDeviceName string) error {
+	accessToken, err := r.getAccessToken(authorizationHeader)
+	if err != nil {
+		return fmt.Errorf("cognito getAccessToken: %w", err)
+	}
+	_, err = r.verifySoftwareToken(ctx, &cognitoidentityprovider.VerifySoftwareTokenInput{
+		AccessToken:        aws.String(accessToken),
+		UserCode:           aws.String(code),
+		FriendlyDeviceName: friendlyDeviceNameOrNil(friendlyDeviceName),
+	})
+	return err
+}
+
+// VerifySoftwareTokenWithSession verifies the TOTP code during an MFA_SETUP
+// challenge and returns the session to respond to the challenge with.
+func (r *AWSCognitoRepository) VerifySoftwareTokenWithSession(ctx context.Context, session, code, friendlyDeviceName string) (string, error) {
+	return r.verifySoftwareToken(ctx, &cognitoidentityprovider.VerifySoftwareTokenInput{
+		Session:            aws.String(session),
+		UserCode:           aws.String(code),
+		FriendlyDeviceName: friendlyDeviceNameOrNil(friendlyDeviceName),
+	})
+}
+
+// SetUserMFAPreference sets the MFA preference of the signed-in user.
+func (r *AWSCognitoRepository) SetUserMFAPreference(ctx context.Context, authorizationHeader string, preference *AWSCognitoMFAPreference) error {
+	accessToken, err := r.getAccessToken(authorizationHeader)
+	if err != nil {
+		return fmt.Errorf("cognito getAccessToken: %w", err)
+	}
+	sms, softwareToken := preference.settings()
+	_, err = r.Client.SetUserMFAPreference(ctx, &cognitoidentityprovider.SetUserMFAPreferenceInput{
+		AccessToken:              aws.String(accessToken),
+		SMSMfaSettings:           sms,
+		SoftwareTokenMfaSettings: softwareToken,
+	})
+	if err != nil {
+		return fmt.Errorf("cognito SetUserMFAPreference: %w", toAWSCognitoError(err))
+	}
+	return nil
+}
+
+// AdminSetUserMFAPreference sets the MFA preference of the user as an administrator.
+func (r *AWSCognitoRepository) AdminSetUserMFAPreference(ctx context.Context, username string, preference *AWSCognitoMFAPreference) error {
+	sms, softwareToken := preference.settings()
+	_, err := r.Client.AdminSetUserMFAPreference(ctx, &cognitoidentityprovider.AdminSetUserMFAPreferenceInput{
+		UserPoolId:               aws.String(r.userPoolID),
+		Username:                 aws.String(username),
+		SMSMfaSettings:           sms,
+		SoftwareTokenMfaSettings: softwareToken,
+	})
+	if err != nil {
+		return fmt.Errorf("cognito AdminSetUserMFAPreference: %w", toAWSCognitoError(err))
+	}
+	return nil
+}
+
+// GetAWSCognitoTOTPURI returns the otpauth:// URI of secretCode, which
+// authenticator apps accept directly or as a QR code.
+func GetAWSCognitoTOTPURI(issuer, username, secretCode string) string {
+	u := url.URL{
+		Scheme: "otpauth",
+		Host:   "totp",
+		Path:   "/" + issuer + ":" + username,
+	}
+	q := url.Values{}
+	q.Set("secret", secretCode)
+	q.Set("issuer", issuer)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// associateSoftwareToken calls AssociateSoftwareToken.
+func (r *AWSCognitoRepository) associateSoftwareToken(ctx context.Context, input *cognitoidentityprovider.AssociateSoftwareTokenInput) (AWSCognitoSoftwareToken, error) {
+	res, err := r.Client.AssociateSoftwareToken(ctx, input)
+	if err != nil {
+		return AWSCognitoSoftwareToken{}, fmt.Errorf("cognito AssociateSoftwareToken: %w", toAWSCognitoError(err))
+	}
+	return AWSCognitoSoftwareToken{
+		SecretCode: aws.ToString(res.SecretCode),
+		Session:    aws.ToString(res.Session),
+	}, nil
+}
+
+// verifySoftwareToken calls VerifySoftwareToken and returns its session.
+func (r *AWSCognitoRepository) verifySoftwareToken(ctx context.Context, input *cognitoidentityprovider.VerifySoftwareTokenInput) (string, error) {
+	res, err := r.Client.VerifySoftwareToken(ctx, input)
+	if err != nil {
+		return "", fmt.Errorf("cognito VerifySoftwareToken: %w", toAWSCognitoError(err))
+	}
+	if res.Status != types.VerifySoftwareTokenResponseTypeSuccess {
+		return "", fmt.Errorf("cognito VerifySoftwareToken: %w", ErrAWSCognitoSoftwareTokenVerificationFailed)
+	}
+	return aws.ToString(res.Session), nil
+}
+
+// settings converts AWSCognitoMFAPreference to Cognito MFA settings.
+func (p *AWSCognitoMFAPreference) settings() (*types.SMSMfaSettingsType, *types.SoftwareTokenMfaSettingsType) {
+	sms := &types.SMSMfaSettingsType{
+		Enabled:      p.SMSEnabled,
+		PreferredMfa: p.SMSPreferred,
+	}
+	softwareToken := &types.SoftwareTokenMfaSettingsType{
+		Enabled:      p.SoftwareTokenEnabled,
+		PreferredMfa: p.SoftwareTokenPreferred,
+	}
+	return sms, softwareToken
+}
+
+// friendlyDeviceNameOrNil returns nil for an empty device name.
+func friendlyDeviceNameOrNil(name string) *string {
+	if name == "" {
+		return nil
+	}
+	return aws.String(name)
+}
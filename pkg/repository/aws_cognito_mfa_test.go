@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAWSCognitoRepository_RespondToNewPasswordChallenge(t *testing.T) {
+	mockClient := &MockCognitoClient{}
+	repo := NewAWSCognitoRepositoryWithInterface(mockClient, "test_pool", "test_client", "test_secret")
+	challenge := &AWSCognitoChallenge{Name: types.ChallengeNameTypeNewPasswordRequired, Session: "session-1"}
+
+	mockClient.On("AdminRespondToAuthChallenge", mock.Anything, mock.MatchedBy(func(input *cognitoidentityprovider.AdminRespondToAuthChallengeInput) bool {
+		return input.ChallengeName == types.ChallengeNameTypeNewPasswordRequired &&
+			*input.Session == "session-1" &&
+			*input.UserPoolId == "test_pool" &&
+			input.ChallengeResponses["USERNAME"] == "test-user" &&
+			input.ChallengeResponses["NEW_PASSWORD"] == "new-password" &&
+			input.ChallengeResponses["SECRET_HASH"] == repo.getSecretHash("test-user")
+	}), mock.Anything).Return(&cognitoidentityprovider.AdminRespondToAuthChallengeOutput{
+		AuthenticationResult: &types.AuthenticationResultType{
+			AccessToken:  aws.String("access-token"),
+			RefreshToken: aws.String("refresh-token"),
+			IdToken:      aws.String("id-token"),
+			ExpiresIn:    3600,
+		},
+	}, nil)
+
+	result, err := repo.RespondToNewPasswordChallenge(context.Background(), "test-user", "new-password", challenge)
+
+	assert.NoError(t, err)
+	assert.False(t, result.ChallengeRequired())
+	assert.Equal(t, "access-token", result.AccessToken)
+	assert.Equal(t, "refresh-token", result.RefreshToken)
+	assert.Equal(t, "id-token", result.IDToken)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSCognitoRepository_RespondToMFAChallenge(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantKey string
+		wantErr error
+		mfa     types.ChallengeNameType
+	}{
+		{name: "sms", mfa: types.ChallengeNameTypeSmsMfa, wantKey: "SMS_MFA_CODE"},
+		{name: "software token", mfa: types.ChallengeNameTypeSoftwareTokenMfa, wantKey: "SOFTWARE_TOKEN_MFA_CODE"},
+		{name: "unsupported", mfa: types.ChallengeNameTypeMfaSetup, wantErr: ErrAWSCognitoChallengeNotSupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockCognitoClient{}
+			repo := NewAWSCognitoRepositoryWithInterface(mockClient, "test_pool", "test_client", "test_secret")
+			if tt.wantErr == nil {
+				mockClient.On("AdminRespondToAuthChallenge", mock.Anything, mock.MatchedBy(func(input *cognitoidentityprovider.AdminRespondToAuthChallengeInput) bool {
+					return input.ChallengeName == tt.mfa && input.ChallengeResponses[tt.wantKey] == "123456"
+				}), mock.Anything).Return(&cognitoidentityprovider.AdminRespondToAuthChallengeOutput{
+					AuthenticationResult: &types.AuthenticationResultType{AccessToken: aws.String("access-token")},
+				}, nil)
+			}
+
+			result, err := repo.RespondToMFAChallenge(context.Background(), "test-user", "123456", &AWSCognitoChallenge{Name: tt.mfa, Session: "s"})
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "access-token", result.AccessToken)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAWSCognitoRepository_RespondToAuthChallenge_NextChallenge(t *testing.T) {
+	mockClient := &MockCognitoClient{}
+	repo := NewAWSCognitoRepositoryWithInterface(mockClient, "test_pool", "test_client", "test_secret")
+
+	mockClient.On("AdminRespondToAuthChallenge", mock.Anything, mock.Anything, mock.Anything).Return(&cognitoidentityprovider.AdminRespondToAuthChallengeOutput{
+		ChallengeName: types.ChallengeNameTypeMfaSetup,
+		Session:       aws.String("session-2"),
+	}, nil)
+
+	result, err := repo.RespondToNewPasswordChallenge(context.Background(), "test-user", "new-password",
+		&AWSCognitoChallenge{Name: types.ChallengeNameTypeNewPasswordRequired, Session: "session-1"})
+
+	assert.NoError(t, err)
+	assert.True(t, result.ChallengeRequired())
+	assert.Equal(t, types.ChallengeNameTypeMfaSetup, result.Challenge.Name)
+	assert.Equal(t, "session-2", result.Challenge.Session)
+}
+
+func TestAWSCognitoRepository_RespondToAuthChallenge_Error(t *testing.T) {
+	mockClient := &MockCognitoClient{}
+	repo := NewAWSCognitoRepositoryWithInterface(mockClient, "test_pool", "test_client", "test_secret")
+
+	mockClient.On("AdminRespondToAuthChallenge", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("code mismatch"))
+
+	_, err := repo.RespondToAuthChallenge(context.Background(), "test-user",
+		&AWSCognitoChallenge{Name: types.ChallengeNameTypeSmsMfa, Session: "s"}, map[string]string{"SMS_MFA_CODE": "1"})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cognito AdminRespondToAuthChallenge")
+}
+
+func TestAWSCognitoRepository_MFASetupChallenge(t *testing.T) {
+	mockClient := &MockCognitoClient{}
+	repo := NewAWSCognitoRepositoryWithInterface(mockClient, "test_pool", "test_client", "test_secret")
+
+	mockClient.On("AssociateSoftwareToken", mock.Anything, mock.MatchedBy(func(input *cognitoidentityprovider.AssociateSoftwareTokenInput) bool {
+		return *input.Session == "session-1" && input.AccessToken == nil
+	}), mock.Anything).Return(&cognitoidentityprovider.AssociateSoftwareTokenOutput{
+		SecretCode: aws.String("SECRET"),
+		Session:    aws.String("session-2"),
+	}, nil)
+	mockClient.On("VerifySoftwareToken", mock.Anything, mock.MatchedBy(func(input *cognitoidentityprovider.VerifySoftwareTokenInput) bool {
+		return *input.Session == "session-2" && *input.UserCode == "123456" && *input.FriendlyDeviceName == "phone"
+	}), mock.Anything).Return(&cognitoidentityprovider.VerifySoftwareTokenOutput{
+		Status:  types.VerifySoftwareTokenResponseTypeSuccess,
+		Session: aws.String("session-3"),
+	}, nil)
+	mockClient.On("AdminRespondToAuthChallenge", mock.Anything, mock.MatchedBy(func(input *cognitoidentityprovider.AdminRespondToAuthChallengeInput) bool {
+		return input.ChallengeName == types.ChallengeNameTypeMfaSetup && *input.Session == "session-3"
+	}), mock.Anything).Return(&cognitoidentityprovider.AdminRespondToAuthChallengeOutput{
+		AuthenticationResult: &types.AuthenticationResultType{AccessToken: aws.String("access-token")},
+	}, nil)
+
+	token, err := repo.AssociateSoftwareTokenWithSession(context.Background(), "session-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "SECRET", token.SecretCode)
+
+	result, err := repo.RespondToMFASetupChallenge(context.Background(), "test-user", "123456", "phone", token.Session)
+	assert.NoError(t, err)
+	assert.Equal(t, "access-token", result.AccessToken)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSCognitoRepository_AssociateAndVerifySoftwareToken(t *testing.T) {
+	mockClient := &MockCognitoClient{}
+	repo := NewAWSCognitoRepositoryWithInterface(mockClient, "test_pool", "test_client", "test_secret")
+
+	mockClient.On("AssociateSoftwareToken", mock.Anything, mock.MatchedBy(func(input *cognitoidentityprovider.AssociateSoftwareTokenInput) bool {
+		return *input.AccessToken == "access-token"
+	}), mock.Anything).Return(&cognitoidentityprovider.AssociateSoftwareTokenOutput{SecretCode: aws.String("SECRET")}, nil)
+	mockClient.On("VerifySoftwareToken", mock.Anything, mock.MatchedBy(func(input *cognitoidentityprovider.VerifySoftwareTokenInput) bool {
+		return *input.AccessToken == "access-token" && input.FriendlyDeviceName == nil
+	}), mock.Anything).Return(&cognitoidentityprovider.VerifySoftwareTokenOutput{
+		Status: types.VerifySoftwareTokenResponseTypeError,
+	}, nil)
+
+	token, err := repo.AssociateSoftwareToken(context.Background(), "Bearer access-token")
+	assert.NoError(t, err)
+	assert.Equal(t, "SECRET", token.SecretCode)
+
+	err = repo.VerifySoftwareToken(context.Background(), "Bearer access-token", "000000", "")
+	assert.ErrorIs(t, err, ErrAWSCognitoSoftwareTokenVerificationFailed)
+
+	_, err = repo.AssociateSoftwareToken(context.Background(), "")
+	assert.ErrorIs(t, err, ErrAWSCognitoAccessTokenNotFound)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSCognitoRepository_SetUserMFAPreference(t *testing.T) {
+	mockClient := &MockCognitoClient{}
+	repo := NewAWSCognitoRepositoryWithInterface(mockClient, "test_pool", "test_client", "test_secret")
+	preference := &AWSCognitoMFAPreference{SoftwareTokenEnabled: true, SoftwareTokenPreferred: true}
+
+	mockClient.On("SetUserMFAPreference", mock.Anything, mock.MatchedBy(func(input *cognitoidentityprovider.SetUserMFAPreferenceInput) bool {
+		return *input.AccessToken == "access-token" &&
+			input.SoftwareTokenMfaSettings.Enabled && input.SoftwareTokenMfaSettings.PreferredMfa &&
+			!input.SMSMfaSettings.Enabled
+	}), mock.Anything).Return(&cognitoidentityprovider.SetUserMFAPreferenceOutput{}, nil)
+	mockClient.On("AdminSetUserMFAPreference", mock.Anything, mock.MatchedBy(func(input *cognitoidentityprovider.AdminSetUserMFAPreferenceInput) bool {
+		return *input.UserPoolId == "test_pool" && *input.Username == "test-user" &&
+			input.SoftwareTokenMfaSettings.PreferredMfa
+	}), mock.Anything).Return(nil, errors.New("user not found"))
+
+	assert.NoError(t, repo.SetUserMFAPreference(context.Background(), "Bearer access-token", preference))
+	err := repo.AdminSetUserMFAPreference(context.Background(), "test-user", preference)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cognito AdminSetUserMFAPreference")
+	mockClient.AssertExpectations(t)
+}
+
+func TestGetAWSCognitoTOTPURI(t *testing.T) {
+	uri := GetAWSCognitoTOTPURI("My App", "user@example.com", "SECRET")
+	assert.Equal(t, "otpauth://totp/My%20App:user@example.com?issuer=My+App&secret=SECRET", uri)
+}
@@ -24,6 +24,7 @@
 var (
 	ErrAWSCognitoAccessTokenNotFound           = errors.New("access token not found")
 	ErrAWSCognitoAccessTokenFormatNotSupported = errors.New("access token format is not supported")
+	ErrAWSCognitoAuthenticationResultNotFound  = errors.New("authentication result not found")
 )
 
 // AWSCognitoIdentityProviderClientInterface defines the interface for Cognito client operations
@@ -52,6 +53,42 @@ type AWSCognitoIdentityProviderClientInterface interface {
 	GlobalSignOut(_ context.Context, _ *cognitoidentityprovider.GlobalSignOutInput, _ ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.GlobalSignOutOutput, error)
 	// RevokeToken revokes a refresh token
 	RevokeToken(_ context.Context, _ *cognitoidentityprovider.RevokeTokenInput, _ ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.RevokeTokenOutput, error)
+	// AdminRespondToAuthChallenge responds to an authentication challenge as an admin
+	AdminRespondToAuthChallenge(_ context.Context, _ *cognitoidentityprovider.AdminRespondToAuthChallengeInput, _ ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminRespondToAuthChallengeOutput, error)
+	// AssociateSoftwareToken begins setup of time-based one-time password (TOTP) MFA for a user
+	AssociateSoftwareToken(_ context.Context, _ *cognitoidentityprovider.AssociateSoftwareTokenInput, _ ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AssociateSoftwareTokenOutput, error)
+	// VerifySoftwareToken verifies a TOTP code and marks the software token MFA as verified
+	VerifySoftwareToken(_ context.Context, _ *cognitoidentityprovider.VerifySoftwareTokenInput, _ ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.VerifySoftwareTokenOutput, error)
+	// SetUserMFAPreference sets the MFA preference of the current user
+	SetUserMFAPreference(_ context.Context, _ *cognitoidentityprovider.SetUserMFAPreferenceInput, _ ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.SetUserMFAPreferenceOutput, error)
+	// AdminSetUserMFAPreference sets the MFA preference of a user as an admin
+	AdminSetUserMFAPreference(_ context.Context, _ *cognitoidentityprovider.AdminSetUserMFAPreferenceInput, _ ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminSetUserMFAPreferenceOutput, error)
+	// AdminUpdateUserAttributes creates or updates user attributes as an admin
+	AdminUpdateUserAttributes(_ context.Context, _ *cognitoidentityprovider.AdminUpdateUserAttributesInput, _ ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminUpdateUserAttributesOutput, error)
+	// AdminDeleteUserAttributes deletes user attributes as an admin
+	AdminDeleteUserAttributes(_ context.Context, _ *cognitoidentityprovider.AdminDeleteUserAttributesInput, _ ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminDeleteUserAttributesOutput, error)
+	// AdminEnableUser enables a user as an admin
+	AdminEnableUser(_ context.Context, _ *cognitoidentityprovider.AdminEnableUserInput, _ ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminEnableUserOutput, error)
+	// AdminDisableUser disables a user as an admin
+	AdminDisableUser(_ context.Context, _ *cognitoidentityprovider.AdminDisableUserInput, _ ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminDisableUserOutput, error)
+	// AdminAddUserToGroup adds a user to a group as an admin
+	AdminAddUserToGroup(_ context.Context, _ *cognitoidentityprovider.AdminAddUserToGroupInput, _ ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminAddUserToGroupOutput, error)
+	// AdminRemoveUserFromGroup removes a user from a group as an admin
+	AdminRemoveUserFromGroup(_ context.Context, _ *cognitoidentityprovider.AdminRemoveUserFromGroupInput, _ ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminRemoveUserFromGroupOutput, error)
+	// AdminListGroupsForUser lists the groups a user belongs to as an admin
+	AdminListGroupsForUser(_ context.Context, _ *cognitoidentityprovider.AdminListGroupsForUserInput, _ ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminListGroupsForUserOutput, error)
+	// ListUsers lists users in the specified user pool
+	ListUsers(_ context.Context, _ *cognitoidentityprovider.ListUsersInput, _ ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.ListUsersOutput, error)
+	// SignUp registers a new user
+	SignUp(_ context.Context, _ *cognitoidentityprovider.SignUpInput, _ ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.SignUpOutput, error)
+	// ConfirmSignUp confirms a registration with the code
+	ConfirmSignUp(_ context.Context, _ *cognitoidentityprovider.ConfirmSignUpInput, _ ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.ConfirmSignUpOutput, error)
+	// ResendConfirmationCode resends the registration confirmation code
+	ResendConfirmationCode(_ context.Context, _ *cognitoidentityprovider.ResendConfirmationCodeInput, _ ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.ResendConfirmationCodeOutput, error)
+	// GetUserAttributeVerificationCode sends a verification code for an attribute of the current user
+	GetUserAttributeVerificationCode(_ context.Context, _ *cognitoidentityprovider.GetUserAttributeVerificationCodeInput, _ ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.GetUserAttributeVerificationCodeOutput, error)
+	// VerifyUserAttribute verifies an attribute of the current user with the code
+	VerifyUserAttribute(_ context.Context, _ *cognitoidentityprovider.VerifyUserAttributeInput, _ ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.VerifyUserAttributeOutput, error)
 }
 
 // AWSCognitoRepository struct.
@@ -68,6 +105,28 @@ type AWSCognitoToken struct {
 	AccessToken          string    `json:"access_token"`
 	AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
 	RefreshToken         string    `json:"refresh_token"`
+	IDToken              string    `json:"id_token,omitempty"`
+}
+
+// AWSCognitoChallenge is an authentication challenge returned by Cognito
+// instead of tokens, e.g. NEW_PASSWORD_REQUIRED, SMS_MFA, SOFTWARE_TOKEN_MFA or MFA_SETUP.
+// Session must be passed back with the challenge response.
+type AWSCognitoChallenge struct {
+	Parameters map[string]string
+	Name       types.ChallengeNameType
+	Session    string
+}
+
+// AWSCognitoLoginResult is the result of Login and RespondToAuthChallenge.
+// Either Challenge is set, or the embedded AWSCognitoToken holds the issued tokens.
+type AWSCognitoLoginResult struct {
+	Challenge *AWSCognitoChallenge
+	AWSCognitoToken
+}
+
+// ChallengeRequired reports whether the user must respond to a challenge to get tokens.
+func (r *AWSCognitoLoginResult) ChallengeRequired() bool {
+	return r.Challenge != nil
 }
 
 // NewAWSCognitoRepository returns AWSCognitoRepository instance.
@@ -103,7 +162,7 @@ func (r *AWSCognitoRepository) ChangePassword(ctx context.Context, authorization
 		AccessToken:      aws.String(accessToken),
 	})
 	if err != nil {
-		return fmt.Errorf("cognito ChangePassword: %w", err)
+		return fmt.Errorf("cognito ChangePassword: %w", toAWSCognitoError(err))
 	}
 	return nil
 }
@@ -115,9 +174,10 @@ func (r *AWSCognitoRepository) ConfirmForgotPassword(ctx context.Context, userna
 		Username:         aws.String(username),
 		Password:         aws.String(password),
 		ConfirmationCode: aws.String(confirmationCode),
+		SecretHash:       aws.String(r.getSecretHash(username)),
 	})
 	if err != nil {
-		return fmt.Errorf("cognito ConfirmForgotPassword: %w", err)
+		return fmt.Errorf("cognito ConfirmForgotPassword: %w", toAWSCognitoError(err))
 	}
 	return nil
 }
@@ -129,7 +189,7 @@ func (r *AWSCognitoRepository) CreateUser(ctx context.Context, username, passwor
 		Username:   aws.String(username),
 	})
 	if err != nil {
-		return fmt.Errorf("cognito AdminCreateUser: %w", err)
+		return fmt.Errorf("cognito AdminCreateUser: %w", toAWSCognitoError(err))
 	}
 
 	_, err = r.Client.AdminSetUserPassword(ctx, &cognitoidentityprovider.AdminSetUserPasswordInput{
@@ -139,7 +199,7 @@ func (r *AWSCognitoRepository) CreateUser(ctx context.Context, username, passwor
 		Permanent:  true,
 	})
 	if err != nil {
-		return fmt.Errorf("cognito AdminSetUserPassword: %w", err)
+		return fmt.Errorf("cognito AdminSetUserPassword: %w", toAWSCognitoError(err))
 	}
 	return nil
 }
@@ -151,7 +211,7 @@ func (r *AWSCognitoRepository) DeleteUser(ctx context.Context, username string)
 		Username:   aws.String(username),
 	})
 	if err != nil {
-		return fmt.Errorf("cognito AdminDeleteUser: %w", err)
+		return fmt.Errorf("cognito AdminDeleteUser: %w", toAWSCognitoError(err))
 	}
 	return nil
 }
@@ -163,13 +223,14 @@ func (r *AWSCognitoRepository) GetUser(ctx context.Context, username string) (*c
 		Username:   aws.String(username),
 	})
 	if err != nil {
-		return nil, fmt.Errorf("cognito AdminGetUser: %w", err)
+		return nil, fmt.Errorf("cognito AdminGetUser: %w", toAWSCognitoError(err))
 	}
 	return res, nil
 }
 
-// Login logs to Cognito.
-func (r *AWSCognitoRepository) Login(ctx context.Context, username, password string) (AWSCognitoToken, error) {
+// Login logs to Cognito. When Cognito requires a challenge (e.g. MFA or a
+// new password), the result holds the challenge and its session instead of tokens.
+func (r *AWSCognitoRepository) Login(ctx context.Context, username, password string) (AWSCognitoLoginResult, error) {
 	res, err := r.Client.AdminInitiateAuth(ctx, &cognitoidentityprovider.AdminInitiateAuthInput{
 		AuthFlow:   types.AuthFlowTypeAdminUserPasswordAuth,
 		ClientId:   aws.String(r.userPoolClientID),
@@ -181,14 +242,13 @@ func (r *AWSCognitoRepository) Login(ctx context.Context, username, password str
 		},
 	})
 	if err != nil {
-		return AWSCognitoToken{}, fmt.Errorf("cognito AdminInitiateAuth: %w", err)
+		return AWSCognitoLoginResult{}, fmt.Errorf("cognito AdminInitiateAuth: %w", toAWSCognitoError(err))
 	}
-
-	return AWSCognitoToken{
-		AccessToken:          *res.AuthenticationResult.AccessToken,
-		AccessTokenExpiresAt: time.Now().Add(time.Second * time.Duration(res.AuthenticationResult.ExpiresIn)),
-		RefreshToken:         *res.AuthenticationResult.RefreshToken,
-	}, nil
+	result, err := newAWSCognitoLoginResult(res.ChallengeName, res.Session, res.ChallengeParameters, res.AuthenticationResult)
+	if err != nil {
+		return AWSCognitoLoginResult{}, fmt.Errorf("cognito AdminInitiateAuth: %w", err)
+	}
+	return result, nil
 }
 
 // Logout logs out of Cognito.
@@ -199,7 +259,7 @@ func (r *AWSCognitoRepository) Logout(ctx context.Context, refreshToken string)
 		ClientSecret: aws.String(r.userPoolClientSecret),
 	})
 	if err != nil {
-		return fmt.Errorf("cognito RevokeToken: %w", err)
+		return fmt.Errorf("cognito RevokeToken: %w", toAWSCognitoError(err))
 	}
 	return nil
 }
@@ -215,13 +275,13 @@ func (r *AWSCognitoRepository) RefreshToken(ctx context.Context, refreshToken, u
 		},
 	})
 	if err != nil {
-		return AWSCognitoToken{}, fmt.Errorf("cognito AdminInitiateAuth(refresh): %w", err)
+		return AWSCognitoToken{}, fmt.Errorf("cognito AdminInitiateAuth(refresh): %w", toAWSCognitoError(err))
+	}
+	if res.AuthenticationResult == nil {
+		return AWSCognitoToken{}, fmt.Errorf("cognito AdminInitiateAuth(refresh): %w", ErrAWSCognitoAuthenticationResultNotFound)
 	}
 
-	return AWSCognitoToken{
-		AccessToken:          *res.AuthenticationResult.AccessToken,
-		AccessTokenExpiresAt: time.Now().Add(time.Second * time.Duration(res.AuthenticationResult.ExpiresIn)),
-	}, nil
+	return newAWSCognitoToken(res.AuthenticationResult), nil
 }
 
 // ResetUserPassword resets the specified user's password in a user pool as an administrator. Works on any user.
@@ -231,7 +291,7 @@ func (r *AWSCognitoRepository) ResetUserPassword(ctx context.Context, username s
 		Username:   aws.String(username),
 	})
 	if err != nil {
-		return fmt.Errorf("cognito AdminResetUserPassword: %w", err)
+		return fmt.Errorf("cognito AdminResetUserPassword: %w", toAWSCognitoError(err))
 	}
 	return nil
 }
@@ -245,7 +305,7 @@ func (r *AWSCognitoRepository) SetUserPassword(ctx context.Context, username, pa
 		Permanent:  permanent,
 	})
 	if err != nil {
-		return fmt.Errorf("cognito AdminSetUserPassword: %w", err)
+		return fmt.Errorf("cognito AdminSetUserPassword: %w", toAWSCognitoError(err))
 	}
 
 	return nil
@@ -253,6 +313,11 @@ func (r *AWSCognitoRepository) SetUserPassword(ctx context.Context, username, pa
 
 // getAccessToken gets the access token.
 func (*AWSCognitoRepository) getAccessToken(authorizationHeader string) (string, error) {
+	return GetAWSCognitoBearerToken(authorizationHeader)
+}
+
+// GetAWSCognitoBearerToken extracts the token from an "Authorization: Bearer <token>" header value.
+func GetAWSCognitoBearerToken(authorizationHeader string) (string, error) {
 	if authorizationHeader == "" {
 		return "", ErrAWSCognitoAccessTokenNotFound
 	}
@@ -266,6 +331,38 @@ func (*AWSCognitoRepository) getAccessToken(authorizationHeader string) (string,
 	return "", fmt.Errorf("%w", ErrAWSCognitoAccessTokenFormatNotSupported)
 }
 
+// newAWSCognitoLoginResult builds AWSCognitoLoginResult from an InitiateAuth or RespondToAuthChallenge response.
+func newAWSCognitoLoginResult(
+	challengeName types.ChallengeNameType,
+	session *string,
+	challengeParameters map[string]string,
+	authenticationResult *types.AuthenticationResultType,
+) (AWSCognitoLoginResult, error) {
+	if challengeName != "" {
+		return AWSCognitoLoginResult{
+			Challenge: &AWSCognitoChallenge{
+				Name:       challengeName,
+				Session:    aws.ToString(session),
+				Parameters: challengeParameters,
+			},
+		}, nil
+	}
+	if authenticationResult == nil {
+		return AWSCognitoLoginResult{}, ErrAWSCognitoAuthenticationResultNotFound
+	}
+	return AWSCognitoLoginResult{AWSCognitoToken: newAWSCognitoToken(authenticationResult)}, nil
+}
+
+// newAWSCognitoToken converts types.AuthenticationResultType to AWSCognitoToken.
+func newAWSCognitoToken(res *types.AuthenticationResultType) AWSCognitoToken {
+	return AWSCognitoToken{
+		AccessToken:          aws.ToString(res.AccessToken),
+		AccessTokenExpiresAt: time.Now().Add(time.Second * time.Duration(res.ExpiresIn)),
+		RefreshToken:         aws.ToString(res.RefreshToken),
+		IDToken:              aws.ToString(res.IdToken),
+	}
+}
+
 // getSecretHash gets the secret hash.
 func (r *AWSCognitoRepository) getSecretHash(username string) string {
 	mac := hmac.New(sha256.New, []byte(r.userPoolClientSecret))
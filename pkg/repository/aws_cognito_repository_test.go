@@ -123,6 +123,142 @@ func (m *MockCognitoClient) RevokeToken(ctx context.Context, input *cognitoident
 	return args.Get(0).(*cognitoidentityprovider.RevokeTokenOutput), args.Error(1)
 }
 
+func (m *MockCognitoClient) AssociateSoftwareToken(ctx context.Context, input *cognitoidentityprovider.AssociateSoftwareTokenInput, opts ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AssociateSoftwareTokenOutput, error) {
+	args := m.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*cognitoidentityprovider.AssociateSoftwareTokenOutput), args.Error(1)
+}
+
+func (m *MockCognitoClient) VerifySoftwareToken(ctx context.Context, input *cognitoidentityprovider.VerifySoftwareTokenInput, opts ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.VerifySoftwareTokenOutput, error) {
+	args := m.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*cognitoidentityprovider.VerifySoftwareTokenOutput), args.Error(1)
+}
+
+func (m *MockCognitoClient) SetUserMFAPreference(ctx context.Context, input *cognitoidentityprovider.SetUserMFAPreferenceInput, opts ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.SetUserMFAPreferenceOutput, error) {
+	args := m.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*cognitoidentityprovider.SetUserMFAPreferenceOutput), args.Error(1)
+}
+
+func (m *MockCognitoClient) AdminSetUserMFAPreference(ctx context.Context, input *cognitoidentityprovider.AdminSetUserMFAPreferenceInput, opts ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminSetUserMFAPreferenceOutput, error) {
+	args := m.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*cognitoidentityprovider.AdminSetUserMFAPreferenceOutput), args.Error(1)
+}
+
+func (m *MockCognitoClient) AdminUpdateUserAttributes(ctx context.Context, input *cognitoidentityprovider.AdminUpdateUserAttributesInput, opts ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminUpdateUserAttributesOutput, error) {
+	args := m.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*cognitoidentityprovider.AdminUpdateUserAttributesOutput), args.Error(1)
+}
+
+func (m *MockCognitoClient) AdminDeleteUserAttributes(ctx context.Context, input *cognitoidentityprovider.AdminDeleteUserAttributesInput, opts ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminDeleteUserAttributesOutput, error) {
+	args := m.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*cognitoidentityprovider.AdminDeleteUserAttributesOutput), args.Error(1)
+}
+
+func (m *MockCognitoClient) AdminEnableUser(ctx context.Context, input *cognitoidentityprovider.AdminEnableUserInput, opts ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminEnableUserOutput, error) {
+	args := m.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*cognitoidentityprovider.AdminEnableUserOutput), args.Error(1)
+}
+
+func (m *MockCognitoClient) AdminDisableUser(ctx context.Context, input *cognitoidentityprovider.AdminDisableUserInput, opts ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminDisableUserOutput, error) {
+	args := m.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*cognitoidentityprovider.AdminDisableUserOutput), args.Error(1)
+}
+
+func (m *MockCognitoClient) AdminAddUserToGroup(ctx context.Context, input *cognitoidentityprovider.AdminAddUserToGroupInput, opts ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminAddUserToGroupOutput, error) {
+	args := m.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*cognitoidentityprovider.AdminAddUserToGroupOutput), args.Error(1)
+}
+
+func (m *MockCognitoClient) AdminRemoveUserFromGroup(ctx context.Context, input *cognitoidentityprovider.AdminRemoveUserFromGroupInput, opts ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminRemoveUserFromGroupOutput, error) {
+	args := m.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*cognitoidentityprovider.AdminRemoveUserFromGroupOutput), args.Error(1)
+}
+
+func (m *MockCognitoClient) AdminListGroupsForUser(ctx context.Context, input *cognitoidentityprovider.AdminListGroupsForUserInput, opts ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.AdminListGroupsForUserOutput, error) {
+	args := m.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*cognitoidentityprovider.AdminListGroupsForUserOutput), args.Error(1)
+}
+
+func (m *MockCognitoClient) ListUsers(ctx context.Context, input *cognitoidentityprovider.ListUsersInput, opts ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.ListUsersOutput, error) {
+	args := m.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*cognitoidentityprovider.ListUsersOutput), args.Error(1)
+}
+
+func (m *MockCognitoClient) SignUp(ctx context.Context, input *cognitoidentityprovider.SignUpInput, opts ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.SignUpOutput, error) {
+	args := m.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*cognitoidentityprovider.SignUpOutput), args.Error(1)
+}
+
+func (m *MockCognitoClient) ConfirmSignUp(ctx context.Context, input *cognitoidentityprovider.ConfirmSignUpInput, opts ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.ConfirmSignUpOutput, error) {
+	args := m.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*cognitoidentityprovider.ConfirmSignUpOutput), args.Error(1)
+}
+
+func (m *MockCognitoClient) ResendConfirmationCode(ctx context.Context, input *cognitoidentityprovider.ResendConfirmationCodeInput, opts ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.ResendConfirmationCodeOutput, error) {
+	args := m.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*cognitoidentityprovider.ResendConfirmationCodeOutput), args.Error(1)
+}
+
+func (m *MockCognitoClient) GetUserAttributeVerificationCode(ctx context.Context, input *cognitoidentityprovider.GetUserAttributeVerificationCodeInput, opts ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.GetUserAttributeVerificationCodeOutput, error) {
+	args := m.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*cognitoidentityprovider.GetUserAttributeVerificationCodeOutput), args.Error(1)
+}
+
+func (m *MockCognitoClient) VerifyUserAttribute(ctx context.Context, input *cognitoidentityprovider.VerifyUserAttributeInput, opts ...func(*cognitoidentityprovider.Options)) (*cognitoidentityprovider.VerifyUserAttributeOutput, error) {
+	args := m.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*cognitoidentityprovider.VerifyUserAttributeOutput), args.Error(1)
+}
+
 // AWSCognitoRepositoryWithMock for testing with mock client
 type AWSCognitoRepositoryWithMock struct {
 	Client               AWSCognitoIdentityProviderClientInterface
@@ -447,6 +583,40 @@ func TestAWSCognitoRepository_Login(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
+func TestAWSCognitoRepository_Login_Challenge(t *testing.T) {
+	mockClient := &MockCognitoClient{}
+	repo := NewAWSCognitoRepositoryWithInterface(mockClient, "test_pool", "test_client", "test_secret")
+
+	mockClient.On("AdminInitiateAuth", mock.Anything, mock.Anything, mock.Anything).Return(&cognitoidentityprovider.AdminInitiateAuthOutput{
+		ChallengeName:       types.ChallengeNameTypeSoftwareTokenMfa,
+		Session:             aws.String("mock-session"),
+		ChallengeParameters: map[string]string{"USER_ID_FOR_SRP": "test-user"},
+	}, nil)
+
+	result, err := repo.Login(context.Background(), "test-user", "test-password")
+
+	assert.NoError(t, err)
+	assert.True(t, result.ChallengeRequired())
+	assert.Equal(t, types.ChallengeNameTypeSoftwareTokenMfa, result.Challenge.Name)
+	assert.Equal(t, "mock-session", result.Challenge.Session)
+	assert.Equal(t, "test-user", result.Challenge.Parameters["USER_ID_FOR_SRP"])
+	assert.Empty(t, result.AccessToken)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSCognitoRepository_Login_NoAuthenticationResult(t *testing.T) {
+	mockClient := &MockCognitoClient{}
+	repo := NewAWSCognitoRepositoryWithInterface(mockClient, "test_pool", "test_client", "test_secret")
+
+	mockClient.On("AdminInitiateAuth", mock.Anything, mock.Anything, mock.Anything).Return(&cognitoidentityprovider.AdminInitiateAuthOutput{}, nil)
+
+	_, err := repo.Login(context.Background(), "test-user", "test-password")
+
+	assert.ErrorIs(t, err, ErrAWSCognitoAuthenticationResultNotFound)
+	assert.ErrorContains(t, err, "cognito AdminInitiateAuth")
+	mockClient.AssertExpectations(t)
+}
+
 func TestAWSCognitoRepository_ChangePassword(t *testing.T) {
 	mockClient := &MockCognitoClient{}
 	userPoolID := "test_pool"
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// AWSCognitoCodeDelivery describes where a confirmation or verification code was sent.
+type AWSCognitoCodeDelivery struct {
+	AttributeName  string
+	DeliveryMedium types.DeliveryMediumType
+	Destination    string
+}
+
+// AWSCognitoSignUpResult is the result of SignUp.
+type AWSCognitoSignUpResult struct {
+	// CodeDelivery is nil when the user is confirmed without a code (e.g. by a pre sign-up trigger).
+	CodeDelivery  *AWSCognitoCodeDelivery
+	UserSub       string
+	UserConfirmed bool
+}
+
+// SignUp registers a new user with password and attributes. Unless the user
+// is confirmed automatically, a confirmation code is sent and the user must be
+// confirmed with ConfirmSignUp.
+func (r *AWSCognitoRepository) SignUp(ctx context.Context, username, password string, attributes map[string]string) (AWSCognitoSignUpResult, error) {
+	res, err := r.Client.SignUp(ctx, &cognitoidentityprovider.SignUpInput{
+		ClientId:       aws.String(r.userPoolClientID),
+		Username:       aws.String(username),
+		Password:       aws.String(password), // pragma: allowlist-secret
+		SecretHash:     aws.String(r.getSecretHash(username)),
+		UserAttributes: newAWSCognitoAttributes(attributes),
+	})
+	if err != nil {
+		return AWSCognitoSignUpResult{}, fmt.Errorf("cognito SignUp: %w", toAWSCognitoError(err))
+	}
+	return AWSCognitoSignUpResult{
+		CodeDelivery:  newAWSCognitoCodeDelivery(res.CodeDeliveryDetails),
+		UserSub:       aws.ToString(res.UserSub),
+		UserConfirmed: res.UserConfirmed,
+	}, nil
+}
+
+// ConfirmSignUp confirms the registration of the user with the confirmation code.
+func (r *AWSCognitoRepository) ConfirmSignUp(ctx context.Context, username, confirmationCode string) error {
+	_, err := r.Client.ConfirmSignUp(ctx, &cognitoidentityprovider.ConfirmSignUpInput{
+		ClientId:         aws.String(r.userPoolClientID),
+		Username:         aws.String(username),
+		ConfirmationCode: aws.String(confirmationCode),
+		SecretHash:       aws.String(r.getSecretHash(username)),
+	})
+	if err != nil {
+		return fmt.Errorf("cognito ConfirmSignUp: %w", toAWSCognitoError(err))
+	}
+	return nil
+}
+
+// ResendConfirmationCode resends the registration confirmation code to the user.
+func (r *AWSCognitoRepository) ResendConfirmationCode(ctx context.Context, username string) (*AWSCognitoCodeDelivery, error) {
+	res, err := r.Client.ResendConfirmationCode(ctx, &cognitoidentityprovider.ResendConfirmationCodeInput{
+		ClientId:   aws.String(r.userPoolClientID),
+		Username:   aws.String(username),
+		SecretHash: aws.String(r.getSecretHash(username)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cognito ResendConfirmationCode: %w", toAWSCognitoError(err))
+	}
+	return newAWSCognitoCodeDelivery(res.CodeDeliveryDetails), nil
+}
+
+// ForgotPassword sends a code to reset the password of the user.
+// The password is reset with ConfirmForgotPassword.
+func (r *AWSCognitoRepository) ForgotPassword(ctx context.Context, username string) (*AWSCognitoCodeDelivery, error) {
+	res, err := r.Client.ForgotPassword(ctx, &cognitoidentityprovider.ForgotPasswordInput{
+		ClientId:   aws.String(r.userPoolClientID),
+		Username:   aws.String(username),
+		SecretHash: aws.String(r.getSecretHash(username)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cognito ForgotPassword: %w", toAWSCognitoError(err))
+	}
+	return newAWSCognitoCodeDelivery(res.CodeDeliveryDetails), nil
+}
+
+// GetUserAttributeVerificationCode sends a code to verify the attribute
+// (e.g. email or phone_number) of the signed-in user.
+func (r *AWSCognitoRepository) GetUserAttributeVerificationCode(ctx context.Context, authorizationHeader, attributeName string) (*AWSCognitoCodeDelivery, error) {
+	accessToken, err := r.getAccessToken(authorizationHeader)
+	if err != nil {
+		return nil, fmt.Errorf("cognito getAccessToken: %w", err)
+	}
+	res, err := r.Client.GetUserAttributeVerificationCode(ctx, &cognitoidentityprovider.GetUserAttributeVerificationCodeInput{
+		AccessToken:   aws.String(accessToken),
+		AttributeName: aws.String(attributeName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cognito GetUserAttributeVerificationCode: %w", toAWSCognitoError(err))
+	}
+	return newAWSCognitoCodeDelivery(res.CodeDeliveryDetails), nil
+}
+
+// VerifyUserAttribute verifies the attribute of the signed-in user with the code.
+func (r *AWSCognitoRepository) VerifyUserAttribute(ctx context.Context, authorizationHeader, attributeName, code string) error {
+	accessToken, err := r.getAccessToken(authorizationHeader)
+	if err != nil {
+		return fmt.Errorf("cognito getAccessToken: %w", err)
+	}
+	_, err = r.Client.VerifyUserAttribute(ctx, &cognitoidentityprovider.VerifyUserAttributeInput{
+		AccessToken:   aws.String(accessToken),
+		AttributeName: aws.String(attributeName),
+		Code:          aws.String(code),
+	})
+	if err != nil {
+		return fmt.Errorf("cognito VerifyUserAttribute: %w", toAWSCognitoError(err))
+	}
+	return nil
+}
+
+// newAWSCognitoCodeDelivery converts types.CodeDeliveryDetailsType to AWSCognitoCodeDelivery.
+func newAWSCognitoCodeDelivery(d *types.CodeDeliveryDetailsType) *AWSCognitoCodeDelivery {
+	if d == nil {
+		return nil
+	}
+	return &AWSCognitoCodeDelivery{
+		AttributeName:  aws.ToString(d.AttributeName),
+		DeliveryMedium: d.DeliveryMedium,
+		Destination:    aws.ToString(d.Destination),
+	}
+}
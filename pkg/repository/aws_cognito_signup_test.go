@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAWSCognitoRepository_SignUp(t *testing.T) {
+	mockClient := &MockCognitoClient{}
+	repo := NewAWSCognitoRepositoryWithInterface(mockClient, "test_pool", "test_client", "test_secret")
+
+	mockClient.On("SignUp", mock.Anything, mock.MatchedBy(func(input *cognitoidentityprovider.SignUpInput) bool {
+		return *input.ClientId == "test_client" && *input.Username == "test-user" &&
+			*input.Password == "test-password" &&
+			*input.SecretHash == repo.getSecretHash("test-user") &&
+			len(input.UserAttributes) == 1 && *input.UserAttributes[0].Name == "email"
+	}), mock.Anything).Return(&cognitoidentityprovider.SignUpOutput{
+		UserSub: aws.String("sub-1"),
+		CodeDeliveryDetails: &types.CodeDeliveryDetailsType{
+			AttributeName:  aws.String("email"),
+			DeliveryMedium: types.DeliveryMediumTypeEmail,
+			Destination:    aws.String("t***@e***"),
+		},
+	}, nil)
+
+	result, err := repo.SignUp(context.Background(), "test-user", "test-password", map[string]string{"email": "test@example.com"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "sub-1", result.UserSub)
+	assert.False(t, result.UserConfirmed)
+	assert.Equal(t, &AWSCognitoCodeDelivery{
+		AttributeName:  "email",
+		DeliveryMedium: types.DeliveryMediumTypeEmail,
+		Destination:    "t***@e***",
+	}, result.CodeDelivery)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSCognitoRepository_SignUp_UsernameExists(t *testing.T) {
+	mockClient := &MockCognitoClient{}
+	repo := NewAWSCognitoRepositoryWithInterface(mockClient, "test_pool", "test_client", "test_secret")
+	mockClient.On("SignUp", mock.Anything, mock.Anything, mock.Anything).Return(nil, &types.UsernameExistsException{Message: aws.String("exists")})
+
+	_, err := repo.SignUp(context.Background(), "test-user", "test-password", nil)
+
+	assert.ErrorIs(t, err, ErrAWSCognitoUsernameExists)
+	var sdkErr *types.UsernameExistsException
+	assert.ErrorAs(t, err, &sdkErr)
+	assert.Contains(t, err.Error(), "cognito SignUp")
+}
+
+func TestAWSCognitoRepository_ConfirmSignUp(t *testing.T) {
+	mockClient := &MockCognitoClient{}
+	repo := NewAWSCognitoRepositoryWithInterface(mockClient, "test_pool", "test_client", "test_secret")
+
+	mockClient.On("ConfirmSignUp", mock.Anything, mock.MatchedBy(func(input *cognitoidentityprovider.ConfirmSignUpInput) bool {
+		return *input.ConfirmationCode == "123456"
+	}), mock.Anything).Return(&cognitoidentityprovider.ConfirmSignUpOutput{}, nil)
+	mockClient.On("ConfirmSignUp", mock.Anything, mock.MatchedBy(func(input *cognitoidentityprovider.ConfirmSignUpInput) bool {
+		return *input.ConfirmationCode == "000000"
+	}), mock.Anything).Return(nil, &types.CodeMismatchException{Message: aws.String("mismatch")})
+	mockClient.On("ConfirmSignUp", mock.Anything, mock.MatchedBy(func(input *cognitoidentityprovider.ConfirmSignUpInput) bool {
+		return *input.ConfirmationCode == "999999"
+	}), mock.Anything).Return(nil, &types.ExpiredCodeException{Message: aws.String("expired")})
+
+	assert.NoError(t, repo.ConfirmSignUp(context.Background(), "test-user", "123456"))
+	assert.ErrorIs(t, repo.ConfirmSignUp(context.Background(), "test-user", "000000"), ErrAWSCognitoCodeMismatch)
+	assert.ErrorIs(t, repo.ConfirmSignUp(context.Background(), "test-user", "999999"), ErrAWSCognitoExpiredCode)
+}
+
+func TestAWSCognitoRepository_ResendConfirmationCode(t *testing.T) {
+	mockClient := &MockCognitoClient{}
+	repo := NewAWSCognitoRepositoryWithInterface(mockClient, "test_pool", "test_client", "test_secret")
+
+	mockClient.On("ResendConfirmationCode", mock.Anything, mock.MatchedBy(func(input *cognitoidentityprovider.ResendConfirmationCodeInput) bool {
+		return *input.Username == "test-user" && *input.SecretHash == repo.getSecretHash("test-user")
+	}), mock.Anything).Return(&cognitoidentityprovider.ResendConfirmationCodeOutput{
+		CodeDeliveryDetails: &types.CodeDeliveryDetailsType{DeliveryMedium: types.DeliveryMediumTypeSms},
+	}, nil)
+
+	delivery, err := repo.ResendConfirmationCode(context.Background(), "test-user")
+
+	assert.NoError(t, err)
+	assert.Equal(t, types.DeliveryMediumTypeSms, delivery.DeliveryMedium)
+}
+
+func TestAWSCognitoRepository_ForgotPassword(t *testing.T) {
+	mockClient := &MockCognitoClient{}
+	repo := NewAWSCognitoRepositoryWithInterface(mockClient, "test_pool", "test_client", "test_secret")
+
+	mockClient.On("ForgotPassword", mock.Anything, mock.MatchedBy(func(input *cognitoidentityprovider.ForgotPasswordInput) bool {
+		return *input.ClientId == "test_client" && *input.SecretHash == repo.getSecretHash("test-user")
+	}), mock.Anything).Return(nil, &types.UserNotFoundException{Message: aws.String("not found")})
+
+	delivery, err := repo.ForgotPassword(context.Background(), "test-user")
+
+	assert.Nil(t, delivery)
+	assert.ErrorIs(t, err, ErrAWSCognitoUserNotFound)
+}
+
+func TestAWSCognitoRepository_VerifyUserAttribute(t *testing.T) {
+	mockClient := &MockCognitoClient{}
+	repo := NewAWSCognitoRepositoryWithInterface(mockClient, "test_pool", "test_client", "test_secret")
+
+	mockClient.On("GetUserAttributeVerificationCode", mock.Anything, mock.MatchedBy(func(input *cognitoidentityprovider.GetUserAttributeVerificationCodeInput) bool {
+		return *input.AccessToken == "access-token" && *input.AttributeName == "email"
+	}), mock.Anything).Return(&cognitoidentityprovider.GetUserAttributeVerificationCodeOutput{
+		CodeDeliveryDetails: &types.CodeDeliveryDetailsType{AttributeName: aws.String("email")},
+	}, nil)
+	mockClient.On("VerifyUserAttribute", mock.Anything, mock.MatchedBy(func(input *cognitoidentityprovider.VerifyUserAttributeInput) bool {
+		return *input.AccessToken == "access-token" && *input.AttributeName == "email" && *input.Code == "123456"
+	}), mock.Anything).Return(&cognitoidentityprovider.VerifyUserAttributeOutput{}, nil)
+
+	delivery, err := repo.GetUserAttributeVerificationCode(context.Background(), "Bearer access-token", "email")
+	assert.NoError(t, err)
+	assert.Equal(t, "email", delivery.AttributeName)
+	assert.NoError(t, repo.VerifyUserAttribute(context.Background(), "Bearer access-token", "email", "123456"))
+
+	_, err = repo.GetUserAttributeVerificationCode(context.Background(), "", "email")
+	assert.ErrorIs(t, err, ErrAWSCognitoAccessTokenNotFound)
+	mockClient.AssertExpectations(t)
+}
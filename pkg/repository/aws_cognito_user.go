@@ -0,0 +1,283 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// Standard Cognito user attribute names.
+const (
+	AWSCognitoAttributeSub                 = "sub"
+	AWSCognitoAttributeEmail               = "email"
+	AWSCognitoAttributeEmailVerified       = "email_verified"
+	AWSCognitoAttributePhoneNumber         = "phone_number"
+	AWSCognitoAttributePhoneNumberVerified = "phone_number_verified"
+	AWSCognitoAttributeName                = "name"
+	AWSCognitoAttributeGivenName           = "given_name"
+	AWSCognitoAttributeFamilyName          = "family_name"
+	AWSCognitoAttributePreferredUsername   = "preferred_username"
+
+	// awsCognitoCustomAttributePrefix is the prefix of custom attribute names.
+	awsCognitoCustomAttributePrefix = "custom:"
+)
+
+// AWSCognitoUser is a Cognito user with typed attribute accessors.
+type AWSCognitoUser struct {
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Attributes          map[string]string
+	Username            string
+	Status              types.UserStatusType
+	PreferredMFASetting string
+	MFASettings         []string
+	Enabled             bool
+}
+
+// Attribute returns the value of the attribute name and whether it is set.
+func (u *AWSCognitoUser) Attribute(name string) (string, bool) {
+	v, ok := u.Attributes[name]
+	return v, ok
+}
+
+// CustomAttribute returns the value of the custom attribute name, with or without the "custom:" prefix.
+func (u *AWSCognitoUser) CustomAttribute(name string) (string, bool) {
+	if !strings.HasPrefix(name, awsCognitoCustomAttributePrefix) {
+		name = awsCognitoCustomAttributePrefix + name
+	}
+	return u.Attribute(name)
+}
+
+// Sub returns the sub attribute, the immutable ID of the user.
+func (u *AWSCognitoUser) Sub() string {
+	return u.Attributes[AWSCognitoAttributeSub]
+}
+
+// Email returns the email attribute.
+func (u *AWSCognitoUser) Email() string {
+	return u.Attributes[AWSCognitoAttributeEmail]
+}
+
+// EmailVerified reports whether email_verified is true.
+func (u *AWSCognitoUser) EmailVerified() bool {
+	return u.Attributes[AWSCognitoAttributeEmailVerified] == "true"
+}
+
+// PhoneNumber returns the phone_number attribute.
+func (u *AWSCognitoUser) PhoneNumber() string {
+	return u.Attributes[AWSCognitoAttributePhoneNumber]
+}
+
+// PhoneNumberVerified reports whether phone_number_verified is true.
+func (u *AWSCognitoUser) PhoneNumberVerified() bool {
+	return u.Attributes[AWSCognitoAttributePhoneNumberVerified] == "true"
+}
+
+// Name returns the name attribute.
+func (u *AWSCognitoUser) Name() string {
+	return u.Attributes[AWSCognitoAttributeName]
+}
+
+// AWSCognitoGroup is a Cognito user pool group.
+type AWSCognitoGroup struct {
+	Precedence  *int32
+	Name        string
+	Description string
+	RoleArn     string
+}
+
+// DescribeUser gets the user as AWSCognitoUser.
+func (r *AWSCognitoRepository) DescribeUser(ctx context.Context, username string) (*AWSCognitoUser, error) {
+	res, err := r.GetUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	return &AWSCognitoUser{
+		CreatedAt:           aws.ToTime(res.UserCreateDate),
+		UpdatedAt:           aws.ToTime(res.UserLastModifiedDate),
+		Attributes:          newAWSCognitoAttributeMap(res.UserAttributes),
+		Username:            aws.ToString(res.Username),
+		Status:              res.UserStatus,
+		PreferredMFASetting: aws.ToString(res.PreferredMfaSetting),
+		MFASettings:         res.UserMFASettingList,
+		Enabled:             res.Enabled,
+	}, nil
+}
+
+// UpdateUserAttributes creates or updates the attributes of the user as an administrator.
+func (r *AWSCognitoRepository) UpdateUserAttributes(ctx context.Context, username string, attributes map[string]string) error {
+	_, err := r.Client.AdminUpdateUserAttributes(ctx, &cognitoidentityprovider.AdminUpdateUserAttributesInput{
+		UserPoolId:     aws.String(r.userPoolID),
+		Username:       aws.String(username),
+		UserAttributes: newAWSCognitoAttributes(attributes),
+	})
+	if err != nil {
+		return fmt.Errorf("cognito AdminUpdateUserAttributes: %w", toAWSCognitoError(err))
+	}
+	return nil
+}
+
+// DeleteUserAttributes deletes the attributes of the user as an administrator.
+func (r *AWSCognitoRepository) DeleteUserAttributes(ctx context.Context, username string, attributeNames ...string) error {
+	_, err := r.Client.AdminDeleteUserAttributes(ctx, &cognitoidentityprovider.AdminDeleteUserAttributesInput{
+		UserPoolId:         aws.String(r.userPoolID),
+		Username:           aws.String(username),
+		UserAttributeNames: attributeNames,
+	})
+	if err != nil {
+		return fmt.Errorf("cognito AdminDeleteUserAttributes: %w", toAWSCognitoError(err))
+	}
+	return nil
+}
+
+// EnableUser enables the user.
+func (r *AWSCognitoRepository) EnableUser(ctx context.Context, username string) error {
+	_, err := r.Client.AdminEnableUser(ctx, &cognitoidentityprovider.AdminEnableUserInput{
+		UserPoolId: aws.String(r.userPoolID),
+		Username:   aws.String(username),
+	})
+	if err != nil {
+		return fmt.Errorf("cognito AdminEnableUser: %w", toAWSCognitoError(err))
+	}
+	return nil
+}
+
+// DisableUser disables the user. Disabled users can't sign in.
+func (r *AWSCognitoRepository) DisableUser(ctx context.Context, username string) error {
+	_, err := r.Client.AdminDisableUser(ctx, &cognitoidentityprovider.AdminDisableUserInput{
+		UserPoolId: aws.String(r.userPoolID),
+		Username:   aws.String(username),
+	})
+	if err != nil {
+		return fmt.Errorf("cognito AdminDisableUser: %w", toAWSCognitoError(err))
+	}
+	return nil
+}
+
+// AddUserToGroup adds the user to the group.
+func (r *AWSCognitoRepository) AddUserToGroup(ctx context.Context, username, groupName string) error {
+	_, err := r.Client.AdminAddUserToGroup(ctx, &cognitoidentityprovider.AdminAddUserToGroupInput{
+		UserPoolId: aws.String(r.userPoolID),
+		Username:   aws.String(username),
+		GroupName:  aws.String(groupName),
+	})
+	if err != nil {
+		return fmt.Errorf("cognito AdminAddUserToGroup: %w", toAWSCognitoError(err))
+	}
+	return nil
+}
+
+// RemoveUserFromGroup removes the user from the group.
+func (r *AWSCognitoRepository) RemoveUserFromGroup(ctx context.Context, username, groupName string) error {
+	_, err := r.Client.AdminRemoveUserFromGroup(ctx, &cognitoidentityprovider.AdminRemoveUserFromGroupInput{
+		UserPoolId: aws.String(r.userPoolID),
+		Username:   aws.String(username),
+		GroupName:  aws.String(groupName),
+	})
+	if err != nil {
+		return fmt.Errorf("cognito AdminRemoveUserFromGroup: %w", toAWSCognitoError(err))
+	}
+	return nil
+}
+
+// ListGroupsForUser lists all groups the user belongs to, following pagination.
+func (r *AWSCognitoRepository) ListGroupsForUser(ctx context.Context, username string) ([]AWSCognitoGroup, error) {
+	var groups []AWSCognitoGroup
+	var nextToken *string
+	for {
+		res, err := r.Client.AdminListGroupsForUser(ctx, &cognitoidentityprovider.AdminListGroupsForUserInput{
+			UserPoolId: aws.String(r.userPoolID),
+			Username:   aws.String(username),
+			NextToken:  nextToken,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("cognito AdminListGroupsForUser: %w", toAWSCognitoError(err))
+		}
+		for i := range res.Groups {
+			g := &res.Groups[i]
+			groups = append(groups, AWSCognitoGroup{
+				Precedence:  g.Precedence,
+				Name:        aws.ToString(g.GroupName),
+				Description: aws.ToString(g.Description),
+				RoleArn:     aws.ToString(g.RoleArn),
+			})
+		}
+		if aws.ToString(res.NextToken) == "" {
+			return groups, nil
+		}
+		nextToken = res.NextToken
+	}
+}
+
+// ListUsers lists all users matching filter, following pagination.
+// filter is a Cognito filter expression such as `email ^= "user"`, see
+// GetAWSCognitoUserFilter. An empty filter lists all users.
+// attributesToGet limits the returned attributes; all attributes are returned when empty.
+func (r *AWSCognitoRepository) ListUsers(ctx context.Context, filter string, attributesToGet ...string) ([]AWSCognitoUser, error) {
+	var users []AWSCognitoUser
+	var paginationToken *string
+	input := &cognitoidentityprovider.ListUsersInput{
+		UserPoolId:      aws.String(r.userPoolID),
+		AttributesToGet: attributesToGet,
+	}
+	if filter != "" {
+		input.Filter = aws.String(filter)
+	}
+	for {
+		input.PaginationToken = paginationToken
+		res, err := r.Client.ListUsers(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("cognito ListUsers: %w", toAWSCognitoError(err))
+		}
+		for i := range res.Users {
+			u := &res.Users[i]
+			users = append(users, AWSCognitoUser{
+				CreatedAt:  aws.ToTime(u.UserCreateDate),
+				UpdatedAt:  aws.ToTime(u.UserLastModifiedDate),
+				Attributes: newAWSCognitoAttributeMap(u.Attributes),
+				Username:   aws.ToString(u.Username),
+				Status:     u.UserStatus,
+				Enabled:    u.Enabled,
+			})
+		}
+		if aws.ToString(res.PaginationToken) == "" {
+			return users, nil
+		}
+		paginationToken = res.PaginationToken
+	}
+}
+
+// GetAWSCognitoUserFilter returns a ListUsers filter expression matching
+// attribute exactly, or by prefix when prefix is true. Quotes and backslashes
+// in value are escaped.
+func GetAWSCognitoUserFilter(attribute, value string, prefix bool) string {
+	operator := "="
+	if prefix {
+		operator = "^="
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+	return fmt.Sprintf(`%s %s "%s"`, attribute, operator, escaped)
+}
+
+// newAWSCognitoAttributeMap converts Cognito attributes to a map.
+func newAWSCognitoAttributeMap(attributes []types.AttributeType) map[string]string {
+	m := make(map[string]string, len(attributes))
+	for _, a := range attributes {
+		m[aws.ToString(a.Name)] = aws.ToString(a.Value)
+	}
+	return m
+}
+
+// newAWSCognitoAttributes converts a map to Cognito attributes.
+func newAWSCognitoAttributes(attributes map[string]string) []types.AttributeType {
+	res := make([]types.AttributeType, 0, len(attributes))
+	for name, value := range attributes {
+		res = append(res, types.AttributeType{Name: aws.String(name), Value: aws.String(value)})
+	}
+	return res
+}
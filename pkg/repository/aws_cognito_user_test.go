@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAWSCognitoRepository_DescribeUser(t *testing.T) {
+	mockClient := &MockCognitoClient{}
+	repo := NewAWSCognitoRepositoryWithInterface(mockClient, "test_pool", "test_client", "test_secret")
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	mockClient.On("AdminGetUser", mock.Anything, mock.Anything, mock.Anything).Return(&cognitoidentityprovider.AdminGetUserOutput{
+		Username: aws.String("test-user"),
+		UserAttributes: []types.AttributeType{
+			{Name: aws.String("sub"), Value: aws.String("sub-1")},
+			{Name: aws.String("email"), Value: aws.String("test@example.com")},
+			{Name: aws.String("email_verified"), Value: aws.String("true")},
+			{Name: aws.String("phone_number"), Value: aws.String("+81900000000")},
+			{Name: aws.String("custom:tenant"), Value: aws.String("tenant-1")},
+		},
+		UserStatus:          types.UserStatusTypeConfirmed,
+		Enabled:             true,
+		UserCreateDate:      &created,
+		PreferredMfaSetting: aws.String("SOFTWARE_TOKEN_MFA"),
+		UserMFASettingList:  []string{"SOFTWARE_TOKEN_MFA"},
+	}, nil)
+
+	user, err := repo.DescribeUser(context.Background(), "test-user")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "test-user", user.Username)
+	assert.Equal(t, "sub-1", user.Sub())
+	assert.Equal(t, "test@example.com", user.Email())
+	assert.True(t, user.EmailVerified())
+	assert.Equal(t, "+81900000000", user.PhoneNumber())
+	assert.False(t, user.PhoneNumberVerified())
+	assert.Empty(t, user.Name())
+	assert.Equal(t, types.UserStatusTypeConfirmed, user.Status)
+	assert.True(t, user.Enabled)
+	assert.Equal(t, created, user.CreatedAt)
+	assert.Equal(t, "SOFTWARE_TOKEN_MFA", user.PreferredMFASetting)
+	v, ok := user.CustomAttribute("tenant")
+	assert.True(t, ok)
+	assert.Equal(t, "tenant-1", v)
+	v, ok = user.CustomAttribute("custom:tenant")
+	assert.True(t, ok)
+	assert.Equal(t, "tenant-1", v)
+	_, ok = user.Attribute("given_name")
+	assert.False(t, ok)
+}
+
+func TestAWSCognitoRepository_DescribeUser_Error(t *testing.T) {
+	mockClient := &MockCognitoClient{}
+	repo := NewAWSCognitoRepositoryWithInterface(mockClient, "test_pool", "test_client", "test_secret")
+	mockClient.On("AdminGetUser", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("user not found"))
+
+	user, err := repo.DescribeUser(context.Background(), "test-user")
+
+	assert.Nil(t, user)
+	assert.Contains(t, err.Error(), "cognito AdminGetUser")
+}
+
+func TestAWSCognitoRepository_UpdateAndDeleteUserAttributes(t *testing.T) {
+	mockClient := &MockCognitoClient{}
+	repo := NewAWSCognitoRepositoryWithInterface(mockClient, "test_pool", "test_client", "test_secret")
+
+	mockClient.On("AdminUpdateUserAttributes", mock.Anything, mock.MatchedBy(func(input *cognitoidentityprovider.AdminUpdateUserAttributesInput) bool {
+		return *input.UserPoolId == "test_pool" && *input.Username == "test-user" &&
+			len(input.UserAttributes) == 1 &&
+			*input.UserAttributes[0].Name == "email" && *input.UserAttributes[0].Value == "new@example.com"
+	}), mock.Anything).Return(&cognitoidentityprovider.AdminUpdateUserAttributesOutput{}, nil)
+	mockClient.On("AdminDeleteUserAttributes", mock.Anything, mock.MatchedBy(func(input *cognitoidentityprovider.AdminDeleteUserAttributesInput) bool {
+		return *input.Username == "test-user" && assert.ObjectsAreEqual([]string{"custom:tenant", "name"}, input.UserAttributeNames)
+	}), mock.Anything).Return(nil, errors.New("invalid parameter"))
+
+	assert.NoError(t, repo.UpdateUserAttributes(context.Background(), "test-user", map[string]string{"email": "new@example.com"}))
+	err := repo.DeleteUserAttributes(context.Background(), "test-user", "custom:tenant", "name")
+	assert.Contains(t, err.Error(), "cognito AdminDeleteUserAttributes")
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSCognitoRepository_EnableDisableUser(t *testing.T) {
+	mockClient := &MockCognitoClient{}
+	repo := NewAWSCognitoRepositoryWithInterface(mockClient, "test_pool", "test_client", "test_secret")
+
+	mockClient.On("AdminEnableUser", mock.Anything, mock.MatchedBy(func(input *cognitoidentityprovider.AdminEnableUserInput) bool {
+		return *input.UserPoolId == "test_pool" && *input.Username == "test-user"
+	}), mock.Anything).Return(&cognitoidentityprovider.AdminEnableUserOutput{}, nil)
+	mockClient.On("AdminDisableUser", mock.Anything, mock.MatchedBy(func(input *cognitoidentityprovider.AdminDisableUserInput) bool {
+		return *input.UserPoolId == "test_pool" && *input.Username == "test-user"
+	}), mock.Anything).Return(&cognitoidentityprovider.AdminDisableUserOutput{}, nil)
+
+	assert.NoError(t, repo.EnableUser(context.Background(), "test-user"))
+	assert.NoError(t, repo.DisableUser(context.Background(), "test-user"))
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSCognitoRepository_UserGroups(t *testing.T) {
+	mockClient := &MockCognitoClient{}
+	repo := NewAWSCognitoRepositoryWithInterface(mockClient, "test_pool", "test_client", "test_secret")
+
+	mockClient.On("AdminAddUserToGroup", mock.Anything, mock.MatchedBy(func(input *cognitoidentityprovider.AdminAddUserToGroupInput) bool {
+		return *input.Username == "test-user" && *input.GroupName == "admin"
+	}), mock.Anything).Return(&cognitoidentityprovider.AdminAddUserToGroupOutput{}, nil)
+	mockClient.On("AdminRemoveUserFromGroup", mock.Anything, mock.MatchedBy(func(input *cognitoidentityprovider.AdminRemoveUserFromGroupInput) bool {
+		return *input.Username == "test-user" && *input.GroupName == "dev"
+	}), mock.Anything).Return(&cognitoidentityprovider.AdminRemoveUserFromGroupOutput{}, nil)
+	mockClient.On("AdminListGroupsForUser", mock.Anything, mock.MatchedBy(func(input *cognitoidentityprovider.AdminListGroupsForUserInput) bool {
+		return input.NextToken == nil
+	}), mock.Anything).Return(&cognitoidentityprovider.AdminListGroupsForUserOutput{
+		Groups:    []types.GroupType{{GroupName: aws.String("admin"), Precedence: aws.Int32(1)}},
+		NextToken: aws.String("page-2"),
+	}, nil)
+	mockClient.On("AdminListGroupsForUser", mock.Anything, mock.MatchedBy(func(input *cognitoidentityprovider.AdminListGroupsForUserInput) bool {
+		return aws.ToString(input.NextToken) == "page-2"
+	}), mock.Anything).Return(&cognitoidentityprovider.AdminListGroupsForUserOutput{
+		Groups: []types.GroupType{{GroupName: aws.String("ops"), Description: aws.String("operators")}},
+	}, nil)
+
+	assert.NoError(t, repo.AddUserToGroup(context.Background(), "test-user", "admin"))
+	assert.NoError(t, repo.RemoveUserFromGroup(context.Background(), "test-user", "dev"))
+	groups, err := repo.ListGroupsForUser(context.Background(), "test-user")
+	assert.NoError(t, err)
+	assert.Equal(t, []AWSCognitoGroup{
+		{Name: "admin", Precedence: aws.Int32(1)},
+		{Name: "ops", Description: "operators"},
+	}, groups)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSCognitoRepository_ListUsers(t *testing.T) {
+	mockClient := &MockCognitoClient{}
+	repo := NewAWSCognitoRepositoryWithInterface(mockClient, "test_pool", "test_client", "test_secret")
+	filter := GetAWSCognitoUserFilter("email", "user", true)
+
+	mockClient.On("ListUsers", mock.Anything, mock.MatchedBy(func(input *cognitoidentityprovider.ListUsersInput) bool {
+		return *input.UserPoolId == "test_pool" && *input.Filter == filter && input.PaginationToken == nil
+	}), mock.Anything).Return(&cognitoidentityprovider.ListUsersOutput{
+		Users: []types.UserType{{
+			Username:   aws.String("user-1"),
+			Attributes: []types.AttributeType{{Name: aws.String("email"), Value: aws.String("user1@example.com")}},
+			Enabled:    true,
+			UserStatus: types.UserStatusTypeConfirmed,
+		}},
+		PaginationToken: aws.String("page-2"),
+	}, nil).Once()
+	mockClient.On("ListUsers", mock.Anything, mock.MatchedBy(func(input *cognitoidentityprovider.ListUsersInput) bool {
+		return aws.ToString(input.PaginationToken) == "page-2"
+	}), mock.Anything).Return(&cognitoidentityprovider.ListUsersOutput{
+		Users: []types.UserType{{Username: aws.String("user-2"), UserStatus: types.UserStatusTypeForceChangePassword}},
+	}, nil).Once()
+
+	users, err := repo.ListUsers(context.Background(), filter)
+
+	assert.NoError(t, err)
+	assert.Len(t, users, 2)
+	assert.Equal(t, "user-1", users[0].Username)
+	assert.Equal(t, "user1@example.com", users[0].Email())
+	assert.Equal(t, "user-2", users[1].Username)
+	assert.Equal(t, types.UserStatusTypeForceChangePassword, users[1].Status)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSCognitoRepository_ListUsers_Error(t *testing.T) {
+	mockClient := &MockCognitoClient{}
+	repo := NewAWSCognitoRepositoryWithInterface(mockClient, "test_pool", "test_client", "test_secret")
+	mockClient.On("ListUsers", mock.Anything, mock.MatchedBy(func(input *cognitoidentityprovider.ListUsersInput) bool {
+		return input.Filter == nil
+	}), mock.Anything).Return(nil, errors.New("throttled"))
+
+	users, err := repo.ListUsers(context.Background(), "")
+
+	assert.Nil(t, users)
+	assert.Contains(t, err.Error(), "cognito ListUsers")
+}
+
+func TestGetAWSCognitoUserFilter(t *testing.T) {
+	assert.Equal(t, `email = "a@example.com"`, GetAWSCognitoUserFilter("email", "a@example.com", false))
+	assert.Equal(t, `name ^= "Jo\"n\\"`, GetAWSCognitoUserFilter("name", `Jo"n\`, true))
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/y-miyazaki/go-common/pkg/utils/aws/awserror"
+)
+
+// Domain errors. Repositories and services can return them directly, and the
+// Is* functions below also classify AWS SDK errors into the same categories,
+// so callers can branch on the category without inspecting error codes.
+// They are the errors of the awserror package, which packages that don't
+// depend on repositories, e.g. handler, use for the same classification.
+var (
+	ErrNotFound     = awserror.ErrNotFound
+	ErrThrottled    = awserror.ErrThrottled
+	ErrUnauthorized = awserror.ErrUnauthorized
+	ErrAccessDenied = awserror.ErrAccessDenied
+	ErrConflict     = awserror.ErrConflict
+)
+
+// IsNotFound reports whether err means the requested resource doesn't exist.
+func IsNotFound(err error) bool {
+	return awserror.IsNotFound(err)
+}
+
+// IsThrottled reports whether err is caused by throttling or exceeded request rate.
+func IsThrottled(err error) bool {
+	return errors.Is(err, ErrAWSCognitoTooManyRequests) || awserror.IsThrottled(err)
+}
+
+// IsUnauthorized reports whether err means the end user failed to authenticate,
+// e.g. a wrong password or an invalid token of Cognito.
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrAWSCognitoNotAuthorized) || awserror.IsUnauthorized(err)
+}
+
+// IsAccessDenied reports whether err means missing permissions or invalid credentials.
+func IsAccessDenied(err error) bool {
+	return awserror.IsAccessDenied(err)
+}
+
+// IsConflict reports whether err means the resource already exists or is being modified.
+func IsConflict(err error) bool {
+	return awserror.IsConflict(err)
+}
+
+// IsRetryable reports whether the operation that returned err may succeed
+// if retried, e.g. on throttling, timeouts, connection errors and 5xx responses.
+func IsRetryable(err error) bool {
+	return errors.Is(err, ErrAWSCognitoTooManyRequests) || awserror.IsRetryable(err)
+}
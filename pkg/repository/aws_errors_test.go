@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAWSErrorClassification(t *testing.T) {
+	tests := []struct {
+		err          error
+		name         string
+		notFound     bool
+		throttled    bool
+		unauthorized bool
+		accessDenied bool
+		conflict     bool
+		retryable    bool
+	}{
+		{name: "nil", err: nil},
+		{name: "plain error", err: errors.New("boom")},
+		{name: "domain not found", err: fmt.Errorf("service Get: %w", ErrNotFound), notFound: true},
+		{name: "domain throttled", err: ErrThrottled, throttled: true, retryable: true},
+		{name: "domain access denied", err: ErrAccessDenied, accessDenied: true},
+		{name: "domain conflict", err: ErrConflict, conflict: true},
+		{name: "cognito user not found", err: toAWSCognitoError(&types.UserNotFoundException{}), unauthorized: true},
+		{name: "cognito username exists", err: toAWSCognitoError(&types.UsernameExistsException{}), conflict: true},
+		{name: "cognito not authorized", err: toAWSCognitoError(&types.NotAuthorizedException{}), unauthorized: true},
+		{name: "cognito not authorized sentinel", err: ErrAWSCognitoNotAuthorized, unauthorized: true},
+		{name: "cognito too many requests", err: toAWSCognitoError(&types.TooManyRequestsException{}), throttled: true, retryable: true},
+		{name: "cognito too many requests sentinel", err: ErrAWSCognitoTooManyRequests, throttled: true, retryable: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.notFound, IsNotFound(tt.err), "IsNotFound")
+			assert.Equal(t, tt.throttled, IsThrottled(tt.err), "IsThrottled")
+			assert.Equal(t, tt.unauthorized, IsUnauthorized(tt.err), "IsUnauthorized")
+			assert.Equal(t, tt.accessDenied, IsAccessDenied(tt.err), "IsAccessDenied")
+			assert.Equal(t, tt.conflict, IsConflict(tt.err), "IsConflict")
+			assert.Equal(t, tt.retryable, IsRetryable(tt.err), "IsRetryable")
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultAWSSecretsManagerCacheTTL             = time.Hour
+	defaultAWSSecretsManagerCacheRefreshInterval = 5 * time.Minute
+	defaultAWSSecretsManagerCacheFetchTimeout    = 30 * time.Second
+)
+
+// AWSSecretsManagerCacheConfig sets configurations.
+type AWSSecretsManagerCacheConfig struct {
+	// OnRotate is called when a cached secret is fetched again with another version,
+	// e.g. to reconnect with rotated credentials.
+	OnRotate func(previous, current *AWSSecretsManagerSecret)
+	// OnError is called with errors of background refreshes.
+	OnError func(secretName string, err error)
+	// TTL is how long a secret is served from the cache before it is fetched again. Default: 1h.
+	TTL time.Duration
+	// RefreshInterval is the interval of background refreshes by Run. Default: 5m.
+	RefreshInterval time.Duration
+	// FetchTimeout bounds a GetSecretValue call shared by concurrent accesses,
+	// which is not canceled with the context of a single caller. Default: 30s.
+	FetchTimeout time.Duration
+}
+
+// AWSSecretsManagerCache caches the secrets of AWSSecretsManagerRepository in process.
+// Secrets are fetched on first access and again when their TTL has passed; Run refreshes
+// them in the background so that accesses don't wait for Secrets Manager.
+// Versions selected by ID never change and are not refreshed. Versions that no
+// longer exist, e.g. AWSPENDING after a rotation, are removed from the cache.
+// Concurrent fetches of a version share one GetSecretValue call. It is safe for concurrent use.
+type AWSSecretsManagerCache struct {
+	repo    *AWSSecretsManagerRepository
+	config  AWSSecretsManagerCacheConfig
+	group   singleflight.Group
+	mu      sync.RWMutex
+	entries map[awsSecretsManagerCacheKey]*awsSecretsManagerCacheEntry
+}
+
+// awsSecretsManagerCacheKey is a version of a secret.
+type awsSecretsManagerCacheKey struct {
+	secretName   string
+	versionStage string
+	versionID    string
+}
+
+// awsSecretsManagerCacheEntry is a cached secret and when it was fetched.
+type awsSecretsManagerCacheEntry struct {
+	fetchedAt time.Time
+	secret    *AWSSecretsManagerSecret
+}
+
+// NewAWSSecretsManagerCache returns AWSSecretsManagerCache instance.
+func NewAWSSecretsManagerCache(repo *AWSSecretsManagerRepository, cfgs ...*AWSSecretsManagerCacheConfig) *AWSSecretsManagerCache {
+	conf := AWSSecretsManagerCacheConfig{}
+	if len(cfgs) > 0 && cfgs[0] != nil {
+		conf = *cfgs[0]
+	}
+	if conf.TTL <= 0 {
+		conf.TTL = defaultAWSSecretsManagerCacheTTL
+	}
+	if conf.RefreshInterval <= 0 {
+		conf.RefreshInterval = defaultAWSSecretsManagerCacheRefreshInterval
+	}
+	if conf.FetchTimeout <= 0 {
+		conf.FetchTimeout = defaultAWSSecretsManagerCacheFetchTimeout
+	}
+	return &AWSSecretsManagerCache{
+		repo:    repo,
+		config:  conf,
+		entries: map[awsSecretsManagerCacheKey]*awsSecretsManagerCacheEntry{},
+	}
+}
+
+// GetSecretString gets the AWSCURRENT version of a secret string.
+func (c *AWSSecretsManagerCache) GetSecretString(ctx context.Context, secretName string) (string, error) {
+	secret, err := c.GetSecret(ctx, secretName, nil)
+	if err != nil {
+		return "", err
+	}
+	return secret.Value, nil
+}
+
+// GetSecretJSON gets the AWSCURRENT version of a JSON secret and decodes it into v.
+func (c *AWSSecretsManagerCache) GetSecretJSON(ctx context.Context, secretName string, v any) error {
+	secret, err := c.GetSecret(ctx, secretName, nil)
+	if err != nil {
+		return err
+	}
+	return secret.Unmarshal(v)
+}
+
+// GetSecret gets a version of a secret. A nil version is the AWSCURRENT version.
+// The returned secret is shared by the callers and must not be modified.
+func (c *AWSSecretsManagerCache) GetSecret(ctx context.Context, secretName string, version *AWSSecretsManagerVersion) (*AWSSecretsManagerSecret, error) {
+	key := newAWSSecretsManagerCacheKey(secretName, version)
+	c.mu.RLock()
+	entry, ok := c.entries[key]
+	c.mu.RUnlock()
+	if ok && (key.versionID != "" || time.Since(entry.fetchedAt) < c.config.TTL) {
+		return entry.secret, nil
+	}
+	return c.fetch(ctx, key)
+}
+
+// Prefetch loads the AWSCURRENT versions of secretNames into the cache with BatchGetSecretValue.
+// Secrets that could be retrieved are cached even when an error is returned.
+func (c *AWSSecretsManagerCache) Prefetch(ctx context.Context, secretNames ...string) error {
+	fetchedAt := time.Now()
+	secrets, err := c.repo.BatchGetSecretValue(ctx, secretNames)
+	for secretName, secret := range secrets {
+		c.store(newAWSSecretsManagerCacheKey(secretName, nil), secret, fetchedAt)
+	}
+	return err
+}
+
+// Invalidate removes all versions of a secret from the cache, e.g. after it was rotated.
+func (c *AWSSecretsManagerCache) Invalidate(secretName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if key.secretName == secretName {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// Refresh fetches the cached secrets again, except the versions selected by ID.
+// A secret that fails to refresh stays cached until its TTL has passed, unless
+// the secret or its version stage no longer exists: it is removed from the cache.
+func (c *AWSSecretsManagerCache) Refresh(ctx context.Context) error {
+	c.mu.RLock()
+	keys := make([]awsSecretsManagerCacheKey, 0, len(c.entries))
+	for key := range c.entries {
+		if key.versionID == "" {
+			keys = append(keys, key)
+		}
+	}
+	c.mu.RUnlock()
+
+	var errs []error
+	for _, key := range keys {
+		if _, err := c.fetch(ctx, key); err != nil {
+			c.onError(key.secretName, err)
+			errs = append(errs, fmt.Errorf("%s: %w", key.secretName, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run refreshes the cached secrets every RefreshInterval until ctx is done.
+// Errors are reported to OnError and don't stop the refreshes.
+func (c *AWSSecretsManagerCache) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.config.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = c.Refresh(ctx)
+		}
+	}
+}
+
+// fetch gets the version of key from Secrets Manager and caches it.
+// Concurrent fetches of key share one call. A version that doesn't exist is removed from the cache.
+func (c *AWSSecretsManagerCache) fetch(ctx context.Context, key awsSecretsManagerCacheKey) (*AWSSecretsManagerSecret, error) {
+	// The call is shared, so it runs without the cancellation of the first caller
+	// and each caller stops waiting when its own context is done.
+	ch := c.group.DoChan(key.secretName+"\x00"+key.versionStage+"\x00"+key.versionID, func() (any, error) {
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.config.FetchTimeout)
+		defer cancel()
+		fetchedAt := time.Now()
+		secret, err := c.repo.GetSecret(fetchCtx, key.secretName, &AWSSecretsManagerVersion{
+			VersionStage: key.versionStage,
+			VersionID:    key.versionID,
+		})
+		if err != nil {
+			if IsNotFound(err) {
+				c.mu.Lock()
+				delete(c.entries, key)
+				c.mu.Unlock()
+			}
+			return nil, err
+		}
+		c.store(key, secret, fetchedAt)
+		return secret, nil
+	})
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("secretsmanager GetSecretValue: %w", context.Cause(ctx))
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*AWSSecretsManagerSecret), nil
+	}
+}
+
+// store caches secret and calls OnRotate when it replaces another version.
+func (c *AWSSecretsManagerCache) store(key awsSecretsManagerCacheKey, secret *AWSSecretsManagerSecret, fetchedAt time.Time) {
+	c.mu.Lock()
+	previous, ok := c.entries[key]
+	if ok && previous.fetchedAt.After(fetchedAt) {
+		// A concurrent fetch started later already stored a newer secret.
+		c.mu.Unlock()
+		return
+	}
+	c.entries[key] = &awsSecretsManagerCacheEntry{fetchedAt: fetchedAt, secret: secret}
+	c.mu.Unlock()
+
+	if ok && c.config.OnRotate != nil && previous.secret.VersionID != secret.VersionID {
+		c.config.OnRotate(previous.secret, secret)
+	}
+}
+
+// onError calls OnError if it's set.
+func (c *AWSSecretsManagerCache) onError(secretName string, err error) {
+	if c.config.OnError != nil {
+		c.config.OnError(secretName, err)
+	}
+}
+
+// newAWSSecretsManagerCacheKey returns the key of a version of a secret. A nil version is AWSCURRENT.
+func newAWSSecretsManagerCacheKey(secretName string, version *AWSSecretsManagerVersion) awsSecretsManagerCacheKey {
+	key := awsSecretsManagerCacheKey{secretName: secretName, versionStage: AWSSecretsManagerVersionStageCurrent}
+	if version != nil {
+		switch {
+		case version.VersionID != "":
+			key.versionStage, key.versionID = "", version.VersionID
+		case version.VersionStage != "":
+			key.versionStage = version.VersionStage
+		}
+	}
+	return key
+}
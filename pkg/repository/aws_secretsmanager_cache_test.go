@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// testGetSecretValueOutput returns the output of GetSecretValue for a version of db.
+func testGetSecretValueOutput(versionID, value string) *secretsmanager.GetSecretValueOutput {
+	return &secretsmanager.GetSecretValueOutput{
+		Name:         aws.String("db"),
+		VersionId:    aws.String(versionID),
+		SecretString: aws.String(value),
+	}
+}
+
+func TestAWSSecretsManagerCache_GetSecret(t *testing.T) {
+	mockClient := new(MockSecretsManagerClient)
+	cache := NewAWSSecretsManagerCache(NewAWSSecretsManagerRepositoryWithInterface(mockClient))
+
+	mockClient.On("GetSecretValue", mock.Anything, mock.MatchedBy(func(input *secretsmanager.GetSecretValueInput) bool {
+		return aws.ToString(input.VersionStage) == "AWSCURRENT"
+	}), mock.Anything).Return(testGetSecretValueOutput("v1", `{"password":"current"}`), nil).Once()
+	mockClient.On("GetSecretValue", mock.Anything, mock.MatchedBy(func(input *secretsmanager.GetSecretValueInput) bool {
+		return aws.ToString(input.VersionStage) == "AWSPENDING"
+	}), mock.Anything).Return(testGetSecretValueOutput("v2", `{"password":"pending"}`), nil).Once()
+
+	// Versions are fetched once and then served from the cache.
+	for range 2 {
+		value, err := cache.GetSecretString(context.Background(), "db")
+		require.NoError(t, err)
+		assert.Equal(t, `{"password":"current"}`, value)
+
+		var pending struct {
+			Password string `json:"password"`
+		}
+		secret, err := cache.GetSecret(context.Background(), "db", &AWSSecretsManagerVersion{VersionStage: AWSSecretsManagerVersionStagePending})
+		require.NoError(t, err)
+		require.NoError(t, secret.Unmarshal(&pending))
+		assert.Equal(t, "pending", pending.Password)
+	}
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSSecretsManagerCache_TTL(t *testing.T) {
+	mockClient := new(MockSecretsManagerClient)
+	var rotated []string
+	cache := NewAWSSecretsManagerCache(NewAWSSecretsManagerRepositoryWithInterface(mockClient), &AWSSecretsManagerCacheConfig{
+		TTL: 10 * time.Millisecond,
+		OnRotate: func(previous, current *AWSSecretsManagerSecret) {
+			rotated = append(rotated, previous.VersionID+">"+current.VersionID)
+		},
+	})
+
+	mockClient.On("GetSecretValue", mock.Anything, mock.Anything, mock.Anything).Return(testGetSecretValueOutput("v1", "old"), nil).Once()
+	mockClient.On("GetSecretValue", mock.Anything, mock.Anything, mock.Anything).Return(testGetSecretValueOutput("v2", "new"), nil).Once()
+
+	value, err := cache.GetSecretString(context.Background(), "db")
+	require.NoError(t, err)
+	assert.Equal(t, "old", value)
+
+	time.Sleep(20 * time.Millisecond)
+	value, err = cache.GetSecretString(context.Background(), "db")
+	require.NoError(t, err)
+	assert.Equal(t, "new", value)
+	assert.Equal(t, []string{"v1>v2"}, rotated)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSSecretsManagerCache_Refresh(t *testing.T) {
+	mockClient := new(MockSecretsManagerClient)
+	var errs []string
+	cache := NewAWSSecretsManagerCache(NewAWSSecretsManagerRepositoryWithInterface(mockClient), &AWSSecretsManagerCacheConfig{
+		OnError: func(secretName string, err error) {
+			errs = append(errs, secretName+": "+err.Error())
+		},
+	})
+
+	mockClient.On("GetSecretValue", mock.Anything, mock.MatchedBy(func(input *secretsmanager.GetSecretValueInput) bool {
+		return input.VersionId == nil
+	}), mock.Anything).Return(testGetSecretValueOutput("v1", "old"), nil).Once()
+	mockClient.On("GetSecretValue", mock.Anything, mock.MatchedBy(func(input *secretsmanager.GetSecretValueInput) bool {
+		return aws.ToString(input.VersionId) == "v0"
+	}), mock.Anything).Return(testGetSecretValueOutput("v0", "oldest"), nil).Once()
+
+	_, err := cache.GetSecretString(context.Background(), "db")
+	require.NoError(t, err)
+	_, err = cache.GetSecret(context.Background(), "db", &AWSSecretsManagerVersion{VersionID: "v0"})
+	require.NoError(t, err)
+
+	// A failed refresh keeps the cached secret.
+	mockClient.On("GetSecretValue", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("throttled")).Once()
+	err = cache.Refresh(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, []string{"db: secretsmanager GetSecretValue: throttled"}, errs)
+	value, err := cache.GetSecretString(context.Background(), "db")
+	require.NoError(t, err)
+	assert.Equal(t, "old", value)
+
+	// Versions selected by ID are not refreshed.
+	mockClient.On("GetSecretValue", mock.Anything, mock.Anything, mock.Anything).Return(testGetSecretValueOutput("v2", "new"), nil).Once()
+	require.NoError(t, cache.Refresh(context.Background()))
+	value, err = cache.GetSecretString(context.Background(), "db")
+	require.NoError(t, err)
+	assert.Equal(t, "new", value)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSSecretsManagerCache_RefreshNotFound(t *testing.T) {
+	mockClient := new(MockSecretsManagerClient)
+	var errs []string
+	cache := NewAWSSecretsManagerCache(NewAWSSecretsManagerRepositoryWithInterface(mockClient), &AWSSecretsManagerCacheConfig{
+		OnError: func(secretName string, _ error) {
+			errs = append(errs, secretName)
+		},
+	})
+
+	mockClient.On("GetSecretValue", mock.Anything, mock.Anything, mock.Anything).Return(testGetSecretValueOutput("v2", "pending"), nil).Once()
+	_, err := cache.GetSecret(context.Background(), "db", &AWSSecretsManagerVersion{VersionStage: AWSSecretsManagerVersionStagePending})
+	require.NoError(t, err)
+
+	// After the rotation, AWSPENDING no longer exists and is removed from the cache.
+	mockClient.On("GetSecretValue", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, &types.ResourceNotFoundException{Message: aws.String("can't find the staging label AWSPENDING")}).Once()
+	err = cache.Refresh(context.Background())
+	require.True(t, IsNotFound(err))
+	require.NoError(t, cache.Refresh(context.Background()))
+	assert.Equal(t, []string{"db"}, errs)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSSecretsManagerCache_ConcurrentFetch(t *testing.T) {
+	mockClient := new(MockSecretsManagerClient)
+	cache := NewAWSSecretsManagerCache(NewAWSSecretsManagerRepositoryWithInterface(mockClient))
+
+	release := make(chan struct{})
+	mockClient.On("GetSecretValue", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(mock.Arguments) { <-release }).Return(testGetSecretValueOutput("v1", "value"), nil).Once()
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			value, err := cache.GetSecretString(context.Background(), "db")
+			assert.NoError(t, err)
+			assert.Equal(t, "value", value)
+		})
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSSecretsManagerCache_ConcurrentFetchCanceled(t *testing.T) {
+	mockClient := new(MockSecretsManagerClient)
+	cache := NewAWSSecretsManagerCache(NewAWSSecretsManagerRepositoryWithInterface(mockClient))
+
+	release := make(chan struct{})
+	mockClient.On("GetSecretValue", mock.MatchedBy(func(ctx context.Context) bool {
+		return ctx.Err() == nil
+	}), mock.Anything, mock.Anything).
+		Run(func(mock.Arguments) { <-release }).Return(testGetSecretValueOutput("v1", "value"), nil).Once()
+
+	// The first caller gives up, which must not fail the fetch shared with the second.
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := cache.GetSecretString(ctx, "db")
+		errCh <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		value, err := cache.GetSecretString(context.Background(), "db")
+		assert.NoError(t, err)
+		assert.Equal(t, "value", value)
+	})
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-errCh, context.Canceled)
+	close(release)
+	wg.Wait()
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSSecretsManagerCache_Run(t *testing.T) {
+	mockClient := new(MockSecretsManagerClient)
+	rotated := make(chan string, 1)
+	cache := NewAWSSecretsManagerCache(NewAWSSecretsManagerRepositoryWithInterface(mockClient), &AWSSecretsManagerCacheConfig{
+		RefreshInterval: 5 * time.Millisecond,
+		OnRotate: func(_, current *AWSSecretsManagerSecret) {
+			rotated <- current.Value
+		},
+	})
+
+	mockClient.On("GetSecretValue", mock.Anything, mock.Anything, mock.Anything).Return(testGetSecretValueOutput("v1", "old"), nil).Once()
+	mockClient.On("GetSecretValue", mock.Anything, mock.Anything, mock.Anything).Return(testGetSecretValueOutput("v2", "new"), nil)
+	_, err := cache.GetSecretString(context.Background(), "db")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		cache.Run(ctx)
+	}()
+
+	select {
+	case value := <-rotated:
+		assert.Equal(t, "new", value)
+	case <-time.After(time.Second):
+		t.Fatal("secret was not refreshed")
+	}
+	cancel()
+	<-done
+}
+
+func TestAWSSecretsManagerCache_Prefetch(t *testing.T) {
+	mockClient := new(MockSecretsManagerClient)
+	cache := NewAWSSecretsManagerCache(NewAWSSecretsManagerRepositoryWithInterface(mockClient))
+
+	mockClient.On("BatchGetSecretValue", mock.Anything, mock.Anything, mock.Anything).Return(&secretsmanager.BatchGetSecretValueOutput{
+		SecretValues: []types.SecretValueEntry{
+			{Name: aws.String("db"), SecretString: aws.String("db-value")},
+		},
+		Errors: []types.APIErrorType{
+			{SecretId: aws.String("api"), ErrorCode: aws.String("DecryptionFailure"), Message: aws.String("kms")},
+		},
+	}, nil).Once()
+
+	err := cache.Prefetch(context.Background(), "db", "api")
+	require.ErrorIs(t, err, ErrAWSSecretsManagerBatchGetSecretValue)
+
+	// Prefetched secrets are served without GetSecretValue.
+	value, err := cache.GetSecretString(context.Background(), "db")
+	require.NoError(t, err)
+	assert.Equal(t, "db-value", value)
+
+	cache.Invalidate("db")
+	mockClient.On("GetSecretValue", mock.Anything, mock.Anything, mock.Anything).Return(testGetSecretValueOutput("v2", "rotated"), nil).Once()
+	value, err = cache.GetSecretString(context.Background(), "db")
+	require.NoError(t, err)
+	assert.Equal(t, "rotated", value)
+	mockClient.AssertExpectations(t)
+}
@@ -3,16 +3,65 @@
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 )
 
+const (
+	// AWSSecretsManagerVersionStageCurrent is the stage of the current version of a secret.
+	AWSSecretsManagerVersionStageCurrent = "AWSCURRENT"
+	// AWSSecretsManagerVersionStagePending is the stage of the version being rotated in.
+	AWSSecretsManagerVersionStagePending = "AWSPENDING"
+	// AWSSecretsManagerVersionStagePrevious is the stage of the version before the last rotation.
+	AWSSecretsManagerVersionStagePrevious = "AWSPREVIOUS"
+	// awsSecretsManagerBatchGetSecretValueMaxIDs is the maximum number of secrets of a BatchGetSecretValue call.
+	awsSecretsManagerBatchGetSecretValueMaxIDs = 20
+)
+
+var (
+	// ErrAWSSecretsManagerBatchGetSecretValue indicates that some secrets of a batch could not be retrieved.
+	ErrAWSSecretsManagerBatchGetSecretValue = errors.New("secretsmanager BatchGetSecretValue failed for some secrets")
+)
+
 // AWSSecretsManagerClientInterface interface for AWS Secrets Manager operations
 type AWSSecretsManagerClientInterface interface {
 	// GetSecretValue retrieves the value of a secret from AWS Secrets Manager
 	GetSecretValue(_ context.Context, _ *secretsmanager.GetSecretValueInput, _ ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+	// BatchGetSecretValue retrieves the current values of up to 20 secrets from AWS Secrets Manager
+	BatchGetSecretValue(_ context.Context, _ *secretsmanager.BatchGetSecretValueInput, _ ...func(*secretsmanager.Options)) (*secretsmanager.BatchGetSecretValueOutput, error)
+}
+
+// AWSSecretsManagerVersion selects a version of a secret.
+// VersionID takes precedence over VersionStage. Default: AWSCURRENT.
+type AWSSecretsManagerVersion struct {
+	// VersionStage is the stage of the version, e.g. AWSCURRENT or AWSPENDING.
+	VersionStage string
+	// VersionID is the ID of the version.
+	VersionID string
+}
+
+// AWSSecretsManagerSecret is a version of a secret.
+type AWSSecretsManagerSecret struct {
+	CreatedDate   time.Time `json:"createdDate"`
+	ARN           string    `json:"arn"`
+	Name          string    `json:"name"`
+	VersionID     string    `json:"versionId"`
+	Value         string    `json:"-"`
+	VersionStages []string  `json:"versionStages"`
+}
+
+// Unmarshal decodes the JSON value of the secret into v.
+func (s *AWSSecretsManagerSecret) Unmarshal(v any) error {
+	if err := json.Unmarshal([]byte(s.Value), v); err != nil {
+		return fmt.Errorf("secretsmanager unmarshal secret %s: %w", s.Name, err)
+	}
+	return nil
 }
 
 // AWSSecretsManagerRepository struct.
@@ -27,21 +76,128 @@ func NewAWSSecretsManagerRepository(c *secretsmanager.Client) *AWSSecretsManager
 	}
 }
 
+// NewAWSSecretsManagerRepositoryWithInterface returns AWSSecretsManagerRepository instance with interface.
+func NewAWSSecretsManagerRepositoryWithInterface(c AWSSecretsManagerClientInterface) *AWSSecretsManagerRepository {
+	return &AWSSecretsManagerRepository{
+		Client: c,
+	}
+}
+
 // GetSecretString gets a secret string from secretsmanager.
 func (r *AWSSecretsManagerRepository) GetSecretString(ctx context.Context, secretName string) (string, error) {
-	result, err := r.Client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+	secret, err := r.GetSecret(ctx, secretName, nil)
+	if err != nil {
+		return "", err
+	}
+	return secret.Value, nil
+}
+
+// GetSecret gets a version of a secret from secretsmanager. A nil version is the AWSCURRENT version.
+func (r *AWSSecretsManagerRepository) GetSecret(ctx context.Context, secretName string, version *AWSSecretsManagerVersion) (*AWSSecretsManagerSecret, error) {
+	input := &secretsmanager.GetSecretValueInput{
 		SecretId: aws.String(secretName),
 		// VersionStage defaults to AWSCURRENT if unspecified
-		VersionStage: aws.String("AWSCURRENT"),
-	})
+		VersionStage: aws.String(AWSSecretsManagerVersionStageCurrent),
+	}
+	if version != nil {
+		switch {
+		case version.VersionID != "":
+			input.VersionId = aws.String(version.VersionID)
+			input.VersionStage = nil
+		case version.VersionStage != "":
+			input.VersionStage = aws.String(version.VersionStage)
+		}
+	}
+	result, err := r.Client.GetSecretValue(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("secretsmanager GetSecretValue: %w", err)
+	}
+	value, err := awsSecretsManagerSecretValue(result.SecretString, result.SecretBinary)
+	if err != nil {
+		return nil, err
+	}
+	return &AWSSecretsManagerSecret{
+		CreatedDate:   aws.ToTime(result.CreatedDate),
+		ARN:           aws.ToString(result.ARN),
+		Name:          aws.ToString(result.Name),
+		VersionID:     aws.ToString(result.VersionId),
+		Value:         value,
+		VersionStages: result.VersionStages,
+	}, nil
+}
+
+// GetSecretJSON gets the AWSCURRENT version of a JSON secret from secretsmanager and decodes it into v.
+func (r *AWSSecretsManagerRepository) GetSecretJSON(ctx context.Context, secretName string, v any) error {
+	secret, err := r.GetSecret(ctx, secretName, nil)
 	if err != nil {
-		return "", fmt.Errorf("secretsmanager GetSecretValue: %w", err)
+		return err
 	}
-	if result.SecretString != nil { // pragma: allowlist-secret
-		return *result.SecretString, nil
+	return secret.Unmarshal(v)
+}
+
+// BatchGetSecretValue gets the AWSCURRENT versions of secretNames from secretsmanager,
+// 20 secrets per call, and returns them by the name or ARN they were requested with.
+// Secrets that could not be retrieved are reported with ErrAWSSecretsManagerBatchGetSecretValue
+// while the others are returned.
+func (r *AWSSecretsManagerRepository) BatchGetSecretValue(ctx context.Context, secretNames []string) (map[string]*AWSSecretsManagerSecret, error) {
+	secrets := make(map[string]*AWSSecretsManagerSecret, len(secretNames))
+	var errs []error
+	for ids := range slices.Chunk(secretNames, awsSecretsManagerBatchGetSecretValueMaxIDs) {
+		input := &secretsmanager.BatchGetSecretValueInput{SecretIdList: ids}
+		for {
+			result, err := r.Client.BatchGetSecretValue(ctx, input)
+			if err != nil {
+				return secrets, fmt.Errorf("secretsmanager BatchGetSecretValue: %w", err)
+			}
+			for i := range result.SecretValues {
+				entry := &result.SecretValues[i]
+				value, err := awsSecretsManagerSecretValue(entry.SecretString, entry.SecretBinary)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", aws.ToString(entry.Name), err))
+					continue
+				}
+				secret := &AWSSecretsManagerSecret{
+					CreatedDate:   aws.ToTime(entry.CreatedDate),
+					ARN:           aws.ToString(entry.ARN),
+					Name:          aws.ToString(entry.Name),
+					VersionID:     aws.ToString(entry.VersionId),
+					Value:         value,
+					VersionStages: entry.VersionStages,
+				}
+				secrets[awsSecretsManagerRequestedID(ids, secret)] = secret
+			}
+			for _, e := range result.Errors {
+				errs = append(errs, fmt.Errorf("%s: %s: %s", aws.ToString(e.SecretId), aws.ToString(e.ErrorCode), aws.ToString(e.Message)))
+			}
+			if result.NextToken == nil {
+				break
+			}
+			input.NextToken = result.NextToken
+		}
+	}
+	if len(errs) > 0 {
+		return secrets, fmt.Errorf("%w: %w", ErrAWSSecretsManagerBatchGetSecretValue, errors.Join(errs...))
+	}
+	return secrets, nil
+}
+
+// awsSecretsManagerRequestedID returns the ID of ids that secret was requested with, which is its name or ARN.
+func awsSecretsManagerRequestedID(ids []string, secret *AWSSecretsManagerSecret) string {
+	for _, id := range ids {
+		if id == secret.ARN {
+			return id
+		}
+	}
+	return secret.Name
+}
+
+// awsSecretsManagerSecretValue returns the string of a secret, or its base64 decoded binary.
+func awsSecretsManagerSecretValue(secretString *string, secretBinary []byte) (string, error) {
+	if secretString != nil { // pragma: allowlist-secret
+		return *secretString, nil
 	}
-	decodedBinarySecretBytes := make([]byte, base64.StdEncoding.DecodedLen(len(result.SecretBinary)))
-	length, err := base64.StdEncoding.Decode(decodedBinarySecretBytes, result.SecretBinary)
+	decodedBinarySecretBytes := make([]byte, base64.StdEncoding.DecodedLen(len(secretBinary)))
+	length, err := base64.StdEncoding.Decode(decodedBinarySecretBytes, secretBinary)
 	if err != nil {
 		return "", fmt.Errorf("secretsmanager decode secret binary: %w", err)
 	}
@@ -4,12 +4,16 @@
 	"context"
 	"encoding/base64"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockSecretsManagerClient is a mock implementation of the SecretsManager client
@@ -25,6 +29,14 @@ func (m *MockSecretsManagerClient) GetSecretValue(ctx context.Context, input *se
 	return args.Get(0).(*secretsmanager.GetSecretValueOutput), args.Error(1)
 }
 
+func (m *MockSecretsManagerClient) BatchGetSecretValue(ctx context.Context, input *secretsmanager.BatchGetSecretValueInput, opts ...func(*secretsmanager.Options)) (*secretsmanager.BatchGetSecretValueOutput, error) {
+	args := m.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*secretsmanager.BatchGetSecretValueOutput), args.Error(1)
+}
+
 func TestNewAWSSecretsManagerRepository(t *testing.T) {
 	mockClient := &secretsmanager.Client{}
 	repo := NewAWSSecretsManagerRepository(mockClient)
@@ -32,6 +44,13 @@ func TestNewAWSSecretsManagerRepository(t *testing.T) {
 	assert.Equal(t, mockClient, repo.Client)
 }
 
+func TestNewAWSSecretsManagerRepositoryWithInterface(t *testing.T) {
+	mockClient := new(MockSecretsManagerClient)
+	repo := NewAWSSecretsManagerRepositoryWithInterface(mockClient)
+	assert.NotNil(t, repo)
+	assert.Equal(t, mockClient, repo.Client)
+}
+
 func TestAWSSecretsManagerRepository_GetSecretString_SecretString(t *testing.T) {
 	mockClient := new(MockSecretsManagerClient)
 	repo := &AWSSecretsManagerRepository{Client: mockClient}
@@ -92,3 +111,137 @@ func TestAWSSecretsManagerRepository_GetSecretString_Error(t *testing.T) {
 	assert.Empty(t, result)
 	mockClient.AssertExpectations(t)
 }
+
+func TestAWSSecretsManagerRepository_GetSecret_Version(t *testing.T) {
+	tests := []struct {
+		name      string
+		version   *AWSSecretsManagerVersion
+		wantStage *string
+		wantID    *string
+	}{
+		{name: "default", wantStage: aws.String(AWSSecretsManagerVersionStageCurrent)},
+		{name: "pending", version: &AWSSecretsManagerVersion{VersionStage: AWSSecretsManagerVersionStagePending}, wantStage: aws.String("AWSPENDING")},
+		{name: "version id", version: &AWSSecretsManagerVersion{VersionStage: "AWSPENDING", VersionID: "v2"}, wantID: aws.String("v2")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := new(MockSecretsManagerClient)
+			repo := NewAWSSecretsManagerRepositoryWithInterface(mockClient)
+			created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+			mockClient.On("GetSecretValue", mock.Anything, mock.MatchedBy(func(input *secretsmanager.GetSecretValueInput) bool {
+				return assert.ObjectsAreEqual(tt.wantStage, input.VersionStage) && assert.ObjectsAreEqual(tt.wantID, input.VersionId)
+			}), mock.Anything).Return(&secretsmanager.GetSecretValueOutput{
+				ARN:           aws.String("arn:aws:secretsmanager:us-east-1:123456789012:secret:db-AbCdEf"),
+				Name:          aws.String("db"),
+				VersionId:     aws.String("v2"),
+				VersionStages: []string{"AWSPENDING"},
+				SecretString:  aws.String("value"),
+				CreatedDate:   aws.Time(created),
+			}, nil)
+
+			secret, err := repo.GetSecret(context.Background(), "db", tt.version)
+
+			require.NoError(t, err)
+			assert.Equal(t, &AWSSecretsManagerSecret{
+				CreatedDate:   created,
+				ARN:           "arn:aws:secretsmanager:us-east-1:123456789012:secret:db-AbCdEf",
+				Name:          "db",
+				VersionID:     "v2",
+				Value:         "value",
+				VersionStages: []string{"AWSPENDING"},
+			}, secret)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAWSSecretsManagerRepository_GetSecretJSON(t *testing.T) {
+	mockClient := new(MockSecretsManagerClient)
+	repo := NewAWSSecretsManagerRepositoryWithInterface(mockClient)
+
+	mockClient.On("GetSecretValue", mock.Anything, mock.Anything, mock.Anything).Return(&secretsmanager.GetSecretValueOutput{
+		Name:         aws.String("db"),
+		SecretString: aws.String(`{"username":"app","password":"pass","port":5432}`),
+	}, nil).Once()
+	mockClient.On("GetSecretValue", mock.Anything, mock.Anything, mock.Anything).Return(&secretsmanager.GetSecretValueOutput{
+		Name:         aws.String("db"),
+		SecretString: aws.String("not json"),
+	}, nil).Once()
+
+	var credentials struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Port     int    `json:"port"`
+	}
+	require.NoError(t, repo.GetSecretJSON(context.Background(), "db", &credentials))
+	assert.Equal(t, "app", credentials.Username)
+	assert.Equal(t, "pass", credentials.Password)
+	assert.Equal(t, 5432, credentials.Port)
+
+	err := repo.GetSecretJSON(context.Background(), "db", &credentials)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "secretsmanager unmarshal secret db")
+}
+
+func TestAWSSecretsManagerRepository_BatchGetSecretValue(t *testing.T) {
+	mockClient := new(MockSecretsManagerClient)
+	repo := NewAWSSecretsManagerRepositoryWithInterface(mockClient)
+
+	names := make([]string, 21)
+	for i := range names {
+		names[i] = fmt.Sprintf("secret-%02d", i)
+	}
+	names[1] = "arn:aws:secretsmanager:us-east-1:123456789012:secret:secret-01-AbCdEf"
+
+	mockClient.On("BatchGetSecretValue", mock.Anything, mock.MatchedBy(func(input *secretsmanager.BatchGetSecretValueInput) bool {
+		return len(input.SecretIdList) == 20 && input.NextToken == nil
+	}), mock.Anything).Return(&secretsmanager.BatchGetSecretValueOutput{
+		SecretValues: []types.SecretValueEntry{
+			{Name: aws.String("secret-00"), SecretString: aws.String("value-00"), VersionId: aws.String("v1")},
+		},
+		NextToken: aws.String("next"),
+	}, nil).Once()
+	mockClient.On("BatchGetSecretValue", mock.Anything, mock.MatchedBy(func(input *secretsmanager.BatchGetSecretValueInput) bool {
+		return len(input.SecretIdList) == 20 && aws.ToString(input.NextToken) == "next"
+	}), mock.Anything).Return(&secretsmanager.BatchGetSecretValueOutput{
+		SecretValues: []types.SecretValueEntry{
+			{ARN: aws.String(names[1]), Name: aws.String("secret-01"), SecretString: aws.String("value-01")},
+		},
+		Errors: []types.APIErrorType{
+			{SecretId: aws.String("secret-02"), ErrorCode: aws.String("ResourceNotFoundException"), Message: aws.String("not found")},
+		},
+	}, nil).Once()
+	mockClient.On("BatchGetSecretValue", mock.Anything, mock.MatchedBy(func(input *secretsmanager.BatchGetSecretValueInput) bool {
+		return assert.ObjectsAreEqual([]string{"secret-20"}, input.SecretIdList)
+	}), mock.Anything).Return(&secretsmanager.BatchGetSecretValueOutput{
+		SecretValues: []types.SecretValueEntry{
+			{Name: aws.String("secret-20"), SecretString: aws.String("value-20")},
+		},
+	}, nil).Once()
+
+	secrets, err := repo.BatchGetSecretValue(context.Background(), names)
+
+	require.ErrorIs(t, err, ErrAWSSecretsManagerBatchGetSecretValue)
+	assert.Contains(t, err.Error(), "secret-02: ResourceNotFoundException: not found")
+	require.Len(t, secrets, 3)
+	assert.Equal(t, "value-00", secrets["secret-00"].Value)
+	assert.Equal(t, "v1", secrets["secret-00"].VersionID)
+	// Secrets requested by ARN are returned by ARN.
+	assert.Equal(t, "value-01", secrets[names[1]].Value)
+	assert.Equal(t, "value-20", secrets["secret-20"].Value)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSSecretsManagerRepository_BatchGetSecretValue_Error(t *testing.T) {
+	mockClient := new(MockSecretsManagerClient)
+	repo := NewAWSSecretsManagerRepositoryWithInterface(mockClient)
+
+	mockClient.On("BatchGetSecretValue", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("access denied"))
+
+	secrets, err := repo.BatchGetSecretValue(context.Background(), []string{"db"})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "secretsmanager BatchGetSecretValue: access denied")
+	assert.Empty(t, secrets)
+}
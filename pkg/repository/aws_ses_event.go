@@ -0,0 +1,288 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+)
+
+// AWSSESEventType is the type of an SES event notification.
+type AWSSESEventType string
+
+// AWSSESEventType values.
+const (
+	AWSSESEventTypeBounce        AWSSESEventType = "Bounce"
+	AWSSESEventTypeComplaint     AWSSESEventType = "Complaint"
+	AWSSESEventTypeDelivery      AWSSESEventType = "Delivery"
+	AWSSESEventTypeSend          AWSSESEventType = "Send"
+	AWSSESEventTypeReject        AWSSESEventType = "Reject"
+	AWSSESEventTypeOpen          AWSSESEventType = "Open"
+	AWSSESEventTypeClick         AWSSESEventType = "Click"
+	AWSSESEventTypeDeliveryDelay AWSSESEventType = "DeliveryDelay"
+)
+
+// AWSSESBounceType values.
+const (
+	AWSSESBounceTypePermanent    = "Permanent"
+	AWSSESBounceTypeTransient    = "Transient"
+	AWSSESBounceTypeUndetermined = "Undetermined"
+)
+
+// awsSNSMessageTypeNotification is the SNS message type of notifications.
+const awsSNSMessageTypeNotification = "Notification"
+
+var (
+	ErrAWSSESEventNotNotification = errors.New("sns message is not a notification")
+	ErrAWSSESEventTypeNotFound    = errors.New("ses event type not found")
+)
+
+// AWSSNSMessage is the JSON body of an SNS HTTP/S delivery.
+// SubscribeURL is set for SubscriptionConfirmation messages.
+type AWSSNSMessage struct {
+	Timestamp    time.Time `json:"Timestamp"`    //nolint:tagliatelle // SNS payload
+	Type         string    `json:"Type"`         //nolint:tagliatelle // SNS payload
+	MessageID    string    `json:"MessageId"`    //nolint:tagliatelle // SNS payload
+	TopicArn     string    `json:"TopicArn"`     //nolint:tagliatelle // SNS payload
+	Subject      string    `json:"Subject"`      //nolint:tagliatelle // SNS payload
+	Message      string    `json:"Message"`      //nolint:tagliatelle // SNS payload
+	SubscribeURL string    `json:"SubscribeURL"` //nolint:tagliatelle // SNS payload
+}
+
+// AWSSESEvent is an SES event notification (event publishing or feedback notification).
+// Only the field of EventType is set among Bounce, Complaint, Delivery, Open and Click.
+type AWSSESEvent struct {
+	Bounce    *AWSSESBounce    `json:"bounce,omitempty"`
+	Complaint *AWSSESComplaint `json:"complaint,omitempty"`
+	Delivery  *AWSSESDelivery  `json:"delivery,omitempty"`
+	Open      *AWSSESOpen      `json:"open,omitempty"`
+	Click     *AWSSESClick     `json:"click,omitempty"`
+	// EventType is the type of event publishing. The notificationType of feedback notifications is copied to it.
+	EventType        AWSSESEventType `json:"eventType"`
+	NotificationType AWSSESEventType `json:"notificationType,omitempty"`
+	Mail             AWSSESMail      `json:"mail"`
+}
+
+// AWSSESMail is the original email of an event.
+type AWSSESMail struct {
+	Timestamp        time.Time           `json:"timestamp"`
+	Tags             map[string][]string `json:"tags,omitempty"`
+	MessageID        string              `json:"messageId"`
+	Source           string              `json:"source"`
+	SourceArn        string              `json:"sourceArn"`
+	SendingAccountID string              `json:"sendingAccountId"`
+	CommonHeaders    AWSSESCommonHeaders `json:"commonHeaders"`
+	Destination      []string            `json:"destination"`
+}
+
+// AWSSESCommonHeaders is the common headers of the original email.
+type AWSSESCommonHeaders struct {
+	MessageID string   `json:"messageId"`
+	Subject   string   `json:"subject"`
+	From      []string `json:"from"`
+	To        []string `json:"to"`
+}
+
+// AWSSESRecipient is a recipient of a bounce or complaint.
+type AWSSESRecipient struct {
+	EmailAddress   string `json:"emailAddress"`
+	Action         string `json:"action,omitempty"`
+	Status         string `json:"status,omitempty"`
+	DiagnosticCode string `json:"diagnosticCode,omitempty"`
+}
+
+// AWSSESBounce is the details of a Bounce event.
+type AWSSESBounce struct {
+	Timestamp         time.Time         `json:"timestamp"`
+	BounceType        string            `json:"bounceType"`
+	BounceSubType     string            `json:"bounceSubType"`
+	FeedbackID        string            `json:"feedbackId"`
+	ReportingMTA      string            `json:"reportingMTA,omitempty"` //nolint:tagliatelle // SES payload
+	BouncedRecipients []AWSSESRecipient `json:"bouncedRecipients"`
+}
+
+// AWSSESComplaint is the details of a Complaint event.
+type AWSSESComplaint struct {
+	Timestamp             time.Time         `json:"timestamp"`
+	FeedbackID            string            `json:"feedbackId"`
+	ComplaintSubType      string            `json:"complaintSubType,omitempty"`
+	ComplaintFeedbackType string            `json:"complaintFeedbackType,omitempty"`
+	UserAgent             string            `json:"userAgent,omitempty"`
+	ComplainedRecipients  []AWSSESRecipient `json:"complainedRecipients"`
+}
+
+// AWSSESDelivery is the details of a Delivery event.
+type AWSSESDelivery struct {
+	Timestamp            time.Time `json:"timestamp"`
+	SMTPResponse         string    `json:"smtpResponse"`
+	ReportingMTA         string    `json:"reportingMTA"` //nolint:tagliatelle // SES payload
+	Recipients           []string  `json:"recipients"`
+	ProcessingTimeMillis int64     `json:"processingTimeMillis"`
+}
+
+// AWSSESOpen is the details of an Open event.
+type AWSSESOpen struct {
+	Timestamp time.Time `json:"timestamp"`
+	IPAddress string    `json:"ipAddress"`
+	UserAgent string    `json:"userAgent"`
+}
+
+// AWSSESClick is the details of a Click event.
+type AWSSESClick struct {
+	Timestamp time.Time           `json:"timestamp"`
+	LinkTags  map[string][]string `json:"linkTags,omitempty"`
+	IPAddress string              `json:"ipAddress"`
+	UserAgent string              `json:"userAgent"`
+	Link      string              `json:"link"`
+}
+
+// IsPermanent reports whether the bounce is a hard bounce, after which the address shouldn't be sent to again.
+func (b *AWSSESBounce) IsPermanent() bool {
+	return b.BounceType == AWSSESBounceTypePermanent
+}
+
+// ParseAWSSNSMessage parses the JSON body of an SNS HTTP/S delivery.
+// It doesn't verify the signature of the message.
+func ParseAWSSNSMessage(body []byte) (*AWSSNSMessage, error) {
+	var msg AWSSNSMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, fmt.Errorf("parse sns message: %w", err)
+	}
+	return &msg, nil
+}
+
+// ParseAWSSESEvent parses an SES event notification. body is either an SNS
+// notification wrapping the event, or the event itself (e.g. SNS raw message
+// delivery or SQS). SNS subscription confirmations return ErrAWSSESEventNotNotification;
+// use ParseAWSSNSMessage to get their SubscribeURL.
+// The signature of SNS messages isn't verified: verify it, or otherwise
+// authenticate the request, before acting on the event.
+func ParseAWSSESEvent(body []byte) (*AWSSESEvent, error) {
+	var envelope struct {
+		Type    string `json:"Type"`    //nolint:tagliatelle // SNS payload
+		Message string `json:"Message"` //nolint:tagliatelle // SNS payload
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, fmt.Errorf("parse ses event: %w", err)
+	}
+	if envelope.Type != "" {
+		if envelope.Type != awsSNSMessageTypeNotification {
+			return nil, fmt.Errorf("parse ses event: %w: %s", ErrAWSSESEventNotNotification, envelope.Type)
+		}
+		body = []byte(envelope.Message)
+	}
+
+	var event AWSSESEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("parse ses event: %w", err)
+	}
+	if event.EventType == "" {
+		event.EventType = event.NotificationType
+	}
+	if event.EventType == "" {
+		return nil, fmt.Errorf("parse ses event: %w", ErrAWSSESEventTypeNotFound)
+	}
+	return &event, nil
+}
+
+// AWSSESEventHandler handles SES events dispatched by DispatchAWSSESEvent.
+// Embed NopAWSSESEventHandler to handle only some types.
+type AWSSESEventHandler interface {
+	// HandleBounce handles a Bounce event.
+	HandleBounce(ctx context.Context, event *AWSSESEvent) error
+	// HandleComplaint handles a Complaint event.
+	HandleComplaint(ctx context.Context, event *AWSSESEvent) error
+	// HandleDelivery handles a Delivery event.
+	HandleDelivery(ctx context.Context, event *AWSSESEvent) error
+	// HandleOpen handles an Open event.
+	HandleOpen(ctx context.Context, event *AWSSESEvent) error
+	// HandleClick handles a Click event.
+	HandleClick(ctx context.Context, event *AWSSESEvent) error
+}
+
+// NopAWSSESEventHandler ignores all events.
+type NopAWSSESEventHandler struct{}
+
+// HandleBounce ignores the event.
+func (NopAWSSESEventHandler) HandleBounce(context.Context, *AWSSESEvent) error { return nil }
+
+// HandleComplaint ignores the event.
+func (NopAWSSESEventHandler) HandleComplaint(context.Context, *AWSSESEvent) error { return nil }
+
+// HandleDelivery ignores the event.
+func (NopAWSSESEventHandler) HandleDelivery(context.Context, *AWSSESEvent) error { return nil }
+
+// HandleOpen ignores the event.
+func (NopAWSSESEventHandler) HandleOpen(context.Context, *AWSSESEvent) error { return nil }
+
+// HandleClick ignores the event.
+func (NopAWSSESEventHandler) HandleClick(context.Context, *AWSSESEvent) error { return nil }
+
+// DispatchAWSSESEvent calls the method of handler for the type of event.
+// Events of other types, or without their details, are ignored.
+func DispatchAWSSESEvent(ctx context.Context, handler AWSSESEventHandler, event *AWSSESEvent) error {
+	switch {
+	case event.EventType == AWSSESEventTypeBounce && event.Bounce != nil:
+		return handler.HandleBounce(ctx, event)
+	case event.EventType == AWSSESEventTypeComplaint && event.Complaint != nil:
+		return handler.HandleComplaint(ctx, event)
+	case event.EventType == AWSSESEventTypeDelivery && event.Delivery != nil:
+		return handler.HandleDelivery(ctx, event)
+	case event.EventType == AWSSESEventTypeOpen && event.Open != nil:
+		return handler.HandleOpen(ctx, event)
+	case event.EventType == AWSSESEventTypeClick && event.Click != nil:
+		return handler.HandleClick(ctx, event)
+	}
+	return nil
+}
+
+// HandleAWSSESEvent parses body with ParseAWSSESEvent and dispatches the event to handler.
+func HandleAWSSESEvent(ctx context.Context, handler AWSSESEventHandler, body []byte) error {
+	event, err := ParseAWSSESEvent(body)
+	if err != nil {
+		return err
+	}
+	return DispatchAWSSESEvent(ctx, handler, event)
+}
+
+// AWSSESSuppressionHandler adds the recipients of permanent bounces and complaints
+// to the account-level suppression list.
+type AWSSESSuppressionHandler struct {
+	NopAWSSESEventHandler
+	repo *AWSSESRepository
+}
+
+// NewAWSSESSuppressionHandler returns AWSSESSuppressionHandler suppressing addresses with repo.
+func NewAWSSESSuppressionHandler(repo *AWSSESRepository) *AWSSESSuppressionHandler {
+	return &AWSSESSuppressionHandler{repo: repo}
+}
+
+// HandleBounce suppresses the bounced recipients of a permanent bounce. Transient bounces are ignored.
+func (h *AWSSESSuppressionHandler) HandleBounce(ctx context.Context, event *AWSSESEvent) error {
+	if !event.Bounce.IsPermanent() {
+		return nil
+	}
+	return h.suppress(ctx, event.Bounce.BouncedRecipients, types.SuppressionListReasonBounce)
+}
+
+// HandleComplaint suppresses the complained recipients.
+func (h *AWSSESSuppressionHandler) HandleComplaint(ctx context.Context, event *AWSSESEvent) error {
+	return h.suppress(ctx, event.Complaint.ComplainedRecipients, types.SuppressionListReasonComplaint)
+}
+
+// suppress adds recipients to the suppression list. It tries all recipients and returns their errors joined.
+func (h *AWSSESSuppressionHandler) suppress(ctx context.Context, recipients []AWSSESRecipient, reason types.SuppressionListReason) error {
+	var errs []error
+	for _, r := range recipients {
+		if r.EmailAddress == "" {
+			continue
+		}
+		if err := h.repo.PutSuppressedDestination(ctx, r.EmailAddress, reason); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
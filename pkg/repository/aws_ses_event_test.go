@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// testAWSSESBounceEvent is an SES Bounce event of event publishing.
+const testAWSSESBounceEvent = `{
+	"eventType": "Bounce",
+	"bounce": {
+		"bounceType": "Permanent",
+		"bounceSubType": "General",
+		"bouncedRecipients": [
+			{"emailAddress": "bounce@example.com", "action": "failed", "status": "5.1.1", "diagnosticCode": "smtp; 550 5.1.1 user unknown"}
+		],
+		"timestamp": "2026-01-02T03:04:05.000Z",
+		"feedbackId": "feedback-id",
+		"reportingMTA": "dsn; mta.example.com"
+	},
+	"mail": {
+		"timestamp": "2026-01-02T03:04:00.000Z",
+		"source": "sender@example.com",
+		"sourceArn": "arn:aws:ses:us-east-1:123456789012:identity/sender@example.com",
+		"sendingAccountId": "123456789012",
+		"messageId": "message-id",
+		"destination": ["bounce@example.com"],
+		"commonHeaders": {"from": ["sender@example.com"], "to": ["bounce@example.com"], "messageId": "message-id", "subject": "Hello"},
+		"tags": {"ses:configuration-set": ["transactional"]}
+	}
+}`
+
+// testAWSSNSNotification returns an SNS notification wrapping message.
+func testAWSSNSNotification(t *testing.T, message string) []byte {
+	t.Helper()
+	b, err := json.Marshal(map[string]string{
+		"Type":      "Notification",
+		"MessageId": "sns-message-id",
+		"TopicArn":  "arn:aws:sns:us-east-1:123456789012:ses-events",
+		"Message":   message,
+		"Timestamp": "2026-01-02T03:04:06.000Z",
+	})
+	require.NoError(t, err)
+	return b
+}
+
+// testAWSSESEventHandler records the dispatched event types.
+type testAWSSESEventHandler struct {
+	NopAWSSESEventHandler
+	err    error
+	called []AWSSESEventType
+}
+
+func (h *testAWSSESEventHandler) HandleBounce(_ context.Context, event *AWSSESEvent) error {
+	h.called = append(h.called, event.EventType)
+	return h.err
+}
+
+func (h *testAWSSESEventHandler) HandleClick(_ context.Context, event *AWSSESEvent) error {
+	h.called = append(h.called, event.EventType)
+	return h.err
+}
+
+func TestParseAWSSESEvent_SNSNotification(t *testing.T) {
+	event, err := ParseAWSSESEvent(testAWSSNSNotification(t, testAWSSESBounceEvent))
+
+	require.NoError(t, err)
+	assert.Equal(t, AWSSESEventTypeBounce, event.EventType)
+	require.NotNil(t, event.Bounce)
+	assert.True(t, event.Bounce.IsPermanent())
+	assert.Equal(t, "General", event.Bounce.BounceSubType)
+	assert.Equal(t, "dsn; mta.example.com", event.Bounce.ReportingMTA)
+	assert.Equal(t, []AWSSESRecipient{{
+		EmailAddress:   "bounce@example.com",
+		Action:         "failed",
+		Status:         "5.1.1",
+		DiagnosticCode: "smtp; 550 5.1.1 user unknown",
+	}}, event.Bounce.BouncedRecipients)
+	assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), event.Bounce.Timestamp)
+	assert.Equal(t, "message-id", event.Mail.MessageID)
+	assert.Equal(t, "Hello", event.Mail.CommonHeaders.Subject)
+	assert.Equal(t, []string{"transactional"}, event.Mail.Tags["ses:configuration-set"])
+	assert.Nil(t, event.Complaint)
+}
+
+func TestParseAWSSESEvent_Raw(t *testing.T) {
+	tests := []struct {
+		check func(t *testing.T, event *AWSSESEvent)
+		name  string
+		body  string
+		want  AWSSESEventType
+	}{
+		{
+			name: "complaint notification",
+			body: `{"notificationType": "Complaint", "complaint": {"complainedRecipients": [{"emailAddress": "c@example.com"}],
+				"timestamp": "2026-01-02T03:04:05Z", "feedbackId": "f", "complaintFeedbackType": "abuse", "complaintSubType": null},
+				"mail": {"messageId": "m"}}`,
+			want: AWSSESEventTypeComplaint,
+			check: func(t *testing.T, event *AWSSESEvent) {
+				t.Helper()
+				assert.Equal(t, "c@example.com", event.Complaint.ComplainedRecipients[0].EmailAddress)
+				assert.Equal(t, "abuse", event.Complaint.ComplaintFeedbackType)
+			},
+		},
+		{
+			name: "delivery",
+			body: `{"eventType": "Delivery", "delivery": {"timestamp": "2026-01-02T03:04:05Z", "processingTimeMillis": 546,
+				"recipients": ["d@example.com"], "smtpResponse": "250 ok", "reportingMTA": "a8-70.smtp-out.amazonses.com"},
+				"mail": {"messageId": "m"}}`,
+			want: AWSSESEventTypeDelivery,
+			check: func(t *testing.T, event *AWSSESEvent) {
+				t.Helper()
+				assert.Equal(t, int64(546), event.Delivery.ProcessingTimeMillis)
+				assert.Equal(t, "250 ok", event.Delivery.SMTPResponse)
+			},
+		},
+		{
+			name: "open",
+			body: `{"eventType": "Open", "open": {"timestamp": "2026-01-02T03:04:05Z", "ipAddress": "192.0.2.1", "userAgent": "Mozilla"},
+				"mail": {"messageId": "m"}}`,
+			want: AWSSESEventTypeOpen,
+			check: func(t *testing.T, event *AWSSESEvent) {
+				t.Helper()
+				assert.Equal(t, "192.0.2.1", event.Open.IPAddress)
+			},
+		},
+		{
+			name: "click",
+			body: `{"eventType": "Click", "click": {"timestamp": "2026-01-02T03:04:05Z", "ipAddress": "192.0.2.1", "userAgent": "Mozilla",
+				"link": "https://example.com", "linkTags": {"campaign": ["spring"]}}, "mail": {"messageId": "m"}}`,
+			want: AWSSESEventTypeClick,
+			check: func(t *testing.T, event *AWSSESEvent) {
+				t.Helper()
+				assert.Equal(t, "https://example.com", event.Click.Link)
+				assert.Equal(t, []string{"spring"}, event.Click.LinkTags["campaign"])
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := ParseAWSSESEvent([]byte(tt.body))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, event.EventType)
+			tt.check(t, event)
+		})
+	}
+}
+
+func TestParseAWSSESEvent_Errors(t *testing.T) {
+	_, err := ParseAWSSESEvent([]byte(`{"Type": "SubscriptionConfirmation", "SubscribeURL": "https://sns.example.com/confirm"}`))
+	require.ErrorIs(t, err, ErrAWSSESEventNotNotification)
+
+	_, err = ParseAWSSESEvent([]byte(`{"mail": {}}`))
+	require.ErrorIs(t, err, ErrAWSSESEventTypeNotFound)
+
+	_, err = ParseAWSSESEvent([]byte(`not json`))
+	require.Error(t, err)
+}
+
+func TestParseAWSSNSMessage(t *testing.T) {
+	msg, err := ParseAWSSNSMessage([]byte(`{"Type": "SubscriptionConfirmation", "MessageId": "id",
+		"TopicArn": "arn:aws:sns:us-east-1:123456789012:ses-events", "SubscribeURL": "https://sns.example.com/confirm",
+		"Timestamp": "2026-01-02T03:04:05.000Z"}`))
+
+	require.NoError(t, err)
+	assert.Equal(t, "SubscriptionConfirmation", msg.Type)
+	assert.Equal(t, "https://sns.example.com/confirm", msg.SubscribeURL)
+}
+
+func TestHandleAWSSESEvent(t *testing.T) {
+	handler := &testAWSSESEventHandler{}
+
+	require.NoError(t, HandleAWSSESEvent(context.Background(), handler, testAWSSNSNotification(t, testAWSSESBounceEvent)))
+	// Events without a handler method or details are ignored.
+	require.NoError(t, HandleAWSSESEvent(context.Background(), handler, []byte(`{"eventType": "Send", "mail": {}}`)))
+	require.NoError(t, HandleAWSSESEvent(context.Background(), handler, []byte(`{"eventType": "Click", "mail": {}}`)))
+
+	assert.Equal(t, []AWSSESEventType{AWSSESEventTypeBounce}, handler.called)
+
+	handler.err = errors.New("handler error")
+	err := HandleAWSSESEvent(context.Background(), handler, testAWSSNSNotification(t, testAWSSESBounceEvent))
+	require.ErrorIs(t, err, handler.err)
+}
+
+func TestAWSSESSuppressionHandler(t *testing.T) {
+	mockClient := &MockSESClient{}
+	handler := NewAWSSESSuppressionHandler(NewAWSSESRepositoryWithInterface(mockClient, nil))
+
+	mockClient.On("PutSuppressedDestination", mock.Anything, mock.MatchedBy(func(input *sesv2.PutSuppressedDestinationInput) bool {
+		return *input.EmailAddress == "bounce@example.com" && input.Reason == types.SuppressionListReasonBounce
+	}), mock.Anything).Return(&sesv2.PutSuppressedDestinationOutput{}, nil).Once()
+	mockClient.On("PutSuppressedDestination", mock.Anything, mock.MatchedBy(func(input *sesv2.PutSuppressedDestinationInput) bool {
+		return *input.EmailAddress == "c1@example.com" && input.Reason == types.SuppressionListReasonComplaint
+	}), mock.Anything).Return(nil, errors.New("throttled")).Once()
+	mockClient.On("PutSuppressedDestination", mock.Anything, mock.MatchedBy(func(input *sesv2.PutSuppressedDestinationInput) bool {
+		return *input.EmailAddress == "c2@example.com" && input.Reason == types.SuppressionListReasonComplaint
+	}), mock.Anything).Return(&sesv2.PutSuppressedDestinationOutput{}, nil).Once()
+
+	require.NoError(t, HandleAWSSESEvent(context.Background(), handler, []byte(testAWSSESBounceEvent)))
+	// Transient bounces aren't suppressed.
+	require.NoError(t, HandleAWSSESEvent(context.Background(), handler, []byte(`{"eventType": "Bounce",
+		"bounce": {"bounceType": "Transient", "bouncedRecipients": [{"emailAddress": "soft@example.com"}]}, "mail": {}}`)))
+	// All recipients are tried even when one of them fails.
+	err := HandleAWSSESEvent(context.Background(), handler, []byte(`{"eventType": "Complaint",
+		"complaint": {"complainedRecipients": [{"emailAddress": "c1@example.com"}, {"emailAddress": "c2@example.com"}]}, "mail": {}}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "throttled")
+
+	mockClient.AssertExpectations(t)
+}
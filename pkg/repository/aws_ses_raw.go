@@ -0,0 +1,307 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"maps"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/http"
+	"net/mail"
+	"net/textproto"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+)
+
+const (
+	// AWSSESMaxRawMessageSize is the maximum size of a raw message accepted by SES, including attachments.
+	AWSSESMaxRawMessageSize = 40 * 1024 * 1024
+	// awsSESBase64LineLength is the maximum line length of base64 encoded MIME bodies.
+	awsSESBase64LineLength = 76
+)
+
+var (
+	ErrAWSSESInvalidAddress   = errors.New("invalid email address")
+	ErrAWSSESInvalidHeader    = errors.New("invalid email header")
+	ErrAWSSESMessageTooLarge  = errors.New("raw message too large")
+	ErrAWSSESAttachmentNoName = errors.New("attachment file name is empty")
+)
+
+// AWSSESAttachment is a file attached to an email.
+type AWSSESAttachment struct {
+	Data     []byte `json:"data"`
+	FileName string `json:"fileName"`
+	// ContentType is detected from FileName or Data when empty.
+	ContentType string `json:"contentType,omitempty"`
+	// ContentID makes the attachment an inline image referenced in HTML as "cid:<ContentID>".
+	ContentID string `json:"contentId,omitempty"`
+}
+
+// AWSSESEmail is an email with optional CC/BCC recipients and attachments.
+type AWSSESEmail struct {
+	From        string             `json:"from"`
+	Subject     string             `json:"subject"`
+	Text        string             `json:"text,omitempty"`
+	HTML        string             `json:"html,omitempty"`
+	ReplyTo     []string           `json:"replyTo,omitempty"`
+	Attachments []AWSSESAttachment `json:"attachments,omitempty"`
+	Destination AWSSESDestination  `json:"destination"`
+}
+
+// awsSESMIMEPart is a MIME entity with its header and encoded body.
+type awsSESMIMEPart struct {
+	header textproto.MIMEHeader
+	body   []byte
+}
+
+// SendMessage sends email. An email without attachments is sent as simple content,
+// and an email with attachments is sent as a raw MIME message.
+func (r *AWSSESRepository) SendMessage(ctx context.Context, email *AWSSESEmail) (*sesv2.SendEmailOutput, error) {
+	if len(email.Attachments) > 0 {
+		data, err := BuildAWSSESRawMessage(email)
+		if err != nil {
+			return nil, fmt.Errorf("ses SendMessage: %w", err)
+		}
+		return r.SendRawEmail(ctx, email.From, &email.Destination, data)
+	}
+
+	body := &types.Body{}
+	if email.Text != "" {
+		body.Text = &types.Content{Charset: aws.String("UTF-8"), Data: aws.String(email.Text)}
+	}
+	if email.HTML != "" {
+		body.Html = &types.Content{Charset: aws.String("UTF-8"), Data: aws.String(email.HTML)}
+	}
+	res, err := r.Client.SendEmail(ctx, &sesv2.SendEmailInput{
+		ConfigurationSetName: r.configurationSetName,
+		FromEmailAddress:     aws.String(email.From),
+		Destination:          email.Destination.toAWSSESDestination(),
+		ReplyToAddresses:     email.ReplyTo,
+		Content: &types.EmailContent{
+			Simple: &types.Message{
+				Body:    body,
+				Subject: &types.Content{Charset: aws.String("UTF-8"), Data: aws.String(email.Subject)},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ses SendMessage: %w", err)
+	}
+	return res, nil
+}
+
+// SendRawEmail sends a raw MIME message, e.g. built by BuildAWSSESRawMessage.
+// destination sets the envelope recipients, so that BCC recipients not in the
+// message headers receive the email.
+func (r *AWSSESRepository) SendRawEmail(ctx context.Context, from string, destination *AWSSESDestination, data []byte) (*sesv2.SendEmailOutput, error) {
+	if len(data) > AWSSESMaxRawMessageSize {
+		return nil, fmt.Errorf("ses SendRawEmail: %w: %d bytes", ErrAWSSESMessageTooLarge, len(data))
+	}
+	in := &sesv2.SendEmailInput{
+		ConfigurationSetName: r.configurationSetName,
+		FromEmailAddress:     aws.String(from),
+		Content: &types.EmailContent{
+			Raw: &types.RawMessage{Data: data},
+		},
+	}
+	if destination != nil {
+		in.Destination = destination.toAWSSESDestination()
+	}
+	res, err := r.Client.SendEmail(ctx, in)
+	if err != nil {
+		return nil, fmt.Errorf("ses SendRawEmail: %w", err)
+	}
+	return res, nil
+}
+
+// BuildAWSSESRawMessage builds the MIME message of email.
+// The body is multipart/alternative when both Text and HTML are set, inline
+// images are added in multipart/related with the body, and the other
+// attachments in multipart/mixed. BCC recipients are not written in the headers.
+func BuildAWSSESRawMessage(email *AWSSESEmail) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeAWSSESHeaders(&buf, email); err != nil {
+		return nil, err
+	}
+
+	var bodies []awsSESMIMEPart
+	if email.Text != "" || email.HTML == "" {
+		bodies = append(bodies, newAWSSESTextPart("text/plain", email.Text))
+	}
+	if email.HTML != "" {
+		bodies = append(bodies, newAWSSESTextPart("text/html", email.HTML))
+	}
+	root, err := newAWSSESMultipart("alternative", bodies)
+	if err != nil {
+		return nil, err
+	}
+
+	inlines := []awsSESMIMEPart{root}
+	attachments := []awsSESMIMEPart{}
+	for i := range email.Attachments {
+		part, err := newAWSSESAttachmentPart(&email.Attachments[i])
+		if err != nil {
+			return nil, err
+		}
+		if email.Attachments[i].ContentID != "" {
+			inlines = append(inlines, part)
+		} else {
+			attachments = append(attachments, part)
+		}
+	}
+	if root, err = newAWSSESMultipart("related", inlines); err != nil {
+		return nil, err
+	}
+	if root, err = newAWSSESMultipart("mixed", append([]awsSESMIMEPart{root}, attachments...)); err != nil {
+		return nil, err
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(root.header)) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", k, root.header.Get(k))
+	}
+	buf.WriteString("\r\n")
+	buf.Write(root.body)
+	if buf.Len() > AWSSESMaxRawMessageSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrAWSSESMessageTooLarge, buf.Len())
+	}
+	return buf.Bytes(), nil
+}
+
+// writeAWSSESHeaders writes the message headers of email except the Content-* headers.
+func writeAWSSESHeaders(buf *bytes.Buffer, email *AWSSESEmail) error {
+	from, err := formatAWSSESAddresses([]string{email.From})
+	if err != nil {
+		return err
+	}
+	if strings.ContainsAny(email.Subject, "\r\n") {
+		return fmt.Errorf("%w: subject contains a line break", ErrAWSSESInvalidHeader)
+	}
+	headers := [][2]string{{"From", from}}
+	for _, h := range []struct {
+		name      string
+		addresses []string
+	}{
+		{"To", email.Destination.To},
+		{"Cc", email.Destination.Cc},
+		{"Reply-To", email.ReplyTo},
+	} {
+		if len(h.addresses) == 0 {
+			continue
+		}
+		v, err := formatAWSSESAddresses(h.addresses)
+		if err != nil {
+			return err
+		}
+		headers = append(headers, [2]string{h.name, v})
+	}
+	headers = append(headers,
+		[2]string{"Subject", mime.QEncoding.Encode("UTF-8", email.Subject)},
+		[2]string{"MIME-Version", "1.0"},
+	)
+	for _, h := range headers {
+		fmt.Fprintf(buf, "%s: %s\r\n", h[0], h[1])
+	}
+	return nil
+}
+
+// formatAWSSESAddresses returns addresses as a header value, encoding non-ASCII display names.
+func formatAWSSESAddresses(addresses []string) (string, error) {
+	res := make([]string, 0, len(addresses))
+	for _, a := range addresses {
+		addr, err := mail.ParseAddress(a)
+		if err != nil {
+			return "", fmt.Errorf("%w: %q: %w", ErrAWSSESInvalidAddress, a, err)
+		}
+		res = append(res, addr.String())
+	}
+	return strings.Join(res, ", "), nil
+}
+
+// newAWSSESTextPart returns a UTF-8 text part encoded in quoted-printable.
+func newAWSSESTextPart(contentType, text string) awsSESMIMEPart {
+	var buf bytes.Buffer
+	w := quotedprintable.NewWriter(&buf)
+	// Writes to bytes.Buffer don't fail.
+	_, _ = w.Write([]byte(text))
+	_ = w.Close()
+	return awsSESMIMEPart{
+		header: textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(contentType, map[string]string{"charset": "UTF-8"})},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		},
+		body: buf.Bytes(),
+	}
+}
+
+// newAWSSESAttachmentPart returns a base64 encoded attachment part.
+func newAWSSESAttachmentPart(a *AWSSESAttachment) (awsSESMIMEPart, error) {
+	if a.FileName == "" {
+		return awsSESMIMEPart{}, ErrAWSSESAttachmentNoName
+	}
+	if strings.ContainsAny(a.ContentID+a.ContentType, "\r\n<>") {
+		return awsSESMIMEPart{}, fmt.Errorf("%w: attachment %s", ErrAWSSESInvalidHeader, a.FileName)
+	}
+	contentType := a.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(a.FileName))
+	}
+	if contentType == "" {
+		contentType = http.DetectContentType(a.Data)
+	}
+	disposition := "attachment"
+	header := textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"base64"},
+	}
+	if a.ContentID != "" {
+		disposition = "inline"
+		header.Set("Content-Id", "<"+a.ContentID+">")
+	}
+	header.Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": a.FileName}))
+
+	encoded := base64.StdEncoding.EncodeToString(a.Data)
+	var buf bytes.Buffer
+	buf.Grow(len(encoded) + len(encoded)/awsSESBase64LineLength*2 + 2)
+	for start := 0; start < len(encoded); start += awsSESBase64LineLength {
+		buf.WriteString(encoded[start:min(start+awsSESBase64LineLength, len(encoded))])
+		buf.WriteString("\r\n")
+	}
+	return awsSESMIMEPart{header: header, body: buf.Bytes()}, nil
+}
+
+// newAWSSESMultipart returns a multipart entity of subtype containing parts.
+// A single part is returned as is.
+func newAWSSESMultipart(subtype string, parts []awsSESMIMEPart) (awsSESMIMEPart, error) {
+	if len(parts) == 1 {
+		return parts[0], nil
+	}
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for _, p := range parts {
+		pw, err := w.CreatePart(p.header)
+		if err != nil {
+			return awsSESMIMEPart{}, fmt.Errorf("create mime part: %w", err)
+		}
+		if _, err := pw.Write(p.body); err != nil {
+			return awsSESMIMEPart{}, fmt.Errorf("write mime part: %w", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return awsSESMIMEPart{}, fmt.Errorf("close multipart: %w", err)
+	}
+	return awsSESMIMEPart{
+		header: textproto.MIMEHeader{
+			"Content-Type": {mime.FormatMediaType("multipart/"+subtype, map[string]string{"boundary": w.Boundary()})},
+		},
+		body: buf.Bytes(),
+	}, nil
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// testAWSSESPart is a part of a multipart body read by readTestAWSSESMultipart.
+type testAWSSESPart struct {
+	header    textproto.MIMEHeader
+	mediaType string
+	body      []byte
+}
+
+// readTestAWSSESMultipart returns the raw parts of a multipart body.
+func readTestAWSSESMultipart(t *testing.T, contentType string, body []byte) []testAWSSESPart {
+	t.Helper()
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(mediaType, "multipart/"), mediaType)
+
+	var parts []testAWSSESPart
+	r := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	for {
+		p, err := r.NextRawPart()
+		if err == io.EOF {
+			return parts
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(p)
+		require.NoError(t, err)
+		mt, _, _ := mime.ParseMediaType(p.Header.Get("Content-Type"))
+		parts = append(parts, testAWSSESPart{header: p.Header, mediaType: mt, body: data})
+	}
+}
+
+// testAWSSESMediaTypes returns the media types of parts.
+func testAWSSESMediaTypes(parts []testAWSSESPart) []string {
+	res := make([]string, 0, len(parts))
+	for _, p := range parts {
+		res = append(res, p.mediaType)
+	}
+	return res
+}
+
+func TestBuildAWSSESRawMessage(t *testing.T) {
+	logo := bytes.Repeat([]byte{0x89, 'P', 'N', 'G'}, 100)
+	data, err := BuildAWSSESRawMessage(&AWSSESEmail{
+		From:    "Sender Name <sender@example.com>",
+		Subject: "Report für März",
+		Text:    "Hello",
+		HTML:    `<p>Hello <img src="cid:logo"></p>`,
+		ReplyTo: []string{"reply@example.com"},
+		Destination: AWSSESDestination{
+			To:  []string{"José <to@example.com>"},
+			Cc:  []string{"cc@example.com"},
+			Bcc: []string{"bcc@example.com"},
+		},
+		Attachments: []AWSSESAttachment{
+			{FileName: "report.csv", Data: []byte("a,b\n1,2\n")},
+			{FileName: "logo.png", Data: logo, ContentID: "logo"},
+		},
+	})
+	require.NoError(t, err)
+
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, `"Sender Name" <sender@example.com>`, msg.Header.Get("From"))
+	assert.Equal(t, "<cc@example.com>", msg.Header.Get("Cc"))
+	assert.Equal(t, "<reply@example.com>", msg.Header.Get("Reply-To"))
+	assert.Empty(t, msg.Header.Get("Bcc"))
+	assert.NotContains(t, string(data), "bcc@example.com")
+	to, err := msg.Header.AddressList("To")
+	require.NoError(t, err)
+	assert.Equal(t, "José", to[0].Name)
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Report für März", subject)
+
+	// multipart/mixed > [multipart/related > [multipart/alternative, logo], report]
+	body, err := io.ReadAll(msg.Body)
+	require.NoError(t, err)
+	mixed := readTestAWSSESMultipart(t, msg.Header.Get("Content-Type"), body)
+	assert.Equal(t, []string{"multipart/related", "text/csv"}, testAWSSESMediaTypes(mixed))
+	assert.Equal(t, "attachment; filename=report.csv", mixed[1].header.Get("Content-Disposition"))
+
+	related := readTestAWSSESMultipart(t, mixed[0].header.Get("Content-Type"), mixed[0].body)
+	assert.Equal(t, []string{"multipart/alternative", "image/png"}, testAWSSESMediaTypes(related))
+	assert.Equal(t, "<logo>", related[1].header.Get("Content-Id"))
+	assert.Equal(t, "inline; filename=logo.png", related[1].header.Get("Content-Disposition"))
+	for line := range strings.Lines(string(related[1].body)) {
+		assert.LessOrEqual(t, len(strings.TrimRight(line, "\r\n")), 76)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(related[1].body), "\r\n", ""))
+	require.NoError(t, err)
+	assert.Equal(t, logo, decoded)
+
+	alternative := readTestAWSSESMultipart(t, related[0].header.Get("Content-Type"), related[0].body)
+	assert.Equal(t, []string{"text/plain", "text/html"}, testAWSSESMediaTypes(alternative))
+}
+
+func TestBuildAWSSESRawMessage_TextOnly(t *testing.T) {
+	data, err := BuildAWSSESRawMessage(&AWSSESEmail{
+		From:        "sender@example.com",
+		Subject:     "Hello",
+		Text:        "Hello",
+		Destination: AWSSESDestination{To: []string{"to@example.com"}},
+	})
+	require.NoError(t, err)
+
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain; charset=UTF-8", msg.Header.Get("Content-Type"))
+	assert.Equal(t, "quoted-printable", msg.Header.Get("Content-Transfer-Encoding"))
+}
+
+func TestBuildAWSSESRawMessage_Invalid(t *testing.T) {
+	tests := []struct {
+		want  error
+		email *AWSSESEmail
+		name  string
+	}{
+		{
+			name:  "invalid from",
+			email: &AWSSESEmail{From: "not an address"},
+			want:  ErrAWSSESInvalidAddress,
+		},
+		{
+			name:  "invalid cc",
+			email: &AWSSESEmail{From: "sender@example.com", Destination: AWSSESDestination{Cc: []string{"x"}}},
+			want:  ErrAWSSESInvalidAddress,
+		},
+		{
+			name:  "subject injection",
+			email: &AWSSESEmail{From: "sender@example.com", Subject: "Hi\r\nBcc: victim@example.com"},
+			want:  ErrAWSSESInvalidHeader,
+		},
+		{
+			name:  "attachment without name",
+			email: &AWSSESEmail{From: "sender@example.com", Attachments: []AWSSESAttachment{{Data: []byte("x")}}},
+			want:  ErrAWSSESAttachmentNoName,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := BuildAWSSESRawMessage(tt.email)
+			require.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestAWSSESRepository_SendMessage_Simple(t *testing.T) {
+	mockClient := &MockSESClient{}
+	repo := NewAWSSESRepositoryWithInterface(mockClient, nil)
+
+	mockClient.On("SendEmail", mock.Anything, mock.MatchedBy(func(input *sesv2.SendEmailInput) bool {
+		return input.Content.Simple != nil &&
+			input.Content.Simple.Body.Html == nil &&
+			*input.Content.Simple.Body.Text.Data == "Hello" &&
+			input.Destination.CcAddresses[0] == "cc@example.com" &&
+			input.Destination.BccAddresses[0] == "bcc@example.com"
+	}), mock.Anything).Return(&sesv2.SendEmailOutput{MessageId: aws.String("message-id")}, nil)
+
+	_, err := repo.SendMessage(context.Background(), &AWSSESEmail{
+		From:    "sender@example.com",
+		Subject: "Hello",
+		Text:    "Hello",
+		Destination: AWSSESDestination{
+			To:  []string{"to@example.com"},
+			Cc:  []string{"cc@example.com"},
+			Bcc: []string{"bcc@example.com"},
+		},
+	})
+
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSSESRepository_SendMessage_Raw(t *testing.T) {
+	mockClient := &MockSESClient{}
+	configSet := "test-config-set"
+	repo := NewAWSSESRepositoryWithInterface(mockClient, &configSet)
+
+	mockClient.On("SendEmail", mock.Anything, mock.MatchedBy(func(input *sesv2.SendEmailInput) bool {
+		return input.Content.Raw != nil &&
+			bytes.Contains(input.Content.Raw.Data, []byte("filename=a.txt")) &&
+			*input.ConfigurationSetName == configSet &&
+			input.Destination.BccAddresses[0] == "bcc@example.com"
+	}), mock.Anything).Return(&sesv2.SendEmailOutput{MessageId: aws.String("message-id")}, nil)
+
+	_, err := repo.SendMessage(context.Background(), &AWSSESEmail{
+		From:        "sender@example.com",
+		Subject:     "Hello",
+		Text:        "Hello",
+		Destination: AWSSESDestination{To: []string{"to@example.com"}, Bcc: []string{"bcc@example.com"}},
+		Attachments: []AWSSESAttachment{{FileName: "a.txt", Data: []byte("x")}},
+	})
+
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSSESRepository_SendRawEmail_TooLarge(t *testing.T) {
+	mockClient := &MockSESClient{}
+	repo := NewAWSSESRepositoryWithInterface(mockClient, nil)
+
+	_, err := repo.SendRawEmail(context.Background(), "sender@example.com", nil, make([]byte, AWSSESMaxRawMessageSize+1))
+
+	require.ErrorIs(t, err, ErrAWSSESMessageTooLarge)
+	mockClient.AssertNotCalled(t, "SendEmail", mock.Anything, mock.Anything, mock.Anything)
+}
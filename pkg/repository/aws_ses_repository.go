@@ -2,9 +2,11 @@
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	"github.com/aws/aws-sdk-go-v2/service/sesv2"
 	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
 )
@@ -15,6 +17,28 @@ type AWSSESClientInterface interface {
 	SendBulkEmail(_ context.Context, _ *sesv2.SendBulkEmailInput, _ ...func(*sesv2.Options)) (*sesv2.SendBulkEmailOutput, error)
 	// SendEmail sends an email using Amazon SES
 	SendEmail(_ context.Context, _ *sesv2.SendEmailInput, _ ...func(*sesv2.Options)) (*sesv2.SendEmailOutput, error)
+	// CreateEmailTemplate creates an email template
+	CreateEmailTemplate(_ context.Context, _ *sesv2.CreateEmailTemplateInput, _ ...func(*sesv2.Options)) (*sesv2.CreateEmailTemplateOutput, error)
+	// UpdateEmailTemplate updates an email template
+	UpdateEmailTemplate(_ context.Context, _ *sesv2.UpdateEmailTemplateInput, _ ...func(*sesv2.Options)) (*sesv2.UpdateEmailTemplateOutput, error)
+	// GetEmailTemplate gets an email template
+	GetEmailTemplate(_ context.Context, _ *sesv2.GetEmailTemplateInput, _ ...func(*sesv2.Options)) (*sesv2.GetEmailTemplateOutput, error)
+	// DeleteEmailTemplate deletes an email template
+	DeleteEmailTemplate(_ context.Context, _ *sesv2.DeleteEmailTemplateInput, _ ...func(*sesv2.Options)) (*sesv2.DeleteEmailTemplateOutput, error)
+	// TestRenderEmailTemplate renders an email template with template data
+	TestRenderEmailTemplate(_ context.Context, _ *sesv2.TestRenderEmailTemplateInput, _ ...func(*sesv2.Options)) (*sesv2.TestRenderEmailTemplateOutput, error)
+	// PutSuppressedDestination adds an email address to the suppression list
+	PutSuppressedDestination(_ context.Context, _ *sesv2.PutSuppressedDestinationInput, _ ...func(*sesv2.Options)) (*sesv2.PutSuppressedDestinationOutput, error)
+	// GetSuppressedDestination gets an email address on the suppression list
+	GetSuppressedDestination(_ context.Context, _ *sesv2.GetSuppressedDestinationInput, _ ...func(*sesv2.Options)) (*sesv2.GetSuppressedDestinationOutput, error)
+	// DeleteSuppressedDestination removes an email address from the suppression list
+	DeleteSuppressedDestination(_ context.Context, _ *sesv2.DeleteSuppressedDestinationInput, _ ...func(*sesv2.Options)) (*sesv2.DeleteSuppressedDestinationOutput, error)
+	// ListSuppressedDestinations lists email addresses on the suppression list
+	ListSuppressedDestinations(_ context.Context, _ *sesv2.ListSuppressedDestinationsInput, _ ...func(*sesv2.Options)) (*sesv2.ListSuppressedDestinationsOutput, error)
+	// PutAccountSuppressionAttributes sets the account-level suppression reasons
+	PutAccountSuppressionAttributes(_ context.Context, _ *sesv2.PutAccountSuppressionAttributesInput, _ ...func(*sesv2.Options)) (*sesv2.PutAccountSuppressionAttributesOutput, error)
+	// PutConfigurationSetSuppressionOptions sets the suppression reasons of a configuration set
+	PutConfigurationSetSuppressionOptions(_ context.Context, _ *sesv2.PutConfigurationSetSuppressionOptionsInput, _ ...func(*sesv2.Options)) (*sesv2.PutConfigurationSetSuppressionOptionsOutput, error)
 }
 
 // AWSSESRepository struct.
@@ -39,19 +63,28 @@ func NewAWSSESRepositoryWithInterface(c AWSSESClientInterface, configurationSetN
 	}
 }
 
-// SendBulkEmail sends bulk emails.
+// SendBulkEmail sends bulk emails with the template named template, or with
+// the template of the ARN when template is an ARN.
 // Note: One or more Destination objects. All of the recipients in a Destination receive the same version of the email.
 // You can specify up to 50 Destination objects within a Destinations array.
-func (r *AWSSESRepository) SendBulkEmail(ctx context.Context, from string, replyTo []string, defaultTemplateData string, bulkEmailEntries []types.BulkEmailEntry) (*sesv2.SendBulkEmailOutput, error) {
+// Use SendBulkTemplatedEmail to send more entries with structured template data.
+func (r *AWSSESRepository) SendBulkEmail(ctx context.Context, from string, replyTo []string, template, defaultTemplateData string, bulkEmailEntries []types.BulkEmailEntry) (*sesv2.SendBulkEmailOutput, error) {
+	var name, templateARN string
+	if arn.IsARN(template) {
+		templateARN = template
+	} else {
+		name = template
+	}
+	t, err := newAWSSESTemplate(name, templateARN, defaultTemplateData)
+	if err != nil {
+		return nil, fmt.Errorf("ses SendBulkEmail: %w", err)
+	}
 	res, err := r.Client.SendBulkEmail(ctx, &sesv2.SendBulkEmailInput{
-		FromEmailAddress: aws.String(from),
-		ReplyToAddresses: replyTo,
-		DefaultContent: &types.BulkEmailContent{
-			Template: &types.Template{
-				TemplateData: aws.String(defaultTemplateData),
-			},
-		},
-		BulkEmailEntries: bulkEmailEntries,
+		ConfigurationSetName: r.configurationSetName,
+		FromEmailAddress:     aws.String(from),
+		ReplyToAddresses:     replyTo,
+		DefaultContent:       &types.BulkEmailContent{Template: t},
+		BulkEmailEntries:     bulkEmailEntries,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("ses SendBulkEmail: %w", err)
@@ -152,3 +185,36 @@ func (r *AWSSESRepository) SendTextEmail(ctx context.Context, from string, to, r
 	}
 	return res, nil
 }
+
+// SendRenderedEmail sends email rendered by EmailTemplateRenderer.
+// Unlike SendEmail, an empty text or HTML body is omitted.
+func (r *AWSSESRepository) SendRenderedEmail(ctx context.Context, from string, to, replyTo []string, email *RenderedEmail) (*sesv2.SendEmailOutput, error) {
+	return r.SendMessage(ctx, &AWSSESEmail{
+		From:        from,
+		Subject:     email.Subject,
+		Text:        email.Text,
+		HTML:        email.HTML,
+		ReplyTo:     replyTo,
+		Destination: AWSSESDestination{To: to},
+	})
+}
+
+// IsAWSSESPermanentError reports whether sending an email failed because of the
+// email or the account, so that sending the same email again would fail too.
+func IsAWSSESPermanentError(err error) bool {
+	var rejected *types.MessageRejected
+	var mailFrom *types.MailFromDomainNotVerifiedException
+	var badRequest *types.BadRequestException
+	var suspended *types.AccountSuspendedException
+	var notFound *types.NotFoundException
+	return errors.As(err, &rejected) ||
+		errors.As(err, &mailFrom) ||
+		errors.As(err, &badRequest) ||
+		errors.As(err, &suspended) ||
+		errors.As(err, &notFound) ||
+		errors.Is(err, ErrAWSSESInvalidAddress) ||
+		errors.Is(err, ErrAWSSESInvalidHeader) ||
+		errors.Is(err, ErrAWSSESMessageTooLarge) ||
+		errors.Is(err, ErrAWSSESAttachmentNoName) ||
+		errors.Is(err, ErrAWSSESTemplateNotSet)
+}
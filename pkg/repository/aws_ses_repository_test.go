@@ -34,6 +34,94 @@ func (m *MockSESClient) SendBulkEmail(ctx context.Context, input *sesv2.SendBulk
 	return args.Get(0).(*sesv2.SendBulkEmailOutput), args.Error(1)
 }
 
+func (m *MockSESClient) CreateEmailTemplate(ctx context.Context, input *sesv2.CreateEmailTemplateInput, opts ...func(*sesv2.Options)) (*sesv2.CreateEmailTemplateOutput, error) {
+	args := m.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sesv2.CreateEmailTemplateOutput), args.Error(1)
+}
+
+func (m *MockSESClient) UpdateEmailTemplate(ctx context.Context, input *sesv2.UpdateEmailTemplateInput, opts ...func(*sesv2.Options)) (*sesv2.UpdateEmailTemplateOutput, error) {
+	args := m.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sesv2.UpdateEmailTemplateOutput), args.Error(1)
+}
+
+func (m *MockSESClient) GetEmailTemplate(ctx context.Context, input *sesv2.GetEmailTemplateInput, opts ...func(*sesv2.Options)) (*sesv2.GetEmailTemplateOutput, error) {
+	args := m.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sesv2.GetEmailTemplateOutput), args.Error(1)
+}
+
+func (m *MockSESClient) DeleteEmailTemplate(ctx context.Context, input *sesv2.DeleteEmailTemplateInput, opts ...func(*sesv2.Options)) (*sesv2.DeleteEmailTemplateOutput, error) {
+	args := m.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sesv2.DeleteEmailTemplateOutput), args.Error(1)
+}
+
+func (m *MockSESClient) TestRenderEmailTemplate(ctx context.Context, input *sesv2.TestRenderEmailTemplateInput, opts ...func(*sesv2.Options)) (*sesv2.TestRenderEmailTemplateOutput, error) {
+	args := m.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sesv2.TestRenderEmailTemplateOutput), args.Error(1)
+}
+
+func (m *MockSESClient) PutSuppressedDestination(ctx context.Context, input *sesv2.PutSuppressedDestinationInput, opts ...func(*sesv2.Options)) (*sesv2.PutSuppressedDestinationOutput, error) {
+	args := m.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sesv2.PutSuppressedDestinationOutput), args.Error(1)
+}
+
+func (m *MockSESClient) GetSuppressedDestination(ctx context.Context, input *sesv2.GetSuppressedDestinationInput, opts ...func(*sesv2.Options)) (*sesv2.GetSuppressedDestinationOutput, error) {
+	args := m.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sesv2.GetSuppressedDestinationOutput), args.Error(1)
+}
+
+func (m *MockSESClient) DeleteSuppressedDestination(ctx context.Context, input *sesv2.DeleteSuppressedDestinationInput, opts ...func(*sesv2.Options)) (*sesv2.DeleteSuppressedDestinationOutput, error) {
+	args := m.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sesv2.DeleteSuppressedDestinationOutput), args.Error(1)
+}
+
+func (m *MockSESClient) ListSuppressedDestinations(ctx context.Context, input *sesv2.ListSuppressedDestinationsInput, opts ...func(*sesv2.Options)) (*sesv2.ListSuppressedDestinationsOutput, error) {
+	args := m.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sesv2.ListSuppressedDestinationsOutput), args.Error(1)
+}
+
+func (m *MockSESClient) PutAccountSuppressionAttributes(ctx context.Context, input *sesv2.PutAccountSuppressionAttributesInput, opts ...func(*sesv2.Options)) (*sesv2.PutAccountSuppressionAttributesOutput, error) {
+	args := m.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sesv2.PutAccountSuppressionAttributesOutput), args.Error(1)
+}
+
+func (m *MockSESClient) PutConfigurationSetSuppressionOptions(ctx context.Context, input *sesv2.PutConfigurationSetSuppressionOptionsInput, opts ...func(*sesv2.Options)) (*sesv2.PutConfigurationSetSuppressionOptionsOutput, error) {
+	args := m.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sesv2.PutConfigurationSetSuppressionOptionsOutput), args.Error(1)
+}
+
 // AWSSESRepositoryWithMock for testing with mock client
 type AWSSESRepositoryWithMock struct {
 	Client               AWSSESClientInterface
@@ -143,13 +231,14 @@ func (r *AWSSESRepositoryWithMock) SendEmail(ctx context.Context, from string, t
 }
 
 // SendBulkEmail sends bulk email.
-func (r *AWSSESRepositoryWithMock) SendBulkEmail(ctx context.Context, from string, replyTo []string, defaultTemplateData string, bulkEmailEntries []types.BulkEmailEntry) (*sesv2.SendBulkEmailOutput, error) {
+func (r *AWSSESRepositoryWithMock) SendBulkEmail(ctx context.Context, from string, replyTo []string, template, defaultTemplateData string, bulkEmailEntries []types.BulkEmailEntry) (*sesv2.SendBulkEmailOutput, error) {
 	res, err := r.Client.SendBulkEmail(ctx, &sesv2.SendBulkEmailInput{
 		ConfigurationSetName: r.configurationSetName,
 		FromEmailAddress:     aws.String(from),
 		ReplyToAddresses:     replyTo,
 		DefaultContent: &types.BulkEmailContent{
 			Template: &types.Template{
+				TemplateName: aws.String(template),
 				TemplateData: aws.String(defaultTemplateData),
 			},
 		},
@@ -378,10 +467,12 @@ func TestAWSSESRepository_SendBulkEmail(t *testing.T) {
 	mockClient.On("SendBulkEmail", mock.Anything, mock.MatchedBy(func(input *sesv2.SendBulkEmailInput) bool {
 		return *input.FromEmailAddress == from &&
 			len(input.BulkEmailEntries) == 2 &&
-			*input.DefaultContent.Template.TemplateData == defaultTemplateData
+			*input.DefaultContent.Template.TemplateData == defaultTemplateData &&
+			aws.ToString(input.DefaultContent.Template.TemplateName) == "welcome" &&
+			input.DefaultContent.Template.TemplateArn == nil
 	}), mock.Anything).Return(expectedOutput, nil)
 
-	result, err := repo.SendBulkEmail(context.Background(), from, replyTo, defaultTemplateData, bulkEmailEntries)
+	result, err := repo.SendBulkEmail(context.Background(), from, replyTo, "welcome", defaultTemplateData, bulkEmailEntries)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedOutput, result)
@@ -389,6 +480,32 @@ func TestAWSSESRepository_SendBulkEmail(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
+func TestAWSSESRepository_SendBulkEmail_TemplateARN(t *testing.T) {
+	mockClient := &MockSESClient{}
+	repo := NewAWSSESRepositoryWithInterface(mockClient, nil)
+
+	templateARN := "arn:aws:ses:us-east-1:123456789012:template/welcome"
+	mockClient.On("SendBulkEmail", mock.Anything, mock.MatchedBy(func(input *sesv2.SendBulkEmailInput) bool {
+		return aws.ToString(input.DefaultContent.Template.TemplateArn) == templateARN &&
+			input.DefaultContent.Template.TemplateName == nil
+	}), mock.Anything).Return(&sesv2.SendBulkEmailOutput{}, nil)
+
+	_, err := repo.SendBulkEmail(context.Background(), "sender@example.com", nil, templateARN, "{}", nil)
+
+	assert.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSSESRepository_SendBulkEmail_TemplateNotSet(t *testing.T) {
+	mockClient := &MockSESClient{}
+	repo := NewAWSSESRepositoryWithInterface(mockClient, nil)
+
+	_, err := repo.SendBulkEmail(context.Background(), "sender@example.com", nil, "", "{}", nil)
+
+	assert.ErrorIs(t, err, ErrAWSSESTemplateNotSet)
+	mockClient.AssertNotCalled(t, "SendBulkEmail", mock.Anything, mock.Anything, mock.Anything)
+}
+
 // Test actual AWSSESRepository functions
 func TestAWSSESRepositoryReal_SendTextEmail(t *testing.T) {
 	if testing.Short() {
@@ -465,7 +582,7 @@ func TestAWSSESRepository_SendBulkEmail_EmptyEntries(t *testing.T) {
 
 	mockClient.On("SendBulkEmail", mock.Anything, mock.Anything, mock.Anything).Return(expectedOutput, nil)
 
-	result, err := repo.SendBulkEmail(context.Background(), from, replyTo, defaultTemplateData, bulkEmailEntries)
+	result, err := repo.SendBulkEmail(context.Background(), from, replyTo, "welcome", defaultTemplateData, bulkEmailEntries)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedOutput, result)
@@ -499,7 +616,7 @@ func TestAWSSESRepository_SendBulkEmail_LargeBatch(t *testing.T) {
 
 	mockClient.On("SendBulkEmail", mock.Anything, mock.Anything, mock.Anything).Return(expectedOutput, nil)
 
-	result, err := repo.SendBulkEmail(context.Background(), from, replyTo, defaultTemplateData, bulkEmailEntries)
+	result, err := repo.SendBulkEmail(context.Background(), from, replyTo, "welcome", defaultTemplateData, bulkEmailEntries)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedOutput, result)
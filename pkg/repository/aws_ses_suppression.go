@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+)
+
+// AWSSESSuppressedDestination is an email address on the account-level suppression list.
+type AWSSESSuppressedDestination struct {
+	LastUpdateTime time.Time
+	EmailAddress   string
+	Reason         types.SuppressionListReason
+	// MessageID and FeedbackID identify the email that caused the suppression. They are set by GetSuppressedDestination only.
+	MessageID  string
+	FeedbackID string
+}
+
+// AWSSESSuppressionListFilter filters ListSuppressedDestinations.
+type AWSSESSuppressionListFilter struct {
+	// StartDate and EndDate filter by the time the address was added to the list.
+	StartDate *time.Time
+	EndDate   *time.Time
+	Reasons   []types.SuppressionListReason
+}
+
+// PutSuppressedDestination adds emailAddress to the account-level suppression list.
+func (r *AWSSESRepository) PutSuppressedDestination(ctx context.Context, emailAddress string, reason types.SuppressionListReason) error {
+	_, err := r.Client.PutSuppressedDestination(ctx, &sesv2.PutSuppressedDestinationInput{
+		EmailAddress: aws.String(emailAddress),
+		Reason:       reason,
+	})
+	if err != nil {
+		return fmt.Errorf("ses PutSuppressedDestination: %w", err)
+	}
+	return nil
+}
+
+// GetSuppressedDestination returns emailAddress on the account-level suppression list.
+// The error satisfies IsNotFound when emailAddress isn't suppressed.
+func (r *AWSSESRepository) GetSuppressedDestination(ctx context.Context, emailAddress string) (*AWSSESSuppressedDestination, error) {
+	res, err := r.Client.GetSuppressedDestination(ctx, &sesv2.GetSuppressedDestinationInput{
+		EmailAddress: aws.String(emailAddress),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ses GetSuppressedDestination: %w", err)
+	}
+	d := res.SuppressedDestination
+	if d == nil {
+		return nil, fmt.Errorf("ses GetSuppressedDestination: %w", ErrNotFound)
+	}
+	dest := &AWSSESSuppressedDestination{
+		EmailAddress:   aws.ToString(d.EmailAddress),
+		LastUpdateTime: aws.ToTime(d.LastUpdateTime),
+		Reason:         d.Reason,
+	}
+	if d.Attributes != nil {
+		dest.MessageID = aws.ToString(d.Attributes.MessageId)
+		dest.FeedbackID = aws.ToString(d.Attributes.FeedbackId)
+	}
+	return dest, nil
+}
+
+// IsSuppressed reports whether emailAddress is on the account-level suppression list.
+func (r *AWSSESRepository) IsSuppressed(ctx context.Context, emailAddress string) (bool, error) {
+	_, err := r.GetSuppressedDestination(ctx, emailAddress)
+	if IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DeleteSuppressedDestination removes emailAddress from the account-level suppression list.
+func (r *AWSSESRepository) DeleteSuppressedDestination(ctx context.Context, emailAddress string) error {
+	_, err := r.Client.DeleteSuppressedDestination(ctx, &sesv2.DeleteSuppressedDestinationInput{
+		EmailAddress: aws.String(emailAddress),
+	})
+	if err != nil {
+		return fmt.Errorf("ses DeleteSuppressedDestination: %w", err)
+	}
+	return nil
+}
+
+// ListSuppressedDestinations lists all addresses on the account-level suppression list
+// matching filter, following pagination. A nil filter lists all addresses.
+func (r *AWSSESRepository) ListSuppressedDestinations(ctx context.Context, filter *AWSSESSuppressionListFilter) ([]AWSSESSuppressedDestination, error) {
+	input := &sesv2.ListSuppressedDestinationsInput{}
+	if filter != nil {
+		input.StartDate = filter.StartDate
+		input.EndDate = filter.EndDate
+		input.Reasons = filter.Reasons
+	}
+	var dests []AWSSESSuppressedDestination
+	for {
+		res, err := r.Client.ListSuppressedDestinations(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("ses ListSuppressedDestinations: %w", err)
+		}
+		for i := range res.SuppressedDestinationSummaries {
+			s := &res.SuppressedDestinationSummaries[i]
+			dests = append(dests, AWSSESSuppressedDestination{
+				EmailAddress:   aws.ToString(s.EmailAddress),
+				LastUpdateTime: aws.ToTime(s.LastUpdateTime),
+				Reason:         s.Reason,
+			})
+		}
+		if aws.ToString(res.NextToken) == "" {
+			return dests, nil
+		}
+		input.NextToken = res.NextToken
+	}
+}
+
+// PutAccountSuppressionReasons sets the reasons for which SES adds addresses to the
+// account-level suppression list automatically. No reason disables it.
+func (r *AWSSESRepository) PutAccountSuppressionReasons(ctx context.Context, reasons ...types.SuppressionListReason) error {
+	_, err := r.Client.PutAccountSuppressionAttributes(ctx, &sesv2.PutAccountSuppressionAttributesInput{
+		SuppressedReasons: nonNilAWSSESSuppressionReasons(reasons),
+	})
+	if err != nil {
+		return fmt.Errorf("ses PutAccountSuppressionAttributes: %w", err)
+	}
+	return nil
+}
+
+// PutConfigurationSetSuppressionReasons overrides the account-level suppression reasons
+// for emails sent with configurationSetName. No reason disables suppression for it.
+func (r *AWSSESRepository) PutConfigurationSetSuppressionReasons(ctx context.Context, configurationSetName string, reasons ...types.SuppressionListReason) error {
+	_, err := r.Client.PutConfigurationSetSuppressionOptions(ctx, &sesv2.PutConfigurationSetSuppressionOptionsInput{
+		ConfigurationSetName: aws.String(configurationSetName),
+		SuppressedReasons:    nonNilAWSSESSuppressionReasons(reasons),
+	})
+	if err != nil {
+		return fmt.Errorf("ses PutConfigurationSetSuppressionOptions: %w", err)
+	}
+	return nil
+}
+
+// nonNilAWSSESSuppressionReasons returns reasons, or an empty slice for nil, so that
+// no reason is sent as an empty list instead of being omitted.
+func nonNilAWSSESSuppressionReasons(reasons []types.SuppressionListReason) []types.SuppressionListReason {
+	if reasons == nil {
+		return []types.SuppressionListReason{}
+	}
+	return reasons
+}
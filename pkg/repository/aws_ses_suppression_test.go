@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAWSSESRepository_PutSuppressedDestination(t *testing.T) {
+	mockClient := &MockSESClient{}
+	repo := NewAWSSESRepositoryWithInterface(mockClient, nil)
+
+	mockClient.On("PutSuppressedDestination", mock.Anything, mock.MatchedBy(func(input *sesv2.PutSuppressedDestinationInput) bool {
+		return *input.EmailAddress == "user@example.com" && input.Reason == types.SuppressionListReasonBounce
+	}), mock.Anything).Return(&sesv2.PutSuppressedDestinationOutput{}, nil)
+
+	err := repo.PutSuppressedDestination(context.Background(), "user@example.com", types.SuppressionListReasonBounce)
+
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSSESRepository_GetSuppressedDestination(t *testing.T) {
+	mockClient := &MockSESClient{}
+	repo := NewAWSSESRepositoryWithInterface(mockClient, nil)
+	updated := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	mockClient.On("GetSuppressedDestination", mock.Anything, mock.Anything, mock.Anything).Return(&sesv2.GetSuppressedDestinationOutput{
+		SuppressedDestination: &types.SuppressedDestination{
+			EmailAddress:   aws.String("user@example.com"),
+			LastUpdateTime: &updated,
+			Reason:         types.SuppressionListReasonComplaint,
+			Attributes: &types.SuppressedDestinationAttributes{
+				MessageId:  aws.String("message-id"),
+				FeedbackId: aws.String("feedback-id"),
+			},
+		},
+	}, nil)
+
+	dest, err := repo.GetSuppressedDestination(context.Background(), "user@example.com")
+
+	require.NoError(t, err)
+	assert.Equal(t, &AWSSESSuppressedDestination{
+		EmailAddress:   "user@example.com",
+		LastUpdateTime: updated,
+		Reason:         types.SuppressionListReasonComplaint,
+		MessageID:      "message-id",
+		FeedbackID:     "feedback-id",
+	}, dest)
+}
+
+func TestAWSSESRepository_IsSuppressed(t *testing.T) {
+	tests := []struct {
+		err     error
+		out     *sesv2.GetSuppressedDestinationOutput
+		name    string
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "suppressed",
+			out: &sesv2.GetSuppressedDestinationOutput{
+				SuppressedDestination: &types.SuppressedDestination{EmailAddress: aws.String("user@example.com")},
+			},
+			want: true,
+		},
+		{
+			name: "not suppressed",
+			err:  &types.NotFoundException{Message: aws.String("not found")},
+		},
+		{
+			name:    "error",
+			err:     errors.New("access denied"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockSESClient{}
+			repo := NewAWSSESRepositoryWithInterface(mockClient, nil)
+			if tt.out != nil {
+				mockClient.On("GetSuppressedDestination", mock.Anything, mock.Anything, mock.Anything).Return(tt.out, nil)
+			} else {
+				mockClient.On("GetSuppressedDestination", mock.Anything, mock.Anything, mock.Anything).Return(nil, tt.err)
+			}
+
+			got, err := repo.IsSuppressed(context.Background(), "user@example.com")
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAWSSESRepository_DeleteSuppressedDestination_Error(t *testing.T) {
+	mockClient := &MockSESClient{}
+	repo := NewAWSSESRepositoryWithInterface(mockClient, nil)
+
+	mockClient.On("DeleteSuppressedDestination", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, errors.New("throttled"))
+
+	err := repo.DeleteSuppressedDestination(context.Background(), "user@example.com")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ses DeleteSuppressedDestination")
+}
+
+func TestAWSSESRepository_ListSuppressedDestinations(t *testing.T) {
+	mockClient := &MockSESClient{}
+	repo := NewAWSSESRepositoryWithInterface(mockClient, nil)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mockClient.On("ListSuppressedDestinations", mock.Anything, mock.MatchedBy(func(input *sesv2.ListSuppressedDestinationsInput) bool {
+		return input.NextToken == nil && input.StartDate.Equal(start) && input.Reasons[0] == types.SuppressionListReasonBounce
+	}), mock.Anything).Return(&sesv2.ListSuppressedDestinationsOutput{
+		SuppressedDestinationSummaries: []types.SuppressedDestinationSummary{
+			{EmailAddress: aws.String("a@example.com"), Reason: types.SuppressionListReasonBounce},
+		},
+		NextToken: aws.String("next"),
+	}, nil).Once()
+	mockClient.On("ListSuppressedDestinations", mock.Anything, mock.MatchedBy(func(input *sesv2.ListSuppressedDestinationsInput) bool {
+		return aws.ToString(input.NextToken) == "next"
+	}), mock.Anything).Return(&sesv2.ListSuppressedDestinationsOutput{
+		SuppressedDestinationSummaries: []types.SuppressedDestinationSummary{
+			{EmailAddress: aws.String("b@example.com"), Reason: types.SuppressionListReasonBounce},
+		},
+	}, nil).Once()
+
+	dests, err := repo.ListSuppressedDestinations(context.Background(), &AWSSESSuppressionListFilter{
+		StartDate: &start,
+		Reasons:   []types.SuppressionListReason{types.SuppressionListReasonBounce},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, dests, 2)
+	assert.Equal(t, "a@example.com", dests[0].EmailAddress)
+	assert.Equal(t, "b@example.com", dests[1].EmailAddress)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSSESRepository_PutSuppressionReasons(t *testing.T) {
+	mockClient := &MockSESClient{}
+	repo := NewAWSSESRepositoryWithInterface(mockClient, nil)
+
+	mockClient.On("PutAccountSuppressionAttributes", mock.Anything, mock.MatchedBy(func(input *sesv2.PutAccountSuppressionAttributesInput) bool {
+		return len(input.SuppressedReasons) == 2
+	}), mock.Anything).Return(&sesv2.PutAccountSuppressionAttributesOutput{}, nil)
+	mockClient.On("PutConfigurationSetSuppressionOptions", mock.Anything, mock.MatchedBy(func(input *sesv2.PutConfigurationSetSuppressionOptionsInput) bool {
+		// No reason is sent as an empty list to disable suppression.
+		return *input.ConfigurationSetName == "transactional" && input.SuppressedReasons != nil && len(input.SuppressedReasons) == 0
+	}), mock.Anything).Return(&sesv2.PutConfigurationSetSuppressionOptionsOutput{}, nil)
+
+	require.NoError(t, repo.PutAccountSuppressionReasons(context.Background(), types.SuppressionListReasonBounce, types.SuppressionListReasonComplaint))
+	require.NoError(t, repo.PutConfigurationSetSuppressionReasons(context.Background(), "transactional"))
+	mockClient.AssertExpectations(t)
+}
@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+)
+
+const (
+	// awsSESMaxBulkEmailEntries is the maximum number of destinations of a SendBulkEmail request.
+	awsSESMaxBulkEmailEntries = 50
+)
+
+var (
+	ErrAWSSESTemplateNotSet = errors.New("template name or arn is not set")
+)
+
+// AWSSESTemplate is an email template of Amazon SES.
+// Subject, Text and HTML can contain {{variable}} replacement tags.
+type AWSSESTemplate struct {
+	Name    string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// AWSSESDestination is the recipients of an email.
+type AWSSESDestination struct {
+	To  []string `json:"to,omitempty"`
+	Cc  []string `json:"cc,omitempty"`
+	Bcc []string `json:"bcc,omitempty"`
+}
+
+// AWSSESTemplatedEmail is an email rendered from a template.
+type AWSSESTemplatedEmail struct {
+	// TemplateData is marshaled to the JSON object of the template variables.
+	TemplateData any    `json:"templateData,omitempty"`
+	From         string `json:"from"`
+	// TemplateName or TemplateARN selects the template.
+	TemplateName string            `json:"templateName,omitempty"`
+	TemplateARN  string            `json:"templateArn,omitempty"`
+	ReplyTo      []string          `json:"replyTo,omitempty"`
+	Destination  AWSSESDestination `json:"destination"`
+}
+
+// AWSSESBulkEmailEntry is a destination of a bulk email with its template variables.
+type AWSSESBulkEmailEntry struct {
+	// TemplateData is marshaled to the JSON object of the template variables
+	// replacing DefaultTemplateData. Nil uses DefaultTemplateData.
+	TemplateData any
+	Destination  AWSSESDestination
+}
+
+// AWSSESBulkTemplatedEmail is a bulk email rendered from a template for each entry.
+type AWSSESBulkTemplatedEmail struct {
+	// DefaultTemplateData is marshaled to the JSON object of the template variables.
+	DefaultTemplateData any
+	From                string
+	// TemplateName or TemplateARN selects the template.
+	TemplateName string
+	TemplateARN  string
+	ReplyTo      []string
+	Entries      []AWSSESBulkEmailEntry
+}
+
+// CreateEmailTemplate creates an email template.
+func (r *AWSSESRepository) CreateEmailTemplate(ctx context.Context, template *AWSSESTemplate) error {
+	_, err := r.Client.CreateEmailTemplate(ctx, &sesv2.CreateEmailTemplateInput{
+		TemplateName:    aws.String(template.Name),
+		TemplateContent: toAWSSESTemplateContent(template),
+	})
+	if err != nil {
+		return fmt.Errorf("ses CreateEmailTemplate: %w", err)
+	}
+	return nil
+}
+
+// UpdateEmailTemplate updates the content of an email template.
+func (r *AWSSESRepository) UpdateEmailTemplate(ctx context.Context, template *AWSSESTemplate) error {
+	_, err := r.Client.UpdateEmailTemplate(ctx, &sesv2.UpdateEmailTemplateInput{
+		TemplateName:    aws.String(template.Name),
+		TemplateContent: toAWSSESTemplateContent(template),
+	})
+	if err != nil {
+		return fmt.Errorf("ses UpdateEmailTemplate: %w", err)
+	}
+	return nil
+}
+
+// PutEmailTemplate creates an email template, or updates it when it already exists.
+func (r *AWSSESRepository) PutEmailTemplate(ctx context.Context, template *AWSSESTemplate) error {
+	err := r.CreateEmailTemplate(ctx, template)
+	var exists *types.AlreadyExistsException
+	if errors.As(err, &exists) {
+		return r.UpdateEmailTemplate(ctx, template)
+	}
+	return err
+}
+
+// GetEmailTemplate returns an email template.
+func (r *AWSSESRepository) GetEmailTemplate(ctx context.Context, name string) (*AWSSESTemplate, error) {
+	out, err := r.Client.GetEmailTemplate(ctx, &sesv2.GetEmailTemplateInput{
+		TemplateName: aws.String(name),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ses GetEmailTemplate: %w", err)
+	}
+	res := &AWSSESTemplate{Name: aws.ToString(out.TemplateName)}
+	if c := out.TemplateContent; c != nil {
+		res.Subject = aws.ToString(c.Subject)
+		res.Text = aws.ToString(c.Text)
+		res.HTML = aws.ToString(c.Html)
+	}
+	return res, nil
+}
+
+// DeleteEmailTemplate deletes an email template.
+func (r *AWSSESRepository) DeleteEmailTemplate(ctx context.Context, name string) error {
+	_, err := r.Client.DeleteEmailTemplate(ctx, &sesv2.DeleteEmailTemplateInput{
+		TemplateName: aws.String(name),
+	})
+	if err != nil {
+		return fmt.Errorf("ses DeleteEmailTemplate: %w", err)
+	}
+	return nil
+}
+
+// TestRenderEmailTemplate renders an email template with templateData and
+// returns the rendered MIME message, to check a template before sending.
+func (r *AWSSESRepository) TestRenderEmailTemplate(ctx context.Context, name string, templateData any) (string, error) {
+	data, err := marshalAWSSESTemplateData(templateData)
+	if err != nil {
+		return "", fmt.Errorf("ses TestRenderEmailTemplate: %w", err)
+	}
+	out, err := r.Client.TestRenderEmailTemplate(ctx, &sesv2.TestRenderEmailTemplateInput{
+		TemplateName: aws.String(name),
+		TemplateData: data,
+	})
+	if err != nil {
+		return "", fmt.Errorf("ses TestRenderEmailTemplate: %w", err)
+	}
+	return aws.ToString(out.RenderedTemplate), nil
+}
+
+// SendTemplatedEmail sends an email rendered from a template.
+func (r *AWSSESRepository) SendTemplatedEmail(ctx context.Context, email *AWSSESTemplatedEmail) (*sesv2.SendEmailOutput, error) {
+	template, err := newAWSSESTemplate(email.TemplateName, email.TemplateARN, email.TemplateData)
+	if err != nil {
+		return nil, fmt.Errorf("ses SendTemplatedEmail: %w", err)
+	}
+	res, err := r.Client.SendEmail(ctx, &sesv2.SendEmailInput{
+		ConfigurationSetName: r.configurationSetName,
+		FromEmailAddress:     aws.String(email.From),
+		Destination:          email.Destination.toAWSSESDestination(),
+		ReplyToAddresses:     email.ReplyTo,
+		Content:              &types.EmailContent{Template: template},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ses SendTemplatedEmail: %w", err)
+	}
+	return res, nil
+}
+
+// SendBulkTemplatedEmail sends an email rendered from a template to each entry.
+// Entries are sent in requests of up to 50 destinations, and the results are
+// returned in the order of entries. A result with a status other than SUCCESS
+// means the email of the entry wasn't sent.
+func (r *AWSSESRepository) SendBulkTemplatedEmail(ctx context.Context, email *AWSSESBulkTemplatedEmail) ([]types.BulkEmailEntryResult, error) {
+	template, err := newAWSSESTemplate(email.TemplateName, email.TemplateARN, email.DefaultTemplateData)
+	if err != nil {
+		return nil, fmt.Errorf("ses SendBulkTemplatedEmail: %w", err)
+	}
+	entries := make([]types.BulkEmailEntry, 0, len(email.Entries))
+	for i := range email.Entries {
+		entry := types.BulkEmailEntry{Destination: email.Entries[i].Destination.toAWSSESDestination()}
+		if email.Entries[i].TemplateData != nil {
+			data, err := marshalAWSSESTemplateData(email.Entries[i].TemplateData)
+			if err != nil {
+				return nil, fmt.Errorf("ses SendBulkTemplatedEmail: entry %d: %w", i, err)
+			}
+			entry.ReplacementEmailContent = &types.ReplacementEmailContent{
+				ReplacementTemplate: &types.ReplacementTemplate{ReplacementTemplateData: data},
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	res := make([]types.BulkEmailEntryResult, 0, len(entries))
+	for start := 0; start < len(entries); start += awsSESMaxBulkEmailEntries {
+		out, err := r.Client.SendBulkEmail(ctx, &sesv2.SendBulkEmailInput{
+			ConfigurationSetName: r.configurationSetName,
+			FromEmailAddress:     aws.String(email.From),
+			ReplyToAddresses:     email.ReplyTo,
+			DefaultContent:       &types.BulkEmailContent{Template: template},
+			BulkEmailEntries:     entries[start:min(start+awsSESMaxBulkEmailEntries, len(entries))],
+		})
+		if err != nil {
+			return res, fmt.Errorf("ses SendBulkTemplatedEmail: %w", err)
+		}
+		res = append(res, out.BulkEmailEntryResults...)
+	}
+	return res, nil
+}
+
+// toAWSSESDestination converts d to types.Destination.
+func (d *AWSSESDestination) toAWSSESDestination() *types.Destination {
+	return &types.Destination{
+		ToAddresses:  d.To,
+		CcAddresses:  d.Cc,
+		BccAddresses: d.Bcc,
+	}
+}
+
+// toAWSSESTemplateContent converts template to types.EmailTemplateContent.
+func toAWSSESTemplateContent(template *AWSSESTemplate) *types.EmailTemplateContent {
+	content := &types.EmailTemplateContent{Subject: aws.String(template.Subject)}
+	if template.Text != "" {
+		content.Text = aws.String(template.Text)
+	}
+	if template.HTML != "" {
+		content.Html = aws.String(template.HTML)
+	}
+	return content
+}
+
+// newAWSSESTemplate returns types.Template selecting a template by name or arn.
+func newAWSSESTemplate(name, arn string, templateData any) (*types.Template, error) {
+	if name == "" && arn == "" {
+		return nil, ErrAWSSESTemplateNotSet
+	}
+	data, err := marshalAWSSESTemplateData(templateData)
+	if err != nil {
+		return nil, err
+	}
+	template := &types.Template{TemplateData: data}
+	if name != "" {
+		template.TemplateName = aws.String(name)
+	}
+	if arn != "" {
+		template.TemplateArn = aws.String(arn)
+	}
+	return template, nil
+}
+
+// marshalAWSSESTemplateData returns templateData as a JSON object.
+// A string or []byte is used as is, and nil is an empty object.
+func marshalAWSSESTemplateData(templateData any) (*string, error) {
+	switch v := templateData.(type) {
+	case nil:
+		return aws.String("{}"), nil
+	case string:
+		return aws.String(v), nil
+	case []byte:
+		return aws.String(string(v)), nil
+	}
+	b, err := json.Marshal(templateData)
+	if err != nil {
+		return nil, fmt.Errorf("marshal template data: %w", err)
+	}
+	return aws.String(string(b)), nil
+}
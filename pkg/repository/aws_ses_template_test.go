@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAWSSESRepository_CreateEmailTemplate(t *testing.T) {
+	mockClient := &MockSESClient{}
+	repo := NewAWSSESRepositoryWithInterface(mockClient, nil)
+
+	mockClient.On("CreateEmailTemplate", mock.Anything, mock.MatchedBy(func(input *sesv2.CreateEmailTemplateInput) bool {
+		return *input.TemplateName == "welcome" &&
+			*input.TemplateContent.Subject == "Hello {{name}}" &&
+			*input.TemplateContent.Html == "<p>Hi {{name}}</p>" &&
+			input.TemplateContent.Text == nil
+	}), mock.Anything).Return(&sesv2.CreateEmailTemplateOutput{}, nil)
+
+	err := repo.CreateEmailTemplate(context.Background(), &AWSSESTemplate{
+		Name:    "welcome",
+		Subject: "Hello {{name}}",
+		HTML:    "<p>Hi {{name}}</p>",
+	})
+
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSSESRepository_PutEmailTemplate_Exists(t *testing.T) {
+	mockClient := &MockSESClient{}
+	repo := NewAWSSESRepositoryWithInterface(mockClient, nil)
+	template := &AWSSESTemplate{Name: "welcome", Subject: "Hello", Text: "Hi"}
+
+	mockClient.On("CreateEmailTemplate", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, &types.AlreadyExistsException{Message: aws.String("exists")})
+	mockClient.On("UpdateEmailTemplate", mock.Anything, mock.MatchedBy(func(input *sesv2.UpdateEmailTemplateInput) bool {
+		return *input.TemplateName == "welcome" && *input.TemplateContent.Text == "Hi"
+	}), mock.Anything).Return(&sesv2.UpdateEmailTemplateOutput{}, nil)
+
+	err := repo.PutEmailTemplate(context.Background(), template)
+
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSSESRepository_PutEmailTemplate_Error(t *testing.T) {
+	mockClient := &MockSESClient{}
+	repo := NewAWSSESRepositoryWithInterface(mockClient, nil)
+
+	mockClient.On("CreateEmailTemplate", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, errors.New("access denied"))
+
+	err := repo.PutEmailTemplate(context.Background(), &AWSSESTemplate{Name: "welcome"})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ses CreateEmailTemplate")
+	mockClient.AssertNotCalled(t, "UpdateEmailTemplate", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAWSSESRepository_GetEmailTemplate(t *testing.T) {
+	mockClient := &MockSESClient{}
+	repo := NewAWSSESRepositoryWithInterface(mockClient, nil)
+
+	mockClient.On("GetEmailTemplate", mock.Anything, mock.Anything, mock.Anything).Return(&sesv2.GetEmailTemplateOutput{
+		TemplateName: aws.String("welcome"),
+		TemplateContent: &types.EmailTemplateContent{
+			Subject: aws.String("Hello"),
+			Html:    aws.String("<p>Hi</p>"),
+		},
+	}, nil)
+
+	template, err := repo.GetEmailTemplate(context.Background(), "welcome")
+
+	require.NoError(t, err)
+	assert.Equal(t, &AWSSESTemplate{Name: "welcome", Subject: "Hello", HTML: "<p>Hi</p>"}, template)
+}
+
+func TestAWSSESRepository_DeleteEmailTemplate_Error(t *testing.T) {
+	mockClient := &MockSESClient{}
+	repo := NewAWSSESRepositoryWithInterface(mockClient, nil)
+
+	mockClient.On("DeleteEmailTemplate", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, &types.NotFoundException{Message: aws.String("not found")})
+
+	err := repo.DeleteEmailTemplate(context.Background(), "welcome")
+
+	var notFound *types.NotFoundException
+	require.ErrorAs(t, err, &notFound)
+}
+
+func TestAWSSESRepository_TestRenderEmailTemplate(t *testing.T) {
+	mockClient := &MockSESClient{}
+	repo := NewAWSSESRepositoryWithInterface(mockClient, nil)
+
+	mockClient.On("TestRenderEmailTemplate", mock.Anything, mock.MatchedBy(func(input *sesv2.TestRenderEmailTemplateInput) bool {
+		return *input.TemplateName == "welcome" && *input.TemplateData == `{"name":"Alice"}`
+	}), mock.Anything).Return(&sesv2.TestRenderEmailTemplateOutput{
+		RenderedTemplate: aws.String("Subject: Hello Alice"),
+	}, nil)
+
+	rendered, err := repo.TestRenderEmailTemplate(context.Background(), "welcome", map[string]string{"name": "Alice"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Subject: Hello Alice", rendered)
+}
+
+func TestAWSSESRepository_SendTemplatedEmail(t *testing.T) {
+	mockClient := &MockSESClient{}
+	configSet := "test-config-set"
+	repo := NewAWSSESRepositoryWithInterface(mockClient, &configSet)
+
+	mockClient.On("SendEmail", mock.Anything, mock.MatchedBy(func(input *sesv2.SendEmailInput) bool {
+		tmpl := input.Content.Template
+		return *input.ConfigurationSetName == configSet &&
+			*tmpl.TemplateArn == "arn:aws:ses:us-east-1:123456789012:template/welcome" &&
+			tmpl.TemplateName == nil &&
+			*tmpl.TemplateData == `{"name":"Alice"}` &&
+			input.Destination.CcAddresses[0] == "cc@example.com" &&
+			input.Destination.BccAddresses[0] == "bcc@example.com"
+	}), mock.Anything).Return(&sesv2.SendEmailOutput{MessageId: aws.String("message-id")}, nil)
+
+	res, err := repo.SendTemplatedEmail(context.Background(), &AWSSESTemplatedEmail{
+		From:        "sender@example.com",
+		TemplateARN: "arn:aws:ses:us-east-1:123456789012:template/welcome",
+		TemplateData: struct {
+			Name string `json:"name"`
+		}{Name: "Alice"},
+		Destination: AWSSESDestination{
+			To:  []string{"to@example.com"},
+			Cc:  []string{"cc@example.com"},
+			Bcc: []string{"bcc@example.com"},
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "message-id", *res.MessageId)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSSESRepository_SendTemplatedEmail_TemplateNotSet(t *testing.T) {
+	mockClient := &MockSESClient{}
+	repo := NewAWSSESRepositoryWithInterface(mockClient, nil)
+
+	_, err := repo.SendTemplatedEmail(context.Background(), &AWSSESTemplatedEmail{From: "sender@example.com"})
+
+	require.ErrorIs(t, err, ErrAWSSESTemplateNotSet)
+	mockClient.AssertNotCalled(t, "SendEmail", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAWSSESRepository_SendBulkTemplatedEmail(t *testing.T) {
+	mockClient := &MockSESClient{}
+	repo := NewAWSSESRepositoryWithInterface(mockClient, nil)
+
+	entries := make([]AWSSESBulkEmailEntry, 120)
+	for i := range entries {
+		entries[i] = AWSSESBulkEmailEntry{
+			Destination:  AWSSESDestination{To: []string{fmt.Sprintf("user%d@example.com", i)}},
+			TemplateData: map[string]int{"index": i},
+		}
+	}
+	entries[0].TemplateData = nil
+
+	matchBatch := func(size int) any {
+		return mock.MatchedBy(func(input *sesv2.SendBulkEmailInput) bool {
+			return *input.DefaultContent.Template.TemplateName == "welcome" &&
+				*input.DefaultContent.Template.TemplateData == `{"name":"there"}` &&
+				len(input.BulkEmailEntries) == size
+		})
+	}
+	batchOutput := func(size int) *sesv2.SendBulkEmailOutput {
+		results := make([]types.BulkEmailEntryResult, size)
+		for i := range results {
+			results[i] = types.BulkEmailEntryResult{Status: types.BulkEmailStatusSuccess}
+		}
+		return &sesv2.SendBulkEmailOutput{BulkEmailEntryResults: results}
+	}
+	mockClient.On("SendBulkEmail", mock.Anything, matchBatch(50), mock.Anything).Return(batchOutput(50), nil).Twice()
+	mockClient.On("SendBulkEmail", mock.Anything, matchBatch(20), mock.Anything).Return(batchOutput(20), nil).Once()
+
+	results, err := repo.SendBulkTemplatedEmail(context.Background(), &AWSSESBulkTemplatedEmail{
+		From:                "sender@example.com",
+		TemplateName:        "welcome",
+		DefaultTemplateData: `{"name":"there"}`,
+		Entries:             entries,
+	})
+
+	require.NoError(t, err)
+	assert.Len(t, results, 120)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSSESRepository_SendBulkTemplatedEmail_Error(t *testing.T) {
+	mockClient := &MockSESClient{}
+	repo := NewAWSSESRepositoryWithInterface(mockClient, nil)
+
+	mockClient.On("SendBulkEmail", mock.Anything, mock.Anything, mock.Anything).
+		Return(&sesv2.SendBulkEmailOutput{BulkEmailEntryResults: make([]types.BulkEmailEntryResult, 50)}, nil).Once()
+	mockClient.On("SendBulkEmail", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, errors.New("throttled")).Once()
+
+	entries := make([]AWSSESBulkEmailEntry, 60)
+	results, err := repo.SendBulkTemplatedEmail(context.Background(), &AWSSESBulkTemplatedEmail{
+		From:         "sender@example.com",
+		TemplateName: "welcome",
+		Entries:      entries,
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ses SendBulkTemplatedEmail")
+	assert.Len(t, results, 50)
+}
+
+func TestMarshalAWSSESTemplateData(t *testing.T) {
+	tests := []struct {
+		data any
+		name string
+		want string
+	}{
+		{name: "nil", data: nil, want: "{}"},
+		{name: "string", data: `{"a":1}`, want: `{"a":1}`},
+		{name: "bytes", data: []byte(`{"b":2}`), want: `{"b":2}`},
+		{name: "map", data: map[string]any{"c": "x"}, want: `{"c":"x"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := marshalAWSSESTemplateData(tt.data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, *got)
+		})
+	}
+
+	_, err := marshalAWSSESTemplateData(make(chan int))
+	require.Error(t, err)
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+)
+
+var (
+	// ErrAWSSSMParameterEmpty indicates that GetParameter returned no parameter.
+	ErrAWSSSMParameterEmpty = errors.New("parameter is empty")
+)
+
+// AWSSSMClientInterface interface for AWS Systems Manager Parameter Store operations
+type AWSSSMClientInterface interface {
+	// GetParameter retrieves a parameter from AWS Systems Manager Parameter Store
+	GetParameter(_ context.Context, _ *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error)
+}
+
+// AWSSSMParameter is a version of a parameter.
+type AWSSSMParameter struct {
+	LastModifiedDate time.Time `json:"lastModifiedDate"`
+	ARN              string    `json:"arn"`
+	Name             string    `json:"name"`
+	Type             string    `json:"type"`
+	Value            string    `json:"-"`
+	Version          int64     `json:"version"`
+}
+
+// AWSSSMRepository struct.
+type AWSSSMRepository struct {
+	Client AWSSSMClientInterface
+}
+
+// NewAWSSSMRepository returns AWSSSMRepository instance.
+func NewAWSSSMRepository(c *ssm.Client) *AWSSSMRepository {
+	return &AWSSSMRepository{
+		Client: c,
+	}
+}
+
+// NewAWSSSMRepositoryWithInterface returns AWSSSMRepository instance with interface.
+func NewAWSSSMRepositoryWithInterface(c AWSSSMClientInterface) *AWSSSMRepository {
+	return &AWSSSMRepository{
+		Client: c,
+	}
+}
+
+// GetParameter gets a parameter from Parameter Store. SecureString parameters are decrypted.
+// name may select a version or label, e.g. "/app/db:3" or "/app/db:prod".
+func (r *AWSSSMRepository) GetParameter(ctx context.Context, name string) (*AWSSSMParameter, error) {
+	result, err := r.Client.GetParameter(ctx, &ssm.GetParameterInput{
+		Name:           aws.String(name),
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ssm GetParameter: %w", err)
+	}
+	if result.Parameter == nil {
+		return nil, fmt.Errorf("ssm GetParameter: %s: %w", name, ErrAWSSSMParameterEmpty)
+	}
+	return &AWSSSMParameter{
+		LastModifiedDate: aws.ToTime(result.Parameter.LastModifiedDate),
+		ARN:              aws.ToString(result.Parameter.ARN),
+		Name:             aws.ToString(result.Parameter.Name),
+		Type:             string(result.Parameter.Type),
+		Value:            aws.ToString(result.Parameter.Value),
+		Version:          result.Parameter.Version,
+	}, nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockSSMClient is a mock implementation of the SSM client
+type MockSSMClient struct {
+	mock.Mock
+}
+
+func (m *MockSSMClient) GetParameter(ctx context.Context, input *ssm.GetParameterInput, opts ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+	args := m.Called(ctx, input, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ssm.GetParameterOutput), args.Error(1)
+}
+
+func TestNewAWSSSMRepository(t *testing.T) {
+	client := &ssm.Client{}
+	repo := NewAWSSSMRepository(client)
+	assert.NotNil(t, repo)
+	assert.Equal(t, client, repo.Client)
+
+	mockClient := new(MockSSMClient)
+	repo = NewAWSSSMRepositoryWithInterface(mockClient)
+	assert.Equal(t, mockClient, repo.Client)
+}
+
+func TestAWSSSMRepository_GetParameter(t *testing.T) {
+	mockClient := new(MockSSMClient)
+	repo := NewAWSSSMRepositoryWithInterface(mockClient)
+	modified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	mockClient.On("GetParameter", mock.Anything, mock.MatchedBy(func(input *ssm.GetParameterInput) bool {
+		return *input.Name == "/app/db/password" && *input.WithDecryption
+	}), mock.Anything).Return(&ssm.GetParameterOutput{
+		Parameter: &types.Parameter{
+			ARN:              aws.String("arn:aws:ssm:us-east-1:123456789012:parameter/app/db/password"),
+			Name:             aws.String("/app/db/password"),
+			Type:             types.ParameterTypeSecureString,
+			Value:            aws.String("pass"),
+			Version:          3,
+			LastModifiedDate: aws.Time(modified),
+		},
+	}, nil)
+
+	parameter, err := repo.GetParameter(context.Background(), "/app/db/password")
+
+	require.NoError(t, err)
+	assert.Equal(t, &AWSSSMParameter{
+		LastModifiedDate: modified,
+		ARN:              "arn:aws:ssm:us-east-1:123456789012:parameter/app/db/password",
+		Name:             "/app/db/password",
+		Type:             "SecureString",
+		Value:            "pass",
+		Version:          3,
+	}, parameter)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSSSMRepository_GetParameter_Error(t *testing.T) {
+	mockClient := new(MockSSMClient)
+	repo := NewAWSSSMRepositoryWithInterface(mockClient)
+
+	mockClient.On("GetParameter", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("not found")).Once()
+	mockClient.On("GetParameter", mock.Anything, mock.Anything, mock.Anything).Return(&ssm.GetParameterOutput{}, nil).Once()
+
+	_, err := repo.GetParameter(context.Background(), "/app/missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ssm GetParameter: not found")
+
+	_, err = repo.GetParameter(context.Background(), "/app/missing")
+	require.ErrorIs(t, err, ErrAWSSSMParameterEmpty)
+}
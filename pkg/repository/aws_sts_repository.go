@@ -10,6 +10,10 @@
 
 // AWSSTSClientInterface defines the interface for STS client operations.
 type AWSSTSClientInterface interface {
+	// AssumeRole returns temporary security credentials of a role.
+	AssumeRole(_ context.Context, _ *sts.AssumeRoleInput, _ ...func(*sts.Options)) (*sts.AssumeRoleOutput, error)
+	// AssumeRoleWithWebIdentity returns temporary security credentials of a role for a web identity token.
+	AssumeRoleWithWebIdentity(_ context.Context, _ *sts.AssumeRoleWithWebIdentityInput, _ ...func(*sts.Options)) (*sts.AssumeRoleWithWebIdentityOutput, error)
 	// GetAccessKeyInfo returns the account identifier for the specified access key.
 	GetAccessKeyInfo(_ context.Context, _ *sts.GetAccessKeyInfoInput, _ ...func(*sts.Options)) (*sts.GetAccessKeyInfoOutput, error)
 	// GetCallerIdentity returns details about the IAM identity whose credentials are used.
@@ -39,6 +43,30 @@ func NewAWSSTSRepositoryWithInterface(c AWSSTSClientInterface) *AWSSTSRepository
 	return &AWSSTSRepository{Client: c}
 }
 
+// AssumeRole returns temporary credentials of a role.
+// For credentials refreshed automatically, use infrastructure.NewAWSAssumeRoleCredentials.
+// https://docs.aws.amazon.com/STS/latest/APIReference/API_AssumeRole.html
+func (r *AWSSTSRepository) AssumeRole(ctx context.Context, in *sts.AssumeRoleInput) (*sts.AssumeRoleOutput, error) {
+	result, err := r.Client.AssumeRole(ctx, in)
+	if err != nil {
+		return nil, fmt.Errorf("sts AssumeRole: %w", err)
+	}
+
+	return result, nil
+}
+
+// AssumeRoleWithWebIdentity returns temporary credentials of a role for a web identity token,
+// e.g. of EKS service accounts or GitHub Actions.
+// https://docs.aws.amazon.com/STS/latest/APIReference/API_AssumeRoleWithWebIdentity.html
+func (r *AWSSTSRepository) AssumeRoleWithWebIdentity(ctx context.Context, in *sts.AssumeRoleWithWebIdentityInput) (*sts.AssumeRoleWithWebIdentityOutput, error) {
+	result, err := r.Client.AssumeRoleWithWebIdentity(ctx, in)
+	if err != nil {
+		return nil, fmt.Errorf("sts AssumeRoleWithWebIdentity: %w", err)
+	}
+
+	return result, nil
+}
+
 // GetAccessKeyInfo returns information about the specified access key.
 // https://docs.aws.amazon.com/STS/latest/APIReference/API_GetAccessKeyInfo.html
 func (r *AWSSTSRepository) GetAccessKeyInfo(ctx context.Context, accessKeyID string) (*sts.GetAccessKeyInfoOutput, error) {
@@ -7,6 +7,7 @@
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/sts/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -16,6 +17,22 @@ type MockSTSClient struct {
 	mock.Mock
 }
 
+func (m *MockSTSClient) AssumeRole(ctx context.Context, params *sts.AssumeRoleInput, optFns ...func(*sts.Options)) (*sts.AssumeRoleOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sts.AssumeRoleOutput), args.Error(1)
+}
+
+func (m *MockSTSClient) AssumeRoleWithWebIdentity(ctx context.Context, params *sts.AssumeRoleWithWebIdentityInput, optFns ...func(*sts.Options)) (*sts.AssumeRoleWithWebIdentityOutput, error) {
+	args := m.Called(ctx, params, optFns)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sts.AssumeRoleWithWebIdentityOutput), args.Error(1)
+}
+
 func (m *MockSTSClient) GetAccessKeyInfo(ctx context.Context, params *sts.GetAccessKeyInfoInput, optFns ...func(*sts.Options)) (*sts.GetAccessKeyInfoOutput, error) {
 	args := m.Called(ctx, params, optFns)
 	if args.Get(0) == nil {
@@ -271,3 +288,37 @@ func TestAWSSTSRepository_GetCallerIdentity_Error(t *testing.T) {
 	assert.Nil(t, result)
 	mockClient.AssertExpectations(t)
 }
+
+func TestAWSSTSRepository_AssumeRole(t *testing.T) {
+	mockClient := &MockSTSClient{}
+	repo := NewAWSSTSRepositoryWithInterface(mockClient)
+
+	expected := &sts.AssumeRoleOutput{AssumedRoleUser: &types.AssumedRoleUser{Arn: aws.String("arn:aws:sts::123456789012:assumed-role/audit/session")}}
+
+	mockClient.On("AssumeRole", mock.Anything, mock.MatchedBy(func(input *sts.AssumeRoleInput) bool {
+		return *input.RoleArn == "arn:aws:iam::123456789012:role/audit" && *input.ExternalId == "external-id"
+	}), mock.Anything).Return(expected, nil)
+
+	result, err := repo.AssumeRole(context.Background(), &sts.AssumeRoleInput{
+		RoleArn:         aws.String("arn:aws:iam::123456789012:role/audit"),
+		RoleSessionName: aws.String("session"),
+		ExternalId:      aws.String("external-id"),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+	mockClient.AssertExpectations(t)
+}
+
+func TestAWSSTSRepository_AssumeRoleWithWebIdentity_Error(t *testing.T) {
+	mockClient := &MockSTSClient{}
+	repo := NewAWSSTSRepositoryWithInterface(mockClient)
+
+	mockClient.On("AssumeRoleWithWebIdentity", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("invalid identity token"))
+
+	result, err := repo.AssumeRoleWithWebIdentity(context.Background(), &sts.AssumeRoleWithWebIdentityInput{})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "sts AssumeRoleWithWebIdentity")
+	assert.Nil(t, result)
+}
@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	// emailCSSCommentRegexp matches CSS comments.
+	emailCSSCommentRegexp = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	// emailCSSSelectorRegexp matches the simple selectors that can be inlined: tag, .class, #id and their compounds.
+	emailCSSSelectorRegexp = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9-]*)?((?:[.#][a-zA-Z_-][a-zA-Z0-9_-]*)*)$`)
+	// emailCSSSelectorPartRegexp matches the .class and #id parts of a selector.
+	emailCSSSelectorPartRegexp = regexp.MustCompile(`[.#][^.#]+`)
+)
+
+// emailCSSSelector is a simple selector of an inlined CSS rule.
+type emailCSSSelector struct {
+	tag     string
+	id      string
+	classes []string
+}
+
+// emailCSSRule is a CSS rule inlined into matching elements.
+type emailCSSRule struct {
+	selector     emailCSSSelector
+	declarations string
+	specificity  int
+	order        int
+}
+
+// InlineEmailCSS moves the rules of <style> elements of an HTML email into the
+// style attributes of the matching elements, because many email clients ignore
+// <style>. Only tag, .class, #id and compound selectors of them (e.g. p.note)
+// are inlined. At-rules such as @media and rules with other selectors are kept
+// in <style>, and style elements left empty are removed. Existing style
+// attributes take precedence over inlined rules.
+// htmlText is returned as is when it has no <style> element.
+func InlineEmailCSS(htmlText string) (string, error) {
+	if !strings.Contains(strings.ToLower(htmlText), "<style") {
+		return htmlText, nil
+	}
+	doc, err := html.Parse(strings.NewReader(htmlText))
+	if err != nil {
+		return "", fmt.Errorf("inline css: %w", err)
+	}
+
+	var rules []emailCSSRule
+	var styles []*html.Node
+	for n := range doc.Descendants() {
+		if n.Type == html.ElementNode && n.Data == "style" {
+			styles = append(styles, n)
+		}
+	}
+	for _, n := range styles {
+		var css strings.Builder
+		for c := range n.ChildNodes() {
+			css.WriteString(c.Data)
+		}
+		inlined, kept := parseEmailCSS(css.String(), len(rules))
+		rules = append(rules, inlined...)
+		if strings.TrimSpace(kept) == "" {
+			n.Parent.RemoveChild(n)
+			continue
+		}
+		for c := n.FirstChild; c != nil; c = n.FirstChild {
+			n.RemoveChild(c)
+		}
+		n.AppendChild(&html.Node{Type: html.TextNode, Data: kept})
+	}
+	// Rules are applied in the order of specificity, then of appearance, so that later ones win.
+	slices.SortStableFunc(rules, func(a, b emailCSSRule) int {
+		return cmp.Or(cmp.Compare(a.specificity, b.specificity), cmp.Compare(a.order, b.order))
+	})
+
+	for n := range doc.Descendants() {
+		if n.Type != html.ElementNode {
+			continue
+		}
+		var declarations []string
+		for i := range rules {
+			if rules[i].selector.match(n) {
+				declarations = append(declarations, rules[i].declarations)
+			}
+		}
+		if len(declarations) == 0 {
+			continue
+		}
+		i := slices.IndexFunc(n.Attr, func(a html.Attribute) bool { return a.Key == "style" })
+		if i < 0 {
+			n.Attr = append(n.Attr, html.Attribute{Key: "style"})
+			i = len(n.Attr) - 1
+		}
+		n.Attr[i].Val = mergeEmailCSSDeclarations(append(declarations, n.Attr[i].Val))
+	}
+
+	var sb strings.Builder
+	if err := html.Render(&sb, doc); err != nil {
+		return "", fmt.Errorf("inline css: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// parseEmailCSS splits css into the rules to inline and the CSS to keep in <style>.
+// order is the order of the first rule.
+func parseEmailCSS(css string, order int) ([]emailCSSRule, string) {
+	css = emailCSSCommentRegexp.ReplaceAllString(css, "")
+	var rules []emailCSSRule
+	var kept strings.Builder
+	for len(strings.TrimSpace(css)) > 0 {
+		open := strings.IndexByte(css, '{')
+		if open < 0 {
+			kept.WriteString(css)
+			break
+		}
+		// Find the matching brace, as at-rules contain nested blocks.
+		end, depth := -1, 0
+		for i := open; i < len(css) && end < 0; i++ {
+			switch css[i] {
+			case '{':
+				depth++
+			case '}':
+				depth--
+				if depth == 0 {
+					end = i
+				}
+			}
+		}
+		if end < 0 {
+			kept.WriteString(css)
+			break
+		}
+		prelude := strings.TrimSpace(css[:open])
+		block := css[open+1 : end]
+		rest := css[end+1:]
+
+		selectors, ok := parseEmailCSSSelectors(prelude)
+		if !ok {
+			kept.WriteString(strings.TrimSpace(css[:end+1]))
+			kept.WriteString("\n")
+		} else {
+			for _, s := range selectors {
+				rules = append(rules, emailCSSRule{
+					selector:     s,
+					declarations: block,
+					specificity:  s.specificity(),
+					order:        order,
+				})
+				order++
+			}
+		}
+		css = rest
+	}
+	return rules, kept.String()
+}
+
+// parseEmailCSSSelectors parses a comma separated selector list.
+// It returns false when one of them can't be inlined.
+func parseEmailCSSSelectors(prelude string) ([]emailCSSSelector, bool) {
+	if prelude == "" || strings.HasPrefix(prelude, "@") {
+		return nil, false
+	}
+	var res []emailCSSSelector
+	for s := range strings.SplitSeq(prelude, ",") {
+		m := emailCSSSelectorRegexp.FindStringSubmatch(strings.TrimSpace(s))
+		if m == nil || (m[1] == "" && m[2] == "") {
+			return nil, false
+		}
+		sel := emailCSSSelector{tag: strings.ToLower(m[1])}
+		for _, part := range emailCSSSelectorPartRegexp.FindAllString(m[2], -1) {
+			if part[0] == '#' {
+				sel.id = part[1:]
+			} else {
+				sel.classes = append(sel.classes, part[1:])
+			}
+		}
+		res = append(res, sel)
+	}
+	return res, true
+}
+
+// specificity returns the CSS specificity of s.
+func (s *emailCSSSelector) specificity() int {
+	res := len(s.classes) * 10
+	if s.id != "" {
+		res += 100
+	}
+	if s.tag != "" {
+		res++
+	}
+	return res
+}
+
+// match reports whether s matches the element n.
+func (s *emailCSSSelector) match(n *html.Node) bool {
+	if s.tag != "" && n.Data != s.tag {
+		return false
+	}
+	var id, class string
+	for _, a := range n.Attr {
+		switch a.Key {
+		case "id":
+			id = a.Val
+		case "class":
+			class = a.Val
+		}
+	}
+	if s.id != "" && s.id != id {
+		return false
+	}
+	classes := strings.Fields(class)
+	for _, c := range s.classes {
+		if !slices.Contains(classes, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeEmailCSSDeclarations merges declaration blocks into a style attribute value.
+// A property declared more than once keeps the last value at the position of the first.
+func mergeEmailCSSDeclarations(blocks []string) string {
+	var names []string
+	values := map[string]string{}
+	for _, block := range blocks {
+		for d := range strings.SplitSeq(block, ";") {
+			name, value, ok := strings.Cut(d, ":")
+			name = strings.ToLower(strings.TrimSpace(name))
+			value = strings.TrimSpace(value)
+			if !ok || name == "" || value == "" {
+				continue
+			}
+			if _, ok := values[name]; !ok {
+				names = append(names, name)
+			}
+			values[name] = value
+		}
+	}
+	res := make([]string, 0, len(names))
+	for _, name := range names {
+		res = append(res, name+": "+values[name])
+	}
+	return strings.Join(res, "; ")
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInlineEmailCSS(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "no style",
+			html:     `<p>Hello</p>`,
+			contains: []string{`<p>Hello</p>`},
+			excludes: []string{"<html>"},
+		},
+		{
+			name: "specificity and existing style",
+			html: `<html><head><style>
+				/* comment */
+				#main { color: red; }
+				p.note { color: blue; font-weight: bold; }
+				p { color: green; margin: 0; }
+				</style></head><body><p id="main" class="note" style="margin: 4px">Hi</p><p>Other</p></body></html>`,
+			contains: []string{
+				`<p id="main" class="note" style="color: red; margin: 4px; font-weight: bold">Hi</p>`,
+				`<p style="color: green; margin: 0">Other</p>`,
+			},
+			excludes: []string{"<style>", "comment"},
+		},
+		{
+			name: "selector list",
+			html: `<html><head><style>h1, .title { font-size: 20px }</style></head>` +
+				`<body><h1>A</h1><div class="title big">B</div></body></html>`,
+			contains: []string{
+				`<h1 style="font-size: 20px">A</h1>`,
+				`<div class="title big" style="font-size: 20px">B</div>`,
+			},
+		},
+		{
+			name: "media queries and complex selectors are kept",
+			html: `<html><head><style>p { color: red; } a:hover { color: blue; } ` +
+				`@media (max-width: 600px) { p { font-size: 12px; } }</style></head><body><p>Hi</p></body></html>`,
+			contains: []string{
+				`<p style="color: red">Hi</p>`,
+				"a:hover { color: blue; }",
+				"@media (max-width: 600px) { p { font-size: 12px; } }",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := InlineEmailCSS(tt.html)
+			require.NoError(t, err)
+			for _, s := range tt.contains {
+				assert.Contains(t, got, s)
+			}
+			for _, s := range tt.excludes {
+				assert.NotContains(t, got, s)
+			}
+		})
+	}
+}
+
+func TestMergeEmailCSSDeclarations(t *testing.T) {
+	got := mergeEmailCSSDeclarations([]string{"color: red; MARGIN: 0", " color : blue ;;invalid", ""})
+
+	assert.Equal(t, "color: blue; margin: 0", got)
+}
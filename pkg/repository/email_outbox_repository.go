@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	model "github.com/y-miyazaki/go-common/pkg/model/gorm"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// defaultEmailOutboxMaxAttempts is the default number of delivery attempts of a job.
+	defaultEmailOutboxMaxAttempts = 8
+)
+
+var (
+	ErrEmailOutboxIdempotencyKeyEmpty = errors.New("email outbox idempotency key is empty")
+	ErrEmailOutboxMessageInvalid      = errors.New("email outbox message must have either email or templated email")
+	ErrEmailOutboxClaimLost           = errors.New("email outbox job is no longer claimed")
+)
+
+// EmailOutboxMessage is the email of an outbox job. Exactly one of Email and Templated is set.
+type EmailOutboxMessage struct {
+	Email     *AWSSESEmail          `json:"email,omitempty"`
+	Templated *AWSSESTemplatedEmail `json:"templated,omitempty"`
+}
+
+// EmailOutboxConfig sets configurations.
+type EmailOutboxConfig struct {
+	// MaxAttempts is the number of delivery attempts of a job before it fails. Default: 8.
+	MaxAttempts int
+}
+
+// EmailOutboxRepository stores email jobs of the transactional outbox in the
+// email_outbox table (model.EmailOutbox). Jobs are enqueued in the transaction
+// of the business changes, so that an email is sent if and only if the
+// transaction commits, and delivered later by a worker.
+type EmailOutboxRepository struct {
+	db          *gorm.DB
+	maxAttempts int
+}
+
+// NewEmailOutboxRepository returns EmailOutboxRepository instance.
+func NewEmailOutboxRepository(db *gorm.DB, cfgs ...*EmailOutboxConfig) *EmailOutboxRepository {
+	r := &EmailOutboxRepository{db: db, maxAttempts: defaultEmailOutboxMaxAttempts}
+	if len(cfgs) > 0 && cfgs[0] != nil && cfgs[0].MaxAttempts > 0 {
+		r.maxAttempts = cfgs[0].MaxAttempts
+	}
+	return r
+}
+
+// Enqueue adds message to the outbox with tx, the transaction given by
+// db.TransactionGorm, so that the job is committed or rolled back with the
+// other changes of the transaction. A job is enqueued once per idempotencyKey:
+// later calls with the same key are ignored.
+func (r *EmailOutboxRepository) Enqueue(tx *gorm.DB, idempotencyKey string, message *EmailOutboxMessage) error {
+	if idempotencyKey == "" {
+		return fmt.Errorf("email outbox enqueue: %w", ErrEmailOutboxIdempotencyKeyEmpty)
+	}
+	if (message.Email == nil) == (message.Templated == nil) {
+		return fmt.Errorf("email outbox enqueue: %w", ErrEmailOutboxMessageInvalid)
+	}
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("email outbox enqueue: %w", err)
+	}
+	now := time.Now().UTC()
+	job := &model.EmailOutbox{
+		BaseModel:      model.BaseModel{CreatedAt: now, UpdatedAt: now},
+		IdempotencyKey: idempotencyKey,
+		Status:         model.EmailOutboxStatusPending,
+		Payload:        string(payload),
+		NextAttemptAt:  now,
+		MaxAttempts:    r.maxAttempts,
+	}
+	err = tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "idempotency_key"}},
+		DoNothing: true,
+	}).Create(job).Error
+	if err != nil {
+		return fmt.Errorf("email outbox enqueue: %w", err)
+	}
+	return nil
+}
+
+// Claim claims up to limit jobs due for delivery for lease, and increments their attempts.
+// Jobs whose lease expired while sending, e.g. because the worker stopped, are claimed again.
+// A job is claimed by only one of concurrent workers.
+func (r *EmailOutboxRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]*model.EmailOutbox, error) {
+	now := time.Now().UTC()
+	var candidates []*model.EmailOutbox
+	err := r.db.WithContext(ctx).
+		Where("(status = ? AND next_attempt_at <= ?) OR (status = ? AND locked_until < ?)",
+			model.EmailOutboxStatusPending, now, model.EmailOutboxStatusSending, now).
+		Order("next_attempt_at").
+		Limit(limit).
+		Find(&candidates).Error
+	if err != nil {
+		return nil, fmt.Errorf("email outbox claim: %w", err)
+	}
+
+	jobs := make([]*model.EmailOutbox, 0, len(candidates))
+	for _, job := range candidates {
+		lockedUntil := now.Add(lease)
+		// The job is claimed only if no other worker has changed it since it was read.
+		res := r.db.WithContext(ctx).Model(&model.EmailOutbox{}).
+			Where("id = ? AND status = ? AND attempts = ?", job.ID, job.Status, job.Attempts).
+			Updates(map[string]any{
+				"status":       model.EmailOutboxStatusSending,
+				"locked_until": lockedUntil,
+				"attempts":     job.Attempts + 1,
+				"updated_at":   now,
+			})
+		if res.Error != nil {
+			return jobs, fmt.Errorf("email outbox claim: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			continue
+		}
+		job.Status = model.EmailOutboxStatusSending
+		job.LockedUntil = &lockedUntil
+		job.Attempts++
+		job.UpdatedAt = now
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// MarkSent marks the claimed job as delivered with the SES message ID.
+func (r *EmailOutboxRepository) MarkSent(ctx context.Context, job *model.EmailOutbox, messageID string) error {
+	now := time.Now().UTC()
+	return r.update(ctx, job, map[string]any{
+		"status":       model.EmailOutboxStatusSent,
+		"message_id":   messageID,
+		"sent_at":      now,
+		"locked_until": nil,
+		"last_error":   "",
+		"updated_at":   now,
+	})
+}
+
+// MarkRetry releases the claimed job to be delivered again at nextAttemptAt.
+func (r *EmailOutboxRepository) MarkRetry(ctx context.Context, job *model.EmailOutbox, cause error, nextAttemptAt time.Time) error {
+	return r.update(ctx, job, map[string]any{
+		"status":          model.EmailOutboxStatusPending,
+		"next_attempt_at": nextAttemptAt.UTC(),
+		"locked_until":    nil,
+		"last_error":      cause.Error(),
+		"updated_at":      time.Now().UTC(),
+	})
+}
+
+// MarkFailed marks the claimed job as failed. It isn't delivered again unless requeued.
+func (r *EmailOutboxRepository) MarkFailed(ctx context.Context, job *model.EmailOutbox, cause error) error {
+	return r.update(ctx, job, map[string]any{
+		"status":       model.EmailOutboxStatusFailed,
+		"locked_until": nil,
+		"last_error":   cause.Error(),
+		"updated_at":   time.Now().UTC(),
+	})
+}
+
+// Get returns the job of idempotencyKey, e.g. to track its status.
+// The error satisfies IsNotFound when there's no such job.
+func (r *EmailOutboxRepository) Get(ctx context.Context, idempotencyKey string) (*model.EmailOutbox, error) {
+	var job model.EmailOutbox
+	err := r.db.WithContext(ctx).Where("idempotency_key = ?", idempotencyKey).Take(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("email outbox get %s: %w", idempotencyKey, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("email outbox get %s: %w", idempotencyKey, err)
+	}
+	return &job, nil
+}
+
+// Requeue makes the failed job of idempotencyKey pending again with its attempts reset.
+// The error satisfies IsNotFound when there's no such failed job.
+func (r *EmailOutboxRepository) Requeue(ctx context.Context, idempotencyKey string) error {
+	now := time.Now().UTC()
+	res := r.db.WithContext(ctx).Model(&model.EmailOutbox{}).
+		Where("idempotency_key = ? AND status = ?", idempotencyKey, model.EmailOutboxStatusFailed).
+		Updates(map[string]any{
+			"status":          model.EmailOutboxStatusPending,
+			"attempts":        0,
+			"next_attempt_at": now,
+			"updated_at":      now,
+		})
+	if res.Error != nil {
+		return fmt.Errorf("email outbox requeue %s: %w", idempotencyKey, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("email outbox requeue %s: %w", idempotencyKey, ErrNotFound)
+	}
+	return nil
+}
+
+// update updates the job if it's still claimed by the caller.
+func (r *EmailOutboxRepository) update(ctx context.Context, job *model.EmailOutbox, values map[string]any) error {
+	res := r.db.WithContext(ctx).Model(&model.EmailOutbox{}).
+		Where("id = ? AND status = ? AND attempts = ?", job.ID, model.EmailOutboxStatusSending, job.Attempts).
+		Updates(values)
+	if res.Error != nil {
+		return fmt.Errorf("email outbox update %d: %w", job.ID, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("email outbox update %d: %w", job.ID, ErrEmailOutboxClaimLost)
+	}
+	return nil
+}
+
+// UnmarshalEmailOutboxMessage returns the message of an outbox job payload.
+func UnmarshalEmailOutboxMessage(payload string) (*EmailOutboxMessage, error) {
+	var message EmailOutboxMessage
+	if err := json.Unmarshal([]byte(payload), &message); err != nil {
+		return nil, fmt.Errorf("email outbox message: %w", err)
+	}
+	if (message.Email == nil) == (message.Templated == nil) {
+		return nil, fmt.Errorf("email outbox message: %w", ErrEmailOutboxMessageInvalid)
+	}
+	return &message, nil
+}
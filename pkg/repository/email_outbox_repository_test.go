@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	model "github.com/y-miyazaki/go-common/pkg/model/gorm"
+	"github.com/y-miyazaki/go-common/pkg/utils/db"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// newTestEmailOutboxDB returns a GORM DB backed by sqlmock.
+func newTestEmailOutboxDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	require.NoError(t, err)
+	return gormDB, sqlMock
+}
+
+func TestEmailOutboxRepository_Enqueue(t *testing.T) {
+	gormDB, sqlMock := newTestEmailOutboxDB(t)
+	repo := NewEmailOutboxRepository(gormDB, &EmailOutboxConfig{MaxAttempts: 3})
+
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectQuery(`INSERT INTO "email_outbox" .* ON CONFLICT \("idempotency_key"\) DO NOTHING RETURNING "id"`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil, "order-1",
+			model.EmailOutboxStatusPending, sqlmock.AnyArg(), "", "", 0, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	sqlMock.ExpectCommit()
+
+	err := db.TransactionGorm(gormDB, func(tx *gorm.DB) error {
+		return repo.Enqueue(tx, "order-1", &EmailOutboxMessage{
+			Email: &AWSSESEmail{From: "from@example.com", Subject: "Hello", Text: "Hi"},
+		})
+	})
+
+	require.NoError(t, err)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestEmailOutboxRepository_Enqueue_Invalid(t *testing.T) {
+	gormDB, _ := newTestEmailOutboxDB(t)
+	repo := NewEmailOutboxRepository(gormDB)
+
+	err := repo.Enqueue(gormDB, "", &EmailOutboxMessage{Email: &AWSSESEmail{}})
+	require.ErrorIs(t, err, ErrEmailOutboxIdempotencyKeyEmpty)
+
+	err = repo.Enqueue(gormDB, "key", &EmailOutboxMessage{})
+	require.ErrorIs(t, err, ErrEmailOutboxMessageInvalid)
+
+	err = repo.Enqueue(gormDB, "key", &EmailOutboxMessage{Email: &AWSSESEmail{}, Templated: &AWSSESTemplatedEmail{}})
+	require.ErrorIs(t, err, ErrEmailOutboxMessageInvalid)
+}
+
+func TestEmailOutboxRepository_Claim(t *testing.T) {
+	gormDB, sqlMock := newTestEmailOutboxDB(t)
+	repo := NewEmailOutboxRepository(gormDB)
+
+	sqlMock.ExpectQuery(`SELECT \* FROM "email_outbox" WHERE .* ORDER BY next_attempt_at LIMIT \$5`).
+		WithArgs(model.EmailOutboxStatusPending, sqlmock.AnyArg(), model.EmailOutboxStatusSending, sqlmock.AnyArg(), 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "idempotency_key", "status", "attempts", "max_attempts"}).
+			AddRow(1, "a", model.EmailOutboxStatusPending, 0, 8).
+			AddRow(2, "b", model.EmailOutboxStatusPending, 1, 8))
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(`UPDATE "email_outbox" SET .* WHERE id = \$5 AND status = \$6 AND attempts = \$7`).
+		WithArgs(1, sqlmock.AnyArg(), model.EmailOutboxStatusSending, sqlmock.AnyArg(), 1, model.EmailOutboxStatusPending, 0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectCommit()
+	// The second job was claimed by another worker.
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(`UPDATE "email_outbox" SET`).
+		WithArgs(2, sqlmock.AnyArg(), model.EmailOutboxStatusSending, sqlmock.AnyArg(), 2, model.EmailOutboxStatusPending, 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	sqlMock.ExpectCommit()
+
+	jobs, err := repo.Claim(context.Background(), 10, time.Minute)
+
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, uint64(1), jobs[0].ID)
+	assert.Equal(t, model.EmailOutboxStatusSending, jobs[0].Status)
+	assert.Equal(t, 1, jobs[0].Attempts)
+	require.NotNil(t, jobs[0].LockedUntil)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), *jobs[0].LockedUntil, 5*time.Second)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestEmailOutboxRepository_MarkSent(t *testing.T) {
+	gormDB, sqlMock := newTestEmailOutboxDB(t)
+	repo := NewEmailOutboxRepository(gormDB)
+	job := &model.EmailOutbox{ID: 1, Status: model.EmailOutboxStatusSending, Attempts: 2}
+
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(`UPDATE "email_outbox" SET .*"message_id"=\$3.* WHERE id = \$7 AND status = \$8 AND attempts = \$9`).
+		WithArgs("", nil, "message-id", sqlmock.AnyArg(), model.EmailOutboxStatusSent, sqlmock.AnyArg(),
+			1, model.EmailOutboxStatusSending, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectCommit()
+
+	require.NoError(t, repo.MarkSent(context.Background(), job, "message-id"))
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestEmailOutboxRepository_MarkRetry_ClaimLost(t *testing.T) {
+	gormDB, sqlMock := newTestEmailOutboxDB(t)
+	repo := NewEmailOutboxRepository(gormDB)
+	job := &model.EmailOutbox{ID: 1, Status: model.EmailOutboxStatusSending, Attempts: 1}
+
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(`UPDATE "email_outbox" SET`).WillReturnResult(sqlmock.NewResult(0, 0))
+	sqlMock.ExpectCommit()
+
+	err := repo.MarkRetry(context.Background(), job, errors.New("throttled"), time.Now().Add(time.Minute))
+
+	require.ErrorIs(t, err, ErrEmailOutboxClaimLost)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestEmailOutboxRepository_Get(t *testing.T) {
+	gormDB, sqlMock := newTestEmailOutboxDB(t)
+	repo := NewEmailOutboxRepository(gormDB)
+
+	sqlMock.ExpectQuery(`SELECT \* FROM "email_outbox" WHERE idempotency_key = \$1 LIMIT \$2`).
+		WithArgs("a", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "idempotency_key", "status", "message_id"}).
+			AddRow(1, "a", model.EmailOutboxStatusSent, "message-id"))
+	sqlMock.ExpectQuery(`SELECT \* FROM "email_outbox"`).
+		WithArgs("missing", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	job, err := repo.Get(context.Background(), "a")
+	require.NoError(t, err)
+	assert.Equal(t, model.EmailOutboxStatusSent, job.Status)
+	assert.Equal(t, "message-id", job.MessageID)
+
+	_, err = repo.Get(context.Background(), "missing")
+	require.Error(t, err)
+	assert.True(t, IsNotFound(err))
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestEmailOutboxRepository_Requeue(t *testing.T) {
+	gormDB, sqlMock := newTestEmailOutboxDB(t)
+	repo := NewEmailOutboxRepository(gormDB)
+
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(`UPDATE "email_outbox" SET .* WHERE idempotency_key = \$5 AND status = \$6`).
+		WithArgs(0, sqlmock.AnyArg(), model.EmailOutboxStatusPending, sqlmock.AnyArg(), "a", model.EmailOutboxStatusFailed).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectCommit()
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(`UPDATE "email_outbox" SET`).WillReturnResult(sqlmock.NewResult(0, 0))
+	sqlMock.ExpectCommit()
+
+	require.NoError(t, repo.Requeue(context.Background(), "a"))
+	err := repo.Requeue(context.Background(), "sent")
+	require.Error(t, err)
+	assert.True(t, IsNotFound(err))
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestUnmarshalEmailOutboxMessage(t *testing.T) {
+	message, err := UnmarshalEmailOutboxMessage(`{"templated": {"templateName": "welcome", "templateData": {"name": "Alice"}}}`)
+	require.NoError(t, err)
+	assert.Nil(t, message.Email)
+	assert.Equal(t, "welcome", message.Templated.TemplateName)
+
+	_, err = UnmarshalEmailOutboxMessage(`{}`)
+	require.ErrorIs(t, err, ErrEmailOutboxMessageInvalid)
+
+	_, err = UnmarshalEmailOutboxMessage(`not json`)
+	require.Error(t, err)
+}
+
+func TestIsAWSSESPermanentError(t *testing.T) {
+	assert.True(t, IsAWSSESPermanentError(fmt.Errorf("ses SendEmail: %w", &types.MessageRejected{Message: aws.String("rejected")})))
+	assert.True(t, IsAWSSESPermanentError(fmt.Errorf("ses SendEmail: %w", ErrAWSSESInvalidAddress)))
+	assert.False(t, IsAWSSESPermanentError(&types.TooManyRequestsException{Message: aws.String("throttled")}))
+	assert.False(t, IsAWSSESPermanentError(errors.New("connection reset")))
+}
@@ -0,0 +1,358 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+)
+
+const (
+	// emailTemplateRoot is the name of the template executed when there's no layout.
+	emailTemplateRoot = "content"
+	// emailTemplateLayout is the name of the layout template.
+	emailTemplateLayout = "layout"
+)
+
+var (
+	ErrEmailTemplateNotFound = errors.New("email template not found")
+	ErrEmailTemplateNoBody   = errors.New("email template has neither text nor html body")
+)
+
+// EmailTemplateConfig sets configurations.
+type EmailTemplateConfig struct {
+	// Funcs are added to the functions available in templates.
+	Funcs map[string]any
+	// DefaultLocale is the locale used when a template doesn't exist in the requested locale. Default: en.
+	DefaultLocale string
+	// Layout is the name of the layout in layouts/. Default: default.
+	Layout string
+	// DisableCSSInline disables inlining <style> rules into style attributes of the HTML body.
+	DisableCSSInline bool
+}
+
+// RenderedEmail is an email rendered by EmailTemplateRenderer, passed to
+// AWSSESRepository.SendEmail as subject, contentText and contentHTML.
+type RenderedEmail struct {
+	Subject string
+	Text    string
+	HTML    string
+	// Locale is the locale of the template actually rendered.
+	Locale string
+}
+
+// emailTemplateSet is a parsed email template of a locale.
+type emailTemplateSet struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+	locale  string
+}
+
+// EmailTemplateRenderer renders emails from html/template and text/template files of fsys
+// (e.g. embed.FS), laid out as follows:
+//
+//	layouts/<layout>.html, layouts/<layout>.txt   optional, render the body with {{template "content" .}}
+//	partials/*.html, partials/*.txt               optional, shared templates
+//	<locale>/<name>.subject.txt                   subject
+//	<locale>/<name>.txt, <locale>/<name>.html     text and/or HTML body
+//	<locale>/messages.json                        optional, {"key": "format"} used by {{t "key" args...}}
+//
+// A locale falls back to its language (e.g. ja-JP to ja), then to the default locale.
+// Parsed templates are cached. It is safe for concurrent use.
+type EmailTemplateRenderer struct {
+	fsys   fs.FS
+	cache  map[string]*emailTemplateSet
+	config EmailTemplateConfig
+	mu     sync.RWMutex
+}
+
+// NewEmailTemplateRenderer returns EmailTemplateRenderer reading templates from fsys.
+func NewEmailTemplateRenderer(fsys fs.FS, cfgs ...*EmailTemplateConfig) *EmailTemplateRenderer {
+	conf := EmailTemplateConfig{}
+	if len(cfgs) > 0 && cfgs[0] != nil {
+		conf = *cfgs[0]
+	}
+	if conf.DefaultLocale == "" {
+		conf.DefaultLocale = "en"
+	}
+	if conf.Layout == "" {
+		conf.Layout = "default"
+	}
+	return &EmailTemplateRenderer{
+		fsys:   fsys,
+		cache:  map[string]*emailTemplateSet{},
+		config: conf,
+	}
+}
+
+// Render renders the email template name in locale with data.
+func (r *EmailTemplateRenderer) Render(name, locale string, data any) (*RenderedEmail, error) {
+	set, err := r.lookup(name, locale)
+	if err != nil {
+		return nil, err
+	}
+	var subject strings.Builder
+	if err := set.subject.Execute(&subject, data); err != nil {
+		return nil, fmt.Errorf("email template %s/%s: render subject: %w", set.locale, name, err)
+	}
+	res := &RenderedEmail{
+		// Line breaks in a subject are folded, so that a value can't inject headers.
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		Locale:  set.locale,
+	}
+	if set.text != nil {
+		var sb strings.Builder
+		if err := set.text.Execute(&sb, data); err != nil {
+			return nil, fmt.Errorf("email template %s/%s: render text: %w", set.locale, name, err)
+		}
+		res.Text = sb.String()
+	}
+	if set.html != nil {
+		var sb strings.Builder
+		if err := set.html.Execute(&sb, data); err != nil {
+			return nil, fmt.Errorf("email template %s/%s: render html: %w", set.locale, name, err)
+		}
+		res.HTML = sb.String()
+		if !r.config.DisableCSSInline {
+			if res.HTML, err = InlineEmailCSS(res.HTML); err != nil {
+				return nil, fmt.Errorf("email template %s/%s: %w", set.locale, name, err)
+			}
+		}
+	}
+	return res, nil
+}
+
+// WritePreview renders the email template name in locale with data and writes
+// <name>.<locale>.subject.txt, <name>.<locale>.txt and <name>.<locale>.html
+// to dir, so that rendered emails can be reviewed, e.g. from tests.
+// It returns the paths of the written files.
+func (r *EmailTemplateRenderer) WritePreview(dir, name, locale string, data any) ([]string, error) {
+	email, err := r.Render(name, locale, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("email template preview: %w", err)
+	}
+	files := []struct {
+		ext     string
+		content string
+	}{
+		{".subject.txt", email.Subject + "\n"},
+		{".txt", email.Text},
+		{".html", email.HTML},
+	}
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		if f.content == "" {
+			continue
+		}
+		p := filepath.Join(dir, fmt.Sprintf("%s.%s%s", filepath.Base(name), email.Locale, f.ext))
+		if err := os.WriteFile(p, []byte(f.content), 0o600); err != nil {
+			return nil, fmt.Errorf("email template preview: %w", err)
+		}
+		paths = append(paths, p)
+	}
+	return paths, nil
+}
+
+// lookup returns the cached template of name for locale, parsing it on first use.
+func (r *EmailTemplateRenderer) lookup(name, locale string) (*emailTemplateSet, error) {
+	resolved, ok := r.resolveLocale(name, locale)
+	if !ok {
+		return nil, fmt.Errorf("email template %s/%s: %w", locale, name, ErrEmailTemplateNotFound)
+	}
+	key := resolved + "/" + name
+
+	r.mu.RLock()
+	set, ok := r.cache[key]
+	r.mu.RUnlock()
+	if ok {
+		return set, nil
+	}
+
+	set, err := r.parse(name, resolved)
+	if err != nil {
+		return nil, fmt.Errorf("email template %s: %w", key, err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cache[key] = set
+	return set, nil
+}
+
+// resolveLocale returns the first locale of locale, its language and the default
+// locale in which the subject of name exists.
+func (r *EmailTemplateRenderer) resolveLocale(name, locale string) (string, bool) {
+	candidates := []string{locale}
+	if i := strings.IndexAny(locale, "-_"); i > 0 {
+		candidates = append(candidates, locale[:i])
+	}
+	candidates = append(candidates, r.config.DefaultLocale)
+	for _, c := range candidates {
+		if c == "" {
+			continue
+		}
+		if r.exists(path.Join(c, name+".subject.txt")) {
+			return c, true
+		}
+	}
+	return "", false
+}
+
+// parse parses the subject and bodies of name in locale with the layout and partials.
+func (r *EmailTemplateRenderer) parse(name, locale string) (*emailTemplateSet, error) {
+	funcs, err := r.funcs(locale)
+	if err != nil {
+		return nil, err
+	}
+	set := &emailTemplateSet{locale: locale}
+
+	subject, err := r.readFile(path.Join(locale, name+".subject.txt"))
+	if err != nil {
+		return nil, err
+	}
+	if set.subject, err = texttemplate.New("subject").Option("missingkey=error").Funcs(funcs).Parse(subject); err != nil {
+		return nil, err
+	}
+
+	textFile, htmlFile := path.Join(locale, name+".txt"), path.Join(locale, name+".html")
+	hasText, hasHTML := r.exists(textFile), r.exists(htmlFile)
+	if !hasText && !hasHTML {
+		return nil, ErrEmailTemplateNoBody
+	}
+	if hasText {
+		if set.text, err = r.parseText(textFile, funcs); err != nil {
+			return nil, err
+		}
+	}
+	if hasHTML {
+		if set.html, err = r.parseHTML(htmlFile, funcs); err != nil {
+			return nil, err
+		}
+	}
+	return set, nil
+}
+
+// parseText parses the text body of file with the text layout and partials.
+func (r *EmailTemplateRenderer) parseText(file string, funcs map[string]any) (*texttemplate.Template, error) {
+	body, err := r.readFile(file)
+	if err != nil {
+		return nil, err
+	}
+	tmpl := texttemplate.New(emailTemplateRoot).Option("missingkey=error").Funcs(funcs)
+	if _, err := tmpl.Parse(body); err != nil {
+		return nil, err
+	}
+	if partials, _ := fs.Glob(r.fsys, "partials/*.txt"); len(partials) > 0 {
+		if _, err := tmpl.ParseFS(r.fsys, partials...); err != nil {
+			return nil, err
+		}
+	}
+	layoutFile := path.Join("layouts", r.config.Layout+".txt")
+	if !r.exists(layoutFile) {
+		return tmpl, nil
+	}
+	layout, err := r.readFile(layoutFile)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tmpl.New(emailTemplateLayout).Parse(layout); err != nil {
+		return nil, err
+	}
+	return tmpl.Lookup(emailTemplateLayout), nil
+}
+
+// parseHTML parses the HTML body of file with the HTML layout and partials.
+func (r *EmailTemplateRenderer) parseHTML(file string, funcs map[string]any) (*htmltemplate.Template, error) {
+	body, err := r.readFile(file)
+	if err != nil {
+		return nil, err
+	}
+	tmpl := htmltemplate.New(emailTemplateRoot).Option("missingkey=error").Funcs(funcs)
+	if _, err := tmpl.Parse(body); err != nil {
+		return nil, err
+	}
+	if partials, _ := fs.Glob(r.fsys, "partials/*.html"); len(partials) > 0 {
+		if _, err := tmpl.ParseFS(r.fsys, partials...); err != nil {
+			return nil, err
+		}
+	}
+	layoutFile := path.Join("layouts", r.config.Layout+".html")
+	if !r.exists(layoutFile) {
+		return tmpl, nil
+	}
+	layout, err := r.readFile(layoutFile)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tmpl.New(emailTemplateLayout).Parse(layout); err != nil {
+		return nil, err
+	}
+	return tmpl.Lookup(emailTemplateLayout), nil
+}
+
+// funcs returns the template functions of locale.
+func (r *EmailTemplateRenderer) funcs(locale string) (map[string]any, error) {
+	messages, err := r.messages(locale)
+	if err != nil {
+		return nil, err
+	}
+	funcs := map[string]any{
+		"locale": func() string { return locale },
+		// t returns the message of key formatted with args, or key when it's not defined.
+		"t": func(key string, args ...any) string {
+			format, ok := messages[key]
+			if !ok {
+				return key
+			}
+			if len(args) == 0 {
+				return format
+			}
+			return fmt.Sprintf(format, args...)
+		},
+	}
+	maps.Copy(funcs, r.config.Funcs)
+	return funcs, nil
+}
+
+// messages returns the messages of the default locale overridden by the messages of locale.
+func (r *EmailTemplateRenderer) messages(locale string) (map[string]string, error) {
+	res := map[string]string{}
+	for _, l := range []string{r.config.DefaultLocale, locale} {
+		b, err := fs.ReadFile(r.fsys, path.Join(l, "messages.json"))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var m map[string]string
+		if err := json.Unmarshal(b, &m); err != nil {
+			return nil, fmt.Errorf("%s/messages.json: %w", l, err)
+		}
+		maps.Copy(res, m)
+	}
+	return res, nil
+}
+
+// readFile returns the content of file in fsys.
+func (r *EmailTemplateRenderer) readFile(file string) (string, error) {
+	b, err := fs.ReadFile(r.fsys, file)
+	return string(b), err
+}
+
+// exists reports whether file exists in fsys.
+func (r *EmailTemplateRenderer) exists(file string) bool {
+	_, err := fs.Stat(r.fsys, file)
+	return err == nil
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// testEmailTemplateFS is a template tree of EmailTemplateRenderer.
+var testEmailTemplateFS = fstest.MapFS{
+	"layouts/default.html": {Data: []byte(`<html><head><style>p { color: #333; } .button { padding: 8px; }</style></head>` +
+		`<body>{{template "content" .}}{{template "footer.html" .}}</body></html>`)},
+	"layouts/default.txt":      {Data: []byte("{{template \"content\" .}}\n--\n{{t \"footer\"}}\n")},
+	"partials/footer.html":     {Data: []byte(`<p class="footer">{{t "footer"}}</p>`)},
+	"en/messages.json":         {Data: []byte(`{"greeting": "Hello %s", "footer": "Example Inc."}`)},
+	"en/welcome.subject.txt":   {Data: []byte("Welcome,\n{{.Name}}")},
+	"en/welcome.txt":           {Data: []byte(`{{t "greeting" .Name}}`)},
+	"en/welcome.html":          {Data: []byte(`<p>{{t "greeting" .Name}}</p><a class="button" href="{{.URL}}">Start</a>`)},
+	"ja/messages.json":         {Data: []byte(`{"greeting": "こんにちは %sさん"}`)},
+	"ja/welcome.subject.txt":   {Data: []byte("ようこそ {{.Name}}さん")},
+	"ja/welcome.txt":           {Data: []byte(`{{t "greeting" .Name}}`)},
+	"en/text-only.subject.txt": {Data: []byte("Text only")},
+	"en/text-only.txt":         {Data: []byte("{{.Missing}}")},
+	"en/no-body.subject.txt":   {Data: []byte("No body")},
+}
+
+// testEmailTemplateData is the data of the welcome template.
+type testEmailTemplateData struct {
+	Name string
+	URL  string
+}
+
+func TestEmailTemplateRenderer_Render(t *testing.T) {
+	r := NewEmailTemplateRenderer(testEmailTemplateFS)
+
+	email, err := r.Render("welcome", "en-US", &testEmailTemplateData{Name: "<Alice>", URL: "https://example.com/start"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "en", email.Locale)
+	assert.Equal(t, "Welcome, <Alice>", email.Subject)
+	assert.Equal(t, "Hello <Alice>\n--\nExample Inc.\n", email.Text)
+	assert.Contains(t, email.HTML, `<p style="color: #333">Hello &lt;Alice&gt;</p>`)
+	assert.Contains(t, email.HTML, `<a class="button" href="https://example.com/start" style="padding: 8px">Start</a>`)
+	assert.Contains(t, email.HTML, `<p class="footer" style="color: #333">Example Inc.</p>`)
+	assert.NotContains(t, email.HTML, "<style>")
+}
+
+func TestEmailTemplateRenderer_Render_Locale(t *testing.T) {
+	r := NewEmailTemplateRenderer(testEmailTemplateFS)
+
+	email, err := r.Render("welcome", "ja-JP", &testEmailTemplateData{Name: "太郎"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "ja", email.Locale)
+	assert.Equal(t, "ようこそ 太郎さん", email.Subject)
+	// The footer falls back to the messages of the default locale.
+	assert.Equal(t, "こんにちは 太郎さん\n--\nExample Inc.\n", email.Text)
+	assert.Empty(t, email.HTML)
+}
+
+func TestEmailTemplateRenderer_Render_Errors(t *testing.T) {
+	r := NewEmailTemplateRenderer(testEmailTemplateFS)
+
+	_, err := r.Render("unknown", "en", nil)
+	require.ErrorIs(t, err, ErrEmailTemplateNotFound)
+
+	_, err = r.Render("no-body", "en", nil)
+	require.ErrorIs(t, err, ErrEmailTemplateNoBody)
+
+	_, err = r.Render("text-only", "en", map[string]string{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "render text")
+}
+
+func TestEmailTemplateRenderer_Config(t *testing.T) {
+	fsys := fstest.MapFS{
+		"fr/invoice.subject.txt": {Data: []byte("Facture {{upper .}}")},
+		"fr/invoice.html":        {Data: []byte(`<html><head><style>p { color: red; }</style></head><body><p>{{.}}</p></body></html>`)},
+	}
+	r := NewEmailTemplateRenderer(fsys, &EmailTemplateConfig{
+		DefaultLocale:    "fr",
+		DisableCSSInline: true,
+		Funcs:            map[string]any{"upper": strings.ToUpper},
+	})
+
+	email, err := r.Render("invoice", "de", "n1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "fr", email.Locale)
+	assert.Equal(t, "Facture N1", email.Subject)
+	assert.Contains(t, email.HTML, "<style>p { color: red; }</style>")
+}
+
+func TestEmailTemplateRenderer_Render_Concurrent(t *testing.T) {
+	r := NewEmailTemplateRenderer(testEmailTemplateFS)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			_, err := r.Render("welcome", "en", &testEmailTemplateData{Name: "Alice"})
+			assert.NoError(t, err)
+		})
+	}
+	wg.Wait()
+}
+
+func TestEmailTemplateRenderer_WritePreview(t *testing.T) {
+	r := NewEmailTemplateRenderer(testEmailTemplateFS)
+	dir := filepath.Join(t.TempDir(), "preview")
+
+	paths, err := r.WritePreview(dir, "welcome", "en", &testEmailTemplateData{Name: "Alice"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "welcome.en.subject.txt"),
+		filepath.Join(dir, "welcome.en.txt"),
+		filepath.Join(dir, "welcome.en.html"),
+	}, paths)
+	subject, err := os.ReadFile(paths[0])
+	require.NoError(t, err)
+	assert.Equal(t, "Welcome, Alice\n", string(subject))
+}
+
+func TestAWSSESRepository_SendRenderedEmail(t *testing.T) {
+	mockClient := &MockSESClient{}
+	repo := NewAWSSESRepositoryWithInterface(mockClient, nil)
+	email, err := NewEmailTemplateRenderer(testEmailTemplateFS).Render("welcome", "ja", &testEmailTemplateData{Name: "太郎"})
+	require.NoError(t, err)
+
+	mockClient.On("SendEmail", mock.Anything, mock.MatchedBy(func(input *sesv2.SendEmailInput) bool {
+		return *input.Content.Simple.Subject.Data == "ようこそ 太郎さん" &&
+			input.Content.Simple.Body.Html == nil &&
+			input.Destination.ToAddresses[0] == "to@example.com"
+	}), mock.Anything).Return(&sesv2.SendEmailOutput{MessageId: aws.String("message-id")}, nil)
+
+	_, err = repo.SendRenderedEmail(context.Background(), "sender@example.com", []string{"to@example.com"}, nil, email)
+
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
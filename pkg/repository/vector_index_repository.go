@@ -0,0 +1,298 @@
+package repository
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	// vectorIndexFormatVersion is the version of the persisted index format.
+	vectorIndexFormatVersion = 1
+)
+
+var (
+	ErrVectorIndexDimensionMismatch = errors.New("vector dimension mismatch")
+	ErrVectorIndexEmptyVector       = errors.New("vector is empty")
+	ErrVectorIndexEmptyID           = errors.New("document id is empty")
+	ErrVectorIndexFormatUnsupported = errors.New("unsupported vector index format")
+)
+
+// VectorDocument is a document stored in VectorIndexRepository.
+type VectorDocument struct {
+	Metadata map[string]string `json:"metadata,omitempty"`
+	ID       string            `json:"id"`
+	Text     string            `json:"text,omitempty"`
+	Vector   []float32         `json:"vector"`
+}
+
+// VectorSearchResult is a document found by Search with its cosine similarity to the query.
+type VectorSearchResult struct {
+	VectorDocument
+	Score float32
+}
+
+// vectorIndexEntry is a stored document with the norm of its vector.
+type vectorIndexEntry struct {
+	doc  VectorDocument
+	norm float64
+}
+
+// vectorIndexFile is the persisted format of VectorIndexRepository.
+type vectorIndexFile struct {
+	Documents []VectorDocument `json:"documents"`
+	Version   int              `json:"version"`
+	Dimension int              `json:"dimension"`
+}
+
+// VectorIndexRepository is a small in-process vector index searched by cosine
+// similarity. It is meant for up to tens of thousands of documents, e.g. the
+// context of RAG features, without a separate vector database.
+// It is safe for concurrent use.
+type VectorIndexRepository struct {
+	entries   map[string]*vectorIndexEntry
+	mu        sync.RWMutex
+	dimension int
+}
+
+// NewVectorIndexRepository returns an empty index. The vector dimension is
+// fixed by the first document added.
+func NewVectorIndexRepository() *VectorIndexRepository {
+	return &VectorIndexRepository{entries: map[string]*vectorIndexEntry{}}
+}
+
+// Upsert adds documents, replacing documents with the same ID.
+// No document is added when one of them is invalid.
+func (r *VectorIndexRepository) Upsert(docs ...VectorDocument) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// The dimension is fixed by the first document of an empty index.
+	dimension := r.dimension
+	if len(r.entries) == 0 {
+		dimension = 0
+	}
+	entries := make([]*vectorIndexEntry, 0, len(docs))
+	for i := range docs {
+		if docs[i].ID == "" {
+			return fmt.Errorf("vector index upsert: %w", ErrVectorIndexEmptyID)
+		}
+		if len(docs[i].Vector) == 0 {
+			return fmt.Errorf("vector index upsert %s: %w", docs[i].ID, ErrVectorIndexEmptyVector)
+		}
+		if dimension == 0 {
+			dimension = len(docs[i].Vector)
+		}
+		if len(docs[i].Vector) != dimension {
+			return fmt.Errorf("vector index upsert %s: %w: got %d, want %d", docs[i].ID, ErrVectorIndexDimensionMismatch, len(docs[i].Vector), dimension)
+		}
+		entries = append(entries, &vectorIndexEntry{doc: docs[i], norm: vectorNorm(docs[i].Vector)})
+	}
+	r.dimension = dimension
+	for _, e := range entries {
+		r.entries[e.doc.ID] = e
+	}
+	return nil
+}
+
+// Delete removes the documents of ids. Unknown ids are ignored.
+func (r *VectorIndexRepository) Delete(ids ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range ids {
+		delete(r.entries, id)
+	}
+}
+
+// Get returns the document of id.
+func (r *VectorIndexRepository) Get(id string) (VectorDocument, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, ok := r.entries[id]
+	if !ok {
+		return VectorDocument{}, false
+	}
+	return e.doc, true
+}
+
+// Len returns the number of documents.
+func (r *VectorIndexRepository) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.entries)
+}
+
+// Search returns the topK documents most similar to query by cosine similarity,
+// in descending order of score. When filter is set, only documents whose
+// metadata has all the key-value pairs of filter are returned.
+func (r *VectorIndexRepository) Search(query []float32, topK int, filter map[string]string) ([]VectorSearchResult, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.entries) == 0 || topK <= 0 {
+		return nil, nil
+	}
+	if len(query) != r.dimension {
+		return nil, fmt.Errorf("vector index search: %w: got %d, want %d", ErrVectorIndexDimensionMismatch, len(query), r.dimension)
+	}
+	queryNorm := vectorNorm(query)
+	res := make([]VectorSearchResult, 0, len(r.entries))
+	for _, e := range r.entries {
+		if !matchVectorMetadata(e.doc.Metadata, filter) {
+			continue
+		}
+		res = append(res, VectorSearchResult{
+			VectorDocument: e.doc,
+			Score:          cosineSimilarity(query, e.doc.Vector, queryNorm, e.norm),
+		})
+	}
+	slices.SortFunc(res, func(a, b VectorSearchResult) int {
+		// IDs break ties so that results are stable.
+		return cmp.Or(cmp.Compare(b.Score, a.Score), strings.Compare(a.ID, b.ID))
+	})
+	if len(res) > topK {
+		res = res[:topK]
+	}
+	return res, nil
+}
+
+// Save writes the index to w as JSON.
+func (r *VectorIndexRepository) Save(w io.Writer) error {
+	r.mu.RLock()
+	f := vectorIndexFile{
+		Version:   vectorIndexFormatVersion,
+		Dimension: r.dimension,
+		Documents: make([]VectorDocument, 0, len(r.entries)),
+	}
+	for _, e := range r.entries {
+		f.Documents = append(f.Documents, e.doc)
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(f.Documents, func(a, b VectorDocument) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	if err := json.NewEncoder(w).Encode(&f); err != nil {
+		return fmt.Errorf("vector index save: %w", err)
+	}
+	return nil
+}
+
+// Load replaces the documents of the index with the index written by Save.
+func (r *VectorIndexRepository) Load(rd io.Reader) error {
+	var f vectorIndexFile
+	if err := json.NewDecoder(rd).Decode(&f); err != nil {
+		return fmt.Errorf("vector index load: %w", err)
+	}
+	if f.Version != vectorIndexFormatVersion {
+		return fmt.Errorf("vector index load: %w: version %d", ErrVectorIndexFormatUnsupported, f.Version)
+	}
+	loaded := NewVectorIndexRepository()
+	if err := loaded.Upsert(f.Documents...); err != nil {
+		return fmt.Errorf("vector index load: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = loaded.entries
+	r.dimension = loaded.dimension
+	return nil
+}
+
+// SaveFile writes the index to filePath. The file is replaced atomically.
+func (r *VectorIndexRepository) SaveFile(filePath string) (err error) {
+	path := filepath.Clean(filePath)
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("vector index save: create temp file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+	if err = r.Save(tmp); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("vector index save: close temp file: %w", err)
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("vector index save: rename temp file: %w", err)
+	}
+	return nil
+}
+
+// LoadFile loads the index written by SaveFile.
+func (r *VectorIndexRepository) LoadFile(filePath string) error {
+	file, err := os.Open(filepath.Clean(filePath))
+	if err != nil {
+		return fmt.Errorf("vector index load: open file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	return r.Load(file)
+}
+
+// SaveS3 writes the index to the object of bucket and key with s3Repo.
+func (r *VectorIndexRepository) SaveS3(s3Repo *AWSS3Repository, bucket, key string) error {
+	var sb strings.Builder
+	if err := r.Save(&sb); err != nil {
+		return err
+	}
+	text := sb.String()
+	if _, err := s3Repo.PutObjectText(bucket, key, &text); err != nil {
+		return fmt.Errorf("vector index save: %w", err)
+	}
+	return nil
+}
+
+// LoadS3 loads the index written by SaveS3.
+func (r *VectorIndexRepository) LoadS3(s3Repo *AWSS3Repository, bucket, key string) error {
+	out, err := s3Repo.GetObject(bucket, key)
+	if err != nil {
+		return fmt.Errorf("vector index load: %w", err)
+	}
+	defer func() { _ = out.Body.Close() }()
+	return r.Load(out.Body)
+}
+
+// matchVectorMetadata reports whether metadata has all the key-value pairs of filter.
+func matchVectorMetadata(metadata, filter map[string]string) bool {
+	for k, v := range filter {
+		if mv, ok := metadata[k]; !ok || mv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// vectorNorm returns the Euclidean norm of v.
+func vectorNorm(v []float32) float64 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	return math.Sqrt(sum)
+}
+
+// cosineSimilarity returns the cosine similarity of a and b with their norms.
+// It returns 0 when one of them is a zero vector.
+func cosineSimilarity(a, b []float32, normA, normB float64) float32 {
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return float32(dot / (normA * normB))
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestVectorIndex(t *testing.T) *VectorIndexRepository {
+	t.Helper()
+	index := NewVectorIndexRepository()
+	require.NoError(t, index.Upsert(
+		VectorDocument{ID: "a", Text: "go", Vector: []float32{1, 0, 0}, Metadata: map[string]string{"lang": "en"}},
+		VectorDocument{ID: "b", Text: "golang", Vector: []float32{0.9, 0.1, 0}, Metadata: map[string]string{"lang": "ja"}},
+		VectorDocument{ID: "c", Text: "rust", Vector: []float32{0, 1, 0}, Metadata: map[string]string{"lang": "en"}},
+		VectorDocument{ID: "d", Text: "zero", Vector: []float32{0, 0, 0}},
+	))
+	return index
+}
+
+func TestVectorIndexRepository_Search(t *testing.T) {
+	index := newTestVectorIndex(t)
+
+	res, err := index.Search([]float32{2, 0, 0}, 2, nil)
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	assert.Equal(t, "a", res[0].ID)
+	assert.InDelta(t, 1.0, res[0].Score, 1e-6)
+	assert.Equal(t, "b", res[1].ID)
+	assert.InDelta(t, 0.9939, res[1].Score, 1e-4)
+
+	res, err = index.Search([]float32{1, 0, 0}, 10, map[string]string{"lang": "en"})
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	assert.Equal(t, "a", res[0].ID)
+	assert.Equal(t, "c", res[1].ID)
+	assert.Zero(t, res[1].Score)
+
+	res, err = index.Search([]float32{1, 0, 0}, 0, nil)
+	require.NoError(t, err)
+	assert.Empty(t, res)
+
+	_, err = index.Search([]float32{1, 0}, 1, nil)
+	assert.ErrorIs(t, err, ErrVectorIndexDimensionMismatch)
+}
+
+func TestVectorIndexRepository_Upsert(t *testing.T) {
+	index := newTestVectorIndex(t)
+	assert.Equal(t, 4, index.Len())
+
+	require.NoError(t, index.Upsert(VectorDocument{ID: "a", Text: "go updated", Vector: []float32{0, 0, 1}}))
+	doc, ok := index.Get("a")
+	require.True(t, ok)
+	assert.Equal(t, "go updated", doc.Text)
+	assert.Equal(t, 4, index.Len())
+
+	err := index.Upsert(VectorDocument{ID: "e", Vector: []float32{1, 0, 0}}, VectorDocument{ID: "f", Vector: []float32{1}})
+	assert.ErrorIs(t, err, ErrVectorIndexDimensionMismatch)
+	_, ok = index.Get("e")
+	assert.False(t, ok, "no document is added when one is invalid")
+
+	assert.ErrorIs(t, index.Upsert(VectorDocument{Vector: []float32{1, 0, 0}}), ErrVectorIndexEmptyID)
+	assert.ErrorIs(t, index.Upsert(VectorDocument{ID: "g"}), ErrVectorIndexEmptyVector)
+
+	index.Delete("a", "b", "c", "d", "unknown")
+	assert.Zero(t, index.Len())
+	require.NoError(t, index.Upsert(VectorDocument{ID: "h", Vector: []float32{1, 2}}), "empty index accepts a new dimension")
+}
+
+func TestVectorIndexRepository_SaveLoadFile(t *testing.T) {
+	index := newTestVectorIndex(t)
+	filePath := filepath.Join(t.TempDir(), "index.json")
+	require.NoError(t, index.SaveFile(filePath))
+
+	loaded := NewVectorIndexRepository()
+	require.NoError(t, loaded.LoadFile(filePath))
+	assert.Equal(t, 4, loaded.Len())
+	res, err := loaded.Search([]float32{1, 0, 0}, 1, map[string]string{"lang": "ja"})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, "golang", res[0].Text)
+
+	assert.Error(t, loaded.LoadFile(filepath.Join(t.TempDir(), "missing.json")))
+	assert.ErrorIs(t, loaded.Load(strings.NewReader(`{"version":2}`)), ErrVectorIndexFormatUnsupported)
+	assert.Equal(t, 4, loaded.Len(), "failed load keeps the documents")
+}
+
+func TestVectorIndexRepository_SaveLoadS3(t *testing.T) {
+	index := newTestVectorIndex(t)
+	mockS3 := new(MockS3Client)
+	s3Repo := NewAWSS3RepositoryWithInterface(mockS3, nil, nil, nil)
+
+	var saved []byte
+	mockS3.On("PutObject", mock.Anything, mock.MatchedBy(func(input *s3.PutObjectInput) bool {
+		return *input.Bucket == "bucket" && *input.Key == "rag/index.json"
+	}), mock.Anything).Run(func(args mock.Arguments) {
+		saved, _ = io.ReadAll(args.Get(1).(*s3.PutObjectInput).Body)
+	}).Return(&s3.PutObjectOutput{}, nil)
+	require.NoError(t, index.SaveS3(s3Repo, "bucket", "rag/index.json"))
+
+	mockS3.On("GetObject", mock.Anything, mock.Anything, mock.Anything).Return(&s3.GetObjectOutput{
+		Body: io.NopCloser(bytes.NewReader(saved)),
+	}, nil)
+	loaded := NewVectorIndexRepository()
+	require.NoError(t, loaded.LoadS3(s3Repo, "bucket", "rag/index.json"))
+	assert.Equal(t, 4, loaded.Len())
+	mockS3.AssertExpectations(t)
+}
+
+func TestVectorIndexRepository_Concurrent(t *testing.T) {
+	index := newTestVectorIndex(t)
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Go(func() {
+			_ = index.Upsert(VectorDocument{ID: string(rune('k' + i)), Vector: []float32{1, 1, 1}})
+			_, _ = index.Search([]float32{1, 0, 0}, 3, nil)
+		})
+	}
+	wg.Wait()
+	assert.Equal(t, 14, index.Len())
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/account"
+	"github.com/y-miyazaki/go-common/pkg/infrastructure"
+	"github.com/y-miyazaki/go-common/pkg/repository"
+)
+
+const (
+	defaultAWSFanOutConcurrency = 10
+)
+
+var (
+	// ErrAWSFanOutNoTarget indicates that a fan-out has no role or no region.
+	ErrAWSFanOutNoTarget = errors.New("fan-out has no role or region")
+)
+
+// AWSConfigProvider returns the config of a role in a region. *infrastructure.AWSCrossAccountConfigs implements it.
+type AWSConfigProvider interface {
+	// Get returns the config of roleARN in region. An empty region is the default region.
+	Get(roleARN, region string) (aws.Config, error)
+}
+
+// AWSAccountInformationGetter returns account information. *repository.AWSAccountRepository implements it.
+type AWSAccountInformationGetter interface {
+	// GetAccountInformation returns the information of accountID, or of the caller account when it's empty.
+	GetAccountInformation(ctx context.Context, accountID string) (*account.GetAccountInformationOutput, error)
+}
+
+// AWSFanOutConfig sets configurations.
+type AWSFanOutConfig struct {
+	// NewAccountRepository returns the repository used to get account names with the config of an account.
+	// Default: repository.NewAWSAccountRepository.
+	NewAccountRepository func(cfg *aws.Config) AWSAccountInformationGetter
+	// Concurrency is the maximum number of targets run at once. Default: 10.
+	Concurrency int
+	// SkipAccountNames disables getting account names, which requires account:GetAccountInformation.
+	SkipAccountNames bool
+}
+
+// AWSFanOutTarget is an account and region a fan-out runs on.
+type AWSFanOutTarget struct {
+	// Config is the config of the role in the region.
+	Config      aws.Config `json:"-"`
+	RoleARN     string     `json:"roleArn"`
+	AccountID   string     `json:"accountId"`
+	AccountName string     `json:"accountName,omitempty"`
+	Region      string     `json:"region"`
+}
+
+// AWSFanOutResult is the result of a fan-out on a target.
+type AWSFanOutResult[T any] struct {
+	Value  T               `json:"value"`
+	Err    error           `json:"-"`
+	Target AWSFanOutTarget `json:"target"`
+}
+
+// AWSFanOutExecutor runs a function on many accounts and regions, e.g. the same
+// repository call for an audit of an organization.
+type AWSFanOutExecutor struct {
+	configs AWSConfigProvider
+	config  AWSFanOutConfig
+	mu      sync.Mutex
+	names   map[string]*awsFanOutAccountName
+	runs    uint64
+}
+
+// NewAWSFanOutExecutor returns AWSFanOutExecutor instance.
+// configs builds the configs of the roles, typically infrastructure.NewAWSCrossAccountConfigs.
+func NewAWSFanOutExecutor(configs AWSConfigProvider, cfgs ...*AWSFanOutConfig) *AWSFanOutExecutor {
+	conf := AWSFanOutConfig{}
+	if len(cfgs) > 0 && cfgs[0] != nil {
+		conf = *cfgs[0]
+	}
+	if conf.Concurrency <= 0 {
+		conf.Concurrency = defaultAWSFanOutConcurrency
+	}
+	if conf.NewAccountRepository == nil {
+		conf.NewAccountRepository = func(cfg *aws.Config) AWSAccountInformationGetter {
+			return repository.NewAWSAccountRepository(infrastructure.NewAWSAccount(cfg))
+		}
+	}
+	return &AWSFanOutExecutor{configs: configs, config: conf, names: map[string]*awsFanOutAccountName{}}
+}
+
+// RunAWSFanOut runs fn on each region of each role with the bounded concurrency
+// of e, and returns the results in the order of roleARNs and then regions.
+// Errors of targets, including building their config, are in the results and
+// don't stop the other targets. Targets not started when ctx is done fail with ctx.Err().
+// Account names are looked up once per role and cached by e. A lookup failure
+// leaves the name empty and is retried by the next run.
+func RunAWSFanOut[T any](ctx context.Context, e *AWSFanOutExecutor, roleARNs, regions []string, fn func(ctx context.Context, target *AWSFanOutTarget) (T, error)) ([]AWSFanOutResult[T], error) {
+	if len(roleARNs) == 0 || len(regions) == 0 {
+		return nil, ErrAWSFanOutNoTarget
+	}
+	results := make([]AWSFanOutResult[T], 0, len(roleARNs)*len(regions))
+	for _, roleARN := range roleARNs {
+		var accountID string
+		if parsed, err := arn.Parse(roleARN); err == nil {
+			accountID = parsed.AccountID
+		}
+		for _, region := range regions {
+			results = append(results, AWSFanOutResult[T]{
+				Target: AWSFanOutTarget{RoleARN: roleARN, AccountID: accountID, Region: region},
+			})
+		}
+	}
+
+	e.mu.Lock()
+	e.runs++
+	run := e.runs
+	e.mu.Unlock()
+
+	sem := make(chan struct{}, e.config.Concurrency)
+	var wg sync.WaitGroup
+	for i := range results {
+		result := &results[i]
+		if ctx.Err() == nil {
+			select {
+			case <-ctx.Done():
+			case sem <- struct{}{}:
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			result.Err = fmt.Errorf("fan-out %s %s: %w", result.Target.RoleARN, result.Target.Region, err)
+			continue
+		}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			result.Value, result.Err = runAWSFanOutTarget(ctx, e, run, &result.Target, fn)
+		})
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// AWSFanOutErrors returns the errors of results joined, or nil when all targets succeeded.
+func AWSFanOutErrors[T any](results []AWSFanOutResult[T]) error {
+	var errs []error
+	for i := range results {
+		if results[i].Err != nil {
+			errs = append(errs, results[i].Err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runAWSFanOutTarget builds the config of target, looks up its account name and calls fn.
+func runAWSFanOutTarget[T any](ctx context.Context, e *AWSFanOutExecutor, run uint64, target *AWSFanOutTarget, fn func(ctx context.Context, target *AWSFanOutTarget) (T, error)) (T, error) {
+	var zero T
+	cfg, err := e.configs.Get(target.RoleARN, target.Region)
+	if err != nil {
+		return zero, fmt.Errorf("fan-out %s %s: %w", target.RoleARN, target.Region, err)
+	}
+	target.Config = cfg
+	if !e.config.SkipAccountNames {
+		target.AccountName = e.accountName(ctx, run, target)
+	}
+	value, err := fn(ctx, target)
+	if err != nil {
+		return value, fmt.Errorf("fan-out %s %s: %w", target.RoleARN, target.Region, err)
+	}
+	return value, nil
+}
+
+// accountName returns the name of the account of target. A name is looked up
+// once per role; a failed lookup is not retried until the next run.
+func (e *AWSFanOutExecutor) accountName(ctx context.Context, run uint64, target *AWSFanOutTarget) string {
+	e.mu.Lock()
+	name, ok := e.names[target.RoleARN]
+	if !ok {
+		name = &awsFanOutAccountName{}
+		e.names[target.RoleARN] = name
+	}
+	e.mu.Unlock()
+
+	// Concurrent regions of an account wait for the same lookup.
+	name.mu.Lock()
+	defer name.mu.Unlock()
+	if name.found || name.failedRun == run {
+		return name.name
+	}
+	out, err := e.config.NewAccountRepository(&target.Config).GetAccountInformation(ctx, "")
+	if err != nil {
+		name.failedRun = run
+		return ""
+	}
+	name.name = aws.ToString(out.AccountName)
+	name.found = true
+	return name.name
+}
+
+// awsFanOutAccountName is the account name of a role. Only successful lookups are cached.
+type awsFanOutAccountName struct {
+	mu        sync.Mutex
+	name      string
+	found     bool
+	failedRun uint64
+}
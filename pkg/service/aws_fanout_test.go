@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/account"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockAWSConfigProvider is a mock implementation of AWSConfigProvider for testing
+type MockAWSConfigProvider struct {
+	mock.Mock
+}
+
+func (m *MockAWSConfigProvider) Get(roleARN, region string) (aws.Config, error) {
+	args := m.Called(roleARN, region)
+	return args.Get(0).(aws.Config), args.Error(1)
+}
+
+// MockAWSAccountInformationGetter is a mock implementation of AWSAccountInformationGetter for testing
+type MockAWSAccountInformationGetter struct {
+	mock.Mock
+}
+
+func (m *MockAWSAccountInformationGetter) GetAccountInformation(ctx context.Context, accountID string) (*account.GetAccountInformationOutput, error) {
+	args := m.Called(ctx, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*account.GetAccountInformationOutput), args.Error(1)
+}
+
+const (
+	testFanOutRoleProd    = "arn:aws:iam::111111111111:role/audit"
+	testFanOutRoleStaging = "arn:aws:iam::222222222222:role/audit"
+)
+
+func TestRunAWSFanOut(t *testing.T) {
+	configs := &MockAWSConfigProvider{}
+	// AppID marks the config with its role so that fn can check it.
+	for _, role := range []string{testFanOutRoleProd, testFanOutRoleStaging} {
+		for _, region := range []string{"us-east-1", "eu-west-1"} {
+			configs.On("Get", role, region).Return(aws.Config{Region: region, AppID: role}, nil).Once()
+		}
+	}
+	accounts := &MockAWSAccountInformationGetter{}
+	var lookups sync.Map
+	executor := NewAWSFanOutExecutor(configs, &AWSFanOutConfig{
+		Concurrency: 2,
+		NewAccountRepository: func(cfg *aws.Config) AWSAccountInformationGetter {
+			count, _ := lookups.LoadOrStore(cfg.AppID, &atomic.Int32{})
+			count.(*atomic.Int32).Add(1)
+			return accounts
+		},
+	})
+	accounts.On("GetAccountInformation", mock.Anything, "").
+		Return(&account.GetAccountInformationOutput{AccountName: aws.String("workload")}, nil)
+
+	var running, maxRunning atomic.Int32
+	results, err := RunAWSFanOut(context.Background(), executor, []string{testFanOutRoleProd, testFanOutRoleStaging}, []string{"us-east-1", "eu-west-1"},
+		func(_ context.Context, target *AWSFanOutTarget) (string, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			if target.Config.AppID != target.RoleARN || target.Config.Region != target.Region {
+				return "", errors.New("unexpected config")
+			}
+			if target.AccountID == "222222222222" && target.Region == "eu-west-1" {
+				return "", errors.New("access denied")
+			}
+			return target.AccountID + "/" + target.Region, nil
+		})
+
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	assert.Equal(t, "111111111111/us-east-1", results[0].Value)
+	assert.Equal(t, "111111111111/eu-west-1", results[1].Value)
+	assert.Equal(t, "222222222222/us-east-1", results[2].Value)
+	require.Error(t, results[3].Err)
+	assert.Contains(t, results[3].Err.Error(), "access denied")
+	assert.Equal(t, AWSFanOutTarget{
+		Config:      aws.Config{Region: "us-east-1", AppID: testFanOutRoleProd},
+		RoleARN:     testFanOutRoleProd,
+		AccountID:   "111111111111",
+		AccountName: "workload",
+		Region:      "us-east-1",
+	}, results[0].Target)
+	assert.LessOrEqual(t, maxRunning.Load(), int32(2))
+
+	// Account names are looked up once per role.
+	lookups.Range(func(_, count any) bool {
+		assert.Equal(t, int32(1), count.(*atomic.Int32).Load())
+		return true
+	})
+
+	err = AWSFanOutErrors(results)
+	require.Error(t, err)
+	assert.Equal(t, 1, strings.Count(err.Error(), "fan-out"))
+	configs.AssertExpectations(t)
+}
+
+func TestRunAWSFanOut_AccountNameRetried(t *testing.T) {
+	configs := &MockAWSConfigProvider{}
+	configs.On("Get", testFanOutRoleProd, mock.Anything).Return(aws.Config{}, nil)
+	accounts := &MockAWSAccountInformationGetter{}
+	accounts.On("GetAccountInformation", mock.Anything, "").Return(nil, errors.New("throttled")).Once()
+	accounts.On("GetAccountInformation", mock.Anything, "").
+		Return(&account.GetAccountInformationOutput{AccountName: aws.String("workload")}, nil).Once()
+	executor := NewAWSFanOutExecutor(configs, &AWSFanOutConfig{
+		NewAccountRepository: func(*aws.Config) AWSAccountInformationGetter { return accounts },
+	})
+	fn := func(_ context.Context, target *AWSFanOutTarget) (string, error) { return target.AccountName, nil }
+	regions := []string{"us-east-1", "eu-west-1"}
+
+	// A failed lookup isn't retried by the other regions of the run.
+	results, err := RunAWSFanOut(context.Background(), executor, []string{testFanOutRoleProd}, regions, fn)
+	require.NoError(t, err)
+	assert.Empty(t, results[0].Value)
+	assert.Empty(t, results[1].Value)
+
+	// The next run looks it up again and caches the name.
+	for range 2 {
+		results, err = RunAWSFanOut(context.Background(), executor, []string{testFanOutRoleProd}, regions, fn)
+		require.NoError(t, err)
+		assert.Equal(t, "workload", results[0].Value)
+		assert.Equal(t, "workload", results[1].Value)
+	}
+	accounts.AssertExpectations(t)
+}
+
+func TestRunAWSFanOut_ConfigError(t *testing.T) {
+	configs := &MockAWSConfigProvider{}
+	configs.On("Get", "invalid", "us-east-1").Return(aws.Config{}, errors.New("invalid IAM role ARN"))
+	executor := NewAWSFanOutExecutor(configs, &AWSFanOutConfig{SkipAccountNames: true})
+
+	results, err := RunAWSFanOut(context.Background(), executor, []string{"invalid"}, []string{"us-east-1"},
+		func(context.Context, *AWSFanOutTarget) (int, error) {
+			t.Fatal("fn must not be called without a config")
+			return 0, nil
+		})
+
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Error(t, results[0].Err)
+	assert.Contains(t, results[0].Err.Error(), "invalid IAM role ARN")
+	assert.Empty(t, results[0].Target.AccountID)
+}
+
+func TestRunAWSFanOut_ContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	executor := NewAWSFanOutExecutor(&MockAWSConfigProvider{}, &AWSFanOutConfig{Concurrency: 1})
+
+	results, err := RunAWSFanOut(ctx, executor, []string{testFanOutRoleProd}, []string{"us-east-1", "eu-west-1"},
+		func(context.Context, *AWSFanOutTarget) (int, error) { return 1, nil })
+
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	for _, result := range results {
+		require.ErrorIs(t, result.Err, context.Canceled)
+	}
+}
+
+func TestRunAWSFanOut_NoTarget(t *testing.T) {
+	executor := NewAWSFanOutExecutor(&MockAWSConfigProvider{})
+
+	_, err := RunAWSFanOut(context.Background(), executor, nil, []string{"us-east-1"},
+		func(context.Context, *AWSFanOutTarget) (int, error) { return 1, nil })
+
+	require.ErrorIs(t, err, ErrAWSFanOutNoTarget)
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	model "github.com/y-miyazaki/go-common/pkg/model/gorm"
+	"github.com/y-miyazaki/go-common/pkg/repository"
+)
+
+const (
+	defaultEmailOutboxPollInterval   = 5 * time.Second
+	defaultEmailOutboxLease          = 5 * time.Minute
+	defaultEmailOutboxRetryBaseDelay = 30 * time.Second
+	defaultEmailOutboxRetryMaxDelay  = time.Hour
+	defaultEmailOutboxBatchSize      = 20
+)
+
+// EmailOutboxSender sends the emails of outbox jobs. *repository.AWSSESRepository implements it.
+type EmailOutboxSender interface {
+	// SendMessage sends an email.
+	SendMessage(ctx context.Context, email *repository.AWSSESEmail) (*sesv2.SendEmailOutput, error)
+	// SendTemplatedEmail sends an email rendered from a template.
+	SendTemplatedEmail(ctx context.Context, email *repository.AWSSESTemplatedEmail) (*sesv2.SendEmailOutput, error)
+}
+
+// EmailOutboxWorkerConfig sets configurations.
+type EmailOutboxWorkerConfig struct {
+	// OnError is called with errors of deliveries and of the outbox table. job is nil for errors of claiming jobs.
+	OnError func(job *model.EmailOutbox, err error)
+	// PollInterval is the wait between polls when no job is due. Default: 5s.
+	PollInterval time.Duration
+	// Lease is how long a claimed job is reserved for the worker. It must be longer than a delivery. Default: 5m.
+	Lease time.Duration
+	// RetryBaseDelay is the delay before the first retry, doubled for each attempt. Default: 30s.
+	RetryBaseDelay time.Duration
+	// RetryMaxDelay is the maximum delay between retries. Default: 1h.
+	RetryMaxDelay time.Duration
+	// BatchSize is the number of jobs claimed at once. Default: 20.
+	BatchSize int
+}
+
+// EmailOutboxWorker delivers the jobs of the email outbox with retries.
+// Delivery is at least once: when the worker stops after SES accepted an email
+// but before the job is marked as sent, the email is sent again after the lease.
+// Several workers can run concurrently.
+type EmailOutboxWorker struct {
+	outbox *repository.EmailOutboxRepository
+	sender EmailOutboxSender
+	config EmailOutboxWorkerConfig
+}
+
+// NewEmailOutboxWorker returns EmailOutboxWorker instance.
+func NewEmailOutboxWorker(outbox *repository.EmailOutboxRepository, sender EmailOutboxSender, cfgs ...*EmailOutboxWorkerConfig) *EmailOutboxWorker {
+	conf := EmailOutboxWorkerConfig{}
+	if len(cfgs) > 0 && cfgs[0] != nil {
+		conf = *cfgs[0]
+	}
+	if conf.PollInterval <= 0 {
+		conf.PollInterval = defaultEmailOutboxPollInterval
+	}
+	if conf.Lease <= 0 {
+		conf.Lease = defaultEmailOutboxLease
+	}
+	if conf.RetryBaseDelay <= 0 {
+		conf.RetryBaseDelay = defaultEmailOutboxRetryBaseDelay
+	}
+	if conf.RetryMaxDelay <= 0 {
+		conf.RetryMaxDelay = defaultEmailOutboxRetryMaxDelay
+	}
+	if conf.BatchSize <= 0 {
+		conf.BatchSize = defaultEmailOutboxBatchSize
+	}
+	return &EmailOutboxWorker{outbox: outbox, sender: sender, config: conf}
+}
+
+// Run delivers jobs until ctx is done. Errors are reported to OnError and don't stop the worker.
+func (w *EmailOutboxWorker) Run(ctx context.Context) {
+	for {
+		n, err := w.ProcessBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			w.onError(nil, err)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		// A full batch means more jobs may be due, so they are claimed without waiting.
+		if err == nil && n == w.config.BatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.config.PollInterval):
+		}
+	}
+}
+
+// ProcessBatch claims due jobs and delivers them. It returns the number of claimed jobs.
+// Delivery errors are recorded in the jobs and reported to OnError.
+func (w *EmailOutboxWorker) ProcessBatch(ctx context.Context) (int, error) {
+	jobs, err := w.outbox.Claim(ctx, w.config.BatchSize, w.config.Lease)
+	for _, job := range jobs {
+		if err := w.deliver(ctx, job); err != nil {
+			w.onError(job, err)
+		}
+	}
+	return len(jobs), err
+}
+
+// deliver sends the email of job and records the result.
+func (w *EmailOutboxWorker) deliver(ctx context.Context, job *model.EmailOutbox) error {
+	// The result is recorded even when ctx is canceled during the delivery.
+	recordCtx := context.WithoutCancel(ctx)
+
+	message, err := repository.UnmarshalEmailOutboxMessage(job.Payload)
+	if err != nil {
+		return errors.Join(err, w.outbox.MarkFailed(recordCtx, job, err))
+	}
+	var out *sesv2.SendEmailOutput
+	if message.Email != nil {
+		out, err = w.sender.SendMessage(ctx, message.Email)
+	} else {
+		out, err = w.sender.SendTemplatedEmail(ctx, message.Templated)
+	}
+	if err == nil {
+		return w.outbox.MarkSent(recordCtx, job, aws.ToString(out.MessageId))
+	}
+	if repository.IsAWSSESPermanentError(err) || job.Attempts >= job.MaxAttempts {
+		return errors.Join(err, w.outbox.MarkFailed(recordCtx, job, err))
+	}
+	return errors.Join(err, w.outbox.MarkRetry(recordCtx, job, err, time.Now().Add(w.retryDelay(job.Attempts))))
+}
+
+// retryDelay returns the delay before the retry after attempts.
+func (w *EmailOutboxWorker) retryDelay(attempts int) time.Duration {
+	delay := w.config.RetryBaseDelay
+	for i := 1; i < attempts && delay < w.config.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, w.config.RetryMaxDelay)
+}
+
+// onError reports err to OnError when it's set.
+func (w *EmailOutboxWorker) onError(job *model.EmailOutbox, err error) {
+	if w.config.OnError != nil {
+		w.config.OnError(job, err)
+	}
+}
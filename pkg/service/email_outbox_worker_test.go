@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	model "github.com/y-miyazaki/go-common/pkg/model/gorm"
+	"github.com/y-miyazaki/go-common/pkg/repository"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// MockEmailOutboxSender is a mock implementation of EmailOutboxSender for testing
+type MockEmailOutboxSender struct {
+	mock.Mock
+}
+
+func (m *MockEmailOutboxSender) SendMessage(ctx context.Context, email *repository.AWSSESEmail) (*sesv2.SendEmailOutput, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sesv2.SendEmailOutput), args.Error(1)
+}
+
+func (m *MockEmailOutboxSender) SendTemplatedEmail(ctx context.Context, email *repository.AWSSESTemplatedEmail) (*sesv2.SendEmailOutput, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sesv2.SendEmailOutput), args.Error(1)
+}
+
+// newTestEmailOutboxWorker returns a worker whose outbox is backed by sqlmock.
+func newTestEmailOutboxWorker(t *testing.T, sender EmailOutboxSender, cfg *EmailOutboxWorkerConfig) (*EmailOutboxWorker, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	require.NoError(t, err)
+	return NewEmailOutboxWorker(repository.NewEmailOutboxRepository(gormDB), sender, cfg), sqlMock
+}
+
+// expectEmailOutboxClaim expects a job with payload to be claimed for its attempt.
+func expectEmailOutboxClaim(sqlMock sqlmock.Sqlmock, payload string, attempt, maxAttempts int) {
+	sqlMock.ExpectQuery(`SELECT \* FROM "email_outbox"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "idempotency_key", "status", "payload", "attempts", "max_attempts"}).
+			AddRow(1, "key", model.EmailOutboxStatusPending, payload, attempt-1, maxAttempts))
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(`UPDATE "email_outbox" SET`).WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectCommit()
+}
+
+// expectEmailOutboxMark expects the claimed job to be updated with status.
+func expectEmailOutboxMark(sqlMock sqlmock.Sqlmock, status model.EmailOutboxStatus) {
+	// The updated columns are sorted by name and followed by id, status and attempts of the claim.
+	n := map[model.EmailOutboxStatus]int{
+		model.EmailOutboxStatusSent:    9, // last_error, locked_until, message_id, sent_at, status, updated_at
+		model.EmailOutboxStatusPending: 8, // last_error, locked_until, next_attempt_at, status, updated_at
+		model.EmailOutboxStatusFailed:  7, // last_error, locked_until, status, updated_at
+	}[status]
+	args := make([]driver.Value, n)
+	for i := range args {
+		args[i] = sqlmock.AnyArg()
+	}
+	args[n-5] = status
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(`UPDATE "email_outbox" SET`).WithArgs(args...).WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectCommit()
+}
+
+const testEmailOutboxPayload = `{"email": {"from": "from@example.com", "subject": "Hello", "text": "Hi", "destination": {"to": ["to@example.com"]}}}`
+
+func TestEmailOutboxWorker_ProcessBatch(t *testing.T) {
+	tests := []struct {
+		sendErr    error
+		name       string
+		payload    string
+		wantStatus model.EmailOutboxStatus
+		attempt    int
+		wantSend   bool
+		wantErr    bool
+	}{
+		{
+			name:       "sent",
+			payload:    testEmailOutboxPayload,
+			attempt:    1,
+			wantSend:   true,
+			wantStatus: model.EmailOutboxStatusSent,
+		},
+		{
+			name:       "retried after a transient error",
+			payload:    testEmailOutboxPayload,
+			attempt:    1,
+			wantSend:   true,
+			sendErr:    &types.TooManyRequestsException{Message: aws.String("throttled")},
+			wantStatus: model.EmailOutboxStatusPending,
+			wantErr:    true,
+		},
+		{
+			name:       "failed after a permanent error",
+			payload:    testEmailOutboxPayload,
+			attempt:    1,
+			wantSend:   true,
+			sendErr:    &types.MessageRejected{Message: aws.String("rejected")},
+			wantStatus: model.EmailOutboxStatusFailed,
+			wantErr:    true,
+		},
+		{
+			name:       "failed after the last attempt",
+			payload:    testEmailOutboxPayload,
+			attempt:    3,
+			wantSend:   true,
+			sendErr:    errors.New("connection reset"),
+			wantStatus: model.EmailOutboxStatusFailed,
+			wantErr:    true,
+		},
+		{
+			name:       "failed with an invalid payload",
+			payload:    `{}`,
+			attempt:    1,
+			wantStatus: model.EmailOutboxStatusFailed,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := &MockEmailOutboxSender{}
+			var gotErrs []error
+			worker, sqlMock := newTestEmailOutboxWorker(t, sender, &EmailOutboxWorkerConfig{
+				OnError: func(job *model.EmailOutbox, err error) {
+					assert.NotNil(t, job)
+					gotErrs = append(gotErrs, err)
+				},
+			})
+			if tt.wantSend {
+				if tt.sendErr != nil {
+					sender.On("SendMessage", mock.Anything, mock.Anything).Return(nil, tt.sendErr).Once()
+				} else {
+					sender.On("SendMessage", mock.Anything, mock.MatchedBy(func(email *repository.AWSSESEmail) bool {
+						return email.Subject == "Hello" && email.Destination.To[0] == "to@example.com"
+					})).Return(&sesv2.SendEmailOutput{MessageId: aws.String("message-id")}, nil).Once()
+				}
+			}
+			expectEmailOutboxClaim(sqlMock, tt.payload, tt.attempt, 3)
+			expectEmailOutboxMark(sqlMock, tt.wantStatus)
+
+			n, err := worker.ProcessBatch(context.Background())
+
+			require.NoError(t, err)
+			assert.Equal(t, 1, n)
+			assert.Equal(t, tt.wantErr, len(gotErrs) == 1)
+			sender.AssertExpectations(t)
+			assert.NoError(t, sqlMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestEmailOutboxWorker_ProcessBatch_Templated(t *testing.T) {
+	sender := &MockEmailOutboxSender{}
+	worker, sqlMock := newTestEmailOutboxWorker(t, sender, nil)
+
+	sender.On("SendTemplatedEmail", mock.Anything, mock.MatchedBy(func(email *repository.AWSSESTemplatedEmail) bool {
+		return email.TemplateName == "welcome"
+	})).Return(&sesv2.SendEmailOutput{MessageId: aws.String("message-id")}, nil).Once()
+	expectEmailOutboxClaim(sqlMock, `{"templated": {"from": "from@example.com", "templateName": "welcome"}}`, 1, 8)
+	expectEmailOutboxMark(sqlMock, model.EmailOutboxStatusSent)
+
+	n, err := worker.ProcessBatch(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	sender.AssertExpectations(t)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestEmailOutboxWorker_ProcessBatch_ClaimError(t *testing.T) {
+	worker, sqlMock := newTestEmailOutboxWorker(t, &MockEmailOutboxSender{}, nil)
+
+	sqlMock.ExpectQuery(`SELECT \* FROM "email_outbox"`).WillReturnError(errors.New("connection refused"))
+
+	n, err := worker.ProcessBatch(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "email outbox claim")
+	assert.Equal(t, 0, n)
+}
+
+func TestEmailOutboxWorker_Run(t *testing.T) {
+	worker, sqlMock := newTestEmailOutboxWorker(t, &MockEmailOutboxSender{}, &EmailOutboxWorkerConfig{PollInterval: time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	sqlMock.ExpectQuery(`SELECT \* FROM "email_outbox"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	sqlMock.ExpectQuery(`SELECT \* FROM "email_outbox"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	// The context is canceled after the worker polled twice.
+	go func() {
+		for sqlMock.ExpectationsWereMet() != nil {
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		worker.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after the context was canceled")
+	}
+}
+
+func TestEmailOutboxWorker_retryDelay(t *testing.T) {
+	worker := NewEmailOutboxWorker(nil, nil, &EmailOutboxWorkerConfig{RetryBaseDelay: time.Second, RetryMaxDelay: 10 * time.Second})
+
+	assert.Equal(t, time.Second, worker.retryDelay(1))
+	assert.Equal(t, 2*time.Second, worker.retryDelay(2))
+	assert.Equal(t, 8*time.Second, worker.retryDelay(4))
+	assert.Equal(t, 10*time.Second, worker.retryDelay(5))
+	assert.Equal(t, 10*time.Second, worker.retryDelay(100))
+}
@@ -0,0 +1,162 @@
+// Package awserror classifies errors of AWS SDK calls into domain categories.
+package awserror
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go"
+)
+
+// Domain errors. Repositories and services can return them directly, and the
+// Is* functions below also classify AWS SDK errors into the same categories,
+// so callers can branch on the category without inspecting error codes.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrThrottled    = errors.New("throttled")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrAccessDenied = errors.New("access denied")
+	ErrConflict     = errors.New("conflict")
+)
+
+// notFoundErrorCodes are error codes of AWS services meaning a missing resource.
+var notFoundErrorCodes = map[string]struct{}{
+	"NotFound":                  {},
+	"NotFoundException":         {},
+	"NoSuchKey":                 {},
+	"NoSuchBucket":              {},
+	"NoSuchUpload":              {},
+	"NoSuchEntity":              {},
+	"ResourceNotFoundException": {},
+	"AccountNotFoundException":  {},
+}
+
+// unauthorizedErrorCodes are error codes of AWS services meaning the end user
+// failed to authenticate, e.g. a wrong password or an invalid token of Cognito.
+// A missing Cognito user is included so that responses don't reveal which users exist.
+var unauthorizedErrorCodes = map[string]struct{}{
+	"NotAuthorizedException": {},
+	"UserNotFoundException":  {},
+}
+
+// accessDeniedErrorCodes are error codes of AWS services meaning missing permissions or invalid credentials.
+var accessDeniedErrorCodes = map[string]struct{}{
+	"AccessDenied":                {},
+	"AccessDeniedException":       {},
+	"UnauthorizedOperation":       {},
+	"ExpiredToken":                {},
+	"ExpiredTokenException":       {},
+	"InvalidClientTokenId":        {},
+	"UnrecognizedClientException": {},
+	"SignatureDoesNotMatch":       {},
+}
+
+// conflictErrorCodes are error codes of AWS services meaning the resource already exists or is in use.
+var conflictErrorCodes = map[string]struct{}{
+	"ConflictException":               {},
+	"AlreadyExistsException":          {},
+	"ResourceExistsException":         {},
+	"ResourceAlreadyExistsException":  {},
+	"ResourceInUseException":          {},
+	"BucketAlreadyExists":             {},
+	"BucketAlreadyOwnedByYou":         {},
+	"UsernameExistsException":         {},
+	"AliasExistsException":            {},
+	"OperationAbortedException":       {},
+	"ConcurrentModificationException": {},
+}
+
+// IsNotFound reports whether err means the requested resource doesn't exist.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound) ||
+		hasErrorCode(err, notFoundErrorCodes) ||
+		httpStatusCode(err) == http.StatusNotFound
+}
+
+// IsThrottled reports whether err is caused by throttling or exceeded request rate.
+func IsThrottled(err error) bool {
+	if errors.Is(err, ErrThrottled) {
+		return true
+	}
+	return retry.IsErrorThrottles(retry.DefaultThrottles).IsErrorThrottle(err) == aws.TrueTernary
+}
+
+// IsUnauthorized reports whether err means the end user failed to authenticate.
+// Invalid AWS credentials of the caller are classified by IsAccessDenied instead.
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized) || hasErrorCode(err, unauthorizedErrorCodes)
+}
+
+// IsAccessDenied reports whether err means missing permissions or invalid credentials.
+func IsAccessDenied(err error) bool {
+	return errors.Is(err, ErrAccessDenied) ||
+		hasErrorCode(err, accessDeniedErrorCodes) ||
+		httpStatusCode(err) == http.StatusForbidden
+}
+
+// IsConflict reports whether err means the resource already exists or is being modified.
+func IsConflict(err error) bool {
+	status := httpStatusCode(err)
+	return errors.Is(err, ErrConflict) ||
+		hasErrorCode(err, conflictErrorCodes) ||
+		status == http.StatusConflict ||
+		status == http.StatusPreconditionFailed
+}
+
+// IsRetryable reports whether the operation that returned err may succeed
+// if retried, e.g. on throttling, timeouts, connection errors and 5xx responses.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if IsThrottled(err) {
+		return true
+	}
+	return retry.IsErrorRetryables(retry.DefaultRetryables).IsErrorRetryable(err) == aws.TrueTernary
+}
+
+// HTTPStatus maps err to an HTTP status code by its category.
+// Unauthorized errors take precedence over the others, so that a failed
+// authentication is never reported as 404 Not Found.
+// Unclassified errors are 500 Internal Server Error.
+func HTTPStatus(err error) int {
+	switch {
+	case err == nil:
+		return http.StatusOK
+	case IsUnauthorized(err):
+		return http.StatusUnauthorized
+	case IsNotFound(err):
+		return http.StatusNotFound
+	case IsAccessDenied(err):
+		return http.StatusForbidden
+	case IsConflict(err):
+		return http.StatusConflict
+	case IsThrottled(err):
+		return http.StatusTooManyRequests
+	case IsRetryable(err):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// hasErrorCode reports whether err is a smithy.APIError with one of codes.
+func hasErrorCode(err error, codes map[string]struct{}) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	_, ok := codes[apiErr.ErrorCode()]
+	return ok
+}
+
+// httpStatusCode returns the HTTP status code of an AWS response error, or 0.
+func httpStatusCode(err error) int {
+	var resErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &resErr) {
+		return resErr.HTTPStatusCode()
+	}
+	return 0
+}
@@ -0,0 +1,67 @@
+package awserror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestResponseError(status int, err error) error {
+	return &awshttp.ResponseError{
+		ResponseError: &smithyhttp.ResponseError{
+			Response: &smithyhttp.Response{Response: &http.Response{StatusCode: status}},
+			Err:      err,
+		},
+	}
+}
+
+func TestClassification(t *testing.T) {
+	tests := []struct {
+		err          error
+		name         string
+		status       int
+		notFound     bool
+		throttled    bool
+		unauthorized bool
+		accessDenied bool
+		conflict     bool
+		retryable    bool
+	}{
+		{name: "nil", err: nil, status: http.StatusOK},
+		{name: "plain error", err: errors.New("boom"), status: http.StatusInternalServerError},
+		{name: "domain not found", err: fmt.Errorf("service Get: %w", ErrNotFound), status: http.StatusNotFound, notFound: true},
+		{name: "domain throttled", err: ErrThrottled, status: http.StatusTooManyRequests, throttled: true, retryable: true},
+		{name: "domain unauthorized", err: ErrUnauthorized, status: http.StatusUnauthorized, unauthorized: true},
+		{name: "domain access denied", err: ErrAccessDenied, status: http.StatusForbidden, accessDenied: true},
+		{name: "domain conflict", err: ErrConflict, status: http.StatusConflict, conflict: true},
+		{name: "s3 no such key", err: fmt.Errorf("s3 GetObject: %w", &s3types.NoSuchKey{}), status: http.StatusNotFound, notFound: true},
+		{name: "throttling exception", err: &smithy.GenericAPIError{Code: "ThrottlingException"}, status: http.StatusTooManyRequests, throttled: true, retryable: true},
+		{name: "not authorized exception", err: &smithy.GenericAPIError{Code: "NotAuthorizedException"}, status: http.StatusUnauthorized, unauthorized: true},
+		{name: "user not found exception", err: &smithy.GenericAPIError{Code: "UserNotFoundException"}, status: http.StatusUnauthorized, unauthorized: true},
+		{name: "unauthorized and not found", err: errors.Join(ErrUnauthorized, ErrNotFound), status: http.StatusUnauthorized, notFound: true, unauthorized: true},
+		{name: "access denied exception", err: &smithy.GenericAPIError{Code: "AccessDeniedException"}, status: http.StatusForbidden, accessDenied: true},
+		{name: "expired token", err: &smithy.GenericAPIError{Code: "ExpiredToken"}, status: http.StatusForbidden, accessDenied: true},
+		{name: "http 404", err: newTestResponseError(http.StatusNotFound, errors.New("not found")), status: http.StatusNotFound, notFound: true},
+		{name: "http 403", err: newTestResponseError(http.StatusForbidden, errors.New("forbidden")), status: http.StatusForbidden, accessDenied: true},
+		{name: "http 409", err: newTestResponseError(http.StatusConflict, errors.New("conflict")), status: http.StatusConflict, conflict: true},
+		{name: "http 503", err: newTestResponseError(http.StatusServiceUnavailable, errors.New("unavailable")), status: http.StatusServiceUnavailable, retryable: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.notFound, IsNotFound(tt.err), "IsNotFound")
+			assert.Equal(t, tt.throttled, IsThrottled(tt.err), "IsThrottled")
+			assert.Equal(t, tt.unauthorized, IsUnauthorized(tt.err), "IsUnauthorized")
+			assert.Equal(t, tt.accessDenied, IsAccessDenied(tt.err), "IsAccessDenied")
+			assert.Equal(t, tt.conflict, IsConflict(tt.err), "IsConflict")
+			assert.Equal(t, tt.retryable, IsRetryable(tt.err), "IsRetryable")
+			assert.Equal(t, tt.status, HTTPStatus(tt.err), "HTTPStatus")
+		})
+	}
+}